@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/Giri-Aayush/starknet-faucet/internal/api"
@@ -25,7 +28,14 @@ func main() {
 	}
 
 	// Initialize logger
-	logger, err := utils.NewLogger(cfg.LogLevel)
+	logger, err := utils.NewLoggerWithOptions(utils.LoggerOptions{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		File:       cfg.LogFile,
+		MaxSizeMB:  cfg.LogMaxSizeMB,
+		MaxBackups: cfg.LogMaxBackups,
+		MaxAgeDays: cfg.LogMaxAgeDays,
+	})
 	if err != nil {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
@@ -35,6 +45,13 @@ func main() {
 		zap.String("network", cfg.Network),
 		zap.String("port", cfg.Port),
 	)
+	if cfg.UsesPublicDevnetKey() {
+		logger.Warn("Using the public devnet account key. Anyone can spend from this account, never use it outside a local devnet.",
+			zap.String("faucet_address", cfg.FaucetAddress),
+		)
+	}
+
+	logProtections(logger, cfg)
 
 	// Initialize Redis
 	logger.Info("Connecting to Redis...")
@@ -47,27 +64,72 @@ func main() {
 		logger.Fatal("Failed to connect to Redis", zap.Error(err))
 	}
 	defer redis.Close()
+	if err := redis.SetDailyResetHour(cfg.DailyResetHour); err != nil {
+		logger.Fatal("Invalid daily reset hour", zap.Error(err))
+	}
 	logger.Info("Connected to Redis",
 		zap.Int("max_requests_per_day_ip", cfg.MaxRequestsPerDayIP),
 		zap.Int("max_challenges_per_hour", cfg.MaxChallengesPerHour),
+		zap.Int("daily_reset_hour", cfg.DailyResetHour),
 	)
 
 	// Initialize Starknet client
 	logger.Info("Initializing Starknet client...")
-	starknetClient, err := starknet.NewFaucetClient(
+	accounts := make([]starknet.AccountCredentials, len(cfg.FaucetAccounts))
+	for i, acc := range cfg.FaucetAccounts {
+		accounts[i] = starknet.AccountCredentials{Address: acc.Address, PrivateKey: acc.PrivateKey}
+	}
+	starknetClient, err := starknet.NewMultiAccountFaucetClient(
 		cfg.StarknetRPCURL,
-		cfg.FaucetPrivateKey,
-		cfg.FaucetAddress,
+		accounts,
 		cfg.ETHTokenAddress,
 		cfg.STRKTokenAddress,
+		starknet.TransportConfig{
+			MaxIdleConns:        cfg.RPCMaxIdleConns,
+			MaxIdleConnsPerHost: cfg.RPCMaxIdleConnsPerHost,
+			MaxConnsPerHost:     cfg.RPCMaxConnsPerHost,
+			Headers:             cfg.RPCHeaders,
+			FallbackURLs:        cfg.RPCFallbackURLs,
+		},
 	)
 	if err != nil {
 		logger.Fatal("Failed to create Starknet client", zap.Error(err))
 	}
+	defer starknetClient.Close()
+	starknetClient.SetLogger(logger)
+	if err := starknetClient.SetTxVersion(cfg.TxVersion, cfg.FeeToken); err != nil {
+		logger.Fatal("Invalid transaction settings", zap.Error(err))
+	}
+	// Each account keeps the protected balance, not just their total
+	for symbol, token := range cfg.Tokens {
+		starknetClient.SetBalanceProtection(symbol, starknet.BalanceProtection{
+			Pct:   token.MinBalanceProtectPct,
+			Floor: starknet.AmountToWei(token.MinBalanceFloor),
+		})
+	}
 	logger.Info("Starknet client initialized",
-		zap.String("faucet_address", cfg.FaucetAddress),
+		zap.Strings("faucet_accounts", starknetClient.AccountAddresses()),
+		zap.Int("tx_version", cfg.TxVersion),
+		zap.String("fee_token", cfg.FeeToken),
+		zap.Int("rpc_endpoints", len(cfg.RPCFallbackURLs)+1),
+		zap.Strings("rpc_headers", cfg.RPCHeaderNames()), // Names only, values are secret
 	)
 
+	// Confirmations arrive over the websocket when one is configured
+	if cfg.StarknetWSURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.RPCTimeout)*time.Second)
+		err := starknetClient.ConnectWebsocket(ctx, cfg.StarknetWSURL)
+		cancel()
+		if err != nil {
+			logger.Warn("Websocket RPC unavailable, polling for receipts instead", zap.Error(err))
+		} else {
+			logger.Info("Websocket RPC connected, waiting for confirmations by subscription")
+		}
+	}
+
+	// Fail fast if the account isn't deployed or the key doesn't control it
+	selfCheck(logger, cfg, starknetClient)
+
 	// Initialize PoW generator
 	powGenerator := pow.NewGenerator(cfg.PoWDifficulty, cfg.ChallengeTTL)
 	logger.Info("PoW generator initialized",
@@ -77,17 +139,36 @@ func main() {
 	// Create API handler
 	handler := api.NewHandler(cfg, logger, redis, starknetClient, powGenerator)
 
+	// Refuse faucet requests up front while the RPC is unreachable
+	go handler.MonitorRPC(context.Background())
+
+	// Keep the Discord /faucet command in sync with the supported tokens
+	if cfg.DiscordEnabled() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := handler.RegisterDiscordCommand(ctx); err != nil {
+			logger.Warn("Discord command registration failed, keeping the existing command", zap.Error(err))
+		} else {
+			logger.Info("Discord /faucet command registered")
+		}
+		cancel()
+	}
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:               "Starknet Faucet API",
 		DisableStartupMessage: false,
+		ReadTimeout:           time.Duration(cfg.ReadTimeout) * time.Second,
+		WriteTimeout:          time.Duration(cfg.WriteTimeout) * time.Second,
+		IdleTimeout:           time.Duration(cfg.IdleTimeout) * time.Second,
+		Concurrency:           cfg.MaxConcurrency,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
 				code = e.Code
 			}
 			return c.Status(code).JSON(fiber.Map{
-				"error": err.Error(),
+				"error":      err.Error(),
+				"request_id": c.GetRespHeader(fiber.HeaderXRequestID),
 			})
 		},
 	})
@@ -98,7 +179,13 @@ func main() {
 	// Start server in goroutine
 	go func() {
 		addr := fmt.Sprintf(":%s", cfg.Port)
-		logger.Info("Server starting", zap.String("addr", addr))
+		logger.Info("Server starting",
+			zap.String("addr", addr),
+			zap.Int("read_timeout", cfg.ReadTimeout),
+			zap.Int("write_timeout", cfg.WriteTimeout),
+			zap.Int("idle_timeout", cfg.IdleTimeout),
+			zap.Int("max_concurrency", cfg.MaxConcurrency),
+		)
 		if err := app.Listen(addr); err != nil {
 			logger.Fatal("Server failed to start", zap.Error(err))
 		}
@@ -116,3 +203,73 @@ func main() {
 
 	logger.Info("Server stopped")
 }
+
+// selfCheck verifies the faucet accounts on-chain and logs their combined
+// balances. It exits if an account is missing or its private key doesn't match,
+// so the server never reports healthy while unable to send anything.
+func selfCheck(logger *zap.Logger, cfg *config.Config, client *starknet.FaucetClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.RPCTimeout)*time.Second)
+	defer cancel()
+
+	err := client.VerifyAccount(ctx)
+	switch {
+	case errors.Is(err, starknet.ErrPublicKeyUnavailable):
+		logger.Warn("Faucet account found, but its public key could not be read to verify the private key",
+			zap.Error(err),
+		)
+	case err != nil:
+		logger.Fatal("Faucet account self-check failed", zap.Error(err))
+	default:
+		logger.Info("Faucet accounts verified", zap.Strings("faucet_accounts", client.AccountAddresses()))
+	}
+
+	for _, symbol := range cfg.TokenSymbols() {
+		balance, err := client.FaucetBalance(ctx, symbol)
+		if err != nil {
+			logger.Warn("Failed to fetch faucet balance", zap.String("token", symbol), zap.Error(err))
+			continue
+		}
+		logger.Info("Faucet balance",
+			zap.String("token", symbol),
+			zap.Float64("balance", starknet.WeiToAmount(balance)),
+		)
+	}
+}
+
+// logProtections warns about protections that are off or weak and sums up the
+// active ones in a single line
+func logProtections(logger *zap.Logger, cfg *config.Config) {
+	for _, warning := range cfg.ProtectionWarnings() {
+		logger.Warn(warning)
+	}
+
+	var distribution, balance []string
+	for _, symbol := range cfg.TokenSymbols() {
+		token := cfg.Tokens[symbol]
+		distribution = append(distribution, fmt.Sprintf("%s %s/h %s/day", symbol, limitOrOff(token.MaxPerHour), limitOrOff(token.MaxPerDay)))
+		floor := fmt.Sprintf("%s %d%%", symbol, token.MinBalanceProtectPct)
+		if token.MinBalanceFloor > 0 {
+			floor += fmt.Sprintf(" or %g", token.MinBalanceFloor)
+		}
+		balance = append(balance, floor)
+	}
+
+	logger.Info("Protections",
+		zap.Int("pow_difficulty", cfg.PoWDifficulty),
+		zap.Int("daily_requests_per_ip", cfg.MaxRequestsPerDayIP),
+		zap.Int("distinct_addresses_per_ip", cfg.MaxDistinctAddressesPerDay), // 0 = unlimited
+		zap.Strings("distribution_limits", distribution),
+		zap.Strings("balance_protection", balance),
+		zap.Int("global_rps", cfg.GlobalRPS), // 0 = unlimited
+		zap.Bool("velocity_alerts", cfg.VelocityAlertMultiple > 0),
+		zap.Int("min_solve_seconds", cfg.MinSolveSeconds),
+	)
+}
+
+// limitOrOff formats a distribution limit, where 0 means none
+func limitOrOff(limit float64) string {
+	if limit <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%g", limit)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.uber.org/zap"
+)
+
+// accessLogger logs every request through zap with its route, status,
+// duration and payload sizes, so latency can be aggregated per endpoint.
+// Requests for skipPaths, or paths below them, are not logged.
+func accessLogger(log *zap.Logger, skipPaths []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skipAccessLog(c.Path(), skipPaths) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			// Let the error handler write the response so its status is logged
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// Fiber reuses the buffers behind its strings once the request ends
+		log.Info("Request",
+			zap.String("request_id", utils.CopyString(requestID(c))),
+			zap.String("method", utils.CopyString(c.Method())),
+			zap.String("path", utils.CopyString(c.Path())),
+			zap.String("route", c.Route().Path), // e.g. /api/v1/status/:address, for grouping
+			zap.Int("status", c.Response().StatusCode()),
+			zap.Duration("duration", time.Since(start)),
+			zap.Int("bytes_in", len(c.Request().Body())),
+			zap.Int("bytes_out", len(c.Response().Body())),
+			zap.String("ip", utils.CopyString(c.IP())),
+		)
+		return nil
+	}
+}
+
+// skipAccessLog reports whether path is one of skipPaths or below one
+func skipAccessLog(path string, skipPaths []string) bool {
+	for _, skip := range skipPaths {
+		skip = strings.TrimSuffix(skip, "/")
+		if path == skip || strings.HasPrefix(path, skip+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	app := fiber.New()
+	app.Use(accessLogger(zap.New(core), []string{"/health"}))
+	app.Post("/echo/:name", func(c *fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusBadGateway, "upstream down")
+	})
+	app.Get("/health", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/health/full", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	send := func(method, path, body string) {
+		resp, err := app.Test(httptest.NewRequest(method, path, strings.NewReader(body)))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	send("POST", "/echo/alice", "hello")
+	send("GET", "/fail", "")
+	send("GET", "/health", "")
+	send("GET", "/health/full", "")
+
+	entries := logs.All()
+	require.Len(t, entries, 2, "skipped paths must not be logged")
+
+	echo := entries[0].ContextMap()
+	assert.Equal(t, "POST", echo["method"])
+	assert.Equal(t, "/echo/alice", echo["path"])
+	assert.Equal(t, "/echo/:name", echo["route"])
+	assert.EqualValues(t, fiber.StatusOK, echo["status"])
+	assert.EqualValues(t, 5, echo["bytes_in"])
+	assert.EqualValues(t, 5, echo["bytes_out"])
+	assert.Contains(t, echo, "duration")
+	assert.Contains(t, echo, "ip")
+
+	// Errors are logged with the status the error handler sends
+	assert.EqualValues(t, fiber.StatusBadGateway, entries[1].ContextMap()["status"])
+}
+
+func TestSkipAccessLog(t *testing.T) {
+	skip := []string{"/health", "/metrics/"}
+
+	assert.True(t, skipAccessLog("/health", skip))
+	assert.True(t, skipAccessLog("/health/full", skip))
+	assert.True(t, skipAccessLog("/metrics", skip))
+	assert.True(t, skipAccessLog("/metrics/go", skip))
+	assert.False(t, skipAccessLog("/healthz", skip))
+	assert.False(t, skipAccessLog("/api/v1/info", skip))
+	assert.False(t, skipAccessLog("/health", nil))
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/cache"
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// adminKeyHeader carries ADMIN_API_KEY on admin endpoints
+const adminKeyHeader = "X-Admin-Key"
+
+// Page sizes for GET /admin/requests
+const (
+	defaultAdminPageSize = 50
+	maxAdminPageSize     = 200
+)
+
+// RequireAdmin rejects requests without the admin key with 401
+func (h *Handler) RequireAdmin(c *fiber.Ctx) error {
+	key := c.Get(adminKeyHeader)
+	if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(h.config.AdminAPIKey)) != 1 {
+		return respondError(c, fiber.StatusUnauthorized, models.ErrorResponse{
+			Error: "Invalid or missing admin key.",
+			Code:  models.ErrCodeUnauthorized,
+		})
+	}
+	return c.Next()
+}
+
+// ipHash identifies a client IP in the audit log without storing the IP. It
+// is keyed by AUDIT_IP_SECRET, so hashing every IPv4 address doesn't reveal it.
+func (h *Handler) ipHash(ip string) string {
+	mac := hmac.New(sha256.New, []byte(h.config.AuditIPSecret))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// recordAudit adds the transfers of a request to the audit log. Failures are
+// logged and otherwise ignored, since the tokens have already been sent.
+func (h *Handler) recordAudit(ctx context.Context, log *zap.Logger, reqID, ip, keyID, address string, transactions []models.TransactionInfo) {
+	if h.config.AuditRetentionDays <= 0 {
+		return
+	}
+
+	now := time.Now()
+	retention := time.Duration(h.config.AuditRetentionDays) * 24 * time.Hour
+	for _, tx := range transactions {
+		entry, err := json.Marshal(models.AuditRecord{
+			Timestamp: now.UnixMilli(),
+			RequestID: reqID,
+			Token:     tx.Token,
+			Amount:    tx.Amount,
+			Recipient: address,
+			TxHash:    tx.TxHash,
+			IPHash:    h.ipHash(ip),
+			APIKeyID:  keyID,
+		})
+		if err == nil {
+			err = h.redis.AppendAudit(ctx, now, string(entry), retention)
+		}
+		if err != nil {
+			log.Error("Failed to record audit log entry", zap.Error(err), zap.String("tx_hash", tx.TxHash))
+		}
+	}
+}
+
+// AdminRequests pages through the audit log, newest first. It filters by
+// token, ip (or its ip_hash) and a from/to time range (RFC 3339 or Unix
+// seconds), and returns at most limit records starting after cursor, the
+// position of the previous page's last record. Total counts every record in
+// the time range, before the token and IP filters.
+func (h *Handler) AdminRequests(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx := c.UserContext()
+
+	limit := defaultAdminPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+				Error: fmt.Sprintf("limit must be a positive number (at most %d)", maxAdminPageSize),
+				Code:  models.ErrCodeInvalidRequest,
+			})
+		}
+		limit = min(n, maxAdminPageSize)
+	}
+
+	from, err := parseAuditTime(c.Query("from"))
+	if err != nil {
+		return invalidAuditTime(c, "from")
+	}
+	to, err := parseAuditTime(c.Query("to"))
+	if err != nil {
+		return invalidAuditTime(c, "to")
+	}
+
+	token := strings.ToUpper(c.Query("token"))
+	ipFilter := strings.ToLower(c.Query("ip_hash"))
+	if ip := c.Query("ip"); ip != "" {
+		ipFilter = h.ipHash(ip)
+	}
+
+	total, err := h.redis.CountAuditEntries(ctx, from, to)
+	if err != nil {
+		return auditReadFailure(c, log, err)
+	}
+
+	// Scan from the cursor in pages of limit entries until the page is full
+	// and one more match shows there is a next page
+	var pos auditCursor
+	if !to.IsZero() {
+		pos.at = to.UnixMilli()
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		if pos, err = parseAuditCursor(cursor); err != nil {
+			return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid cursor. Pass the next_cursor of the previous page, or start again without one.",
+				Code:  models.ErrCodeInvalidRequest,
+			})
+		}
+		if !to.IsZero() && pos.at > to.UnixMilli() {
+			pos = auditCursor{at: to.UnixMilli()}
+		}
+	}
+
+	response := models.AdminRequestsResponse{
+		Requests: make([]models.AuditRecord, 0, limit),
+		Total:    total,
+	}
+	var pageEnd auditCursor
+	for {
+		end := to
+		if pos.at != 0 {
+			end = time.UnixMilli(pos.at)
+		}
+		entries, err := h.redis.AuditEntries(ctx, from, end, pos.skip, limit)
+		if err != nil {
+			return auditReadFailure(c, log, err)
+		}
+
+		for _, entry := range entries {
+			pos = pos.advance(entry)
+			record, ok := matchAudit(log, entry.Entry, token, ipFilter)
+			if !ok {
+				continue
+			}
+			if len(response.Requests) == limit {
+				response.NextCursor = pageEnd.String()
+				return c.JSON(response)
+			}
+			response.Requests = append(response.Requests, record)
+			pageEnd = pos
+		}
+		if len(entries) < limit {
+			return c.JSON(response)
+		}
+	}
+}
+
+// auditCursor is a position in the audit log: after the first skip entries
+// recorded at Unix millisecond at. Zero is the newest entry.
+type auditCursor struct {
+	at   int64
+	skip int
+}
+
+// advance returns the position after entry, the entry following p
+func (p auditCursor) advance(entry cache.AuditEntry) auditCursor {
+	if entry.At == p.at {
+		return auditCursor{at: p.at, skip: p.skip + 1}
+	}
+	return auditCursor{at: entry.At, skip: 1}
+}
+
+// String encodes the cursor for next_cursor, as "<at>-<skip>"
+func (p auditCursor) String() string {
+	return fmt.Sprintf("%d-%d", p.at, p.skip)
+}
+
+// parseAuditCursor decodes a next_cursor
+func parseAuditCursor(value string) (auditCursor, error) {
+	atPart, skipPart, ok := strings.Cut(value, "-")
+	if !ok {
+		return auditCursor{}, fmt.Errorf("malformed cursor %q", value)
+	}
+	at, err := strconv.ParseInt(atPart, 10, 64)
+	if err != nil || at <= 0 {
+		return auditCursor{}, fmt.Errorf("malformed cursor %q", value)
+	}
+	skip, err := strconv.Atoi(skipPart)
+	if err != nil || skip < 0 {
+		return auditCursor{}, fmt.Errorf("malformed cursor %q", value)
+	}
+	return auditCursor{at: at, skip: skip}, nil
+}
+
+// matchAudit decodes an audit log entry and reports whether it is for token
+// and ipHash (empty matches any). Undecodable entries are logged and skipped.
+func matchAudit(log *zap.Logger, entry, token, ipHash string) (models.AuditRecord, bool) {
+	var record models.AuditRecord
+	if err := json.Unmarshal([]byte(entry), &record); err != nil {
+		log.Warn("Skipping malformed audit log entry", zap.Error(err))
+		return record, false
+	}
+	return record, (token == "" || record.Token == token) && (ipHash == "" || record.IPHash == ipHash)
+}
+
+// auditReadFailure responds with 500 when the audit log can't be read
+func auditReadFailure(c *fiber.Ctx, log *zap.Logger, err error) error {
+	log.Error("Failed to read audit log", zap.Error(err))
+	return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+		Error: "Failed to read audit log",
+		Code:  models.ErrCodeInternal,
+	})
+}
+
+// parseAuditTime parses an RFC 3339 time or Unix seconds. Empty input gives
+// the zero time, which leaves that end of the range open.
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// invalidAuditTime responds with 400 for an unparseable time filter
+func invalidAuditTime(c *fiber.Ctx, param string) error {
+	return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+		Error: fmt.Sprintf("%s must be an RFC 3339 time or Unix seconds", param),
+		Code:  models.ErrCodeInvalidRequest,
+	})
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+)
+
+const testAdminKey = "admin-key-0123456789"
+
+// getAdminRequests queries the audit log with the admin key and decodes the
+// response into out
+func getAdminRequests(t *testing.T, app *fiber.App, query url.Values, out interface{}) int {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/requests?"+query.Encode(), nil)
+	req.Header.Set(adminKeyHeader, testAdminKey)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	return resp.StatusCode
+}
+
+func TestAdminRequests(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	h.config.AdminAPIKey = testAdminKey
+	h.config.AuditRetentionDays = 7
+	h.config.AuditIPSecret = "audit-secret-0123456789"
+	app := fiber.New()
+	SetupRoutes(app, h)
+	ctx := context.Background()
+
+	// A real faucet request is logged along with seeded transfers
+	challengeID, nonce := requestChallenge(t, app)
+	var faucetResp models.FaucetResponse
+	require.Equal(t, fiber.StatusOK, postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &faucetResp))
+
+	for i := range 4 {
+		token := "STRK"
+		if i%2 == 1 {
+			token = "ETH"
+		}
+		h.recordAudit(ctx, h.logger, fmt.Sprintf("req-%d", i), "203.0.113.7", "", "0x0123", []models.TransactionInfo{
+			{Token: token, Amount: "1", TxHash: fmt.Sprintf("0xseed%d", i)},
+		})
+	}
+
+	t.Run("pages cover every record once", func(t *testing.T) {
+		seen := make(map[string]bool)
+		query := url.Values{"limit": {"2"}}
+		for page := 0; ; page++ {
+			require.Less(t, page, 5)
+			var resp models.AdminRequestsResponse
+			require.Equal(t, fiber.StatusOK, getAdminRequests(t, app, query, &resp))
+			assert.Equal(t, 5, resp.Total)
+			assert.LessOrEqual(t, len(resp.Requests), 2)
+			for _, r := range resp.Requests {
+				assert.False(t, seen[r.TxHash], "record %s repeated", r.TxHash)
+				seen[r.TxHash] = true
+			}
+			if resp.NextCursor == "" {
+				break
+			}
+			query.Set("cursor", resp.NextCursor)
+		}
+		assert.Len(t, seen, 5)
+		assert.True(t, seen[faucetResp.TxHash])
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		var resp models.AdminRequestsResponse
+		require.Equal(t, fiber.StatusOK, getAdminRequests(t, app, url.Values{"token": {"eth"}}, &resp))
+		assert.Len(t, resp.Requests, 2)
+
+		require.Equal(t, fiber.StatusOK, getAdminRequests(t, app, url.Values{"ip_hash": {h.ipHash("0.0.0.0")}}, &resp))
+		require.Len(t, resp.Requests, 1)
+		assert.Equal(t, faucetResp.TxHash, resp.Requests[0].TxHash)
+		assert.Equal(t, "0x0742d469482a89e7", resp.Requests[0].Recipient)
+		assert.Equal(t, 5, resp.Total, "total ignores the token and IP filters")
+
+		// Pages are filled past records the filters skip
+		var first, second models.AdminRequestsResponse
+		require.Equal(t, fiber.StatusOK, getAdminRequests(t, app, url.Values{"ip": {"203.0.113.7"}, "limit": {"3"}}, &first))
+		assert.Len(t, first.Requests, 3)
+		require.NotEmpty(t, first.NextCursor)
+		require.Equal(t, fiber.StatusOK, getAdminRequests(t, app, url.Values{"ip": {"203.0.113.7"}, "limit": {"3"}, "cursor": {first.NextCursor}}, &second))
+		assert.Len(t, second.Requests, 1)
+		assert.Empty(t, second.NextCursor)
+
+		require.Equal(t, fiber.StatusOK, getAdminRequests(t, app, url.Values{"from": {"2999-01-01T00:00:00Z"}}, &resp))
+		assert.Equal(t, 0, resp.Total)
+		assert.NotNil(t, resp.Requests)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{"limit": {"0"}},
+			{"from": {"yesterday"}},
+			{"cursor": {"0xunknown"}},
+		} {
+			var errResp models.ErrorResponse
+			assert.Equal(t, fiber.StatusBadRequest, getAdminRequests(t, app, query, &errResp), query.Encode())
+			assert.Equal(t, models.ErrCodeInvalidRequest, errResp.Code)
+		}
+	})
+
+	t.Run("requires the admin key", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/admin/requests", nil)
+		req.Header.Set(adminKeyHeader, "wrong-admin-key-000")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestAdminRequestsDisabled(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/requests", nil)
+	req.Header.Set(adminKeyHeader, testAdminKey)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+)
+
+// maxAuthorizationLifetime is how far ahead an authorization's deadline may
+// be. Used nonces are remembered until the deadline, so this also bounds how
+// long they are kept.
+const maxAuthorizationLifetime = time.Hour
+
+// RequestTokensAuthorized sends tokens for a request signed by the recipient's
+// account and submitted by a relayer, so integrations can fund users who never
+// call the faucet themselves. The signature replaces the proof of work, and
+// limits apply to the signing address instead of the relayer's IP.
+func (h *Handler) RequestTokensAuthorized(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx := context.Background()
+
+	ip := c.IP()
+	if h.isBlocklisted(ip) {
+		return blockedError(c)
+	}
+
+	// Watch for request spikes, counting rejected requests too
+	h.velocity.observe(ctx, time.Now())
+
+	var req models.AuthorizedFaucetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  models.ErrCodeInvalidRequest,
+		})
+	}
+	req.Token = utils.NormalizeToken(req.Token)
+
+	if err := validate.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, validationErrorResponse(fieldErrors(err)))
+	}
+	if err := utils.ValidateStarknetAddress(req.Address); err != nil {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Invalid address: %s", err.Error()),
+			Code:  models.ErrCodeInvalidAddress,
+		})
+	}
+	tokens, err := h.requestedTokens(req.Token)
+	if err != nil {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: err.Error(),
+			Code:  models.ErrCodeInvalidToken,
+		})
+	}
+	nonce, err := new(felt.Felt).SetString(req.Nonce)
+	if err != nil {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error:       "Invalid request: nonce must be a felt",
+			Code:        models.ErrCodeInvalidRequest,
+			FieldErrors: map[string]string{"nonce": "must be a felt"},
+		})
+	}
+	signature := make([]*felt.Felt, len(req.Signature))
+	for i, part := range req.Signature {
+		if signature[i], err = new(felt.Felt).SetString(part); err != nil {
+			return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+				Error:       "Invalid request: signature must be a list of felts",
+				Code:        models.ErrCodeInvalidRequest,
+				FieldErrors: map[string]string{"signature": "must be a list of felts"},
+			})
+		}
+	}
+
+	deadline := time.Unix(req.Deadline, 0)
+	if !deadline.After(time.Now()) {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "The authorization has expired. Sign a new one with a later deadline.",
+			Code:  models.ErrCodeSignatureInvalid,
+		})
+	}
+	if time.Until(deadline) > maxAuthorizationLifetime {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("The authorization deadline must be within %s.", maxAuthorizationLifetime),
+			Code:  models.ErrCodeSignatureInvalid,
+		})
+	}
+
+	// Checking the signature needs the node
+	if !h.rpcHealth.available() {
+		failure := h.rpcUnavailableFailure()
+		return respondError(c, failure.status, failure.resp)
+	}
+	auth := starknet.Authorization{
+		Recipient: req.Address,
+		Token:     req.Token,
+		Nonce:     nonce.String(),
+		Deadline:  req.Deadline,
+	}
+	if failure := h.verifyAuthorization(c.UserContext(), log, auth, signature); failure != nil {
+		return respondError(c, failure.status, failure.resp)
+	}
+
+	// Spend the nonce only once the signature holds, so others can't burn it
+	address := recipientKey(req.Address)
+	fresh, err := h.redis.UseAuthorizationNonce(ctx, address, auth.Nonce, time.Until(deadline))
+	if err != nil {
+		log.Error("Failed to record authorization nonce", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to process request",
+			Code:  models.ErrCodeInternal,
+		})
+	}
+	if !fresh {
+		return respondError(c, fiber.StatusConflict, models.ErrorResponse{
+			Error: "This authorization was already used. Sign a new one with another nonce.",
+			Code:  models.ErrCodeSignatureInvalid,
+		})
+	}
+
+	log = log.With(zap.String("relayer_ip", ip))
+	resp, failure := h.dispense(c.UserContext(), log, dispenseRequest{
+		req:       models.FaucetRequest{Address: req.Address, Token: req.Token},
+		tokens:    tokens,
+		client:    "address:" + address,
+		skipPoW:   true,
+		requestID: requestID(c),
+		wait:      c.QueryBool("wait"),
+	})
+	if failure != nil {
+		return respondError(c, failure.status, failure.resp)
+	}
+	return c.JSON(resp)
+}
+
+// verifyAuthorization checks that the recipient's account signed auth for the
+// faucet's network. RPC calls are bounded by RPC_TIMEOUT within parent.
+func (h *Handler) verifyAuthorization(parent context.Context, log *zap.Logger, auth starknet.Authorization, signature []*felt.Felt) *faucetError {
+	rpcCtx, cancel := h.rpcDeadline(parent)
+	defer cancel()
+
+	chainID, err := h.starknet.ChainID(rpcCtx)
+	var hash *felt.Felt
+	if err == nil {
+		if hash, err = starknet.AuthorizationHash(chainID, auth); err != nil {
+			return &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+				Error: fmt.Sprintf("Invalid authorization: %s", err.Error()),
+				Code:  models.ErrCodeInvalidRequest,
+			}}
+		}
+	}
+	var valid bool
+	if err == nil {
+		valid, err = h.starknet.IsValidSignature(rpcCtx, auth.Recipient, hash, signature)
+	}
+
+	switch {
+	case errors.Is(err, starknet.ErrAccountNotDeployed):
+		return &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "No account is deployed at the address to check the signature. Deploy the account first, or use the proof-of-work flow.",
+			Code:  models.ErrCodeSignatureInvalid,
+		}}
+	case err != nil:
+		log.Error("Failed to verify authorization", zap.Error(err))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return rpcTimeoutFailure()
+		}
+		if starknet.IsUnavailableError(err) {
+			h.rpcHealth.markDown(err)
+			return h.rpcUnavailableFailure()
+		}
+		return &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to verify the signature",
+			Code:  models.ErrCodeInternal,
+		}}
+	case !valid:
+		log.Warn("Invalid authorization signature", zap.String("recipient", auth.Recipient))
+		return &faucetError{fiber.StatusUnauthorized, models.ErrorResponse{
+			Error: "The signature does not match the authorization or wasn't made by the recipient's account.",
+			Code:  models.ErrCodeSignatureInvalid,
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet"
+)
+
+// signedRequest builds an authorized request that the mock client accepts:
+// its signature is the authorization hash itself
+func signedRequest(t *testing.T, address, token, nonce string, deadline time.Time) models.AuthorizedFaucetRequest {
+	t.Helper()
+
+	hash, err := starknet.AuthorizationHash("SN_SEPOLIA", starknet.Authorization{
+		Recipient: address,
+		Token:     token,
+		Nonce:     nonce,
+		Deadline:  deadline.Unix(),
+	})
+	require.NoError(t, err)
+	return models.AuthorizedFaucetRequest{
+		Address:   address,
+		Token:     token,
+		Nonce:     nonce,
+		Deadline:  deadline.Unix(),
+		Signature: []string{hash.String()},
+	}
+}
+
+// postAuthorized submits req to the authorized faucet endpoint and decodes
+// the response into out
+func postAuthorized(t *testing.T, app *fiber.App, req models.AuthorizedFaucetRequest, out interface{}) int {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest("POST", "/api/v1/faucet/authorized", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	return resp.StatusCode
+}
+
+func TestRequestTokensAuthorizedDisabled(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/faucet/authorized", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestRequestTokensAuthorized(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.SignedRequestsEnabled = true
+	app := fiber.New()
+	SetupRoutes(app, h)
+	ctx := context.Background()
+	deadline := time.Now().Add(10 * time.Minute)
+
+	// The relayer's IP has no quota left; the signer's address has
+	useDailyRequests(t, h, 5)
+
+	req := signedRequest(t, "0x0742d469482a89e7", "STRK", "0x1", deadline)
+	var resp models.FaucetResponse
+	require.Equal(t, fiber.StatusOK, postAuthorized(t, app, req, &resp))
+	assert.True(t, resp.Success)
+	require.Equal(t, 1, mock.TransferCount())
+	assert.Equal(t, "0x0742d469482a89e7", mock.Transfers[0].Recipient)
+
+	used, _, _, _, err := h.redis.GetIPDailyQuota(ctx, "address:"+recipientKey("0x0742d469482a89e7"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, used, "limits apply to the signing address")
+
+	var errResp models.ErrorResponse
+	t.Run("reused nonce", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusConflict, postAuthorized(t, app, req, &errResp))
+		assert.Equal(t, models.ErrCodeSignatureInvalid, errResp.Code)
+	})
+
+	t.Run("wrong signer", func(t *testing.T) {
+		// Signed for another address
+		forged := signedRequest(t, "0x0742d469482a89e8", "ETH", "0x2", deadline)
+		forged.Address = "0x0742d469482a89e7"
+		assert.Equal(t, fiber.StatusUnauthorized, postAuthorized(t, app, forged, &errResp))
+		assert.Equal(t, models.ErrCodeSignatureInvalid, errResp.Code)
+	})
+
+	t.Run("tampered token", func(t *testing.T) {
+		tampered := signedRequest(t, "0x0742d469482a89e7", "ETH", "0x3", deadline)
+		tampered.Token = "BOTH"
+		assert.Equal(t, fiber.StatusUnauthorized, postAuthorized(t, app, tampered, &errResp))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired := signedRequest(t, "0x0742d469482a89e7", "ETH", "0x4", time.Now().Add(-time.Second))
+		assert.Equal(t, fiber.StatusBadRequest, postAuthorized(t, app, expired, &errResp))
+		assert.Equal(t, models.ErrCodeSignatureInvalid, errResp.Code)
+		assert.Contains(t, errResp.Error, "expired")
+	})
+
+	t.Run("deadline too far", func(t *testing.T) {
+		late := signedRequest(t, "0x0742d469482a89e7", "ETH", "0x5", time.Now().Add(2*time.Hour))
+		assert.Equal(t, fiber.StatusBadRequest, postAuthorized(t, app, late, &errResp))
+		assert.Equal(t, models.ErrCodeSignatureInvalid, errResp.Code)
+	})
+
+	t.Run("account not deployed", func(t *testing.T) {
+		mock.Undeployed = map[string]bool{"0x0742d469482a89e9": true}
+		undeployed := signedRequest(t, "0x0742d469482a89e9", "ETH", "0x6", deadline)
+		assert.Equal(t, fiber.StatusBadRequest, postAuthorized(t, app, undeployed, &errResp))
+		assert.Contains(t, errResp.Error, "Deploy the account first")
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		unsigned := signedRequest(t, "0x0742d469482a89e7", "ETH", "0x7", deadline)
+		unsigned.Signature = nil
+		assert.Equal(t, fiber.StatusBadRequest, postAuthorized(t, app, unsigned, &errResp))
+		assert.Contains(t, errResp.FieldErrors, "signature")
+	})
+
+	t.Run("signature not felts", func(t *testing.T) {
+		garbled := signedRequest(t, "0x0742d469482a89e7", "ETH", "0x8", deadline)
+		garbled.Signature = []string{"not-a-felt"}
+		assert.Equal(t, fiber.StatusBadRequest, postAuthorized(t, app, garbled, &errResp))
+		assert.Equal(t, models.ErrCodeInvalidRequest, errResp.Code)
+		assert.Contains(t, errResp.FieldErrors, "signature")
+	})
+
+	t.Run("too many signature parts", func(t *testing.T) {
+		long := signedRequest(t, "0x0742d469482a89e7", "ETH", "0x9", deadline)
+		for len(long.Signature) <= 64 {
+			long.Signature = append(long.Signature, "0x1")
+		}
+		assert.Equal(t, fiber.StatusBadRequest, postAuthorized(t, app, long, &errResp))
+		assert.Contains(t, errResp.FieldErrors, "signature")
+	})
+
+	assert.Equal(t, 1, mock.TransferCount(), "rejected authorizations send nothing")
+}
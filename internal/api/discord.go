@@ -0,0 +1,321 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/config"
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// discordAPIURL is the Discord REST API the bot calls
+const discordAPIURL = "https://discord.com/api/v10"
+
+// discordRequestTimeout bounds each call to the Discord API
+const discordRequestTimeout = 10 * time.Second
+
+// discordMaxClockSkew is how far an interaction's signed timestamp may be from
+// the server clock, so a captured request can't be replayed later
+const discordMaxClockSkew = 5 * time.Minute
+
+// discordCommandName is the slash command users run: /faucet <address> [token]
+const discordCommandName = "faucet"
+
+// Interaction and response types from the Discord API
+const (
+	discordInteractionPing    = 1
+	discordInteractionCommand = 2
+
+	discordResponsePong            = 1
+	discordResponseMessage         = 4
+	discordResponseDeferredMessage = 5
+
+	discordFlagEphemeral = 1 << 6 // Only the user who ran the command sees the message
+)
+
+// discordInteraction is the part of an incoming interaction the faucet reads
+type discordInteraction struct {
+	ID            string `json:"id"`
+	ApplicationID string `json:"application_id"`
+	Type          int    `json:"type"`
+	Token         string `json:"token"` // Authorizes follow-up messages for 15 minutes
+	Data          struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+	Member *struct {
+		User discordUser `json:"user"`
+	} `json:"member"` // Set in servers
+	User *discordUser `json:"user"` // Set in direct messages
+}
+
+type discordUser struct {
+	ID string `json:"id"`
+}
+
+// userID returns the ID of the user who ran the command
+func (in *discordInteraction) userID() string {
+	if in.Member != nil {
+		return in.Member.User.ID
+	}
+	if in.User != nil {
+		return in.User.ID
+	}
+	return ""
+}
+
+// option returns a string option of the command, or "" if it wasn't given
+func (in *discordInteraction) option(name string) string {
+	for _, opt := range in.Data.Options {
+		if opt.Name == name {
+			var value string
+			if json.Unmarshal(opt.Value, &value) == nil {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// discordResponse answers an interaction
+type discordResponse struct {
+	Type int             `json:"type"`
+	Data *discordMessage `json:"data,omitempty"`
+}
+
+type discordMessage struct {
+	Content string `json:"content,omitempty"`
+	Flags   int    `json:"flags,omitempty"`
+}
+
+// discordBot verifies interactions and calls the Discord API
+type discordBot struct {
+	publicKey ed25519.PublicKey
+	botToken  string
+	apiURL    string
+	client    *http.Client
+}
+
+// newDiscordBot creates a bot from the validated DISCORD_* settings
+func newDiscordBot(cfg *config.Config) *discordBot {
+	key, _ := hex.DecodeString(cfg.DiscordPublicKey)
+	return &discordBot{
+		publicKey: key,
+		botToken:  cfg.DiscordBotToken,
+		apiURL:    discordAPIURL,
+		client:    &http.Client{Timeout: discordRequestTimeout},
+	}
+}
+
+// verify checks Discord's Ed25519 signature over timestamp+body and that the
+// timestamp is recent
+func (b *discordBot) verify(signature, timestamp string, body []byte, now time.Time) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(secs, 0)); skew > discordMaxClockSkew || skew < -discordMaxClockSkew {
+		return false
+	}
+	return ed25519.Verify(b.publicKey, append([]byte(timestamp), body...), sig)
+}
+
+// call sends a request to the Discord API, with body encoded as JSON unless
+// nil, and decodes a JSON reply into out, if given
+func (b *discordBot) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.apiURL+path, payload)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bot "+b.botToken)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("discord %s %s returned status %d", method, path, resp.StatusCode)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// editReply replaces the deferred reply to an interaction with content
+func (b *discordBot) editReply(ctx context.Context, in *discordInteraction, content string) error {
+	path := fmt.Sprintf("/webhooks/%s/%s/messages/@original", in.ApplicationID, in.Token)
+	return b.call(ctx, http.MethodPatch, path, discordMessage{Content: content}, nil)
+}
+
+// RegisterDiscordCommand creates or updates the /faucet command of the bot's
+// application, offering the supported tokens as choices
+func (h *Handler) RegisterDiscordCommand(ctx context.Context) error {
+	var app struct {
+		ID string `json:"id"`
+	}
+	if err := h.discord.call(ctx, http.MethodGet, "/applications/@me", nil, &app); err != nil {
+		return fmt.Errorf("failed to look up Discord application: %w", err)
+	}
+
+	type choice struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	var choices []choice
+	for _, symbol := range append(h.config.TokenSymbols(), "BOTH", "ALL") {
+		choices = append(choices, choice{Name: symbol, Value: symbol})
+	}
+
+	command := map[string]interface{}{
+		"name":        discordCommandName,
+		"description": "Request testnet tokens from the faucet",
+		"options": []map[string]interface{}{
+			{"type": 3, "name": "address", "description": "Starknet address to fund", "required": true},
+			{"type": 3, "name": "token", "description": "Token to request (default STRK)", "choices": choices},
+		},
+	}
+	// Creating a command with an existing name updates it
+	if err := h.discord.call(ctx, http.MethodPost, "/applications/"+app.ID+"/commands", command, nil); err != nil {
+		return fmt.Errorf("failed to register /%s command: %w", discordCommandName, err)
+	}
+	return nil
+}
+
+// DiscordInteractions answers Discord's interaction webhook. The /faucet
+// command is acknowledged at once with a deferred reply, since a transfer can
+// outlast Discord's 3-second deadline, and the reply is edited with the result.
+// Limits apply to the Discord user in place of an IP, and no PoW is asked for.
+func (h *Handler) DiscordInteractions(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx := context.Background()
+
+	body := c.Body()
+	if !h.discord.verify(c.Get("X-Signature-Ed25519"), c.Get("X-Signature-Timestamp"), body, time.Now()) {
+		return respondError(c, fiber.StatusUnauthorized, models.ErrorResponse{
+			Error: "Invalid request signature",
+			Code:  models.ErrCodeUnauthorized,
+		})
+	}
+
+	in := &discordInteraction{}
+	if err := json.Unmarshal(body, in); err != nil {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid interaction",
+			Code:  models.ErrCodeInvalidRequest,
+		})
+	}
+
+	if in.Type == discordInteractionPing {
+		return c.JSON(discordResponse{Type: discordResponsePong})
+	}
+	userID := in.userID()
+	if in.Type != discordInteractionCommand || in.Data.Name != discordCommandName || userID == "" {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "Unsupported interaction",
+			Code:  models.ErrCodeInvalidRequest,
+		})
+	}
+
+	// Reject bad input right away instead of through the deferred reply
+	address := in.option("address")
+	if err := utils.ValidateStarknetAddress(address); err != nil {
+		return c.JSON(discordReply(fmt.Sprintf("Invalid address: %s", err.Error())))
+	}
+	token := utils.NormalizeToken(in.option("token"))
+	if token == "" {
+		token = "STRK"
+	}
+	tokens, err := h.requestedTokens(token)
+	if err != nil {
+		return c.JSON(discordReply(err.Error()))
+	}
+
+	h.velocity.observe(ctx, time.Now())
+
+	d := dispenseRequest{
+		req:       models.FaucetRequest{Address: address, Token: token},
+		tokens:    tokens,
+		client:    "discord:" + userID,
+		skipPoW:   true,
+		requestID: requestID(c),
+	}
+	go h.answerDiscordCommand(log, in, d)
+
+	return c.JSON(discordResponse{
+		Type: discordResponseDeferredMessage,
+		Data: &discordMessage{Flags: discordFlagEphemeral},
+	})
+}
+
+// answerDiscordCommand runs the transfer and edits the deferred reply with
+// the outcome
+func (h *Handler) answerDiscordCommand(log *zap.Logger, in *discordInteraction, d dispenseRequest) {
+	log = log.With(zap.String("discord_user", strings.TrimPrefix(d.client, "discord:")))
+	resp, failure := h.dispense(context.Background(), log, d)
+
+	ctx, cancel := context.WithTimeout(context.Background(), discordRequestTimeout)
+	defer cancel()
+	if err := h.discord.editReply(ctx, in, discordResult(resp, failure)); err != nil {
+		log.Error("Failed to send Discord reply", zap.Error(err))
+	}
+}
+
+// discordReply is an immediate reply only the user sees
+func discordReply(content string) discordResponse {
+	return discordResponse{
+		Type: discordResponseMessage,
+		Data: &discordMessage{Content: content, Flags: discordFlagEphemeral},
+	}
+}
+
+// discordResult formats the outcome of a /faucet command as a message
+func discordResult(resp *models.FaucetResponse, failure *faucetError) string {
+	if failure != nil {
+		return "Request failed: " + failure.resp.Error
+	}
+
+	lines := []string{resp.Message}
+	for _, tx := range resp.Transactions {
+		link := tx.TxHash
+		if tx.ExplorerURL != "" {
+			link = "<" + tx.ExplorerURL + ">" // Angle brackets suppress the link preview
+		}
+		lines = append(lines, fmt.Sprintf("• %s %s: %s", tx.Amount, tx.Token, link))
+	}
+	if resp.Warning != "" {
+		lines = append(lines, "Note: "+resp.Warning)
+	}
+	return strings.Join(lines, "\n")
+}
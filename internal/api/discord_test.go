@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDiscord records the requests a discordBot makes to the Discord API
+type fakeDiscord struct {
+	server   *httptest.Server
+	requests chan string // "METHOD path body"
+}
+
+func newFakeDiscord(t *testing.T) *fakeDiscord {
+	t.Helper()
+	f := &fakeDiscord{requests: make(chan string, 10)}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		f.requests <- r.Method + " " + r.URL.Path + " " + string(body)
+		if r.URL.Path == "/applications/@me" {
+			w.Write([]byte(`{"id":"app-1"}`))
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// next waits for the bot's next request to the Discord API
+func (f *fakeDiscord) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case req := <-f.requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("no request reached the Discord API")
+		return ""
+	}
+}
+
+// newDiscordTestApp returns an app with the Discord command enabled and the
+// private key interactions must be signed with
+func newDiscordTestApp(t *testing.T) (*fiber.App, *Handler, ed25519.PrivateKey, *fakeDiscord) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	h, _, _ := newTestHandler(t)
+	h.config.DiscordPublicKey = hex.EncodeToString(pub)
+	h.config.DiscordBotToken = "bot-token"
+	h.discord = newDiscordBot(h.config)
+
+	fake := newFakeDiscord(t)
+	h.discord.apiURL = fake.server.URL
+
+	app := fiber.New()
+	SetupRoutes(app, h)
+	return app, h, priv, fake
+}
+
+// postInteraction signs and sends an interaction, returning the status and
+// the decoded interaction response
+func postInteraction(t *testing.T, app *fiber.App, key ed25519.PrivateKey, interaction string, at time.Time) (int, discordResponse) {
+	t.Helper()
+
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	sig := ed25519.Sign(key, []byte(timestamp+interaction))
+
+	req := httptest.NewRequest("POST", "/api/v1/discord/interactions", bytes.NewReader([]byte(interaction)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(sig))
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out discordResponse
+	if resp.StatusCode == fiber.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp.StatusCode, out
+}
+
+func faucetCommand(address, token string) string {
+	return `{"id":"int-1","application_id":"app-1","type":2,"token":"tok","member":{"user":{"id":"42"}},` +
+		`"data":{"name":"faucet","options":[{"name":"address","value":"` + address + `"},{"name":"token","value":"` + token + `"}]}}`
+}
+
+func TestDiscordInteractions(t *testing.T) {
+	app, _, key, fake := newDiscordTestApp(t)
+	now := time.Now()
+
+	t.Run("ping", func(t *testing.T) {
+		status, resp := postInteraction(t, app, key, `{"type":1}`, now)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, discordResponsePong, resp.Type)
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		status, _ := postInteraction(t, app, otherKey, `{"type":1}`, now)
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+
+	t.Run("stale timestamp", func(t *testing.T) {
+		status, _ := postInteraction(t, app, key, `{"type":1}`, now.Add(-time.Hour))
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+
+	t.Run("invalid address is answered immediately", func(t *testing.T) {
+		status, resp := postInteraction(t, app, key, faucetCommand("nope", "STRK"), now)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, discordResponseMessage, resp.Type)
+		assert.Contains(t, resp.Data.Content, "Invalid address")
+	})
+
+	t.Run("transfer result edits the deferred reply", func(t *testing.T) {
+		status, resp := postInteraction(t, app, key, faucetCommand("0x0742d469482a89e7", "strk"), now)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, discordResponseDeferredMessage, resp.Type)
+		assert.Equal(t, discordFlagEphemeral, resp.Data.Flags)
+
+		req := fake.next(t)
+		assert.Contains(t, req, "PATCH /webhooks/app-1/tok/messages/@original")
+		assert.Contains(t, req, "10 STRK")
+
+		// Limits are tracked per Discord user
+		postInteraction(t, app, key, faucetCommand("0x0742d469482a89e7", "STRK"), now)
+		assert.Contains(t, fake.next(t), "Request failed: STRK hourly throttle active")
+	})
+}
+
+func TestRegisterDiscordCommand(t *testing.T) {
+	_, h, _, fake := newDiscordTestApp(t)
+
+	require.NoError(t, h.RegisterDiscordCommand(t.Context()))
+	assert.Equal(t, "GET /applications/@me ", fake.next(t))
+
+	register := fake.next(t)
+	assert.Contains(t, register, "POST /applications/app-1/commands")
+	assert.Contains(t, register, `"name":"faucet"`)
+	assert.Contains(t, register, `{"name":"BOTH","value":"BOTH"}`)
+}
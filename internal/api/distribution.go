@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/cache"
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+)
+
+// defaultDistributionWindow is the window of a distribution series when the
+// request doesn't name one
+const defaultDistributionWindow = 24 * time.Hour
+
+// recordDistribution adds the transfers of a request to the distribution
+// series. Failures are logged and otherwise ignored, since the tokens have
+// already been sent.
+func (h *Handler) recordDistribution(ctx context.Context, log *zap.Logger, transactions []models.TransactionInfo) {
+	now := time.Now()
+	for _, tx := range transactions {
+		amount, _ := strconv.ParseFloat(tx.Amount, 64)
+		if err := h.redis.RecordDistribution(ctx, tx.Token, amount, now); err != nil {
+			log.Error("Failed to record distribution", zap.Error(err), zap.String("token", tx.Token))
+		}
+	}
+}
+
+// DistributionSeries returns how much of a token was sent over the window,
+// in minute buckets for windows up to DistributionMinuteRetention and hour
+// buckets up to DistributionHourRetention. Query: token (default STRK) and
+// window, a Go duration such as 30m or 24h (default 24h).
+func (h *Handler) DistributionSeries(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx := c.UserContext()
+
+	token := utils.NormalizeToken(c.Query("token", "STRK"))
+	if _, ok := h.config.Tokens[token]; !ok {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("Unsupported token %q. Supported: %s.", token, strings.Join(h.config.TokenSymbols(), ", ")),
+			Code:  models.ErrCodeInvalidToken,
+		})
+	}
+
+	window := defaultDistributionWindow
+	if raw := c.Query("window"); raw != "" {
+		var err error
+		window, err = time.ParseDuration(raw)
+		if err != nil || window < time.Minute || window > cache.DistributionHourRetention {
+			return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+				Error: fmt.Sprintf("window must be a duration from 1m to %s, e.g. 24h", shortDuration(cache.DistributionHourRetention)),
+				Code:  models.ErrCodeInvalidRequest,
+			})
+		}
+	}
+
+	bucket := time.Hour
+	if window <= cache.DistributionMinuteRetention {
+		bucket = time.Minute
+	}
+	count := int((window + bucket - 1) / bucket)
+
+	series, err := h.redis.DistributionSeries(ctx, token, bucket, count, time.Now())
+	if err != nil {
+		log.Error("Failed to get distribution series", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to get distribution series",
+			Code:  models.ErrCodeInternal,
+		})
+	}
+
+	points := make([]models.DistributionPoint, len(series))
+	for i, b := range series {
+		points[i] = models.DistributionPoint{Start: b.Start.UTC(), Amount: b.Amount, Drips: b.Drips}
+	}
+	return c.JSON(models.DistributionSeriesResponse{
+		Token:         token,
+		Window:        window.String(),
+		BucketSeconds: int(bucket / time.Second),
+		Buckets:       points,
+	})
+}
+
+// shortDuration formats whole hours as e.g. 168h instead of 168h0m0s
+func shortDuration(d time.Duration) string {
+	return strings.TrimSuffix(d.String(), "0m0s")
+}
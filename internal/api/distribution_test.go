@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+)
+
+func TestDistributionSeries(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	challengeID, nonce := requestChallenge(t, app)
+	var resp models.FaucetResponse
+	require.Equal(t, fiber.StatusOK, postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "BOTH",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &resp))
+
+	series := func(query string) (int, models.DistributionSeriesResponse, models.ErrorResponse) {
+		res, err := app.Test(httptest.NewRequest("GET", "/api/v1/distribution/series"+query, nil))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var out models.DistributionSeriesResponse
+		var errResp models.ErrorResponse
+		if res.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+		} else {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&errResp))
+		}
+		return res.StatusCode, out, errResp
+	}
+
+	// The default is a day of STRK in hour buckets
+	status, day, _ := series("")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "STRK", day.Token)
+	assert.Equal(t, "24h0m0s", day.Window)
+	assert.Equal(t, 3600, day.BucketSeconds)
+	require.Len(t, day.Buckets, 24)
+	assert.Equal(t, models.DistributionPoint{Start: day.Buckets[23].Start, Amount: 10, Drips: 1}, day.Buckets[23])
+	assert.Zero(t, day.Buckets[0].Drips)
+
+	// Short windows use minute buckets
+	status, hour, _ := series("?token=eth&window=30m")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 60, hour.BucketSeconds)
+	require.Len(t, hour.Buckets, 30)
+	assert.Equal(t, 0.01, hour.Buckets[29].Amount)
+
+	status, _, errResp := series("?token=BTC")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, models.ErrCodeInvalidToken, errResp.Code)
+
+	for _, window := range []string{"forever", "30s", "200h"} {
+		status, _, errResp = series("?window=" + window)
+		assert.Equal(t, fiber.StatusBadRequest, status, window)
+		assert.Equal(t, models.ErrCodeInvalidRequest, errResp.Code)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/config"
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet"
+)
+
+// feeEstimateTTL is how long an estimated drip fee is reused before the RPC
+// is asked again
+const feeEstimateTTL = time.Minute
+
+// feeEstimate caches the estimated fee of one drip, so /info doesn't have the
+// node estimate a transaction on every call. The zero value is ready to use.
+type feeEstimate struct {
+	mu        sync.Mutex
+	fee       *big.Int // nil when the last estimate failed
+	estimated time.Time
+}
+
+// estimatedFee returns the fee, in wei of the fee token, of a STRK drip, or
+// nil when it can't be estimated. Results, failures included, are reused for
+// feeEstimateTTL.
+func (h *Handler) estimatedFee(ctx context.Context, log *zap.Logger) *big.Int {
+	h.fee.mu.Lock()
+	defer h.fee.mu.Unlock()
+
+	if !h.fee.estimated.IsZero() && time.Since(h.fee.estimated) < feeEstimateTTL {
+		return h.fee.fee
+	}
+	// Don't wait on a node that is known to be down, nor cache its absence
+	if !h.rpcHealth.available() {
+		return nil
+	}
+
+	var fee *big.Int
+	amount, err := config.ParseDripAmount(h.config.DripAmountSTRK)
+	if err == nil {
+		fee, err = h.starknet.EstimateTransferFee(ctx, "STRK", starknet.AmountToWei(amount))
+	}
+	if err != nil {
+		log.Warn("Failed to estimate drip fee", zap.Error(err))
+		fee = nil
+	}
+
+	h.fee.fee, h.fee.estimated = fee, time.Now()
+	return fee
+}
@@ -2,28 +2,82 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"math/rand/v2"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/gofiber/fiber/v2"
 	"github.com/Giri-Aayush/starknet-faucet/internal/cache"
 	"github.com/Giri-Aayush/starknet-faucet/internal/config"
 	"github.com/Giri-Aayush/starknet-faucet/internal/models"
 	"github.com/Giri-Aayush/starknet-faucet/internal/pow"
+	"github.com/Giri-Aayush/starknet-faucet/internal/receipt"
 	"github.com/Giri-Aayush/starknet-faucet/internal/starknet"
 	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
+// maxConcurrentBalanceFetches bounds parallel balance reads so a long token
+// list doesn't flood the RPC node
+const maxConcurrentBalanceFetches = 4
+
+// reservationConfirmTimeout is how long to wait for a transfer to be accepted
+// before releasing its balance reservation anyway
+const reservationConfirmTimeout = 5 * time.Minute
+
+// apiKeyHeader carries the key of a trusted integration
+const apiKeyHeader = "X-API-Key"
+
+// maxChallengeBatch caps how many challenges one /challenges call may issue
+const maxChallengeBatch = 10
+
+// requestLockMargin is added to RPC_TIMEOUT to bound how long an in-flight
+// request lock can outlive a request that never released it
+const requestLockMargin = 30 * time.Second
+
+// tokenThrottleHours is how long an IP must wait between requests for the same token
+const tokenThrottleHours = 1
+
+// StarknetClient is the chain access the handlers need. It is satisfied by
+// *starknet.FaucetClient and, in tests, by starknettest.MockClient.
+type StarknetClient interface {
+	TransferTokens(ctx context.Context, recipient string, token string, amount *big.Int) (string, error)
+	EstimateTransferFee(ctx context.Context, token string, amount *big.Int) (*big.Int, error)
+	FaucetBalance(ctx context.Context, token string) (*big.Int, error)
+	WaitForTransaction(ctx context.Context, txHash, level string) (string, *starknet.Receipt, error)
+	Ping(ctx context.Context) error
+	IsDeployed(ctx context.Context, address string) (bool, error)
+	IsAccount(ctx context.Context, address string) (bool, error)
+	IsValidSignature(ctx context.Context, address string, hash *felt.Felt, signature []*felt.Felt) (bool, error)
+	ChainID(ctx context.Context) (string, error)
+	RPCEndpoint() (active, total int)
+	FeeToken() string
+	AccountAddresses() []string
+}
+
+var _ StarknetClient = (*starknet.FaucetClient)(nil)
+
 // Handler contains dependencies for API handlers
 type Handler struct {
-	config        *config.Config
-	logger        *zap.Logger
-	redis         *cache.RedisClient
-	starknet      *starknet.FaucetClient
-	powGenerator  *pow.Generator
+	config       *config.Config
+	logger       *zap.Logger
+	redis        *cache.RedisClient
+	starknet     StarknetClient
+	powGenerator *pow.Generator
+	velocity     *velocityMonitor
+	rpcHealth    *rpcHealth
+	discord      *discordBot // nil unless the Discord command is configured
+	fee          feeEstimate
 }
 
 // NewHandler creates a new API handler
@@ -31,343 +85,848 @@ func NewHandler(
 	cfg *config.Config,
 	logger *zap.Logger,
 	redis *cache.RedisClient,
-	starknetClient *starknet.FaucetClient,
+	starknetClient StarknetClient,
 	powGenerator *pow.Generator,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		config:       cfg,
 		logger:       logger,
 		redis:        redis,
 		starknet:     starknetClient,
 		powGenerator: powGenerator,
+		velocity:     newVelocityMonitor(cfg, logger, redis),
+		rpcHealth:    newRPCHealth(starknetClient, logger, time.Duration(cfg.RPCHealthInterval)*time.Second),
+	}
+	if cfg.DiscordEnabled() {
+		h.discord = newDiscordBot(cfg)
 	}
+	return h
+}
+
+// MonitorRPC checks in the background that the Starknet RPC is reachable,
+// every RPC_HEALTH_INTERVAL, until ctx is done. While the last check failed,
+// faucet requests are refused at once with 503 RPC_UNAVAILABLE.
+func (h *Handler) MonitorRPC(ctx context.Context) {
+	h.rpcHealth.run(ctx)
 }
 
 // GetChallenge generates a new PoW challenge
 func (h *Handler) GetChallenge(c *fiber.Ctx) error {
-	ctx := context.Background()
+	log := h.requestLogger(c)
+	ctx := c.UserContext()
 
-	// Check challenge rate limit for this IP
 	ip := c.IP()
-	canRequest, err := h.redis.CheckChallengeRateLimit(ctx, ip)
-	if err != nil {
-		h.logger.Error("Failed to check challenge rate limit", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to check rate limit",
-		})
+	if h.isBlocklisted(ip) {
+		return blockedError(c)
 	}
-	if !canRequest {
-		return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
-			Error: "Too many challenge requests. Please try again later.",
+	allowlisted := h.isAllowlisted(ip)
+
+	bonus := c.QueryBool("bonus")
+	if bonus && h.config.BonusDifficulty <= 0 {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "Bonus challenges are not enabled on this faucet.",
+			Code:  models.ErrCodeInvalidRequest,
 		})
 	}
+	token, failure := h.challengeToken(c)
+	if failure != nil {
+		return respondError(c, failure.status, failure.resp)
+	}
 
-	// Generate challenge
-	response, challenge, err := h.powGenerator.GenerateChallenge()
+	// Check challenge rate limit for this IP
+	if !allowlisted {
+		canRequest, err := h.redis.CheckChallengeRateLimit(ctx, ip)
+		if err != nil {
+			log.Error("Failed to check challenge rate limit", zap.Error(err))
+			return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to check rate limit",
+				Code:  models.ErrCodeInternal,
+			})
+		}
+		if !canRequest {
+			return respondError(c, fiber.StatusTooManyRequests, models.ErrorResponse{
+				Error: "Too many challenge requests. Please try again later.",
+				Code:  models.ErrCodeRateLimited,
+			})
+		}
+	}
+
+	response, err := h.issueChallenge(ctx, bonus, token)
 	if err != nil {
-		h.logger.Error("Failed to generate challenge", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		log.Error("Failed to issue challenge", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to generate challenge",
+			Code:  models.ErrCodeInternal,
 		})
 	}
 
-	// Store challenge in Redis
-	ttl := time.Duration(h.config.ChallengeTTL) * time.Second
-	if err := h.redis.StoreChallenge(ctx, challenge.ID, challenge.Challenge, ttl); err != nil {
-		h.logger.Error("Failed to store challenge", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to store challenge",
+	// Increment challenge rate limit counter
+	if !allowlisted {
+		if err := h.redis.IncrementChallengeRateLimit(ctx, ip); err != nil {
+			log.Error("Failed to increment challenge rate limit", zap.Error(err))
+		}
+	}
+
+	log.Info("Challenge generated",
+		zap.String("challenge_id", response.ChallengeID),
+		zap.String("ip", ip),
+	)
+
+	return c.JSON(response)
+}
+
+// GetChallenges issues up to maxChallengeBatch challenges in one call
+// (?count=n) so scripted clients can skip a round trip per request. The batch
+// is charged to the hourly challenge limit as a whole.
+func (h *Handler) GetChallenges(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx := c.UserContext()
+
+	ip := c.IP()
+	if h.isBlocklisted(ip) {
+		return blockedError(c)
+	}
+	allowlisted := h.isAllowlisted(ip)
+
+	count, err := strconv.Atoi(c.Query("count", "1"))
+	if err != nil || count < 1 || count > maxChallengeBatch {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: fmt.Sprintf("count must be between 1 and %d", maxChallengeBatch),
+			Code:  models.ErrCodeInvalidRequest,
 		})
 	}
+	token, failure := h.challengeToken(c)
+	if failure != nil {
+		return respondError(c, failure.status, failure.resp)
+	}
 
-	// Increment challenge rate limit counter
-	if err := h.redis.IncrementChallengeRateLimit(ctx, ip); err != nil {
-		h.logger.Error("Failed to increment challenge rate limit", zap.Error(err))
+	// The whole batch must fit in what is left of this hour's challenge limit
+	if !allowlisted {
+		remaining, err := h.redis.RemainingChallenges(ctx, ip)
+		if err != nil {
+			log.Error("Failed to check challenge rate limit", zap.Error(err))
+			return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to check rate limit",
+				Code:  models.ErrCodeInternal,
+			})
+		}
+		if count > remaining {
+			return respondError(c, fiber.StatusTooManyRequests, models.ErrorResponse{
+				Error: fmt.Sprintf("Too many challenge requests: asked for %d but only %d remain this hour.", count, remaining),
+				Code:  models.ErrCodeRateLimited,
+			})
+		}
 	}
 
-	h.logger.Info("Challenge generated",
-		zap.String("challenge_id", challenge.ID),
+	challenges := make([]models.ChallengeResponse, 0, count)
+	for range count {
+		response, err := h.issueChallenge(ctx, false, token)
+		if err != nil {
+			log.Error("Failed to issue challenge", zap.Error(err))
+			break
+		}
+		challenges = append(challenges, *response)
+	}
+
+	// Charge only the challenges actually issued
+	if !allowlisted && len(challenges) > 0 {
+		if err := h.redis.IncrementChallengeRateLimitBy(ctx, ip, len(challenges)); err != nil {
+			log.Error("Failed to increment challenge rate limit", zap.Error(err))
+		}
+	}
+
+	if len(challenges) == 0 {
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to generate challenge",
+			Code:  models.ErrCodeInternal,
+		})
+	}
+
+	log.Info("Challenges generated",
+		zap.Int("count", len(challenges)),
 		zap.String("ip", ip),
 	)
 
+	return c.JSON(models.ChallengeBatchResponse{Challenges: challenges})
+}
+
+// challengeToken returns the token a challenge is requested for (?token=),
+// which it can then only be redeemed for. Without one the challenge works
+// for any token, unless REQUIRE_CHALLENGE_TOKEN is set.
+func (h *Handler) challengeToken(c *fiber.Ctx) (string, *faucetError) {
+	token := utils.NormalizeToken(c.Query("token"))
+	switch token {
+	case "":
+		if h.config.RequireChallengeToken {
+			return "", &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+				Error: "This faucet binds each challenge to a token. Request it with ?token=STRK (or ETH, BOTH, ALL).",
+				Code:  models.ErrCodeInvalidRequest,
+			}}
+		}
+	case "ETH", "STRK", "BOTH", "ALL":
+	default:
+		return "", &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "token must be one of ETH, STRK, BOTH or ALL",
+			Code:  models.ErrCodeInvalidRequest,
+		}}
+	}
+	return token, nil
+}
+
+// issueChallenge generates a challenge and stores it for single use, bound
+// to token unless it is empty. Bonus challenges are BONUS_DIFFICULTY harder.
+func (h *Handler) issueChallenge(ctx context.Context, bonus bool, token string) (*models.ChallengeResponse, error) {
+	difficulty := h.velocity.difficulty()
+	if bonus {
+		difficulty += h.config.BonusDifficulty
+	}
+	response, challenge, err := h.powGenerator.GenerateChallengeAt(difficulty)
+	if err != nil {
+		return nil, err
+	}
+	response.Bonus = bonus
+	response.Token = token
+
+	ttl := time.Duration(h.config.ChallengeTTL) * time.Second
+	if err := h.redis.StoreChallenge(ctx, challenge.ID, challenge.Challenge, challenge.Difficulty, token, bonus, ttl); err != nil {
+		return nil, fmt.Errorf("failed to store challenge: %w", err)
+	}
+	response.MinSolveSeconds = h.config.MinSolveSeconds
+	return response, nil
+}
+
+// VerifyPoW checks a solution against any challenge and difficulty, without
+// consuming a real challenge, so alternative clients can test their solvers
+// against the server's hashing: sha256(challenge + decimal nonce), valid when
+// the hex digest starts with difficulty zeros
+func (h *Handler) VerifyPoW(c *fiber.Ctx) error {
+	var req models.PoWVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  models.ErrCodeInvalidRequest,
+		})
+	}
+	if err := validate.Struct(req); err != nil {
+		return respondError(c, fiber.StatusBadRequest, validationErrorResponse(fieldErrors(err)))
+	}
+
+	return c.JSON(models.PoWVerifyResponse{
+		Valid:      pow.Solves(req.Challenge, *req.Nonce, req.Difficulty),
+		Hash:       pow.Hash(req.Challenge, *req.Nonce),
+		Difficulty: req.Difficulty,
+	})
+}
+
+// PoWInfo describes the proof of work new challenges need and how long a
+// typical CPU takes to solve it. The difficulty only changes when the velocity
+// monitor re-evaluates, so clients may cache the answer that long.
+func (h *Handler) PoWInfo(c *fiber.Ctx) error {
+	difficulty := h.velocity.difficulty()
+	response := models.PoWInfoResponse{
+		Difficulty:            difficulty,
+		Algorithm:             pow.Algorithm,
+		ExpectedAttempts:      pow.ExpectedAttempts(difficulty),
+		AssumedHashRate:       pow.AssumedHashRate,
+		EstimatedSolveSeconds: int64(pow.EstimateSolveTime(difficulty).Seconds()),
+	}
+	if h.config.BonusDifficulty > 0 {
+		response.BonusDifficulty = difficulty + h.config.BonusDifficulty
+	}
+
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(velocityEvalInterval.Seconds())))
 	return c.JSON(response)
 }
 
 // RequestTokens handles faucet requests
 func (h *Handler) RequestTokens(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
 	ctx := context.Background()
 
+	ip := c.IP()
+	if h.isBlocklisted(ip) {
+		return blockedError(c)
+	}
+
+	// Watch for request spikes, counting rejected requests too
+	h.velocity.observe(ctx, time.Now())
+
+	// Trusted integrations authenticate with an API key instead of solving PoW,
+	// and are limited by a per-key quota instead of the IP limits
+	keyID, keySent := h.apiKeyID(c)
+	if keySent && keyID == "" {
+		log.Warn("Rejected unknown API key", zap.String("ip", ip))
+		return unauthorizedError(c)
+	}
+
 	// Parse request
 	var req models.FaucetRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
 			Error: "Invalid request body",
+			Code:  models.ErrCodeInvalidRequest,
 		})
 	}
+	req.Token = utils.NormalizeToken(req.Token)
+
+	// Check the struct tag rules (required fields, token values)
+	if err := validateFaucetRequest(req, keyID != ""); err != nil {
+		return respondError(c, fiber.StatusBadRequest, validationErrorResponse(fieldErrors(err)))
+	}
 
 	// Validate address
 	if err := utils.ValidateStarknetAddress(req.Address); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
 			Error: fmt.Sprintf("Invalid address: %s", err.Error()),
+			Code:  models.ErrCodeInvalidAddress,
 		})
 	}
 
-	// Validate token
-	req.Token = strings.ToUpper(req.Token)
-	if err := utils.ValidateToken(req.Token); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+	// Validate token (BOTH and ALL expand to several tokens)
+	tokens, err := h.requestedTokens(req.Token)
+	if err != nil {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
 			Error: err.Error(),
+			Code:  models.ErrCodeInvalidToken,
 		})
 	}
 
-	// NEW SIMPLIFIED RATE LIMITING
+	resp, failure := h.dispense(c.UserContext(), log, dispenseRequest{
+		req:       req,
+		tokens:    tokens,
+		client:    ip,
+		keyID:     keyID,
+		skipPoW:   keyID != "",
+		requestID: requestID(c),
+		wait:      c.QueryBool("wait"),
+	})
+	if failure != nil {
+		return respondError(c, failure.status, failure.resp)
+	}
+	return c.JSON(resp)
+}
 
-	ip := c.IP()
+// dispenseRequest is a validated faucet request, from the HTTP API or a chat
+// integration
+type dispenseRequest struct {
+	req       models.FaucetRequest
+	tokens    []string // Tokens to send, with BOTH and ALL expanded
+	client    string   // Who the rate limits apply to: the client IP, or e.g. "discord:<user ID>"
+	keyID     string   // Trusted API key, whose quota replaces the client's limits
+	skipPoW   bool     // The caller was authenticated another way, e.g. by API key
+	requestID string
+	wait      bool     // Respond only once transfers reach CONFIRMATION_LEVEL
+	paused    []string // Requested tokens left out because the operator paused them
+}
 
-	// 1. Check IP daily limit (5 requests/day) and 24h cooldown
-	canRequest, currentCount, cooldownEnd, err := h.redis.CheckIPDailyLimit(ctx, ip)
-	if err != nil {
-		h.logger.Error("Failed to check IP daily limit", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to check rate limit",
-		})
-	}
+// faucetError is a rejected faucet request: the HTTP status and error body
+type faucetError struct {
+	status int
+	resp   models.ErrorResponse
+}
 
-	// If in 24h cooldown after hitting limit
-	if !canRequest && cooldownEnd != nil {
-		hoursRemaining := time.Until(*cooldownEnd).Hours()
-		errorMsg := fmt.Sprintf("Daily limit reached. In 24-hour cooldown (%.1f hours remaining). Run 'starknet-faucet limits' for details.",
-			hoursRemaining)
-		return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
-			Error: errorMsg,
-		})
-	}
+// dispense runs the rate limits and proof-of-work check for a request and
+// sends the tokens. RPC calls are bounded by RPC_TIMEOUT within parent.
+func (h *Handler) dispense(parent context.Context, log *zap.Logger, d dispenseRequest) (res *models.FaucetResponse, failure *faucetError) {
+	ctx := context.Background()
 
-	// Calculate how many requests this will consume (1 for single token, 2 for BOTH)
-	requestCost := 1
-	if req.Token == "BOTH" {
-		requestCost = 2
+	// BOTH and ALL skip paused tokens; a request for nothing else is refused
+	d.tokens, d.paused = h.splitPausedTokens(d.tokens)
+	if len(d.tokens) == 0 {
+		return nil, h.tokenPausedFailure(parent, log, d.paused)
 	}
+	req, ip, keyID, tokens := d.req, d.client, d.keyID, d.tokens
 
-	// Check if there's enough quota
-	if !canRequest || (currentCount+requestCost) > h.config.MaxRequestsPerDayIP {
-		used, _, _, _ := h.redis.GetIPDailyQuota(ctx, ip)
-		errorMsg := fmt.Sprintf("IP daily limit reached (%d/%d requests used). Run 'starknet-faucet limits' for details.",
-			used, h.config.MaxRequestsPerDayIP)
-		return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
-			Error: errorMsg,
-		})
+	// Don't spend the challenge or the rate limits while nothing can be sent
+	if !h.rpcHealth.available() {
+		return nil, h.rpcUnavailableFailure()
 	}
 
-	// 2. Check per-token hourly throttle
-	if req.Token == "BOTH" {
-		// For BOTH, check both STRK and ETH throttles
-		canRequestSTRK, nextSTRK, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, "STRK")
+	// One request per client at a time, so a double submit can't pass the rate
+	// checks twice before either is counted. API keys are limited per key
+	// rather than per IP, and allowlisted IPs have no limits to protect.
+	if keyID != "" || !h.isAllowlisted(ip) {
+		lockClient, holder := ip, "your IP"
+		if keyID != "" {
+			lockClient, holder = "apikey:"+keyID, "this API key"
+		}
+		lockTTL := time.Duration(h.config.RPCTimeout)*time.Second + requestLockMargin
+		lockToken, locked, err := h.redis.AcquireRequestLock(ctx, lockClient, lockTTL)
 		if err != nil {
-			h.logger.Error("Failed to check STRK throttle", zap.Error(err))
-			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			log.Error("Failed to acquire request lock", zap.Error(err))
+			return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
 				Error: "Failed to check rate limit",
-			})
+				Code:  models.ErrCodeInternal,
+			}}
 		}
-		if !canRequestSTRK {
-			minutesRemaining := int(time.Until(*nextSTRK).Minutes())
-			used, _, _, _ := h.redis.GetIPDailyQuota(ctx, ip)
-			errorMsg := fmt.Sprintf("STRK hourly throttle active. Next request in %d min. Daily quota: %d/%d used. Run 'starknet-faucet limits' for details.",
-				minutesRemaining, used, h.config.MaxRequestsPerDayIP)
-			return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
-				Error: errorMsg,
-			})
+		if !locked {
+			return nil, &faucetError{fiber.StatusConflict, models.ErrorResponse{
+				Error: fmt.Sprintf("A request from %s is already in progress. Wait for it to finish.", holder),
+				Code:  models.ErrCodeInProgress,
+			}}
 		}
+		defer func() {
+			if err := h.redis.ReleaseRequestLock(context.Background(), lockClient, lockToken); err != nil {
+				log.Error("Failed to release request lock", zap.Error(err))
+			}
+		}()
+	}
 
-		canRequestETH, nextETH, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, "ETH")
+	// A solved bonus challenge pays for a single-token request past the
+	// daily quota
+	useBonus := false
+
+	if keyID != "" {
+		used, err := h.redis.GetAPIKeyDailyUsage(ctx, keyID)
 		if err != nil {
-			h.logger.Error("Failed to check ETH throttle", zap.Error(err))
-			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			log.Error("Failed to check API key quota", zap.Error(err))
+			return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
 				Error: "Failed to check rate limit",
-			})
+				Code:  models.ErrCodeInternal,
+			}}
 		}
-		if !canRequestETH {
-			minutesRemaining := int(time.Until(*nextETH).Minutes())
-			used, _, _, _ := h.redis.GetIPDailyQuota(ctx, ip)
-			errorMsg := fmt.Sprintf("ETH hourly throttle active. Next request in %d min. Daily quota: %d/%d used. Run 'starknet-faucet limits' for details.",
-				minutesRemaining, used, h.config.MaxRequestsPerDayIP)
-			return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
-				Error: errorMsg,
-			})
+		if used+len(tokens) > h.config.MaxRequestsPerDayAPIKey {
+			return nil, &faucetError{fiber.StatusTooManyRequests, models.ErrorResponse{
+				Error: fmt.Sprintf("API key daily limit reached (%d/%d requests used).", used, h.config.MaxRequestsPerDayAPIKey),
+				Code:  models.ErrCodeRateLimited,
+			}}
 		}
-	} else {
-		// For single token, check that token's throttle
-		canRequestToken, nextAvailable, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, req.Token)
+	} else if !h.isAllowlisted(ip) {
+		// NEW SIMPLIFIED RATE LIMITING (allowlisted IPs are exempt)
+		// Calculate how many requests this will consume (1 per token)
+		requestCost := len(tokens)
+
+		// 1. Count the request against the IP daily limit (5 requests/day) and
+		// 24h cooldown in one step, so concurrent requests can't all pass the
+		// check. Whatever isn't sent is given back once the request ends.
+		reserved, _, cooldownEnd, err := h.redis.ReserveIPDailyLimit(ctx, ip, requestCost)
 		if err != nil {
-			h.logger.Error("Failed to check token throttle", zap.Error(err), zap.String("token", req.Token))
-			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			log.Error("Failed to check IP daily limit", zap.Error(err))
+			return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
 				Error: "Failed to check rate limit",
-			})
+				Code:  models.ErrCodeInternal,
+			}}
 		}
-		if !canRequestToken {
-			minutesRemaining := int(time.Until(*nextAvailable).Minutes())
-			used, _, _, _ := h.redis.GetIPDailyQuota(ctx, ip)
-			errorMsg := fmt.Sprintf("%s hourly throttle active. Next request in %d min. Daily quota: %d/%d used. Run 'starknet-faucet limits' for details.",
-				req.Token, minutesRemaining, used, h.config.MaxRequestsPerDayIP)
-			return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
+		if reserved {
+			defer func() {
+				sent := 0
+				if res != nil {
+					sent = len(res.Transactions)
+				}
+				h.releaseIPQuota(log, ip, requestCost-sent)
+			}()
+		}
+
+		overLimit := !reserved
+		if overLimit && !d.skipPoW && requestCost == 1 {
+			useBonus, err = h.bonusAvailable(ctx, ip, req.ChallengeID)
+			if err != nil {
+				log.Error("Failed to check bonus requests", zap.Error(err))
+				return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+					Error: "Failed to check rate limit",
+					Code:  models.ErrCodeInternal,
+				}}
+			}
+		}
+
+		// If in 24h cooldown after hitting limit
+		if cooldownEnd != nil && !useBonus {
+			hoursRemaining := time.Until(*cooldownEnd).Hours()
+			errorMsg := fmt.Sprintf("Daily limit reached. In cooldown (%.1f hours remaining). Run 'starknet-faucet limits' for details.",
+				hoursRemaining)
+			return nil, &faucetError{fiber.StatusTooManyRequests, models.ErrorResponse{
 				Error: errorMsg,
-			})
+				Code:  models.ErrCodeRateLimited,
+			}}
+		}
+
+		// Check if there's enough quota
+		if overLimit && !useBonus {
+			used, remaining, _, _, _ := h.redis.GetIPDailyQuota(ctx, ip)
+			errorMsg := fmt.Sprintf("IP daily limit reached (%d/%d requests used). Run 'starknet-faucet limits' for details.",
+				used, h.config.MaxRequestsPerDayIP)
+			// A multi-token request may not fit even though a single token would
+			var affordable []string
+			if requestCost > 1 && remaining > 0 {
+				affordable = h.unthrottledTokens(ctx, log, ip, tokens)
+				errorMsg = fmt.Sprintf("%s needs %d daily requests (1 per token) but only %d of your %d remain.",
+					req.Token, requestCost, remaining, h.config.MaxRequestsPerDayIP)
+				if len(affordable) > 0 {
+					errorMsg += fmt.Sprintf(" Request a single token instead: %s (use --token %s).", strings.Join(affordable, ", "), affordable[0])
+				}
+				errorMsg += " Run 'starknet-faucet limits' for details."
+			}
+			return nil, &faucetError{fiber.StatusTooManyRequests, models.ErrorResponse{
+				Error:           errorMsg,
+				Code:            models.ErrCodeRateLimited,
+				AvailableTokens: affordable,
+			}}
+		}
+
+		// 2. Check per-token hourly throttle for every requested token
+		for _, token := range tokens {
+			canRequestToken, nextAvailable, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, token)
+			if err != nil {
+				log.Error("Failed to check token throttle", zap.Error(err), zap.String("token", token))
+				return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+					Error: "Failed to check rate limit",
+					Code:  models.ErrCodeInternal,
+				}}
+			}
+			if !canRequestToken {
+				minutesRemaining := int(time.Until(*nextAvailable).Minutes())
+				used, _, _, _, _ := h.redis.GetIPDailyQuota(ctx, ip)
+				errorMsg := fmt.Sprintf("%s hourly throttle active. Next request in %d min. Daily quota: %d/%d used. Run 'starknet-faucet limits' for details.",
+					token, minutesRemaining, used, h.config.MaxRequestsPerDayIP)
+				return nil, &faucetError{fiber.StatusTooManyRequests, models.ErrorResponse{
+					Error: errorMsg,
+					Code:  models.ErrCodeRateLimited,
+				}}
+			}
+		}
+
+		// 3. Limit how many different addresses this IP can fund per day
+		if maxAddresses := h.config.MaxDistinctAddressesPerDay; maxAddresses > 0 {
+			known, err := h.redis.IsKnownRecipient(ctx, ip, recipientKey(req.Address))
+			var count int
+			if err == nil && !known {
+				count, err = h.redis.CountDistinctRecipients(ctx, ip)
+			}
+			if err != nil {
+				log.Error("Failed to check distinct recipients", zap.Error(err))
+				return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+					Error: "Failed to check rate limit",
+					Code:  models.ErrCodeInternal,
+				}}
+			}
+			if !known && count >= maxAddresses {
+				log.Warn("Distinct address limit reached", zap.String("ip", ip), zap.Int("addresses", count))
+				return nil, &faucetError{fiber.StatusTooManyRequests, models.ErrorResponse{
+					Error: fmt.Sprintf("This IP has already funded %d different addresses today, the daily maximum. Addresses funded earlier today can still request tokens.", count),
+					Code:  models.ErrCodeRateLimited,
+				}}
+			}
+		}
+
+		// 4. Spread quick repeat requests out with the burst allowance. A
+		// multi-token request takes at most the whole bucket, so it still fits.
+		if burst := h.config.BurstSize; burst > 0 {
+			cost := min(requestCost, burst)
+			allowed, wait, err := h.redis.TakeBurst(ctx, ip, cost, burst, h.config.RefillRate, time.Now())
+			if err != nil {
+				log.Error("Failed to check burst allowance", zap.Error(err))
+				return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+					Error: "Failed to check rate limit",
+					Code:  models.ErrCodeInternal,
+				}}
+			}
+			if !allowed {
+				seconds := int(math.Ceil(wait.Seconds()))
+				return nil, &faucetError{fiber.StatusTooManyRequests, models.ErrorResponse{
+					Error:      fmt.Sprintf("Too many requests in a short time. Next request in %d seconds. Run 'starknet-faucet limits' for details.", seconds),
+					Code:       models.ErrCodeRateLimited,
+					RetryAfter: seconds,
+				}}
+			}
+			defer func() {
+				sent := 0
+				if res != nil {
+					sent = len(res.Transactions)
+				}
+				h.returnBurst(log, ip, cost-min(sent, cost))
+			}()
 		}
 	}
 
-	// Verify challenge exists
-	storedChallenge, err := h.redis.GetChallenge(ctx, req.ChallengeID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Invalid or expired challenge",
-		})
+	if !d.skipPoW {
+		// Reject implausibly fast solves before consuming the challenge, so a
+		// client that submitted early can still resubmit once the floor passes
+		if h.config.MinSolveSeconds > 0 {
+			ttl := time.Duration(h.config.ChallengeTTL) * time.Second
+			minSolve := time.Duration(h.config.MinSolveSeconds) * time.Second
+			age, err := h.redis.ChallengeAge(ctx, req.ChallengeID, ttl)
+			if err == nil && age < minSolve {
+				log.Warn("Challenge submitted too fast",
+					zap.String("challenge_id", req.ChallengeID),
+					zap.Duration("age", age),
+					zap.String("ip", ip),
+				)
+				return nil, &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+					Error: fmt.Sprintf("Solution submitted %.1fs after the challenge was issued; this faucet requires at least %ds.",
+						age.Seconds(), h.config.MinSolveSeconds),
+					Code: models.ErrCodeSolvedTooFast,
+				}}
+			}
+			// Missing challenges are reported by ConsumeChallenge below
+		}
+
+		// Check the solution before anything is spent on the request, so an
+		// unsolved challenge can't be replayed to run the recipient check
+		storedChallenge, difficulty, boundToken, err := h.redis.GetChallenge(ctx, req.ChallengeID)
+		if err != nil {
+			return nil, &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid or expired challenge",
+				Code:  models.ErrCodeChallengeInvalid,
+			}}
+		}
+
+		// A challenge bound to a token only pays for that token. Unbound ones
+		// are refused once binding is required, even if issued before.
+		if boundToken != req.Token && (boundToken != "" || h.config.RequireChallengeToken) {
+			errorMsg := fmt.Sprintf("This challenge was issued for %s, not %s. Request a new challenge with ?token=%s.", boundToken, req.Token, req.Token)
+			if boundToken == "" {
+				errorMsg = fmt.Sprintf("This challenge isn't bound to a token. Request a new challenge with ?token=%s.", req.Token)
+			}
+			h.discardChallenge(log, req.ChallengeID)
+			return nil, &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+				Error: errorMsg,
+				Code:  models.ErrCodeChallengeInvalid,
+			}}
+		}
+
+		// Verify PoW solution
+		// Challenges issued during a velocity alert must meet their raised difficulty
+		if !h.powGenerator.VerifyPoW(storedChallenge, *req.Nonce, h.config.PoWDifficulty) ||
+			!pow.Solves(storedChallenge, *req.Nonce, difficulty) {
+			log.Warn("Invalid PoW solution",
+				zap.String("challenge_id", req.ChallengeID),
+				zap.Uint64("nonce", *req.Nonce),
+				zap.String("ip", ip),
+			)
+			h.discardChallenge(log, req.ChallengeID)
+			return nil, &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid proof of work solution",
+				Code:  models.ErrCodePoWInvalid,
+			}}
+		}
 	}
 
-	// Verify PoW solution
-	if !h.powGenerator.VerifyPoW(storedChallenge, req.Nonce, h.config.PoWDifficulty) {
-		h.logger.Warn("Invalid PoW solution",
-			zap.String("challenge_id", req.ChallengeID),
-			zap.Int64("nonce", req.Nonce),
-			zap.String("ip", ip),
-		)
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Invalid proof of work solution",
-		})
+	// Refuse recipients that aren't accounts before the challenge is spent
+	if failure := h.checkAccountRecipient(parent, log, req.Address); failure != nil {
+		return nil, failure
 	}
 
-	// Delete challenge to prevent reuse
-	if err := h.redis.DeleteChallenge(ctx, req.ChallengeID); err != nil {
-		h.logger.Error("Failed to delete challenge", zap.Error(err))
+	if !d.skipPoW {
+		// Consume challenge atomically (single-use, even under concurrent submits)
+		if _, _, _, err := h.redis.ConsumeChallenge(ctx, req.ChallengeID); err != nil {
+			return nil, &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+				Error: "Invalid or expired challenge",
+				Code:  models.ErrCodeChallengeInvalid,
+			}}
+		}
 	}
 
-	// Handle BOTH token request
-	if req.Token == "BOTH" {
-		return h.handleBothTokensRequest(c, ctx, req, ip)
+	// Handle multi-token request (BOTH or ALL), even when pausing left one token
+	if len(tokens) > 1 || len(d.paused) > 0 {
+		return h.handleMultiTokenRequest(parent, log, d)
 	}
 
+	// Bound all RPC calls for this request by the configured timeout
+	rpcCtx, cancel := h.rpcDeadline(parent)
+	defer cancel()
+
 	// Determine amount (single token)
 	var amountStr string
 	var amountFloat float64
 	var maxHourly, maxDaily float64
+	var err error
 	if req.Token == "STRK" {
-		amountStr = h.config.DripAmountSTRK
-		amountFloat, _ = strconv.ParseFloat(amountStr, 64)
+		amountStr, amountFloat, err = h.dripAmount(h.config.DripAmountSTRK)
 		maxHourly = h.config.MaxTokensPerHourSTRK
 		maxDaily = h.config.MaxTokensPerDaySTRK
 	} else {
-		amountStr = h.config.DripAmountETH
-		amountFloat, _ = strconv.ParseFloat(amountStr, 64)
+		amountStr, amountFloat, err = h.dripAmount(h.config.DripAmountETH)
 		maxHourly = h.config.MaxTokensPerHourETH
 		maxDaily = h.config.MaxTokensPerDayETH
 	}
+	if err != nil {
+		log.Error("Invalid drip amount", zap.Error(err), zap.String("token", req.Token))
+		return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to process request",
+			Code:  models.ErrCodeInternal,
+		}}
+	}
 
 	// Check global distribution limits (anti-drain protection)
 	canDistribute, err := h.redis.TrackGlobalDistribution(ctx, req.Token, amountFloat, maxHourly, maxDaily)
 	if err != nil {
-		h.logger.Error("Failed to check global distribution limits", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		log.Error("Failed to check global distribution limits", zap.Error(err))
+		return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to process request",
-		})
+			Code:  models.ErrCodeInternal,
+		}}
 	}
 	if !canDistribute {
-		h.logger.Warn("Global distribution limit reached",
+		log.Warn("Global distribution limit reached",
 			zap.String("token", req.Token),
 			zap.String("ip", ip),
 		)
-		return c.Status(fiber.StatusServiceUnavailable).JSON(models.ErrorResponse{
-			Error: "Faucet has reached its distribution limit. Please try again later.",
-		})
+		available := h.availableTokens(rpcCtx, log, req.Token)
+		return nil, &faucetError{fiber.StatusServiceUnavailable, models.ErrorResponse{
+			Error:           "Faucet has reached its distribution limit. Please try again later." + availableTokensHint(available),
+			Code:            models.ErrCodeDistributionLimit,
+			AvailableTokens: available,
+		}}
 	}
 
 	// Check minimum balance protection (stop at configured percentage)
-	currentBalance, err := h.starknet.GetBalance(ctx, h.config.FaucetAddress, req.Token)
+	currentBalance, err := h.starknet.FaucetBalance(rpcCtx, req.Token)
 	if err != nil {
-		h.logger.Error("Failed to check faucet balance", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		log.Error("Failed to check faucet balance", zap.Error(err))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, rpcTimeoutFailure()
+		}
+		if starknet.IsUnavailableError(err) {
+			h.rpcHealth.markDown(err)
+			return nil, h.rpcUnavailableFailure()
+		}
+		return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to check faucet balance",
-		})
+			Code:  models.ErrCodeInternal,
+		}}
 	}
 
 	// Convert amount to wei for comparison
 	amountWei := starknet.AmountToWei(amountFloat)
 
-	// Check if balance would drop below minimum threshold
-	minBalancePct := float64(h.config.MinBalanceProtectPct) / 100.0
-	currentBalanceFloat := starknet.WeiToAmount(currentBalance)
-	minBalanceRequired := currentBalanceFloat * minBalancePct
-	balanceAfterTransfer := currentBalanceFloat - amountFloat
-
-	if balanceAfterTransfer < minBalanceRequired {
-		h.logger.Warn("Balance protection triggered",
+	// Reserve the amount so concurrent requests can't collectively drain the
+	// faucet below the minimum threshold before their transfers land
+	reserved, err := h.reserveBalance(ctx, log, req.Token, amountFloat, currentBalance)
+	if err != nil {
+		log.Error("Failed to reserve balance", zap.Error(err))
+		return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to process request",
+			Code:  models.ErrCodeInternal,
+		}}
+	}
+	if !reserved {
+		currentBalanceFloat := starknet.WeiToAmount(currentBalance)
+		floorDetail := balanceFloorDetail(h.config.Tokens[req.Token], currentBalance)
+		log.Warn("Balance protection triggered",
 			zap.String("token", req.Token),
 			zap.Float64("current_balance", currentBalanceFloat),
-			zap.Float64("min_balance_required", minBalanceRequired),
+			zap.String("floor", floorDetail),
 			zap.String("ip", ip),
 		)
-		return c.Status(fiber.StatusServiceUnavailable).JSON(models.ErrorResponse{
-			Error: fmt.Sprintf("Faucet balance too low. Current %s balance: %.4f", req.Token, currentBalanceFloat),
-		})
+		available := h.availableTokens(rpcCtx, log, req.Token)
+		return nil, &faucetError{fiber.StatusServiceUnavailable, models.ErrorResponse{
+			Error:           fmt.Sprintf("Faucet balance too low. Current %s balance: %.4f (%s).%s", req.Token, currentBalanceFloat, floorDetail, availableTokensHint(available)),
+			Code:            models.ErrCodeFaucetEmpty,
+			AvailableTokens: available,
+		}}
 	}
 
+	warning := h.deploymentWarning(rpcCtx, log, req.Address)
+
 	// Transfer tokens
-	h.logger.Info("Transferring tokens",
+	log.Info("Transferring tokens",
 		zap.String("recipient", req.Address),
 		zap.String("token", req.Token),
 		zap.String("amount", amountStr),
 		zap.String("ip", ip),
 	)
 
-	txHash, err := h.starknet.TransferTokens(ctx, req.Address, req.Token, amountWei)
+	txHash, err := h.starknet.TransferTokens(rpcCtx, req.Address, req.Token, amountWei)
 	if err != nil {
-		h.logger.Error("Failed to transfer tokens",
+		h.releaseBalance(log, req.Token, amountFloat)
+		log.Error("Failed to transfer tokens",
 			zap.Error(err),
 			zap.String("recipient", req.Address),
 			zap.String("token", req.Token),
 		)
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, rpcTimeoutFailure()
+		}
+		if starknet.IsUnavailableError(err) {
+			h.rpcHealth.markDown(err)
+			return nil, h.rpcUnavailableFailure()
+		}
+		if errors.Is(err, starknet.ErrNoFundedAccount) {
+			return nil, &faucetError{fiber.StatusServiceUnavailable, models.ErrorResponse{
+				Error: fmt.Sprintf("Faucet balance too low: no faucet account can send %s %s and keep its protected balance. Please try again later.", amountStr, req.Token),
+				Code:  models.ErrCodeFaucetEmpty,
+			}}
+		}
+		errorCode := models.ErrCodeTransferFailed
+		if starknet.IsInsufficientFeeError(err) {
+			errorCode = models.ErrCodeFeeInsufficient
+		}
+		return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to send tokens. Please try again later.",
-		})
+			Code:  errorCode,
+		}}
 	}
 
-	// Increment IP daily counter (1 for single token)
-	if err := h.redis.IncrementIPDailyLimit(ctx, ip, 1); err != nil {
-		h.logger.Error("Failed to increment IP daily limit", zap.Error(err))
-	}
+	go h.releaseAfterConfirmation(log, req.Token, amountFloat, txHash)
+
+	if keyID != "" {
+		if err := h.redis.IncrementAPIKeyDailyUsage(ctx, keyID, 1); err != nil {
+			log.Error("Failed to increment API key quota", zap.Error(err))
+		}
+	} else if !h.isAllowlisted(ip) {
+		// The daily quota was counted when the request was reserved
+		if useBonus {
+			if err := h.redis.IncrementBonusRequests(ctx, ip); err != nil {
+				log.Error("Failed to increment bonus requests", zap.Error(err))
+			}
+		}
+
+		// Set token hourly throttle (1 hour cooldown for this token)
+		if err := h.redis.SetTokenHourlyThrottle(ctx, ip, req.Token); err != nil {
+			log.Error("Failed to set token throttle", zap.Error(err))
+		}
 
-	// Set token hourly throttle (1 hour cooldown for this token)
-	if err := h.redis.SetTokenHourlyThrottle(ctx, ip, req.Token); err != nil {
-		h.logger.Error("Failed to set token throttle", zap.Error(err))
+		h.trackRecipient(ctx, log, ip, req.Address)
 	}
 
-	// Build response
-	response := models.FaucetResponse{
-		Success:     true,
-		TxHash:      txHash,
-		Amount:      amountStr,
+	// Build response; the transfer is repeated in Transactions so clients see
+	// the same shape as for BOTH and ALL
+	tx := models.TransactionInfo{
 		Token:       req.Token,
+		Amount:      amountStr,
+		TxHash:      txHash,
 		ExplorerURL: h.config.GetExplorerURL(txHash),
-		Message:     "Tokens sent successfully",
+		Receipt:     h.signedReceipt(req.Address, req.Token, amountStr, txHash),
+	}
+	h.confirmTransaction(rpcCtx, log, &tx, d.wait)
+	response := models.FaucetResponse{
+		Success:            true,
+		TxHash:             tx.TxHash,
+		Amount:             tx.Amount,
+		Token:              tx.Token,
+		ExplorerURL:        tx.ExplorerURL,
+		Message:            "Tokens sent successfully",
+		Transactions:       []models.TransactionInfo{tx},
+		ConfirmationStatus: tx.ConfirmationStatus,
+		BlockNumber:        tx.BlockNumber,
+		FinalityStatus:     tx.FinalityStatus,
+		Warning:            warning,
 	}
 
-	h.logger.Info("Tokens sent successfully",
+	h.recordAudit(ctx, log, d.requestID, ip, keyID, req.Address, response.Transactions)
+	h.recordDistribution(ctx, log, response.Transactions)
+
+	log.Info("Tokens sent successfully",
 		zap.String("tx_hash", txHash),
 		zap.String("recipient", req.Address),
 		zap.String("token", req.Token),
 	)
 
-	return c.JSON(response)
+	return &response, nil
 }
 
 // GetStatus returns the status of an address
 func (h *Handler) GetStatus(c *fiber.Ctx) error {
-	ctx := context.Background()
+	log := h.requestLogger(c)
+	ctx := c.UserContext()
 
 	address := c.Params("address")
 
 	// Validate address
 	if err := utils.ValidateStarknetAddress(address); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
 			Error: fmt.Sprintf("Invalid address: %s", err.Error()),
+			Code:  models.ErrCodeInvalidAddress,
 		})
 	}
 
@@ -375,22 +934,48 @@ func (h *Handler) GetStatus(c *fiber.Ctx) error {
 	ip := c.IP()
 
 	// Get IP daily quota
-	used, remaining, cooldownEnd, err := h.redis.GetIPDailyQuota(ctx, ip)
+	used, remaining, cooldownEnd, resetAt, err := h.redis.GetIPDailyQuota(ctx, ip)
 	if err != nil {
-		h.logger.Error("Failed to get IP daily quota", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		log.Error("Failed to get IP daily quota", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to check status",
+			Code:  models.ErrCodeInternal,
 		})
 	}
 
-	canRequest := remaining > 0 && cooldownEnd == nil
+	// A request needs daily quota left and a token outside its hourly throttle
+	var next *time.Time
+	if cooldownEnd != nil {
+		next = cooldownEnd
+	} else if remaining <= 0 {
+		next = resetAt
+	}
+	throttleEnd, lastRequest, err := h.tokenThrottles(ctx, ip)
+	if err != nil {
+		log.Error("Failed to check token throttles", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to check status",
+			Code:  models.ErrCodeInternal,
+		})
+	}
+	if throttleEnd != nil && (next == nil || throttleEnd.After(*next)) {
+		next = throttleEnd
+	}
+	canRequest := remaining > 0 && cooldownEnd == nil && throttleEnd == nil
 
 	response := models.StatusResponse{
-		Address:    address,
-		CanRequest: canRequest,
+		Address:     address,
+		CanRequest:  canRequest,
+		LastRequest: lastRequest,
+		ResetAt:     resetAt,
+	}
+	if !canRequest && next != nil {
+		remainingHours := time.Until(*next).Hours()
+		response.NextRequestTime = next
+		response.RemainingHours = &remainingHours
 	}
 
-	h.logger.Info("Status check",
+	log.Info("Status check",
 		zap.String("address", address),
 		zap.String("ip", ip),
 		zap.Int("daily_quota_used", used),
@@ -400,226 +985,986 @@ func (h *Handler) GetStatus(c *fiber.Ctx) error {
 	return c.JSON(response)
 }
 
-// GetInfo returns information about the faucet
-func (h *Handler) GetInfo(c *fiber.Ctx) error {
-	ctx := context.Background()
+// tokenThrottles checks the hourly throttle of every token for ip. It returns
+// when the first token comes out of its throttle, nil if one already has, and
+// when ip last requested any token, nil if not within the throttle window.
+func (h *Handler) tokenThrottles(ctx context.Context, ip string) (throttleEnd, lastRequest *time.Time, err error) {
+	available := false
+	for _, token := range h.config.TokenSymbols() {
+		ok, nextAt, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, token)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			available = true
+		} else if nextAt != nil && (throttleEnd == nil || nextAt.Before(*throttleEnd)) {
+			throttleEnd = nextAt
+		}
 
-	// Get faucet balances
-	strkBalance, err := h.starknet.GetBalance(ctx, h.config.FaucetAddress, "STRK")
-	if err != nil {
-		h.logger.Error("Failed to get STRK balance", zap.Error(err))
-		strkBalance = nil
+		last, err := h.redis.GetTokenLastRequest(ctx, ip, token)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last != nil && (lastRequest == nil || last.After(*lastRequest)) {
+			lastRequest = last
+		}
 	}
 
-	ethBalance, err := h.starknet.GetBalance(ctx, h.config.FaucetAddress, "ETH")
-	if err != nil {
-		h.logger.Error("Failed to get ETH balance", zap.Error(err))
-		ethBalance = nil
+	if available {
+		throttleEnd = nil
 	}
+	return throttleEnd, lastRequest, nil
+}
+
+// GetInfo returns information about the faucet
+func (h *Handler) GetInfo(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx, cancel := h.rpcContext(c)
+	defer cancel()
+
+	// Get faucet balances (concurrently, nil on error)
+	balances := h.fetchBalances(ctx, log, h.config.TokenSymbols())
 
 	// Convert to readable format
 	strkBalanceStr := "0"
 	ethBalanceStr := "0"
-	if strkBalance != nil {
+	if strkBalance := balances["STRK"]; strkBalance != nil {
 		strkBalanceStr = fmt.Sprintf("%.2f", starknet.WeiToAmount(strkBalance))
 	}
-	if ethBalance != nil {
+	if ethBalance := balances["ETH"]; ethBalance != nil {
 		ethBalanceStr = fmt.Sprintf("%.4f", starknet.WeiToAmount(ethBalance))
 	}
 
 	response := models.InfoResponse{
-		Network: h.config.Network,
+		Network:         h.config.Network,
+		FaucetAddress:   h.config.FaucetAddress,
+		FaucetAddresses: h.starknet.AccountAddresses(),
 		Limits: models.LimitInfo{
 			StrkPerRequest:     h.config.DripAmountSTRK,
 			EthPerRequest:      h.config.DripAmountETH,
 			DailyRequestsPerIP: h.config.MaxRequestsPerDayIP,
-			TokenThrottleHours: 1, // 1 hour throttle per token
+			TokenThrottleHours: tokenThrottleHours,
+			ChallengesPerHour:  h.config.MaxChallengesPerHour,
+			Tokens:             h.tokenLimits(),
 		},
 		PoW: models.PoWInfo{
-			Enabled:    true,
-			Difficulty: h.config.PoWDifficulty,
+			Enabled:       true,
+			Difficulty:    h.velocity.difficulty(),
+			TokenRequired: h.config.RequireChallengeToken,
 		},
 		FaucetBalance: models.BalanceInfo{
 			STRK: strkBalanceStr,
 			ETH:  ethBalanceStr,
 		},
+		AvailableTokens:         h.dispensableTokens(ctx, log, balances),
+		EstimatedArrivalSeconds: h.config.EstimatedArrivalSeconds,
+	}
+	if h.config.SignReceipts {
+		response.ReceiptPublicKey = receipt.PublicKey(h.config.ReceiptKey)
+	}
+	_, response.PausedTokens = h.splitPausedTokens(h.config.TokenSymbols())
+	if fee := h.estimatedFee(ctx, log); fee != nil {
+		response.EstimatedFee = &models.FeeEstimate{
+			Amount: fmt.Sprintf("%.6f", starknet.WeiToAmount(fee)),
+			Token:  h.starknet.FeeToken(),
+		}
+	}
+	if h.config.BonusDifficulty > 0 {
+		response.Limits.BonusRequestsPerDay = h.config.MaxBonusRequestsPerDay
+		response.PoW.BonusDifficulty = response.PoW.Difficulty + h.config.BonusDifficulty
 	}
 
 	return c.JSON(response)
 }
 
-// handleBothTokensRequest handles requests for both STRK and ETH tokens
-func (h *Handler) handleBothTokensRequest(c *fiber.Ctx, ctx context.Context, req models.FaucetRequest, ip string) error {
-	// Process both STRK and ETH
-	tokens := []string{"STRK", "ETH"}
+// tokenLimits describes how often one IP can request each token. The hourly
+// throttle caps a token at 24/tokenThrottleHours requests a day, and the
+// daily limit shared by all tokens may cap it lower.
+func (h *Handler) tokenLimits() []models.TokenLimitInfo {
+	perDay := min(24/tokenThrottleHours, h.config.MaxRequestsPerDayIP)
+	var limits []models.TokenLimitInfo
+	for _, symbol := range h.config.TokenSymbols() {
+		limits = append(limits, models.TokenLimitInfo{
+			Symbol:           symbol,
+			AmountPerRequest: h.config.Tokens[symbol].DripAmount,
+			ThrottleHours:    tokenThrottleHours,
+			RequestsPerDay:   perDay,
+		})
+	}
+	return limits
+}
+
+// fetchBalances reads the faucet balance of each token concurrently.
+// Tokens whose balance can't be read map to nil.
+func (h *Handler) fetchBalances(ctx context.Context, log *zap.Logger, tokens []string) map[string]*big.Int {
+	balances := make(map[string]*big.Int, len(tokens))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentBalanceFetches)
+
+	for _, token := range tokens {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			balance, err := h.starknet.FaucetBalance(ctx, token)
+			if err != nil {
+				log.Error("Failed to get balance", zap.Error(err), zap.String("token", token))
+				balance = nil
+			}
+
+			mu.Lock()
+			balances[token] = balance
+			mu.Unlock()
+		}(token)
+	}
+
+	wg.Wait()
+	return balances
+}
+
+// GetTokens returns the supported tokens with their drip amounts and limits
+func (h *Handler) GetTokens(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx, cancel := h.rpcContext(c)
+	defer cancel()
+
+	balances := h.fetchBalances(ctx, log, h.config.TokenSymbols())
+
+	tokens := make([]models.TokenInfo, 0, len(h.config.Tokens))
+	for _, symbol := range h.config.TokenSymbols() {
+		tokenCfg := h.config.Tokens[symbol]
+
+		exhausted, err := h.isDistributionExhausted(ctx, tokenCfg)
+		if err != nil {
+			log.Error("Failed to get global distribution", zap.Error(err), zap.String("token", symbol))
+			return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to get token limits",
+				Code:  models.ErrCodeInternal,
+			})
+		}
+
+		tokens = append(tokens, models.TokenInfo{
+			Symbol:          tokenCfg.Symbol,
+			ContractAddress: tokenCfg.Address,
+			Decimals:        tokenCfg.Decimals,
+			DripAmount:      tokenCfg.DripAmount,
+			ThrottleHours:   tokenThrottleHours,
+			MaxPerHour:      tokenCfg.MaxPerHour,
+			MaxPerDay:       tokenCfg.MaxPerDay,
+			LimitExhausted:  exhausted,
+			Dispensable:     !tokenCfg.Paused && !exhausted && h.hasBalanceForDrip(tokenCfg, balances[symbol]),
+			Paused:          tokenCfg.Paused,
+		})
+	}
+
+	return c.JSON(models.TokensResponse{Tokens: tokens})
+}
+
+// isDistributionExhausted reports whether another drip of the token would exceed
+// its global hourly or daily distribution limit
+func (h *Handler) isDistributionExhausted(ctx context.Context, tokenCfg config.TokenConfig) (bool, error) {
+	if tokenCfg.MaxPerHour == 0 && tokenCfg.MaxPerDay == 0 {
+		return false, nil
+	}
+
+	hourly, daily, err := h.redis.GetGlobalDistribution(ctx, tokenCfg.Symbol)
+	if err != nil {
+		return false, err
+	}
+
+	amount, err := config.ParseDripAmount(tokenCfg.DripAmount)
+	if err != nil {
+		return false, err
+	}
+	if tokenCfg.MaxPerHour > 0 && hourly+amount > tokenCfg.MaxPerHour {
+		return true, nil
+	}
+	if tokenCfg.MaxPerDay > 0 && daily+amount > tokenCfg.MaxPerDay {
+		return true, nil
+	}
+	return false, nil
+}
+
+// isTokenDispensable reports whether the faucet can currently send a drip of
+// token: its global distribution limit isn't reached and the transfer wouldn't
+// breach minimum balance protection
+func (h *Handler) isTokenDispensable(ctx context.Context, token string) (bool, error) {
+	tokenCfg, ok := h.config.Tokens[token]
+	if !ok {
+		return false, fmt.Errorf("unsupported token: %s", token)
+	}
+	if tokenCfg.Paused {
+		return false, nil
+	}
+
+	exhausted, err := h.isDistributionExhausted(ctx, tokenCfg)
+	if err != nil || exhausted {
+		return false, err
+	}
+
+	balance, err := h.starknet.FaucetBalance(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	return h.hasBalanceForDrip(tokenCfg, balance), nil
+}
+
+// hasBalanceForDrip reports whether balance covers a drip of the token without
+// triggering balance protection. A nil balance (failed lookup) or an invalid
+// drip amount counts as no.
+func (h *Handler) hasBalanceForDrip(tokenCfg config.TokenConfig, balance *big.Int) bool {
+	if balance == nil {
+		return false
+	}
+	amount, err := config.ParseDripAmount(tokenCfg.DripAmount)
+	if err != nil {
+		return false
+	}
+	return !h.isBalanceProtected(tokenCfg, balance, amount)
+}
+
+// isBalanceProtected reports whether sending amount would take the faucet
+// below the token's minimum balance threshold
+func (h *Handler) isBalanceProtected(tokenCfg config.TokenConfig, balance *big.Int, amount float64) bool {
+	currentBalance := starknet.WeiToAmount(balance)
+	floor, _ := balanceFloor(tokenCfg, currentBalance)
+	return currentBalance-amount < floor
+}
+
+// balanceFloor returns the balance the faucet must keep for the token, the
+// larger of its percentage and absolute floors, and which of the two applies
+func balanceFloor(tokenCfg config.TokenConfig, balance float64) (float64, string) {
+	pctFloor := balance * float64(tokenCfg.MinBalanceProtectPct) / 100.0
+	if tokenCfg.MinBalanceFloor > pctFloor {
+		return tokenCfg.MinBalanceFloor, "absolute floor"
+	}
+	return pctFloor, fmt.Sprintf("%d%% floor", tokenCfg.MinBalanceProtectPct)
+}
+
+// balanceFloorDetail describes the floor protecting the token's balance for
+// error messages, e.g. "absolute floor: 50.0000 STRK"
+func balanceFloorDetail(tokenCfg config.TokenConfig, balance *big.Int) string {
+	floor, kind := balanceFloor(tokenCfg, starknet.WeiToAmount(balance))
+	return fmt.Sprintf("%s: %.4f %s", kind, floor, tokenCfg.Symbol)
+}
+
+// reserveBalance reserves amount of token against the faucet balance and reports
+// whether the balance stays above protection once every in-flight transfer
+// lands. A reservation that doesn't fit is released again.
+func (h *Handler) reserveBalance(ctx context.Context, log *zap.Logger, token string, amount float64, balance *big.Int) (bool, error) {
+	reserved, err := h.redis.ReserveBalance(ctx, token, amount)
+	if err != nil {
+		return false, err
+	}
+
+	if h.isBalanceProtected(h.config.Tokens[token], balance, reserved) {
+		h.releaseBalance(log, token, amount)
+		return false, nil
+	}
+	return true, nil
+}
+
+// releaseBalance drops a balance reservation
+func (h *Handler) releaseBalance(log *zap.Logger, token string, amount float64) {
+	if err := h.redis.ReleaseBalance(context.Background(), token, amount); err != nil {
+		log.Error("Failed to release balance reservation", zap.Error(err), zap.String("token", token))
+	}
+}
+
+// releaseAfterConfirmation releases a reservation once its transfer is executed,
+// or after reservationConfirmTimeout if it never is
+func (h *Handler) releaseAfterConfirmation(log *zap.Logger, token string, amount float64, txHash string) {
+	ctx, cancel := context.WithTimeout(context.Background(), reservationConfirmTimeout)
+	defer cancel()
+
+	// The faucet balance reflects a transfer once it is pre-confirmed
+	if _, _, err := h.starknet.WaitForTransaction(ctx, txHash, starknet.ConfirmationPreConfirmed); err != nil {
+		log.Warn("Transfer not confirmed, releasing reservation anyway",
+			zap.Error(err),
+			zap.String("tx_hash", txHash),
+			zap.String("token", token),
+		)
+	}
+	h.releaseBalance(log, token, amount)
+}
+
+// confirmTransaction sets how far tx has progressed. Once a ?wait=true request
+// sees it executed, the block and finality from its receipt are added too.
+func (h *Handler) confirmTransaction(ctx context.Context, log *zap.Logger, tx *models.TransactionInfo, wait bool) {
+	status, receipt := h.confirmationStatus(ctx, log, tx.TxHash, wait)
+	tx.ConfirmationStatus = status
+	if receipt != nil {
+		tx.BlockNumber = receipt.BlockNumber
+		tx.FinalityStatus = receipt.FinalityStatus
+	}
+}
+
+// confirmationStatus reports how far a transfer just sent has progressed. With
+// wait (?wait=true) it first waits, bounded by ctx, for CONFIRMATION_LEVEL and
+// returns the receipt the wait saw, if any; otherwise the node has only
+// received it.
+func (h *Handler) confirmationStatus(ctx context.Context, log *zap.Logger, txHash string, wait bool) (string, *starknet.Receipt) {
+	if !wait {
+		return starknet.ConfirmationReceived, nil
+	}
+
+	status, receipt, err := h.starknet.WaitForTransaction(ctx, txHash, h.config.ConfirmationLevel)
+	if err != nil {
+		log.Warn("Transfer not confirmed before responding",
+			zap.Error(err),
+			zap.String("tx_hash", txHash),
+			zap.String("status", status),
+		)
+	}
+	if status == "" {
+		return starknet.ConfirmationReceived, receipt
+	}
+	return status, receipt
+}
+
+// availableTokens returns the tokens other than exclude that can currently be
+// dispensed, so a user asking for an empty token can switch
+func (h *Handler) availableTokens(ctx context.Context, log *zap.Logger, exclude string) []string {
+	var available []string
+	for _, symbol := range h.config.TokenSymbols() {
+		if symbol == exclude {
+			continue
+		}
+		ok, err := h.isTokenDispensable(ctx, symbol)
+		if err != nil {
+			log.Error("Failed to check token availability", zap.Error(err), zap.String("token", symbol))
+			continue
+		}
+		if ok {
+			available = append(available, symbol)
+		}
+	}
+	return available
+}
+
+// dispensableTokens filters already-fetched balances down to the tokens that
+// can currently be dispensed, in symbol order
+func (h *Handler) dispensableTokens(ctx context.Context, log *zap.Logger, balances map[string]*big.Int) []string {
+	available := []string{}
+	for _, symbol := range h.config.TokenSymbols() {
+		balance, ok := balances[symbol]
+		if !ok {
+			continue
+		}
+		tokenCfg := h.config.Tokens[symbol]
+		if tokenCfg.Paused {
+			continue
+		}
+		exhausted, err := h.isDistributionExhausted(ctx, tokenCfg)
+		if err != nil {
+			log.Error("Failed to get global distribution", zap.Error(err), zap.String("token", symbol))
+			continue
+		}
+		if !exhausted && h.hasBalanceForDrip(tokenCfg, balance) {
+			available = append(available, symbol)
+		}
+	}
+	return available
+}
+
+// unthrottledTokens returns the tokens whose hourly throttle has expired for ip
+func (h *Handler) unthrottledTokens(ctx context.Context, log *zap.Logger, ip string, tokens []string) []string {
+	var unthrottled []string
+	for _, token := range tokens {
+		canRequest, _, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, token)
+		if err != nil {
+			log.Error("Failed to check token throttle", zap.Error(err), zap.String("token", token))
+			continue
+		}
+		if canRequest {
+			unthrottled = append(unthrottled, token)
+		}
+	}
+	return unthrottled
+}
+
+// bonusAvailable reports whether challengeID is a bonus challenge and ip has
+// bonus requests left today. The challenge is only peeked at; it is consumed
+// and its solution checked with the rest of the PoW.
+func (h *Handler) bonusAvailable(ctx context.Context, ip, challengeID string) (bool, error) {
+	if h.config.BonusDifficulty <= 0 {
+		return false, nil
+	}
+	// Missing challenges are reported when the PoW is checked
+	bonus, err := h.redis.IsBonusChallenge(ctx, challengeID)
+	if err != nil || !bonus {
+		return false, nil
+	}
+	used, err := h.redis.BonusRequestsUsed(ctx, ip)
+	if err != nil {
+		return false, err
+	}
+	return used < h.config.MaxBonusRequestsPerDay, nil
+}
+
+// availableTokensHint suggests switching to one of the available tokens
+func availableTokensHint(available []string) string {
+	if len(available) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" Available now: %s (use --token %s).", strings.Join(available, ", "), available[0])
+}
+
+// splitPausedTokens separates the tokens the operator paused from the rest,
+// keeping their order
+func (h *Handler) splitPausedTokens(tokens []string) (enabled, paused []string) {
+	for _, token := range tokens {
+		if h.config.Tokens[token].Paused {
+			paused = append(paused, token)
+		} else {
+			enabled = append(enabled, token)
+		}
+	}
+	return enabled, paused
+}
+
+// tokenPausedFailure refuses a request for paused tokens only, suggesting
+// the tokens that can be requested instead
+func (h *Handler) tokenPausedFailure(parent context.Context, log *zap.Logger, paused []string) *faucetError {
+	rpcCtx, cancel := h.rpcDeadline(parent)
+	defer cancel()
+	available := h.availableTokens(rpcCtx, log, "")
+
+	return &faucetError{fiber.StatusServiceUnavailable, models.ErrorResponse{
+		Error:           fmt.Sprintf("%s distribution is paused on this faucet. Please try again later.", strings.Join(paused, " and ")) + availableTokensHint(available),
+		Code:            models.ErrCodeTokenDisabled,
+		AvailableTokens: available,
+	}}
+}
+
+// requestedTokens expands a requested token value into the tokens to send.
+// BOTH means STRK and ETH, ALL means every supported token.
+func (h *Handler) requestedTokens(token string) ([]string, error) {
+	switch token {
+	case "BOTH":
+		return []string{"STRK", "ETH"}, nil
+	case "ALL":
+		return h.config.TokenSymbols(), nil
+	}
+	if err := utils.ValidateToken(token); err != nil {
+		return nil, err
+	}
+	return []string{token}, nil
+}
+
+// handleMultiTokenRequest handles requests for several tokens at once (BOTH or ALL)
+func (h *Handler) handleMultiTokenRequest(parent context.Context, log *zap.Logger, d dispenseRequest) (*models.FaucetResponse, *faucetError) {
+	ctx := context.Background()
+	req, ip, keyID, tokens := d.req, d.client, d.keyID, d.tokens
+
 	var transactions []models.TransactionInfo
 	var failedToken string
+	var failedCode string
+	var failedDetail string
+
+	// Bound all RPC calls for this request by the configured timeout
+	rpcCtx, cancel := h.rpcDeadline(parent)
+	defer cancel()
+
+	warning := h.deploymentWarning(rpcCtx, log, req.Address)
 
 	for _, token := range tokens {
 		// Determine amount
-		var amountStr string
-		var amountFloat float64
-		var maxHourly, maxDaily float64
-		if token == "STRK" {
-			amountStr = h.config.DripAmountSTRK
-			amountFloat, _ = strconv.ParseFloat(amountStr, 64)
-			maxHourly = h.config.MaxTokensPerHourSTRK
-			maxDaily = h.config.MaxTokensPerDaySTRK
-		} else {
-			amountStr = h.config.DripAmountETH
-			amountFloat, _ = strconv.ParseFloat(amountStr, 64)
-			maxHourly = h.config.MaxTokensPerHourETH
-			maxDaily = h.config.MaxTokensPerDayETH
+		tokenCfg := h.config.Tokens[token]
+		amountStr, amountFloat, err := h.dripAmount(tokenCfg.DripAmount)
+		if err != nil {
+			log.Error("Invalid drip amount", zap.Error(err), zap.String("token", token))
+			failedToken = token
+			failedCode = models.ErrCodeInternal
+			break
 		}
+		maxHourly, maxDaily := tokenCfg.MaxPerHour, tokenCfg.MaxPerDay
 
 		// Check global distribution limits
 		canDistribute, err := h.redis.TrackGlobalDistribution(ctx, token, amountFloat, maxHourly, maxDaily)
 		if err != nil {
-			h.logger.Error("Failed to check global distribution limits", zap.Error(err), zap.String("token", token))
+			log.Error("Failed to check global distribution limits", zap.Error(err), zap.String("token", token))
 			failedToken = token
+			failedCode = models.ErrCodeInternal
 			break
 		}
 		if !canDistribute {
-			h.logger.Warn("Global distribution limit reached", zap.String("token", token), zap.String("ip", ip))
+			log.Warn("Global distribution limit reached", zap.String("token", token), zap.String("ip", ip))
 			failedToken = token
+			failedCode = models.ErrCodeDistributionLimit
 			break
 		}
 
 		// Check minimum balance protection
-		currentBalance, err := h.starknet.GetBalance(ctx, h.config.FaucetAddress, token)
+		currentBalance, err := h.starknet.FaucetBalance(rpcCtx, token)
 		if err != nil {
-			h.logger.Error("Failed to check faucet balance", zap.Error(err), zap.String("token", token))
+			log.Error("Failed to check faucet balance", zap.Error(err), zap.String("token", token))
 			failedToken = token
+			failedCode = models.ErrCodeInternal
+			if errors.Is(err, context.DeadlineExceeded) {
+				failedCode = models.ErrCodeRPCTimeout
+			} else if starknet.IsUnavailableError(err) {
+				h.rpcHealth.markDown(err)
+				failedCode = models.ErrCodeRPCUnavailable
+			}
 			break
 		}
 
 		amountWei := starknet.AmountToWei(amountFloat)
-		minBalancePct := float64(h.config.MinBalanceProtectPct) / 100.0
-		currentBalanceFloat := starknet.WeiToAmount(currentBalance)
-		minBalanceRequired := currentBalanceFloat * minBalancePct
-		balanceAfterTransfer := currentBalanceFloat - amountFloat
-
-		if balanceAfterTransfer < minBalanceRequired {
-			h.logger.Warn("Balance protection triggered", zap.String("token", token), zap.Float64("current_balance", currentBalanceFloat))
+		reserved, err := h.reserveBalance(ctx, log, token, amountFloat, currentBalance)
+		if err != nil {
+			log.Error("Failed to reserve balance", zap.Error(err), zap.String("token", token))
 			failedToken = token
+			failedCode = models.ErrCodeInternal
+			break
+		}
+		if !reserved {
+			failedDetail = balanceFloorDetail(tokenCfg, currentBalance)
+			log.Warn("Balance protection triggered", zap.String("token", token), zap.Float64("current_balance", starknet.WeiToAmount(currentBalance)), zap.String("floor", failedDetail))
+			failedToken = token
+			failedCode = models.ErrCodeFaucetEmpty
 			break
 		}
 
 		// Transfer tokens
-		h.logger.Info("Transferring tokens", zap.String("recipient", req.Address), zap.String("token", token), zap.String("amount", amountStr))
+		log.Info("Transferring tokens", zap.String("recipient", req.Address), zap.String("token", token), zap.String("amount", amountStr))
 
-		txHash, err := h.starknet.TransferTokens(ctx, req.Address, token, amountWei)
+		txHash, err := h.starknet.TransferTokens(rpcCtx, req.Address, token, amountWei)
 		if err != nil {
-			h.logger.Error("Failed to transfer tokens", zap.Error(err), zap.String("token", token))
+			h.releaseBalance(log, token, amountFloat)
+			log.Error("Failed to transfer tokens", zap.Error(err), zap.String("token", token))
 			failedToken = token
+			failedCode = models.ErrCodeTransferFailed
+			if errors.Is(err, context.DeadlineExceeded) {
+				failedCode = models.ErrCodeRPCTimeout
+			} else if starknet.IsUnavailableError(err) {
+				h.rpcHealth.markDown(err)
+				failedCode = models.ErrCodeRPCUnavailable
+			} else if errors.Is(err, starknet.ErrNoFundedAccount) {
+				failedCode = models.ErrCodeFaucetEmpty
+				failedDetail = "no faucet account can cover it"
+			} else if starknet.IsInsufficientFeeError(err) {
+				failedCode = models.ErrCodeFeeInsufficient
+			}
 			break
 		}
 
+		go h.releaseAfterConfirmation(log, token, amountFloat, txHash)
+
 		// Add to transactions list
 		transactions = append(transactions, models.TransactionInfo{
 			Token:       token,
 			Amount:      amountStr,
 			TxHash:      txHash,
 			ExplorerURL: h.config.GetExplorerURL(txHash),
+			Receipt:     h.signedReceipt(req.Address, token, amountStr, txHash),
 		})
 
-		h.logger.Info("Tokens sent successfully", zap.String("tx_hash", txHash), zap.String("token", token))
+		log.Info("Tokens sent successfully", zap.String("tx_hash", txHash), zap.String("token", token))
 	}
 
 	// If any token failed and we have partial success, still return success with what worked
 	if len(transactions) > 0 {
-		// Increment IP daily counter by 2 (BOTH = 1 STRK + 1 ETH)
-		if err := h.redis.IncrementIPDailyLimit(ctx, ip, 2); err != nil {
-			h.logger.Error("Failed to increment IP daily limit", zap.Error(err))
+		// Only charge quota and throttle for tokens that were actually sent
+		h.recordSuccessfulTransfers(ctx, log, ip, keyID, req.Address, transactions)
+		h.recordAudit(ctx, log, d.requestID, ip, keyID, req.Address, transactions)
+		h.recordDistribution(ctx, log, transactions)
+
+		wait := d.wait
+		for i := range transactions {
+			h.confirmTransaction(rpcCtx, log, &transactions[i], wait)
 		}
 
-		// Set hourly throttle for both tokens
-		for _, tx := range transactions {
-			if err := h.redis.SetTokenHourlyThrottle(ctx, ip, tx.Token); err != nil {
-				h.logger.Error("Failed to set token throttle", zap.Error(err), zap.String("token", tx.Token))
-			}
+		message := "All tokens sent successfully"
+		if req.Token == "BOTH" {
+			message = "Both tokens sent successfully"
 		}
-
-		message := "Both tokens sent successfully"
 		if failedToken != "" {
 			message = fmt.Sprintf("Sent %d token(s) successfully, but %s failed", len(transactions), failedToken)
+		} else if len(d.paused) > 0 {
+			message = fmt.Sprintf("Sent %d token(s) successfully", len(transactions))
+		}
+		if len(d.paused) > 0 {
+			message += fmt.Sprintf("; skipped %s, paused by the operator", strings.Join(d.paused, " and "))
 		}
 
-		return c.JSON(models.FaucetResponse{
+		return &models.FaucetResponse{
 			Success:      true,
 			Transactions: transactions,
 			Message:      message,
-		})
+			Warning:      warning,
+			PausedTokens: d.paused,
+		}, nil
 	}
 
 	// If no transactions succeeded, return error
-	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+	if failedCode == models.ErrCodeRPCTimeout {
+		return nil, rpcTimeoutFailure()
+	}
+	if failedCode == models.ErrCodeRPCUnavailable {
+		return nil, h.rpcUnavailableFailure()
+	}
+	// An empty or capped token is the faucet's state, not a server error, so
+	// it is answered like the single-token path with the tokens still available
+	switch failedCode {
+	case models.ErrCodeFaucetEmpty, models.ErrCodeDistributionLimit:
+		errorMsg := fmt.Sprintf("Faucet %s balance too low (%s). Please try again later.", failedToken, failedDetail)
+		if failedCode == models.ErrCodeDistributionLimit {
+			errorMsg = fmt.Sprintf("Faucet has reached its %s distribution limit. Please try again later.", failedToken)
+		}
+		available := h.availableTokens(rpcCtx, log, failedToken)
+		return nil, &faucetError{fiber.StatusServiceUnavailable, models.ErrorResponse{
+			Error:           errorMsg + availableTokensHint(available),
+			Code:            failedCode,
+			AvailableTokens: available,
+		}}
+	}
+	return nil, &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
 		Error: fmt.Sprintf("Failed to send %s tokens. Please try again later.", failedToken),
-	})
+		Code:  failedCode,
+	}}
+}
+
+// dripJitterDecimals is how many decimal places a jittered drip is rounded to
+const dripJitterDecimals = 6
+
+// dripAmount returns the amount to send for a drip of base, both as the
+// string reported to clients and as a number. With DRIP_JITTER_PCT set it is
+// randomized within ±that percent of base; the result is what gets tracked,
+// reserved and sent, so limits see the real amount. Config validation rejects
+// bad amounts at startup, so an error here means the handler was misconfigured.
+func (h *Handler) dripAmount(base string) (string, float64, error) {
+	amount, err := config.ParseDripAmount(base)
+	if err != nil {
+		return "", 0, err
+	}
+	if h.config.DripJitterPct <= 0 {
+		return base, amount, nil
+	}
+
+	factor := 1 + (rand.Float64()*2-1)*h.config.DripJitterPct/100
+	scale := math.Pow10(dripJitterDecimals)
+	amount = math.Round(amount*factor*scale) / scale
+	return strconv.FormatFloat(amount, 'f', -1, 64), amount, nil
+}
+
+// recordSuccessfulTransfers sets the hourly throttle only for the tokens sent,
+// so a partial failure doesn't penalize the user for tokens they never
+// received. The IP's daily quota was reserved up front by dispense, which
+// gives back the unsent part. Requests made with an API key (keyID set)
+// charge the key's quota instead.
+func (h *Handler) recordSuccessfulTransfers(ctx context.Context, log *zap.Logger, ip, keyID, address string, transactions []models.TransactionInfo) {
+	if len(transactions) == 0 {
+		return
+	}
+
+	if keyID != "" {
+		if err := h.redis.IncrementAPIKeyDailyUsage(ctx, keyID, len(transactions)); err != nil {
+			log.Error("Failed to increment API key quota", zap.Error(err))
+		}
+		return
+	}
+
+	if h.isAllowlisted(ip) {
+		return
+	}
+
+	for _, tx := range transactions {
+		if err := h.redis.SetTokenHourlyThrottle(ctx, ip, tx.Token); err != nil {
+			log.Error("Failed to set token throttle", zap.Error(err), zap.String("token", tx.Token))
+		}
+	}
+
+	h.trackRecipient(ctx, log, ip, address)
+}
+
+// releaseIPQuota gives back n daily requests reserved for tokens that weren't
+// sent. It runs after the request is answered, so a failure only gets logged.
+func (h *Handler) releaseIPQuota(log *zap.Logger, ip string, n int) {
+	if n <= 0 {
+		return
+	}
+	if err := h.redis.ReleaseIPDailyLimit(context.Background(), ip, n); err != nil {
+		log.Error("Failed to release IP daily limit", zap.Error(err), zap.Int("requests", n))
+	}
+}
+
+// signedReceipt returns the faucet's signed receipt for a transfer, or nil
+// when SIGN_RECEIPTS is off
+func (h *Handler) signedReceipt(address, token, amount, txHash string) *models.Receipt {
+	if !h.config.SignReceipts {
+		return nil
+	}
+	r := &models.Receipt{
+		Recipient: utils.NormalizeStarknetAddress(address),
+		Token:     token,
+		Amount:    amount,
+		TxHash:    txHash,
+		Timestamp: time.Now().Unix(),
+	}
+	receipt.Sign(h.config.ReceiptKey, r)
+	return r
+}
+
+// returnBurst gives n requests back to an IP's burst allowance when they
+// weren't sent. Like releaseIPQuota, a failure only gets logged.
+func (h *Handler) returnBurst(log *zap.Logger, ip string, n int) {
+	if n <= 0 {
+		return
+	}
+	if err := h.redis.ReturnBurst(context.Background(), ip, n, h.config.BurstSize, h.config.RefillRate, time.Now()); err != nil {
+		log.Error("Failed to return burst allowance", zap.Error(err), zap.Int("requests", n))
+	}
+}
+
+// deploymentWarning returns a warning for the response when CHECK_DEPLOYMENT
+// is on and no contract is deployed at address. Tokens can still be sent to
+// it, so this never blocks the request, and a failed check only gets logged.
+func (h *Handler) deploymentWarning(ctx context.Context, log *zap.Logger, address string) string {
+	if !h.config.CheckDeployment {
+		return ""
+	}
+
+	deployed, err := h.starknet.IsDeployed(ctx, address)
+	if err != nil {
+		log.Warn("Failed to check recipient deployment", zap.Error(err), zap.String("recipient", address))
+		return ""
+	}
+	if deployed {
+		return ""
+	}
+	return "No account is deployed at this address yet. The tokens will arrive, but most wallets must deploy the account before it can use them."
+}
+
+// discardChallenge deletes a challenge that was answered wrongly, so each
+// challenge gets a single attempt
+func (h *Handler) discardChallenge(log *zap.Logger, challengeID string) {
+	if err := h.redis.DeleteChallenge(context.Background(), challengeID); err != nil {
+		log.Error("Failed to delete challenge", zap.Error(err))
+	}
+}
+
+// checkAccountRecipient refuses address when REQUIRE_ACCOUNT_RECIPIENT is on
+// and it isn't a deployed account contract. The RPC call is bounded by
+// RPC_TIMEOUT within parent.
+func (h *Handler) checkAccountRecipient(parent context.Context, log *zap.Logger, address string) *faucetError {
+	if !h.config.RequireAccountRecipient {
+		return nil
+	}
+
+	rpcCtx, cancel := h.rpcDeadline(parent)
+	defer cancel()
+	isAccount, err := h.starknet.IsAccount(rpcCtx, address)
+	switch {
+	case errors.Is(err, starknet.ErrAccountNotDeployed):
+		return &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "No account is deployed at this address. This faucet only funds deployed accounts, so deploy the account first.",
+			Code:  models.ErrCodeInvalidAddress,
+		}}
+	case err != nil:
+		log.Error("Failed to check recipient account", zap.Error(err), zap.String("recipient", address))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return rpcTimeoutFailure()
+		}
+		if starknet.IsUnavailableError(err) {
+			h.rpcHealth.markDown(err)
+			return h.rpcUnavailableFailure()
+		}
+		return &faucetError{fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to check the recipient",
+			Code:  models.ErrCodeInternal,
+		}}
+	case !isAccount:
+		return &faucetError{fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "The contract at this address isn't an account (it doesn't support the SNIP-6 account interface). This faucet only funds account contracts.",
+			Code:  models.ErrCodeInvalidAddress,
+		}}
+	}
+	return nil
+}
+
+// trackRecipient counts address towards the IP's distinct recipients for the
+// day. Nothing is tracked while MAX_DISTINCT_ADDRESSES_PER_DAY is unset.
+func (h *Handler) trackRecipient(ctx context.Context, log *zap.Logger, ip, address string) {
+	if h.config.MaxDistinctAddressesPerDay <= 0 {
+		return
+	}
+	if err := h.redis.TrackRecipient(ctx, ip, recipientKey(address)); err != nil {
+		log.Error("Failed to track recipient", zap.Error(err))
+	}
+}
+
+// recipientKey returns the form of address used to count distinct recipients,
+// so one account written with or without leading zeros counts once
+func recipientKey(address string) string {
+	return strings.ToLower(utils.NormalizeStarknetAddress(address))
 }
 
 // GetQuota returns the current rate limit quota for the requesting IP
 func (h *Handler) GetQuota(c *fiber.Ctx) error {
-	ctx := context.Background()
+	log := h.requestLogger(c)
+	ctx := c.UserContext()
 	ip := c.IP()
 
 	// Get IP daily quota
-	used, remaining, cooldownEnd, err := h.redis.GetIPDailyQuota(ctx, ip)
+	used, remaining, cooldownEnd, resetAt, err := h.redis.GetIPDailyQuota(ctx, ip)
 	if err != nil {
-		h.logger.Error("Failed to get IP daily quota", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		log.Error("Failed to get IP daily quota", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to get quota",
+			Code:  models.ErrCodeInternal,
 		})
 	}
 
 	// Check token throttles
 	strkThrottled, strkNext, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, "STRK")
 	if err != nil {
-		h.logger.Error("Failed to check STRK throttle", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		log.Error("Failed to check STRK throttle", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to check throttle",
+			Code:  models.ErrCodeInternal,
 		})
 	}
 
 	ethThrottled, ethNext, err := h.redis.CheckTokenHourlyThrottle(ctx, ip, "ETH")
 	if err != nil {
-		h.logger.Error("Failed to check ETH throttle", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		log.Error("Failed to check ETH throttle", zap.Error(err))
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to check throttle",
+			Code:  models.ErrCodeInternal,
 		})
 	}
 
-	response := map[string]interface{}{
-		"daily_limit": map[string]interface{}{
-			"total":              h.config.MaxRequestsPerDayIP,
-			"used":               used,
-			"remaining":          remaining,
-			"cooldown_end":       cooldownEnd,
-			"in_cooldown":        cooldownEnd != nil,
+	response := models.QuotaResponse{
+		DailyLimit: models.DailyLimitInfo{
+			Total:       h.config.MaxRequestsPerDayIP,
+			Used:        used,
+			Remaining:   remaining,
+			CooldownEnd: cooldownEnd,
+			InCooldown:  cooldownEnd != nil,
+			ResetAt:     resetAt,
 		},
-		"hourly_throttle": map[string]interface{}{
-			"strk": map[string]interface{}{
-				"available":        strkThrottled,
-				"next_request_at":  strkNext,
-			},
-			"eth": map[string]interface{}{
-				"available":       ethThrottled,
-				"next_request_at": ethNext,
-			},
+		HourlyThrottle: models.HourlyThrottleInfo{
+			STRK: models.TokenThrottle{Available: strkThrottled, NextRequestAt: strkNext},
+			ETH:  models.TokenThrottle{Available: ethThrottled, NextRequestAt: ethNext},
 		},
 	}
 
 	return c.JSON(response)
 }
 
+// requestLogger returns a child logger tagged with the request's correlation ID
+func (h *Handler) requestLogger(c *fiber.Ctx) *zap.Logger {
+	return h.logger.With(zap.String("request_id", requestID(c)))
+}
+
+// requestID returns the correlation ID assigned by the requestid middleware
+func requestID(c *fiber.Ctx) string {
+	id, _ := c.Locals("requestid").(string)
+	return id
+}
+
+// respondError writes an error response tagged with the request ID
+func respondError(c *fiber.Ctx, status int, resp models.ErrorResponse) error {
+	resp.RequestID = requestID(c)
+	if resp.RetryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(resp.RetryAfter))
+	}
+	return c.Status(status).JSON(resp)
+}
+
+// rpcContext returns a context bounded by the configured RPC timeout
+func (h *Handler) rpcContext(c *fiber.Ctx) (context.Context, context.CancelFunc) {
+	return h.rpcDeadline(c.UserContext())
+}
+
+// rpcDeadline bounds parent by the configured RPC timeout
+func (h *Handler) rpcDeadline(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, time.Duration(h.config.RPCTimeout)*time.Second)
+}
+
+// isBlocklisted reports whether ip is on the configured blocklist
+func (h *Handler) isBlocklisted(ip string) bool {
+	return utils.IPInList(ip, h.config.IPBlocklist)
+}
+
+// isAllowlisted reports whether ip is on the configured allowlist and
+// therefore exempt from rate limits
+func (h *Handler) isAllowlisted(ip string) bool {
+	return utils.IPInList(ip, h.config.IPAllowlist)
+}
+
+// apiKeyID checks the X-API-Key header against the trusted keys. It returns
+// a short ID for the key (never the key itself, which must not reach logs or
+// Redis) and whether a key was sent at all. The ID is empty for unknown keys.
+func (h *Handler) apiKeyID(c *fiber.Ctx) (id string, sent bool) {
+	key := c.Get(apiKeyHeader)
+	if key == "" {
+		return "", false
+	}
+
+	// Compare against every key in constant time so timing doesn't leak a prefix
+	matched := false
+	for _, trusted := range h.config.TrustedAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(trusted)) == 1 {
+			matched = true
+		}
+	}
+	if !matched {
+		return "", true
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8]), true
+}
+
+// unauthorizedError responds with 401 for an unknown API key
+func unauthorizedError(c *fiber.Ctx) error {
+	return respondError(c, fiber.StatusUnauthorized, models.ErrorResponse{
+		Error: "Invalid API key. Remove the X-API-Key header to use the public proof-of-work flow.",
+		Code:  models.ErrCodeUnauthorized,
+	})
+}
+
+// blockedError responds with 403 for a blocklisted client IP
+func blockedError(c *fiber.Ctx) error {
+	return respondError(c, fiber.StatusForbidden, models.ErrorResponse{
+		Error: "Requests from your IP address are not allowed.",
+		Code:  models.ErrCodeForbidden,
+	})
+}
+
+// rpcTimeoutFailure is the 504 for a Starknet RPC that missed its deadline
+func rpcTimeoutFailure() *faucetError {
+	return &faucetError{fiber.StatusGatewayTimeout, models.ErrorResponse{
+		Error: "Starknet RPC did not respond in time. Please try again later.",
+		Code:  models.ErrCodeRPCTimeout,
+	}}
+}
+
+// rpcUnavailableFailure is the 503 for a Starknet RPC that can't be reached,
+// asking the client to come back after the next health check
+func (h *Handler) rpcUnavailableFailure() *faucetError {
+	return &faucetError{fiber.StatusServiceUnavailable, models.ErrorResponse{
+		Error:      "The blockchain node is temporarily unavailable. Please try again shortly.",
+		Code:       models.ErrCodeRPCUnavailable,
+		RetryAfter: int(h.rpcHealth.retryAfter().Seconds()),
+	}}
+}
+
 // Health returns the health status of the API
 func (h *Handler) Health(c *fiber.Ctx) error {
 	ctx := context.Background()
 
 	// Check Redis
 	if err := h.redis.Ping(ctx); err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(models.ErrorResponse{
+		return respondError(c, fiber.StatusServiceUnavailable, models.ErrorResponse{
 			Error: "Redis unavailable",
+			Code:  models.ErrCodeUnavailable,
 		})
 	}
 
@@ -628,3 +1973,68 @@ func (h *Handler) Health(c *fiber.Ctx) error {
 		Timestamp: time.Now().Unix(),
 	})
 }
+
+// HealthFull checks Redis, the Starknet RPC provider and the faucet's fee-token
+// balance. It is slower than Health, so load balancers should keep probing /health.
+func (h *Handler) HealthFull(c *fiber.Ctx) error {
+	log := h.requestLogger(c)
+	ctx, cancel := h.rpcContext(c)
+	defer cancel()
+
+	checks := map[string]models.DependencyStatus{
+		"redis": checkDependency(func() (string, error) {
+			return "", h.redis.Ping(ctx)
+		}),
+		"starknet_rpc": checkDependency(func() (string, error) {
+			chainID, err := h.starknet.ChainID(ctx)
+			if active, total := h.starknet.RPCEndpoint(); total > 1 && err == nil {
+				// Endpoints are numbered, their URLs may hold API keys
+				return fmt.Sprintf("%s via endpoint %d of %d", chainID, active, total), nil
+			}
+			return chainID, err
+		}),
+		"faucet_balance": checkDependency(func() (string, error) {
+			feeToken := h.starknet.FeeToken()
+			balance, err := h.starknet.FaucetBalance(ctx, feeToken)
+			if err != nil {
+				return "", err
+			}
+			if balance.Sign() <= 0 {
+				return "", fmt.Errorf("faucet has no %s to pay transaction fees", feeToken)
+			}
+			return fmt.Sprintf("%s %.4f", feeToken, starknet.WeiToAmount(balance)), nil
+		}),
+	}
+
+	status := "ok"
+	httpStatus := fiber.StatusOK
+	for name, check := range checks {
+		if check.Status != "ok" {
+			status = "degraded"
+			httpStatus = fiber.StatusServiceUnavailable
+			log.Warn("Health check failed", zap.String("dependency", name), zap.String("error", check.Error))
+		}
+	}
+
+	return c.Status(httpStatus).JSON(models.FullHealthResponse{
+		Status:    status,
+		Timestamp: time.Now().Unix(),
+		Checks:    checks,
+	})
+}
+
+// checkDependency runs a single health check and times it
+func checkDependency(check func() (string, error)) models.DependencyStatus {
+	start := time.Now()
+	detail, err := check()
+	result := models.DependencyStatus{
+		Status:    "ok",
+		LatencyMs: time.Since(start).Milliseconds(),
+		Detail:    detail,
+	}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+	return result
+}
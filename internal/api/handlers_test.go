@@ -0,0 +1,1801 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/cache"
+	"github.com/Giri-Aayush/starknet-faucet/internal/config"
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/pow"
+	"github.com/Giri-Aayush/starknet-faucet/internal/receipt"
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet"
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet/starknettest"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+)
+
+var _ StarknetClient = (*starknettest.MockClient)(nil)
+
+// newTestHandler creates a handler backed by an in-memory Redis and a mock
+// Starknet client funded with 1000 STRK and 1 ETH
+func newTestHandler(t *testing.T) (*Handler, *miniredis.Miniredis, *starknettest.MockClient) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	redisClient, err := cache.NewRedisClient("redis://"+mr.Addr(), 5, 8)
+	require.NoError(t, err)
+	t.Cleanup(func() { redisClient.Close() })
+
+	cfg := &config.Config{
+		Network:              "sepolia",
+		Explorer:             "voyager",
+		FaucetAddress:        "0x0123",
+		DripAmountSTRK:       "10",
+		DripAmountETH:        "0.01",
+		PoWDifficulty:        1,
+		ChallengeTTL:         300,
+		RPCTimeout:           5,
+		RPCHealthInterval:    15,
+		ConfirmationLevel:    "ACCEPTED_ON_L2",
+		MinBalanceProtectPct: 5,
+		MaxRequestsPerDayIP:  5,
+		MaxChallengesPerHour: 8,
+	}
+	cfg.Tokens = map[string]config.TokenConfig{
+		"STRK": {Symbol: "STRK", Decimals: 18, DripAmount: cfg.DripAmountSTRK, MinBalanceProtectPct: 5},
+		"ETH":  {Symbol: "ETH", Decimals: 18, DripAmount: cfg.DripAmountETH, MinBalanceProtectPct: 5},
+	}
+
+	mock := starknettest.NewMockClient()
+	mock.SetBalance("STRK", starknet.AmountToWei(1000))
+	mock.SetBalance("ETH", starknet.AmountToWei(1))
+
+	powGenerator := pow.NewGenerator(cfg.PoWDifficulty, cfg.ChallengeTTL)
+
+	return NewHandler(cfg, zap.NewNop(), redisClient, mock, powGenerator), mr, mock
+}
+
+// useDailyRequests counts n requests against the test client's daily IP limit
+func useDailyRequests(t *testing.T, h *Handler, n int) {
+	t.Helper()
+
+	allowed, _, _, err := h.redis.ReserveIPDailyLimit(context.Background(), "0.0.0.0", n)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestRequestTokensBothPartialFailure(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+	ctx := context.Background()
+
+	// BOTH request where STRK is sent but ETH, below its balance floor, fails
+	mock.SetBalance("ETH", big.NewInt(0))
+	challengeID, nonce := requestChallenge(t, app)
+
+	var resp models.FaucetResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "BOTH",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &resp)
+	require.Equal(t, fiber.StatusOK, status)
+	require.Len(t, resp.Transactions, 1)
+	assert.Equal(t, "STRK", resp.Transactions[0].Token)
+
+	strkAvailable, _, err := h.redis.CheckTokenHourlyThrottle(ctx, "0.0.0.0", "STRK")
+	require.NoError(t, err)
+	assert.False(t, strkAvailable, "STRK was sent and should be throttled")
+
+	ethAvailable, _, err := h.redis.CheckTokenHourlyThrottle(ctx, "0.0.0.0", "ETH")
+	require.NoError(t, err)
+	assert.True(t, ethAvailable, "ETH was never sent and should not be throttled")
+}
+
+func TestRecordSuccessfulTransfersNoneSent(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	ctx := context.Background()
+	ip := "203.0.113.8"
+
+	h.recordSuccessfulTransfers(ctx, h.logger, ip, "", "0x0742d469482a89e7", nil)
+
+	used, _, _, _, err := h.redis.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 0, used)
+}
+
+func TestRespondErrorIncludesRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return respondError(c, fiber.StatusBadRequest, models.ErrorResponse{
+			Error: "bad request",
+			Code:  models.ErrCodeInvalidRequest,
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/fail", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "test-request-id")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "test-request-id", resp.Header.Get(fiber.HeaderXRequestID))
+
+	var body models.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "test-request-id", body.RequestID)
+	assert.Equal(t, models.ErrCodeInvalidRequest, body.Code)
+}
+
+func TestIsBalanceProtected(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	tokenCfg := config.TokenConfig{Symbol: "STRK", MinBalanceProtectPct: 5}
+
+	tests := []struct {
+		name    string
+		balance float64
+		amount  float64
+		want    bool
+	}{
+		{"plenty of balance", 100, 10, false},
+		{"drip lands exactly on threshold", 100, 95, false},
+		{"drip dips below threshold", 100, 96, true},
+		{"empty faucet", 0, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.isBalanceProtected(tokenCfg, starknet.AmountToWei(tt.balance), tt.amount)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBalanceProtectionPerToken(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	h.config.Tokens = map[string]config.TokenConfig{
+		"STRK": {Symbol: "STRK", DripAmount: "10", MinBalanceProtectPct: 50},
+		"ETH":  {Symbol: "ETH", DripAmount: "10", MinBalanceProtectPct: 5},
+	}
+	ctx := context.Background()
+	balance := starknet.AmountToWei(100)
+
+	// A 10 drip from 100 leaves 90, above both floors
+	assert.True(t, h.hasBalanceForDrip(h.config.Tokens["STRK"], balance))
+	assert.True(t, h.hasBalanceForDrip(h.config.Tokens["ETH"], balance))
+
+	// With 45 already reserved, another drip would leave STRK at 45, below its
+	// 50% floor, while ETH still has room above 5%
+	_, err := h.redis.ReserveBalance(ctx, "STRK", 45)
+	require.NoError(t, err)
+	_, err = h.redis.ReserveBalance(ctx, "ETH", 45)
+	require.NoError(t, err)
+
+	ok, err := h.reserveBalance(ctx, h.logger, "STRK", 10, balance)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = h.reserveBalance(ctx, h.logger, "ETH", 10, balance)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBalanceFloor(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	tokenCfg := config.TokenConfig{Symbol: "STRK", MinBalanceProtectPct: 10, MinBalanceFloor: 50}
+
+	tests := []struct {
+		name      string
+		balance   float64
+		amount    float64
+		protected bool
+		detail    string
+	}{
+		// 10% of 1000 is 100, above the absolute floor
+		{"percentage floor applies", 1000, 10, false, "10% floor: 100.0000 STRK"},
+		{"percentage floor triggers", 1000, 901, true, "10% floor: 100.0000 STRK"},
+		// 10% of 200 is 20, so the absolute floor of 50 wins
+		{"absolute floor applies", 200, 10, false, "absolute floor: 50.0000 STRK"},
+		{"absolute floor triggers", 55, 10, true, "absolute floor: 50.0000 STRK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance := starknet.AmountToWei(tt.balance)
+			assert.Equal(t, tt.protected, h.isBalanceProtected(tokenCfg, balance, tt.amount))
+			assert.Equal(t, tt.detail, balanceFloorDetail(tokenCfg, balance))
+		})
+	}
+}
+
+func TestRequestTokensAbsoluteFloorError(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	strk := h.config.Tokens["STRK"]
+	strk.MinBalanceFloor = 995
+	h.config.Tokens["STRK"] = strk
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	challengeID, nonce := requestChallenge(t, app)
+
+	var errResp models.ErrorResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &errResp)
+
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+	assert.Equal(t, models.ErrCodeFaucetEmpty, errResp.Code)
+	assert.Contains(t, errResp.Error, "absolute floor: 995.0000 STRK")
+	assert.Equal(t, 0, mock.TransferCount())
+
+	// BOTH stops at the same floor with the same status, suggesting ETH
+	challengeID, nonce = requestChallenge(t, app)
+	errResp = models.ErrorResponse{}
+	status = postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "BOTH",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &errResp)
+
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+	assert.Equal(t, models.ErrCodeFaucetEmpty, errResp.Code)
+	assert.Contains(t, errResp.Error, "absolute floor: 995.0000 STRK")
+	assert.Equal(t, []string{"ETH"}, errResp.AvailableTokens)
+	assert.Equal(t, 0, mock.TransferCount())
+}
+
+func TestDispensableTokens(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	h.config.Tokens = map[string]config.TokenConfig{
+		"STRK": {Symbol: "STRK", DripAmount: "10", MaxPerHour: 100, MinBalanceProtectPct: 5},
+		"ETH":  {Symbol: "ETH", DripAmount: "0.01", MinBalanceProtectPct: 5},
+	}
+	ctx := context.Background()
+
+	balances := map[string]*big.Int{
+		"STRK": starknet.AmountToWei(1000),
+		"ETH":  starknet.AmountToWei(0.001), // below one drip
+	}
+	assert.Equal(t, []string{"STRK"}, h.dispensableTokens(ctx, h.logger, balances))
+
+	// Exhaust STRK's hourly distribution limit
+	for i := 0; i < 10; i++ {
+		ok, err := h.redis.TrackGlobalDistribution(ctx, "STRK", 10, 100, 0)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	assert.Empty(t, h.dispensableTokens(ctx, h.logger, balances))
+
+	// A failed balance lookup is never dispensable
+	balances["ETH"] = nil
+	assert.Empty(t, h.dispensableTokens(ctx, h.logger, balances))
+}
+
+func TestReserveBalanceConcurrent(t *testing.T) {
+	h, mr, _ := newTestHandler(t)
+	ctx := context.Background()
+	balance := starknet.AmountToWei(100)
+
+	// 20 racing 10 STRK drips against 100 STRK with a 5% floor: only 9 fit
+	const racers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reservedCount := 0
+
+	start := make(chan struct{})
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			ok, err := h.reserveBalance(ctx, h.logger, "STRK", 10, balance)
+			assert.NoError(t, err)
+			if ok {
+				mu.Lock()
+				reservedCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, 9, reservedCount)
+
+	reserved, err := mr.Get("reserved:balance:STRK")
+	require.NoError(t, err)
+	total, err := strconv.ParseFloat(reserved, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, 90, total, 1e-9, "rejected reservations must be released")
+}
+
+// requestChallenge fetches a challenge from the app and solves it
+func requestChallenge(t *testing.T, app *fiber.App) (string, uint64) {
+	t.Helper()
+	return requestChallengeAt(t, app, "/api/v1/challenge")
+}
+
+// requestChallengeAt fetches a challenge from path and solves it
+func requestChallengeAt(t *testing.T, app *fiber.App, path string) (string, uint64) {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest("POST", path, nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var challenge models.ChallengeResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&challenge))
+
+	nonce, err := pow.SolveChallenge(challenge.Challenge, challenge.Difficulty, nil)
+	require.NoError(t, err)
+
+	return challenge.ChallengeID, nonce
+}
+
+// noncePtr returns a pointer to n, for FaucetRequest.Nonce literals
+func noncePtr(n uint64) *uint64 {
+	return &n
+}
+
+// postFaucet submits a faucet request and decodes the response into out
+func postFaucet(t *testing.T, app *fiber.App, req models.FaucetRequest, out interface{}) int {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest("POST", "/api/v1/faucet", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	return resp.StatusCode
+}
+
+func TestRequestTokensAcceptsZeroNonce(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	// Find a challenge that nonce 0 already solves at the test difficulty
+	var challenge string
+	for i := 0; ; i++ {
+		challenge = fmt.Sprintf("zero-nonce-%d", i)
+		if h.powGenerator.VerifyPoW(challenge, 0, h.config.PoWDifficulty) {
+			break
+		}
+	}
+	require.NoError(t, h.redis.StoreChallenge(context.Background(), "zero", challenge, 1, "", false, time.Minute))
+
+	var resp models.FaucetResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: "zero",
+		Nonce:       noncePtr(0),
+	}, &resp)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 1, mock.TransferCount())
+}
+
+func TestRequestTokensSingleTokenDrip(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	challengeID, nonce := requestChallenge(t, app)
+
+	var resp models.FaucetResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &resp)
+
+	require.Equal(t, fiber.StatusOK, status)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "STRK", resp.Token)
+	assert.Equal(t, "10", resp.Amount)
+	assert.NotEmpty(t, resp.TxHash)
+	assert.Equal(t, starknet.ConfirmationReceived, resp.ConfirmationStatus)
+	require.Len(t, resp.Transactions, 1, "single-token responses list their transfer too")
+	assert.Equal(t, models.TransactionInfo{
+		Token:              "STRK",
+		Amount:             "10",
+		TxHash:             resp.TxHash,
+		ExplorerURL:        resp.ExplorerURL,
+		ConfirmationStatus: starknet.ConfirmationReceived,
+	}, resp.Transactions[0])
+
+	require.Equal(t, 1, mock.TransferCount())
+	assert.Equal(t, "0x0742d469482a89e7", mock.Transfers[0].Recipient)
+	assert.Equal(t, starknet.AmountToWei(10), mock.Transfers[0].Amount)
+
+	used, _, _, _, err := h.redis.GetIPDailyQuota(context.Background(), "0.0.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, 1, used)
+}
+
+func TestRequestTokensTokenAlias(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	challengeID, nonce := requestChallenge(t, app)
+
+	var resp models.FaucetResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       " Ether ",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &resp)
+
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "ETH", resp.Token)
+	require.Equal(t, 1, mock.TransferCount())
+	assert.Equal(t, "ETH", mock.Transfers[0].Token)
+}
+
+func TestRequestTokensChargesOnlySentTokens(t *testing.T) {
+	t.Run("partial failure", func(t *testing.T) {
+		h, _, mock := newTestHandler(t)
+		app := fiber.New()
+		SetupRoutes(app, h)
+
+		// ETH is below its balance floor, so only STRK goes out
+		mock.SetBalance("ETH", big.NewInt(0))
+		challengeID, nonce := requestChallenge(t, app)
+
+		var resp models.FaucetResponse
+		status := postFaucet(t, app, models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       "BOTH",
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, &resp)
+		require.Equal(t, fiber.StatusOK, status)
+		require.Len(t, resp.Transactions, 1)
+
+		used, remaining, cooldownEnd, _, err := h.redis.GetIPDailyQuota(context.Background(), "0.0.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, 1, used, "only the successful transfer should be charged")
+		assert.Equal(t, 4, remaining)
+		assert.Nil(t, cooldownEnd)
+	})
+
+	t.Run("failed transfer", func(t *testing.T) {
+		h, _, mock := newTestHandler(t)
+		app := fiber.New()
+		SetupRoutes(app, h)
+		ctx := context.Background()
+
+		// The last daily request starts the cooldown until it is given back
+		useDailyRequests(t, h, 4)
+		mock.TransferErr = errors.New("transaction rejected")
+		challengeID, nonce := requestChallenge(t, app)
+
+		var errResp models.ErrorResponse
+		status := postFaucet(t, app, models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       "STRK",
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, &errResp)
+		require.Equal(t, fiber.StatusInternalServerError, status)
+
+		used, remaining, cooldownEnd, _, err := h.redis.GetIPDailyQuota(ctx, "0.0.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, 4, used)
+		assert.Equal(t, 1, remaining)
+		assert.Nil(t, cooldownEnd)
+	})
+}
+
+func TestRequestTokensNoFundedAccount(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+	mock.TransferErr = fmt.Errorf("wrapped: %w", starknet.ErrNoFundedAccount)
+
+	challengeID, nonce := requestChallenge(t, app)
+	var errResp models.ErrorResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &errResp)
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+	assert.Equal(t, models.ErrCodeFaucetEmpty, errResp.Code)
+	assert.Contains(t, errResp.Error, "no faucet account can send 10 STRK")
+}
+
+func TestRequestTokensBurst(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.BurstSize = 1
+	h.config.RefillRate = 2
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	request := func(token string, out interface{}) int {
+		challengeID, nonce := requestChallenge(t, app)
+		return postFaucet(t, app, models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       token,
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, out)
+	}
+
+	// A failed transfer gives its request back
+	var errResp models.ErrorResponse
+	mock.TransferErr = errors.New("transaction rejected")
+	require.Equal(t, fiber.StatusInternalServerError, request("STRK", &errResp))
+	mock.TransferErr = nil
+
+	var resp models.FaucetResponse
+	require.Equal(t, fiber.StatusOK, request("STRK", &resp))
+
+	// ETH isn't throttled, but the burst is spent until it refills
+	require.Equal(t, fiber.StatusTooManyRequests, request("ETH", &errResp))
+	assert.Equal(t, models.ErrCodeRateLimited, errResp.Code)
+	assert.Contains(t, errResp.Error, "Too many requests in a short time")
+	assert.InDelta(t, 1800, errResp.RetryAfter, 5)
+	assert.Equal(t, 1, mock.TransferCount())
+
+	// Allowlisted IPs skip it like the other limits
+	allowlist, err := utils.ParseIPList("0.0.0.0")
+	require.NoError(t, err)
+	h.config.IPAllowlist = allowlist
+	require.Equal(t, fiber.StatusOK, request("ETH", &resp))
+}
+
+func TestRequestTokensInvalidDripAmount(t *testing.T) {
+	for _, amount := range []string{"abc", "0"} {
+		t.Run(amount, func(t *testing.T) {
+			h, _, mock := newTestHandler(t)
+			h.config.DripAmountSTRK = amount
+			strk := h.config.Tokens["STRK"]
+			strk.DripAmount = amount
+			h.config.Tokens["STRK"] = strk
+			app := fiber.New()
+			SetupRoutes(app, h)
+
+			for _, token := range []string{"STRK", "BOTH"} {
+				challengeID, nonce := requestChallenge(t, app)
+				var errResp models.ErrorResponse
+				status := postFaucet(t, app, models.FaucetRequest{
+					Address:     "0x0742d469482a89e7",
+					Token:       token,
+					ChallengeID: challengeID,
+					Nonce:       &nonce,
+				}, &errResp)
+				assert.Equal(t, fiber.StatusInternalServerError, status, token)
+				assert.Equal(t, models.ErrCodeInternal, errResp.Code)
+			}
+
+			assert.Equal(t, 0, mock.TransferCount(), "nothing is sent")
+			used, _, _, _, err := h.redis.GetIPDailyQuota(context.Background(), "0.0.0.0")
+			require.NoError(t, err)
+			assert.Equal(t, 0, used, "no quota is spent")
+
+			_, _, err = h.dripAmount(amount)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDripAmountJitter(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	amount, value, err := h.dripAmount("10")
+	require.NoError(t, err)
+	assert.Equal(t, "10", amount, "no jitter by default")
+	assert.Equal(t, 10.0, value)
+
+	h.config.DripJitterPct = 20
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		amount, value, err := h.dripAmount("0.01")
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, value, 0.008)
+		assert.LessOrEqual(t, value, 0.012)
+		parsed, err := strconv.ParseFloat(amount, 64)
+		require.NoError(t, err)
+		assert.Equal(t, value, parsed, "the reported amount must be the one sent")
+		seen[amount] = true
+	}
+	assert.Greater(t, len(seen), 1, "amounts should vary")
+}
+
+func TestRequestTokensJitteredDrip(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.DripJitterPct = 10
+	h.config.MaxTokensPerHourSTRK = 100 // Distribution is only tracked under a cap
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	challengeID, nonce := requestChallenge(t, app)
+	var resp models.FaucetResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &resp)
+	require.Equal(t, fiber.StatusOK, status)
+
+	sent, err := strconv.ParseFloat(resp.Amount, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, 10, sent, 1)
+	require.Equal(t, 1, mock.TransferCount())
+	assert.Equal(t, starknet.AmountToWei(sent), mock.Transfers[0].Amount)
+
+	// Global distribution counts what was actually sent
+	hourly, _, err := h.redis.GetGlobalDistribution(context.Background(), "STRK")
+	require.NoError(t, err)
+	assert.InDelta(t, sent, hourly, 1e-9)
+}
+
+func TestRequestTokensWaitsForConfirmation(t *testing.T) {
+	h, mr, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	post := func() models.FaucetResponse {
+		challengeID, nonce := requestChallenge(t, app)
+		body, err := json.Marshal(models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       "STRK",
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		})
+		require.NoError(t, err)
+
+		httpReq := httptest.NewRequest("POST", "/api/v1/faucet?wait=true", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var out models.FaucetResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return out
+	}
+
+	mock.Block = 812345
+	resp := post()
+	assert.Equal(t, starknet.ConfirmationAcceptedOnL2, resp.ConfirmationStatus)
+	assert.EqualValues(t, 812345, resp.BlockNumber, "the block comes from the receipt")
+	assert.Equal(t, starknet.ConfirmationAcceptedOnL2, resp.FinalityStatus)
+	assert.EqualValues(t, 812345, resp.Transactions[0].BlockNumber)
+
+	// A wait that times out still succeeds and reports how far it got
+	mock.WaitStatus = starknet.ConfirmationPreConfirmed
+	mock.WaitErr = context.DeadlineExceeded
+	mr.Del("throttle:ip:token:0.0.0.0:STRK")
+
+	resp = post()
+	assert.True(t, resp.Success)
+	assert.Equal(t, starknet.ConfirmationPreConfirmed, resp.ConfirmationStatus)
+	assert.Equal(t, starknet.ConfirmationPreConfirmed, resp.FinalityStatus)
+
+	// Without a receipt the transfer is still reported, only without a block
+	mock.WaitErr = nil
+	mock.NoReceipt = true
+	mr.Del("throttle:ip:token:0.0.0.0:STRK")
+
+	resp = post()
+	assert.True(t, resp.Success)
+	assert.Equal(t, starknet.ConfirmationPreConfirmed, resp.ConfirmationStatus)
+	assert.Zero(t, resp.BlockNumber)
+	assert.Empty(t, resp.FinalityStatus)
+}
+
+func TestRequestTokensRPCUnavailable(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	submit := func(challengeID string, nonce uint64) *http.Response {
+		body, err := json.Marshal(models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       "STRK",
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		})
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest("POST", "/api/v1/faucet", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	// A transfer that can't reach the node is a distinct 503, not a 500
+	mock.TransferErr = errors.New(`The error is not a valid RPC error: Post "http://node": dial tcp 10.0.0.1:443: connect: connection refused`)
+	challengeID, nonce := requestChallenge(t, app)
+	resp := submit(challengeID, nonce)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "15", resp.Header.Get(fiber.HeaderRetryAfter))
+	var errResp models.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, models.ErrCodeRPCUnavailable, errResp.Code)
+	assert.Equal(t, 15, errResp.RetryAfter)
+	assert.Contains(t, errResp.Error, "temporarily unavailable")
+
+	// Until a check finds the node back, requests are refused before their
+	// challenge is spent
+	mock.TransferErr = nil
+	challengeID, nonce = requestChallenge(t, app)
+	assert.Equal(t, fiber.StatusServiceUnavailable, submit(challengeID, nonce).StatusCode)
+
+	h.rpcHealth.check(context.Background())
+	assert.Equal(t, fiber.StatusOK, submit(challengeID, nonce).StatusCode)
+	assert.Equal(t, 1, mock.TransferCount())
+}
+
+func TestRequestTokensDeploymentWarning(t *testing.T) {
+	h, mr, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	request := func(token string) models.FaucetResponse {
+		challengeID, nonce := requestChallenge(t, app)
+		var resp models.FaucetResponse
+		require.Equal(t, fiber.StatusOK, postFaucet(t, app, models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       token,
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, &resp))
+		mr.Del("throttle:ip:token:0.0.0.0:STRK")
+		mr.Del("throttle:ip:token:0.0.0.0:ETH")
+		return resp
+	}
+
+	mock.Undeployed = map[string]bool{"0x0742d469482a89e7": true}
+	assert.Empty(t, request("STRK").Warning, "check is off by default")
+
+	// The daily quota of 5 covers the four requests below (BOTH counts twice)
+	h.config.CheckDeployment = true
+	assert.Contains(t, request("STRK").Warning, "No account is deployed")
+	assert.Contains(t, request("BOTH").Warning, "No account is deployed")
+
+	// A failed check doesn't block the transfer
+	mock.DeployErr = errors.New("rpc unavailable")
+	resp := request("STRK")
+	assert.True(t, resp.Success)
+	assert.Empty(t, resp.Warning)
+}
+
+func TestRequireAccountRecipient(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.RequireAccountRecipient = true
+	app := fiber.New()
+	SetupRoutes(app, h)
+	mock.Undeployed = map[string]bool{"0x0742d469482a89e8": true}
+	mock.NonAccounts = map[string]bool{"0x0742d469482a89e9": true}
+
+	request := func(address string, out interface{}) (int, string) {
+		challengeID, nonce := requestChallenge(t, app)
+		status := postFaucet(t, app, models.FaucetRequest{
+			Address:     address,
+			Token:       "STRK",
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, out)
+		return status, challengeID
+	}
+
+	var errResp models.ErrorResponse
+	status, challengeID := request("0x0742d469482a89e8", &errResp)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, models.ErrCodeInvalidAddress, errResp.Code)
+	assert.Contains(t, errResp.Error, "deploy the account first")
+	_, _, _, err := h.redis.GetChallenge(context.Background(), challengeID)
+	assert.NoError(t, err, "the challenge isn't spent")
+
+	status, _ = request("0x0742d469482a89e9", &errResp)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Contains(t, errResp.Error, "isn't an account")
+
+	mock.AccountErr = errors.New("node exploded")
+	status, _ = request("0x0742d469482a89e7", &errResp)
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+	mock.AccountErr = nil
+
+	var resp models.FaucetResponse
+	status, _ = request("0x0742d469482a89e7", &resp)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 1, mock.TransferCount())
+
+	used, _, _, _, err := h.redis.GetIPDailyQuota(context.Background(), "0.0.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, 1, used, "refused recipients cost no quota")
+}
+
+func TestRequireAccountRecipientChecksPoWFirst(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.RequireAccountRecipient = true
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	challengeID, _ := requestChallenge(t, app)
+	challenge, difficulty, _, err := h.redis.GetChallenge(context.Background(), challengeID)
+	require.NoError(t, err)
+	wrong := uint64(0)
+	for pow.Solves(challenge, wrong, difficulty) {
+		wrong++
+	}
+
+	// Replaying the challenge with wrong nonces never reaches the RPC
+	var errResp models.ErrorResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       &wrong,
+	}, &errResp)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, models.ErrCodePoWInvalid, errResp.Code)
+
+	status = postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       noncePtr(wrong + 1),
+	}, &errResp)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, models.ErrCodeChallengeInvalid, errResp.Code, "a wrong answer spends the challenge")
+	assert.Zero(t, mock.AccountChecks)
+}
+
+func TestGetQuotaIncludesResetAt(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	// Nothing used yet, so a rolling window has nothing to reset
+	var quota struct {
+		DailyLimit struct {
+			Used    int        `json:"used"`
+			ResetAt *time.Time `json:"reset_at"`
+		} `json:"daily_limit"`
+	}
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/quota", nil))
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&quota))
+	resp.Body.Close()
+	assert.Nil(t, quota.DailyLimit.ResetAt)
+
+	useDailyRequests(t, h, 1)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/v1/quota", nil))
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&quota))
+	resp.Body.Close()
+	assert.Equal(t, 1, quota.DailyLimit.Used)
+	require.NotNil(t, quota.DailyLimit.ResetAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *quota.DailyLimit.ResetAt, time.Minute)
+}
+
+func TestGetStatus(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+	ctx := context.Background()
+
+	status := func() models.StatusResponse {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/status/0x0742d469482a89e7", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var out models.StatusResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return out
+	}
+
+	fresh := status()
+	assert.True(t, fresh.CanRequest)
+	assert.Nil(t, fresh.LastRequest)
+	assert.Nil(t, fresh.NextRequestTime)
+	assert.Nil(t, fresh.RemainingHours)
+
+	// One throttled token leaves the other to request
+	require.NoError(t, h.redis.SetTokenHourlyThrottle(ctx, "0.0.0.0", "STRK"))
+	useDailyRequests(t, h, 1)
+	strkOnly := status()
+	assert.True(t, strkOnly.CanRequest)
+	require.NotNil(t, strkOnly.LastRequest)
+	assert.WithinDuration(t, time.Now(), *strkOnly.LastRequest, 2*time.Second)
+	assert.Nil(t, strkOnly.NextRequestTime)
+
+	// With every token throttled, the next request waits for the first to free up
+	require.NoError(t, h.redis.SetTokenHourlyThrottle(ctx, "0.0.0.0", "ETH"))
+	throttled := status()
+	assert.False(t, throttled.CanRequest)
+	require.NotNil(t, throttled.NextRequestTime)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *throttled.NextRequestTime, time.Minute)
+	require.NotNil(t, throttled.RemainingHours)
+	assert.InDelta(t, 1, *throttled.RemainingHours, 0.05)
+
+	// The daily cooldown outlasts the throttles
+	useDailyRequests(t, h, 4)
+	cooldown := status()
+	assert.False(t, cooldown.CanRequest)
+	require.NotNil(t, cooldown.NextRequestTime)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *cooldown.NextRequestTime, time.Minute)
+	assert.InDelta(t, 24, *cooldown.RemainingHours, 0.05)
+}
+
+func TestGetInfoIncludesFaucetAddress(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.FaucetPrivateKey = "0xsecret"
+	mock.Accounts = []string{"0x0123", "0x0456"}
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/info", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var info models.InfoResponse
+	require.NoError(t, json.Unmarshal(body, &info))
+	assert.Equal(t, "0x0123", info.FaucetAddress)
+	assert.Equal(t, []string{"0x0123", "0x0456"}, info.FaucetAddresses, "every sending account is listed")
+	assert.NotContains(t, string(body), "0xsecret")
+
+	assert.Equal(t, 8, info.Limits.ChallengesPerHour)
+	assert.Equal(t, []models.TokenLimitInfo{
+		{Symbol: "ETH", AmountPerRequest: "0.01", ThrottleHours: 1, RequestsPerDay: 5},
+		{Symbol: "STRK", AmountPerRequest: "10", ThrottleHours: 1, RequestsPerDay: 5},
+	}, info.Limits.Tokens)
+}
+
+func TestGetInfoEstimatedFee(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	mock.Fee = starknet.AmountToWei(0.0004)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	getInfo := func() models.InfoResponse {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/info", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var info models.InfoResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info
+	}
+
+	info := getInfo()
+	assert.Equal(t, &models.FeeEstimate{Amount: "0.000400", Token: "STRK"}, info.EstimatedFee)
+	getInfo()
+	assert.Equal(t, 1, mock.FeeEstimates, "the estimate is cached")
+
+	// A failed estimate is left out, and also cached
+	h.fee.estimated = time.Time{}
+	mock.FeeErr = errors.New("estimate failed")
+	assert.Nil(t, getInfo().EstimatedFee)
+	mock.FeeErr = nil
+	assert.Nil(t, getInfo().EstimatedFee)
+	assert.Equal(t, 2, mock.FeeEstimates)
+}
+
+func TestSignedReceipts(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	getInfo := func() models.InfoResponse {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/info", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var info models.InfoResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info
+	}
+	assert.Empty(t, getInfo().ReceiptPublicKey, "receipts are off by default")
+
+	key, err := receipt.ParseKey(strings.Repeat("01", 32))
+	require.NoError(t, err)
+	h.config.SignReceipts = true
+	h.config.ReceiptKey = key
+	publicKey := getInfo().ReceiptPublicKey
+	require.NotEmpty(t, publicKey)
+
+	challengeID, nonce := requestChallenge(t, app)
+	var resp models.FaucetResponse
+	require.Equal(t, fiber.StatusOK, postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "BOTH",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &resp))
+
+	require.Len(t, resp.Transactions, 2)
+	for _, tx := range resp.Transactions {
+		require.NotNil(t, tx.Receipt, tx.Token)
+		assert.Equal(t, utils.NormalizeStarknetAddress("0x0742d469482a89e7"), tx.Receipt.Recipient)
+		assert.Equal(t, tx.Token, tx.Receipt.Token)
+		assert.Equal(t, tx.Amount, tx.Receipt.Amount)
+		assert.Equal(t, tx.TxHash, tx.Receipt.TxHash)
+		assert.WithinDuration(t, time.Now(), time.Unix(tx.Receipt.Timestamp, 0), time.Minute)
+		assert.NoError(t, receipt.Verify(publicKey, *tx.Receipt))
+	}
+}
+
+func TestBlocklistedIPIsForbidden(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	// app.Test requests come from 0.0.0.0
+	blocklist, err := utils.ParseIPList("0.0.0.0/8")
+	require.NoError(t, err)
+	h.config.IPBlocklist = blocklist
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/challenge", nil))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	var errResp models.ErrorResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: "unused",
+		Nonce:       noncePtr(1),
+	}, &errResp)
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.Equal(t, models.ErrCodeForbidden, errResp.Code)
+	assert.Equal(t, 0, mock.TransferCount())
+}
+
+func TestAllowlistedIPBypassesRateLimits(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	allowlist, err := utils.ParseIPList("0.0.0.0")
+	require.NoError(t, err)
+	h.config.IPAllowlist = allowlist
+	h.config.MaxChallengesPerHour = 1
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	// Two requests for the same token would normally hit the challenge
+	// limit and the hourly throttle
+	for i := 0; i < 2; i++ {
+		challengeID, nonce := requestChallenge(t, app)
+
+		var resp models.FaucetResponse
+		status := postFaucet(t, app, models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       "STRK",
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, &resp)
+		require.Equal(t, fiber.StatusOK, status)
+	}
+	assert.Equal(t, 2, mock.TransferCount())
+
+	used, _, _, _, err := h.redis.GetIPDailyQuota(context.Background(), "0.0.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, 0, used)
+
+}
+
+// postFaucetWithKey submits a faucet request with an X-API-Key header
+func postFaucetWithKey(t *testing.T, app *fiber.App, key string, req models.FaucetRequest, out interface{}) int {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest("POST", "/api/v1/faucet", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(apiKeyHeader, key)
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	return resp.StatusCode
+}
+
+func TestDistinctAddressLimit(t *testing.T) {
+	h, mr, mock := newTestHandler(t)
+	h.config.MaxDistinctAddressesPerDay = 2
+	h.config.MaxRequestsPerDayIP = 10
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	// Each request clears the hourly throttle so only the address limit applies
+	request := func(address string) (int, models.ErrorResponse) {
+		mr.Del("throttle:ip:token:0.0.0.0:STRK")
+		challengeID, nonce := requestChallenge(t, app)
+		var errResp models.ErrorResponse
+		status := postFaucet(t, app, models.FaucetRequest{
+			Address:     address,
+			Token:       "STRK",
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, &errResp)
+		return status, errResp
+	}
+
+	status, _ := request("0x0742d469482a89e7")
+	require.Equal(t, fiber.StatusOK, status)
+	status, _ = request("0x0742d469482a89e8")
+	require.Equal(t, fiber.StatusOK, status)
+
+	// A third new address is refused
+	status, errResp := request("0x0742d469482a89e9")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, models.ErrCodeRateLimited, errResp.Code)
+
+	// An address funded today may come back, even written with leading zeros
+	status, _ = request("0x000742D469482A89E7")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 3, mock.TransferCount())
+}
+
+func TestTrustedAPIKeySkipsPoW(t *testing.T) {
+	const key = "partner-key-0123456789"
+	h, _, mock := newTestHandler(t)
+	h.config.TrustedAPIKeys = []string{key}
+	h.config.MaxRequestsPerDayAPIKey = 3
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	// No challenge, and more requests than the IP limits would allow for STRK
+	for i := 0; i < 3; i++ {
+		var resp models.FaucetResponse
+		status := postFaucetWithKey(t, app, key, models.FaucetRequest{
+			Address: "0x0742d469482a89e7",
+			Token:   "STRK",
+		}, &resp)
+		require.Equal(t, fiber.StatusOK, status)
+	}
+	assert.Equal(t, 3, mock.TransferCount())
+
+	// The key's own quota applies
+	var errResp models.ErrorResponse
+	status := postFaucetWithKey(t, app, key, models.FaucetRequest{
+		Address: "0x0742d469482a89e7",
+		Token:   "STRK",
+	}, &errResp)
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, models.ErrCodeRateLimited, errResp.Code)
+
+	// The IP quota was never charged
+	used, _, _, _, err := h.redis.GetIPDailyQuota(context.Background(), "0.0.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, 0, used)
+}
+
+func TestUnknownAPIKeyIsRejected(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.TrustedAPIKeys = []string{"partner-key-0123456789"}
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	var errResp models.ErrorResponse
+	status := postFaucetWithKey(t, app, "guessed-key-0123456789", models.FaucetRequest{
+		Address: "0x0742d469482a89e7",
+		Token:   "STRK",
+	}, &errResp)
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Equal(t, models.ErrCodeUnauthorized, errResp.Code)
+	assert.Equal(t, 0, mock.TransferCount())
+}
+
+func TestRequestTokensBothWithOneSlotLeft(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+	ctx := context.Background()
+
+	// 4 of 5 daily requests used, and ETH was requested within the hour
+	useDailyRequests(t, h, 4)
+	require.NoError(t, h.redis.SetTokenHourlyThrottle(ctx, "0.0.0.0", "ETH"))
+
+	challengeID, nonce := requestChallenge(t, app)
+
+	var errResp models.ErrorResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "BOTH",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &errResp)
+
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, models.ErrCodeRateLimited, errResp.Code)
+	assert.Contains(t, errResp.Error, "BOTH needs 2 daily requests (1 per token) but only 1 of your 5 remain")
+	assert.Contains(t, errResp.Error, "--token STRK")
+	assert.Equal(t, []string{"STRK"}, errResp.AvailableTokens)
+	assert.Equal(t, 0, mock.TransferCount())
+}
+
+func TestPausedToken(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	eth := h.config.Tokens["ETH"]
+	eth.Paused = true
+	h.config.Tokens["ETH"] = eth
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	// A paused token is refused by name, before the challenge is spent
+	challengeID, nonce := requestChallenge(t, app)
+	var errResp models.ErrorResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "ETH",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &errResp)
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+	assert.Equal(t, models.ErrCodeTokenDisabled, errResp.Code)
+	assert.Contains(t, errResp.Error, "ETH distribution is paused")
+	assert.Equal(t, []string{"STRK"}, errResp.AvailableTokens)
+	assert.Equal(t, 0, mock.TransferCount())
+
+	// BOTH sends the rest and says what it skipped
+	var resp models.FaucetResponse
+	status = postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "BOTH",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}, &resp)
+	require.Equal(t, fiber.StatusOK, status)
+	require.Len(t, resp.Transactions, 1)
+	assert.Equal(t, "STRK", resp.Transactions[0].Token)
+	assert.Equal(t, []string{"ETH"}, resp.PausedTokens)
+	assert.Equal(t, "Sent 1 token(s) successfully; skipped ETH, paused by the operator", resp.Message)
+	assert.Equal(t, 1, mock.TransferCount())
+
+	// /info and /tokens show it
+	httpResp, err := app.Test(httptest.NewRequest("GET", "/api/v1/info", nil))
+	require.NoError(t, err)
+	var info models.InfoResponse
+	require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&info))
+	httpResp.Body.Close()
+	assert.Equal(t, []string{"ETH"}, info.PausedTokens)
+	assert.Equal(t, []string{"STRK"}, info.AvailableTokens)
+
+	httpResp, err = app.Test(httptest.NewRequest("GET", "/api/v1/tokens", nil))
+	require.NoError(t, err)
+	var tokens models.TokensResponse
+	require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&tokens))
+	httpResp.Body.Close()
+	require.Len(t, tokens.Tokens, 2)
+	assert.Equal(t, "ETH", tokens.Tokens[0].Symbol)
+	assert.True(t, tokens.Tokens[0].Paused)
+	assert.False(t, tokens.Tokens[0].Dispensable)
+	assert.False(t, tokens.Tokens[1].Paused)
+}
+
+func TestRequestTokensBonusChallenge(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+	ctx := context.Background()
+
+	// Disabled by default
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/challenge?bonus=true", nil))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	h.config.BonusDifficulty = 1
+	h.config.MaxBonusRequestsPerDay = 1
+	useDailyRequests(t, h, 5)
+
+	submit := func(token, path string) (int, models.ErrorResponse) {
+		challengeID, nonce := requestChallengeAt(t, app, path)
+		var errResp models.ErrorResponse
+		status := postFaucet(t, app, models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       token,
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, &errResp)
+		return status, errResp
+	}
+
+	// A normal challenge can't go past the quota
+	status, errResp := submit("STRK", "/api/v1/challenge")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, models.ErrCodeRateLimited, errResp.Code)
+
+	// Nor can one issued as hard as a bonus challenge during a velocity alert
+	require.NoError(t, h.redis.StoreChallenge(ctx, "raised", "abc123", 2, "", false, time.Minute))
+	raisedNonce, err := pow.SolveChallenge("abc123", 2, nil)
+	require.NoError(t, err)
+	status = postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: "raised",
+		Nonce:       &raisedNonce,
+	}, &errResp)
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+
+	// A bonus challenge is harder and pays for one request
+	resp, err = app.Test(httptest.NewRequest("POST", "/api/v1/challenge?bonus=true", nil))
+	require.NoError(t, err)
+	var challenge models.ChallengeResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&challenge))
+	resp.Body.Close()
+	assert.True(t, challenge.Bonus)
+	assert.Equal(t, 2, challenge.Difficulty)
+
+	status, _ = submit("STRK", "/api/v1/challenge?bonus=true")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 1, mock.TransferCount())
+
+	used, err := h.redis.BonusRequestsUsed(ctx, "0.0.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, 1, used)
+	quotaUsed, _, _, _, err := h.redis.GetIPDailyQuota(ctx, "0.0.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, 5, quotaUsed, "a bonus request must not be charged to the quota")
+
+	// Bonus requests are capped per day
+	status, errResp = submit("ETH", "/api/v1/challenge?bonus=true")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, models.ErrCodeRateLimited, errResp.Code)
+	assert.Equal(t, 1, mock.TransferCount())
+}
+
+func TestRequestTokensChallengeToken(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	submit := func(token, challengeID string, nonce uint64) (int, models.ErrorResponse) {
+		var errResp models.ErrorResponse
+		status := postFaucet(t, app, models.FaucetRequest{
+			Address:     "0x0742d469482a89e7",
+			Token:       token,
+			ChallengeID: challengeID,
+			Nonce:       &nonce,
+		}, &errResp)
+		return status, errResp
+	}
+	unboundID, unboundNonce := requestChallengeAt(t, app, "/api/v1/challenge")
+
+	// Once required, challenges must name a known token
+	h.config.RequireChallengeToken = true
+	for _, path := range []string{"/api/v1/challenge", "/api/v1/challenge?token=DOGE", "/api/v1/challenges?count=2"} {
+		resp, err := app.Test(httptest.NewRequest("POST", path, nil))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, path)
+	}
+	status, errResp := submit("STRK", unboundID, unboundNonce)
+	assert.Equal(t, fiber.StatusBadRequest, status, "challenges issued unbound are refused too")
+	assert.Equal(t, models.ErrCodeChallengeInvalid, errResp.Code)
+
+	// A bound challenge only pays for its token
+	h.config.RequireChallengeToken = false
+	challengeID, nonce := requestChallengeAt(t, app, "/api/v1/challenge?token=strk")
+	status, errResp = submit("ETH", challengeID, nonce)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, models.ErrCodeChallengeInvalid, errResp.Code)
+	assert.Contains(t, errResp.Error, "issued for STRK, not ETH")
+
+	challengeID, nonce = requestChallengeAt(t, app, "/api/v1/challenge?token=STRK")
+	status, _ = submit("STRK", challengeID, nonce)
+	assert.Equal(t, fiber.StatusOK, status)
+
+	// Unbound challenges keep working for any token while binding is optional
+	challengeID, nonce = requestChallengeAt(t, app, "/api/v1/challenge")
+	status, _ = submit("ETH", challengeID, nonce)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 2, mock.TransferCount())
+}
+
+func TestRequestTokensMinSolveTime(t *testing.T) {
+	h, mr, mock := newTestHandler(t)
+	h.config.MinSolveSeconds = 10
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/challenge", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var challenge models.ChallengeResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&challenge))
+	assert.Equal(t, 10, challenge.MinSolveSeconds, "clients learn the floor with the challenge")
+
+	nonce, err := pow.SolveChallenge(challenge.Challenge, challenge.Difficulty, nil)
+	require.NoError(t, err)
+	req := models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challenge.ChallengeID,
+		Nonce:       &nonce,
+	}
+
+	mr.FastForward(4 * time.Second)
+	var errResp models.ErrorResponse
+	assert.Equal(t, fiber.StatusBadRequest, postFaucet(t, app, req, &errResp))
+	assert.Equal(t, models.ErrCodeSolvedTooFast, errResp.Code)
+	assert.Contains(t, errResp.Error, "4.0s")
+	assert.Zero(t, mock.TransferCount())
+
+	// The early submit didn't burn the challenge
+	mr.FastForward(6 * time.Second)
+	var faucetResp models.FaucetResponse
+	assert.Equal(t, fiber.StatusOK, postFaucet(t, app, req, &faucetResp))
+	assert.Equal(t, 1, mock.TransferCount())
+}
+
+func TestRequestTokensInFlightLock(t *testing.T) {
+	h, mr, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	challengeID, nonce := requestChallenge(t, app)
+	req := models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challengeID,
+		Nonce:       &nonce,
+	}
+
+	// Another request from this IP is still running
+	token, ok, err := h.redis.AcquireRequestLock(context.Background(), "0.0.0.0", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var errResp models.ErrorResponse
+	assert.Equal(t, fiber.StatusConflict, postFaucet(t, app, req, &errResp))
+	assert.Equal(t, models.ErrCodeInProgress, errResp.Code)
+	assert.Zero(t, mock.TransferCount())
+
+	// Once it finishes, the challenge is still usable
+	require.NoError(t, h.redis.ReleaseRequestLock(context.Background(), "0.0.0.0", token))
+	var resp models.FaucetResponse
+	assert.Equal(t, fiber.StatusOK, postFaucet(t, app, req, &resp))
+	assert.False(t, mr.Exists("lock:request:ip:0.0.0.0"), "the lock is released after success")
+
+	// and after failures, here the hourly token throttle
+	assert.Equal(t, fiber.StatusTooManyRequests, postFaucet(t, app, req, &errResp))
+	assert.False(t, mr.Exists("lock:request:ip:0.0.0.0"), "the lock is released after an error")
+}
+
+func TestRequestLockSkipsIPForKeysAndAllowlist(t *testing.T) {
+	const key = "partner-key-0123456789"
+	h, mr, mock := newTestHandler(t)
+	h.config.TrustedAPIKeys = []string{key}
+	h.config.MaxRequestsPerDayAPIKey = 3
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	// A request from this IP is still running
+	_, ok, err := h.redis.AcquireRequestLock(context.Background(), "0.0.0.0", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// An API key is locked per key, not per IP
+	req := models.FaucetRequest{Address: "0x0742d469482a89e7", Token: "STRK"}
+	var resp models.FaucetResponse
+	assert.Equal(t, fiber.StatusOK, postFaucetWithKey(t, app, key, req, &resp))
+	assert.Equal(t, 1, mock.TransferCount())
+
+	sum := sha256.Sum256([]byte(key))
+	keyLock := "apikey:" + hex.EncodeToString(sum[:8])
+	_, ok, err = h.redis.AcquireRequestLock(context.Background(), keyLock, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok, "the key's lock is released after the request")
+
+	var errResp models.ErrorResponse
+	assert.Equal(t, fiber.StatusConflict, postFaucetWithKey(t, app, key, req, &errResp))
+	assert.Equal(t, models.ErrCodeInProgress, errResp.Code)
+
+	// Allowlisted IPs skip the lock like the other limits
+	allowlist, err := utils.ParseIPList("0.0.0.0")
+	require.NoError(t, err)
+	h.config.IPAllowlist = allowlist
+	challengeID, nonce := requestChallenge(t, app)
+	req.ChallengeID, req.Nonce = challengeID, &nonce
+	assert.Equal(t, fiber.StatusOK, postFaucet(t, app, req, &resp))
+	assert.Equal(t, 2, mock.TransferCount())
+	assert.True(t, mr.Exists("lock:request:ip:0.0.0.0"), "the held IP lock is untouched")
+}
+
+func TestGetChallengesBatch(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	getChallenges := func(count string) (int, models.ChallengeBatchResponse) {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/challenges?count="+count, nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var batch models.ChallengeBatchResponse
+		if resp.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+		}
+		return resp.StatusCode, batch
+	}
+
+	for _, count := range []string{"0", "11", "x"} {
+		status, _ := getChallenges(count)
+		assert.Equal(t, fiber.StatusBadRequest, status, "count=%s", count)
+	}
+
+	// 5 + 3 uses up the hourly limit of 8; 4 more would exceed it
+	status, batch := getChallenges("5")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Len(t, batch.Challenges, 5)
+	ids := make(map[string]bool)
+	for _, challenge := range batch.Challenges {
+		ids[challenge.ChallengeID] = true
+	}
+	assert.Len(t, ids, 5, "challenge IDs must be unique")
+
+	status, _ = getChallenges("4")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	status, _ = getChallenges("3")
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = getChallenges("1")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+
+	// Each challenge is single-use on its own
+	challenge := batch.Challenges[0]
+	nonce, err := pow.SolveChallenge(challenge.Challenge, challenge.Difficulty, nil)
+	require.NoError(t, err)
+	req := models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challenge.ChallengeID,
+		Nonce:       &nonce,
+	}
+	var resp models.FaucetResponse
+	require.Equal(t, fiber.StatusOK, postFaucet(t, app, req, &resp))
+
+	// A different token, so the hourly token throttle doesn't answer first
+	req.Token = "ETH"
+	var errResp models.ErrorResponse
+	postFaucet(t, app, req, &errResp)
+	assert.Equal(t, models.ErrCodeChallengeInvalid, errResp.Code)
+	assert.Equal(t, 1, mock.TransferCount())
+}
+
+func TestVerifyPoWEndpoint(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	verify := func(body string, out interface{}) int {
+		req := httptest.NewRequest("POST", "/api/v1/pow/verify", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		return resp.StatusCode
+	}
+
+	// Any difficulty can be checked, not just the server's
+	nonce, err := pow.SolveChallenge("abc", 3, nil)
+	require.NoError(t, err)
+	var result models.PoWVerifyResponse
+	require.Equal(t, fiber.StatusOK, verify(fmt.Sprintf(`{"challenge":"abc","nonce":%d,"difficulty":3}`, nonce), &result))
+	assert.True(t, result.Valid)
+	assert.Equal(t, pow.Hash("abc", nonce), result.Hash)
+	assert.Equal(t, 3, result.Difficulty)
+
+	require.Equal(t, fiber.StatusOK, verify(`{"challenge":"abc","nonce":42,"difficulty":1}`, &result))
+	assert.False(t, result.Valid)
+	assert.Equal(t, "8216ac77f5c9ed66907b03f1d78b795e7cdd315cecd13df42cd7245d45e16b06", result.Hash)
+
+	var errResp models.ErrorResponse
+	require.Equal(t, fiber.StatusBadRequest, verify(`{"challenge":"abc","difficulty":0}`, &errResp))
+	assert.Equal(t, models.ErrCodeInvalidRequest, errResp.Code)
+	assert.Equal(t, map[string]string{"nonce": "is required", "difficulty": "must be at least 1"}, errResp.FieldErrors)
+
+	// Light per-IP limit
+	for i := 0; i < powVerifyPerMinute-3; i++ {
+		verify(`{"challenge":"abc","nonce":42,"difficulty":1}`, &result)
+	}
+	assert.Equal(t, fiber.StatusTooManyRequests, verify(`{"challenge":"abc","nonce":42,"difficulty":1}`, &errResp))
+	assert.Equal(t, models.ErrCodeRateLimited, errResp.Code)
+}
+
+func TestPoWInfo(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	h.config.PoWDifficulty = 6
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	get := func() models.PoWInfoResponse {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/pow/info", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "public, max-age=10", resp.Header.Get("Cache-Control"))
+		var info models.PoWInfoResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info
+	}
+
+	info := get()
+	assert.Equal(t, 6, info.Difficulty)
+	assert.Equal(t, "sha256", info.Algorithm)
+	assert.Equal(t, uint64(16777216), info.ExpectedAttempts)
+	assert.Equal(t, float64(pow.AssumedHashRate), info.AssumedHashRate)
+	assert.Equal(t, int64(40), info.EstimatedSolveSeconds)
+	assert.Zero(t, info.BonusDifficulty)
+
+	// Follows the difficulty raised during a velocity alert
+	h.config.VelocityDifficultyBump = 1
+	h.config.BonusDifficulty = 2
+	h.velocity.alerting = true
+	info = get()
+	assert.Equal(t, 7, info.Difficulty)
+	assert.Equal(t, 9, info.BonusDifficulty)
+	assert.Equal(t, int64(644), info.EstimatedSolveSeconds)
+}
+
+func TestGlobalRateLimit(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	h.config.GlobalRPS = 2
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	statuses := make([]int, 3)
+	for i := range statuses {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/challenge", nil))
+		require.NoError(t, err)
+		statuses[i] = resp.StatusCode
+
+		if i == len(statuses)-1 {
+			var errResp models.ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+			assert.Equal(t, models.ErrCodeServerBusy, errResp.Code)
+		}
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusServiceUnavailable}, statuses)
+
+	// The ceiling is shared with the faucet endpoint
+	var errResp models.ErrorResponse
+	status := postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: "unused",
+		Nonce:       noncePtr(1),
+	}, &errResp)
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+}
+
+func TestRequireJSON(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	body := `{"address":"0x0742d469482a89e7","token":"STRK","challenge_id":"c1","nonce":1}`
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		req := httptest.NewRequest("POST", "/api/v1/faucet", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var errResp models.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+		resp.Body.Close()
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode, contentType)
+		assert.Equal(t, models.ErrCodeInvalidRequest, errResp.Code)
+		assert.Contains(t, errResp.Error, "application/json")
+		if contentType != "" {
+			assert.Contains(t, errResp.Error, contentType, "the rejected type is named")
+		}
+	}
+	assert.Zero(t, mock.TransferCount())
+
+	// Parameters such as the charset are fine
+	req := httptest.NewRequest("POST", "/api/v1/pow/verify", strings.NewReader(`{"challenge":"abc","nonce":42,"difficulty":1}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// Challenges take no body, so no content type is needed
+	resp, err = app.Test(httptest.NewRequest("POST", "/api/v1/challenge", nil))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestRouteTimeout(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	deadline := h.routeTimeout(50 * time.Millisecond)
+
+	// Fails once its context is cancelled, as Redis and RPC calls do
+	app.Get("/stuck", deadline, func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return respondError(c, fiber.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to check rate limit",
+			Code:  models.ErrCodeInternal,
+		})
+	})
+	// Finishes late but successfully, e.g. a transfer that was sent
+	app.Get("/late", deadline, func(c *fiber.Ctx) error {
+		time.Sleep(100 * time.Millisecond)
+		return c.SendString("sent")
+	})
+	// Already explains the timeout
+	app.Get("/rpc", deadline, func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		failure := rpcTimeoutFailure()
+		return respondError(c, failure.status, failure.resp)
+	})
+	app.Get("/off", h.routeTimeout(0), func(c *fiber.Ctx) error {
+		_, ok := c.UserContext().Deadline()
+		assert.False(t, ok, "0 sets no deadline")
+		return c.SendString("ok")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/stuck", nil), -1)
+	require.NoError(t, err)
+	var errResp models.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, models.ErrCodeRequestTimeout, errResp.Code)
+	assert.Contains(t, errResp.Error, "50ms")
+
+	for path, want := range map[string]int{"/late": fiber.StatusOK, "/rpc": fiber.StatusGatewayTimeout, "/off": fiber.StatusOK} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, want, resp.StatusCode, path)
+	}
+}
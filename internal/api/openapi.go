@@ -0,0 +1,290 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// openAPIDocument describes the public API. Its schemas are generated from the
+// models the handlers encode, so they can't drift from the responses.
+var openAPIDocument = buildOpenAPIDocument()
+
+// OpenAPI serves the OpenAPI 3 description of the API
+func (h *Handler) OpenAPI(c *fiber.Ctx) error {
+	return c.JSON(openAPIDocument)
+}
+
+// schemaSet collects the component schemas of the models a document refers to
+type schemaSet map[string]interface{}
+
+// ref returns the schema of v's type, registering named structs as components
+func (s schemaSet) ref(v interface{}) map[string]interface{} {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s schemaSet) schema(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.schema(t.Elem())
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := s[name]; !ok {
+			s[name] = nil // Reserve the name while the fields are walked
+			s[name] = s.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// object describes a struct by its json tags. A field is required when its
+// validate tag says so or, outside request models, when it is always encoded.
+// Pointers encoded without omitempty may be null.
+func (s schemaSet) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+		rules := field.Tag.Get("validate")
+
+		prop := s.schema(field.Type)
+		if field.Type.Kind() == reflect.Ptr && !omitempty {
+			prop = nullable(prop)
+		}
+		applyValidateRules(prop, rules)
+		properties[name] = prop
+
+		if strings.Contains(rules, "required") || (rules == "" && !omitempty) {
+			required = append(required, name)
+		}
+	}
+
+	object := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+// nullable lets a property be null. Siblings of $ref are ignored, so a
+// reference is wrapped in allOf.
+func nullable(prop map[string]interface{}) map[string]interface{} {
+	if _, ok := prop["$ref"]; ok {
+		return map[string]interface{}{"allOf": []interface{}{prop}, "nullable": true}
+	}
+	prop["nullable"] = true
+	return prop
+}
+
+// applyValidateRules copies the oneof, min and max rules of a validate tag
+// into a property schema. On arrays, min and max bound the item count.
+func applyValidateRules(prop map[string]interface{}, rules string) {
+	minKey, maxKey := "minimum", "maximum"
+	if prop["type"] == "array" {
+		minKey, maxKey = "minItems", "maxItems"
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "oneof":
+			prop["enum"] = strings.Fields(value)
+		case "min":
+			if n, err := strconv.Atoi(value); err == nil {
+				prop[minKey] = n
+			}
+		case "max":
+			if n, err := strconv.Atoi(value); err == nil {
+				prop[maxKey] = n
+			}
+		}
+	}
+}
+
+// buildOpenAPIDocument describes each public endpoint. The admin and Discord
+// endpoints are left out: they are optional and not meant for integrators.
+func buildOpenAPIDocument() map[string]interface{} {
+	schemas := schemaSet{}
+
+	jsonContent := func(v interface{}) map[string]interface{} {
+		return map[string]interface{}{"application/json": map[string]interface{}{"schema": schemas.ref(v)}}
+	}
+	// operation describes an endpoint answering ok with v and failing with an
+	// ErrorResponse for each of errorStatuses
+	operation := func(summary string, v interface{}, errorStatuses ...int) map[string]interface{} {
+		responses := map[string]interface{}{
+			"200": map[string]interface{}{"description": "OK", "content": jsonContent(v)},
+		}
+		for _, status := range errorStatuses {
+			responses[strconv.Itoa(status)] = map[string]interface{}{
+				"description": http.StatusText(status),
+				"content":     jsonContent(models.ErrorResponse{}),
+			}
+		}
+		return map[string]interface{}{"summary": summary, "responses": responses}
+	}
+	// withBody adds a JSON request body, and the 415 other content types get
+	withBody := func(op map[string]interface{}, v interface{}) map[string]interface{} {
+		op["requestBody"] = map[string]interface{}{"required": true, "content": jsonContent(v)}
+		op["responses"].(map[string]interface{})[strconv.Itoa(http.StatusUnsupportedMediaType)] = map[string]interface{}{
+			"description": http.StatusText(http.StatusUnsupportedMediaType),
+			"content":     jsonContent(models.ErrorResponse{}),
+		}
+		return op
+	}
+	withParams := func(op map[string]interface{}, params ...map[string]interface{}) map[string]interface{} {
+		op["parameters"] = params
+		return op
+	}
+	param := func(in, name, typ, description string) map[string]interface{} {
+		return map[string]interface{}{
+			"in":          in,
+			"name":        name,
+			"required":    in == "path",
+			"description": description,
+			"schema":      map[string]interface{}{"type": typ},
+		}
+	}
+
+	paths := map[string]interface{}{
+		"/health": map[string]interface{}{
+			"get": operation("Check that the API is up", models.HealthResponse{}, http.StatusServiceUnavailable),
+		},
+		"/health/full": map[string]interface{}{
+			"get": operation("Check the API and each of its dependencies", models.FullHealthResponse{}),
+		},
+		"/api/v1/challenge": map[string]interface{}{
+			"post": withParams(
+				operation("Get a proof-of-work challenge", models.ChallengeResponse{},
+					http.StatusBadRequest, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable),
+				param("query", "bonus", "boolean", "Issue a harder challenge that pays for a request past the daily limit"),
+				param("query", "token", "string", "Bind the challenge to ETH, STRK, BOTH or ALL; required when pow.token_required is set"),
+			),
+		},
+		"/api/v1/challenges": map[string]interface{}{
+			"post": withParams(
+				operation("Get several proof-of-work challenges", models.ChallengeBatchResponse{},
+					http.StatusBadRequest, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable),
+				param("query", "count", "integer", "Number of challenges, at most 10"),
+				param("query", "token", "string", "Bind the challenges to ETH, STRK, BOTH or ALL; required when pow.token_required is set"),
+			),
+		},
+		"/api/v1/pow/verify": map[string]interface{}{
+			"post": withBody(
+				operation("Check a proof-of-work solution", models.PoWVerifyResponse{}, http.StatusBadRequest, http.StatusTooManyRequests),
+				models.PoWVerifyRequest{},
+			),
+		},
+		"/api/v1/pow/info": map[string]interface{}{
+			"get": operation("Get the proof-of-work difficulty and typical solve time", models.PoWInfoResponse{}),
+		},
+		"/api/v1/faucet": map[string]interface{}{
+			"post": withParams(
+				withBody(
+					operation("Request tokens", models.FaucetResponse{},
+						http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict,
+						http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout),
+					models.FaucetRequest{},
+				),
+				param("query", "wait", "boolean", "Respond once the transfer reaches the server's confirmation level"),
+				param("header", "X-API-Key", "string", "Trusted API key; challenge_id and nonce may then be omitted"),
+			),
+		},
+		"/api/v1/faucet/authorized": map[string]interface{}{
+			"post": withParams(
+				withBody(
+					operation("Request tokens with an authorization signed by the recipient (when SIGNED_REQUESTS_ENABLED)", models.FaucetResponse{},
+						http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict,
+						http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout),
+					models.AuthorizedFaucetRequest{},
+				),
+				param("query", "wait", "boolean", "Respond once the transfer reaches the server's confirmation level"),
+			),
+		},
+		"/api/v1/status/{address}": map[string]interface{}{
+			"get": withParams(
+				operation("Get the request status of an address", models.StatusResponse{}, http.StatusBadRequest, http.StatusInternalServerError),
+				param("path", "address", "string", "Starknet address"),
+			),
+		},
+		"/api/v1/info": map[string]interface{}{
+			"get": operation("Get faucet limits, difficulty and balances", models.InfoResponse{}),
+		},
+		"/api/v1/tokens": map[string]interface{}{
+			"get": operation("List the supported tokens", models.TokensResponse{}),
+		},
+		"/api/v1/quota": map[string]interface{}{
+			"get": operation("Get the caller's daily quota and token throttles", models.QuotaResponse{}, http.StatusInternalServerError),
+		},
+		"/api/v1/distribution/series": map[string]interface{}{
+			"get": withParams(
+				operation("Get the amount of a token sent over time, in buckets", models.DistributionSeriesResponse{}, http.StatusBadRequest, http.StatusInternalServerError),
+				param("query", "token", "string", "Token symbol (default STRK)"),
+				param("query", "window", "string", "Duration to cover, from 1m to 168h (default 24h); up to 2h uses minute buckets"),
+			),
+		},
+	}
+
+	// Every /api/v1 route can answer 503 REQUEST_TIMEOUT past its deadline
+	for path, item := range paths {
+		if !strings.HasPrefix(path, "/api/v1/") {
+			continue
+		}
+		for _, op := range item.(map[string]interface{}) {
+			responses := op.(map[string]interface{})["responses"].(map[string]interface{})
+			if _, ok := responses[strconv.Itoa(http.StatusServiceUnavailable)]; !ok {
+				responses[strconv.Itoa(http.StatusServiceUnavailable)] = map[string]interface{}{
+					"description": http.StatusText(http.StatusServiceUnavailable),
+					"content":     jsonContent(models.ErrorResponse{}),
+				}
+			}
+		}
+	}
+
+	// Clients branch on the error code, so list its values
+	errorSchema := schemas["ErrorResponse"].(map[string]interface{})
+	errorSchema["properties"].(map[string]interface{})["code"].(map[string]interface{})["enum"] = models.ErrorCodes
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Starknet Faucet API",
+			"version":     "1",
+			"description": "Testnet STRK and ETH for Starknet addresses, paid for with proof of work",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": map[string]interface{}(schemas)},
+	}
+}
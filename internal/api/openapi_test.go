@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/openapi.json", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string                          `json:"required"`
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+
+	// Every public route is described, in OpenAPI's {param} form
+	param := regexp.MustCompile(`:(\w+)`)
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || route.Method == fiber.MethodOptions || route.Method == fiber.MethodTrace ||
+			route.Path == "/openapi.json" || strings.HasPrefix(route.Path, "/api/v1/admin") {
+			continue
+		}
+		path := param.ReplaceAllString(route.Path, "{$1}")
+		assert.Contains(t, doc.Paths[path], strings.ToLower(route.Method), "%s %s is not documented", route.Method, path)
+	}
+
+	schemas := doc.Components.Schemas
+	faucetRequest := schemas["FaucetRequest"]
+	assert.ElementsMatch(t, []string{"address", "token", "challenge_id", "nonce"}, faucetRequest.Required)
+	assert.Equal(t, []interface{}{"ETH", "STRK", "BOTH", "ALL"}, faucetRequest.Properties["token"]["enum"])
+	assert.Equal(t, "integer", faucetRequest.Properties["nonce"]["type"])
+
+	assert.Equal(t, float64(64), schemas["PoWVerifyRequest"].Properties["difficulty"]["maximum"])
+	assert.Contains(t, schemas["ErrorResponse"].Properties["code"]["enum"], "RATE_LIMITED")
+	assert.NotContains(t, schemas["FaucetResponse"].Required, "tx_hash", "omitempty fields are optional")
+
+	// Quota is described from the struct the handler encodes
+	cooldownEnd := schemas["DailyLimitInfo"].Properties["cooldown_end"]
+	assert.Equal(t, "date-time", cooldownEnd["format"])
+	assert.Equal(t, true, cooldownEnd["nullable"])
+}
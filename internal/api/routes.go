@@ -1,43 +1,188 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"go.uber.org/zap"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
 )
 
 // SetupRoutes sets up all API routes
 func SetupRoutes(app *fiber.App, handler *Handler) {
 	// Middleware
 	app.Use(recover.New())
-	app.Use(logger.New())
+	// Tag every request with a correlation ID (echoed in the X-Request-ID header)
+	app.Use(requestid.New())
+	// Structured access log with per-endpoint latency and payload sizes
+	app.Use(accessLogger(handler.logger, handler.config.LogSkipPaths))
 	// CORS - Allow all origins for public faucet API
 	// CLI and frontend can make requests from anywhere
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",  // Public API - allow all domains
-		AllowHeaders: "Origin, Content-Type, Accept",
-		AllowMethods: "GET, POST, OPTIONS",
+		AllowOrigins:  "*", // Public API - allow all domains
+		AllowHeaders:  "Origin, Content-Type, Accept, X-Request-ID, X-API-Key, X-Admin-Key",
+		ExposeHeaders: "X-Request-ID",
+		AllowMethods:  "GET, POST, OPTIONS",
 	}))
 
-	// Health check
+	// Health checks: /health is a fast probe for load balancers,
+	// /health/full also checks the RPC provider and faucet balance
 	app.Get("/health", handler.Health)
+	app.Get("/health/full", handler.HealthFull)
+
+	// Machine-readable API description for integrators
+	app.Get("/openapi.json", handler.OpenAPI)
 
 	// API v1 routes
 	v1 := app.Group("/api/v1")
 
-	// Challenge endpoint
-	v1.Post("/challenge", handler.GetChallenge)
+	// Global ceiling protecting the RPC node, shared by all IPs
+	globalLimit := globalRateLimiter(handler.config.GlobalRPS)
+
+	// Deadlines: short for lookups, longer for the faucet's transfers
+	quick := handler.routeTimeout(time.Duration(handler.config.RouteTimeout) * time.Second)
+	slow := handler.routeTimeout(time.Duration(handler.config.FaucetRouteTimeout) * time.Second)
+
+	// Challenge endpoints take their options from the query, so they accept
+	// any body; the other POST endpoints below require JSON
+	v1.Post("/challenge", quick, globalLimit, handler.GetChallenge)
+	v1.Post("/challenges", quick, globalLimit, handler.GetChallenges)
+
+	// Solver self-test, limited per IP since it does no other rate limiting
+	v1.Post("/pow/verify", quick, requireJSON, perIPRateLimiter(powVerifyPerMinute), handler.VerifyPoW)
+
+	// Current difficulty and typical solve time, so clients can set expectations
+	v1.Get("/pow/info", quick, handler.PoWInfo)
 
 	// Faucet endpoint
-	v1.Post("/faucet", handler.RequestTokens)
+	v1.Post("/faucet", slow, requireJSON, globalLimit, handler.RequestTokens)
+
+	// Requests signed by the recipient, submitted by a relayer
+	if handler.config.SignedRequestsEnabled {
+		v1.Post("/faucet/authorized", slow, requireJSON, globalLimit, handler.RequestTokensAuthorized)
+	}
 
 	// Status endpoint
-	v1.Get("/status/:address", handler.GetStatus)
+	v1.Get("/status/:address", quick, handler.GetStatus)
 
 	// Info endpoint
-	v1.Get("/info", handler.GetInfo)
+	v1.Get("/info", quick, handler.GetInfo)
+
+	// Tokens endpoint
+	v1.Get("/tokens", quick, handler.GetTokens)
 
 	// Quota endpoint
-	v1.Get("/quota", handler.GetQuota)
+	v1.Get("/quota", quick, handler.GetQuota)
+
+	// Drip volume over time, for dashboards
+	v1.Get("/distribution/series", quick, handler.DistributionSeries)
+
+	// Discord slash command webhook, authenticated by Discord's signature
+	if handler.discord != nil {
+		v1.Post("/discord/interactions", quick, handler.DiscordInteractions)
+	}
+
+	// Admin endpoints, only served once ADMIN_API_KEY is set
+	if handler.config.AdminAPIKey != "" {
+		admin := v1.Group("/admin", quick, handler.RequireAdmin)
+		admin.Get("/requests", handler.AdminRequests)
+	}
+}
+
+// requireJSON rejects requests whose Content-Type isn't application/json with
+// 415, so a client bug is reported as such rather than as an unparsable body
+func requireJSON(c *fiber.Ctx) error {
+	if c.Is("json") {
+		return c.Next()
+	}
+
+	message := "Missing Content-Type header. Send the request body as JSON with Content-Type: application/json."
+	if contentType := c.Get(fiber.HeaderContentType); contentType != "" {
+		message = fmt.Sprintf("Unsupported Content-Type %q. Send the request body as JSON with Content-Type: application/json.", contentType)
+	}
+	return respondError(c, fiber.StatusUnsupportedMediaType, models.ErrorResponse{
+		Error: message,
+		Code:  models.ErrCodeInvalidRequest,
+	})
+}
+
+// routeTimeout cancels the context of the handlers after it once timeout has
+// passed. A request that then fails, with an error or a 500, is answered 503
+// REQUEST_TIMEOUT instead; responses that made it in time, or that already
+// say why the request timed out, are kept. 0 disables the deadline.
+func (h *Handler) routeTimeout(timeout time.Duration) fiber.Handler {
+	if timeout <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if ctx.Err() == nil || (err == nil && c.Response().StatusCode() != fiber.StatusInternalServerError) {
+			return err
+		}
+
+		h.requestLogger(c).Warn("Request timed out", zap.Duration("timeout", timeout), zap.Error(err))
+		return respondError(c, fiber.StatusServiceUnavailable, models.ErrorResponse{
+			Error: fmt.Sprintf("The faucet took longer than %s to handle the request. Please try again shortly.", timeout),
+			Code:  models.ErrCodeRequestTimeout,
+		})
+	}
+}
+
+// powVerifyPerMinute is how many /pow/verify calls one IP may make per minute
+const powVerifyPerMinute = 60
+
+// perIPRateLimiter caps requests per minute from each IP. Counts are kept in
+// memory, so each server instance limits separately.
+func perIPRateLimiter(perMinute int) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:               perMinute,
+		Expiration:        time.Minute,
+		LimiterMiddleware: limiter.SlidingWindow{},
+		LimitReached: func(c *fiber.Ctx) error {
+			return respondError(c, fiber.StatusTooManyRequests, models.ErrorResponse{
+				Error: "Too many requests. Please try again in a minute.",
+				Code:  models.ErrCodeRateLimited,
+			})
+		},
+	})
+}
+
+// globalRateLimiter caps requests per second across all clients. It answers
+// 503 rather than 429 so clients can tell it apart from their own per-IP
+// limits. A limit of 0 disables it.
+func globalRateLimiter(rps int) fiber.Handler {
+	if rps <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return limiter.New(limiter.Config{
+		Max:               rps,
+		Expiration:        time.Second,
+		LimiterMiddleware: limiter.SlidingWindow{},
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "global"
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return respondError(c, fiber.StatusServiceUnavailable, models.ErrorResponse{
+				Error: "The faucet is receiving too many requests. Please try again in a few seconds.",
+				Code:  models.ErrCodeServerBusy,
+			})
+		},
+	})
 }
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rpcHealth tracks whether the Starknet RPC can be reached, so requests made
+// during an outage are refused at once instead of each one waiting on the
+// node. A background check keeps it current, and calls that fail because the
+// node is unreachable mark it down right away.
+type rpcHealth struct {
+	client   StarknetClient
+	logger   *zap.Logger
+	interval time.Duration
+
+	mu    sync.Mutex
+	down  bool
+	since time.Time // When the RPC went down
+}
+
+// newRPCHealth creates a tracker that checks client every interval. The RPC
+// is assumed up until a check or a call says otherwise.
+func newRPCHealth(client StarknetClient, logger *zap.Logger, interval time.Duration) *rpcHealth {
+	return &rpcHealth{
+		client:   client,
+		logger:   logger,
+		interval: max(interval, time.Second),
+	}
+}
+
+// run checks the RPC every interval until ctx is done
+func (r *rpcHealth) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check pings the RPC once and records the result. A ping that outlasts the
+// interval counts as a failure.
+func (r *rpcHealth) check(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, r.interval)
+	defer cancel()
+
+	err := r.client.Ping(ctx)
+	switch {
+	case parent.Err() != nil:
+		// Shutting down, which says nothing about the RPC
+	case err != nil:
+		r.markDown(err)
+	default:
+		r.markUp()
+	}
+}
+
+// markDown records that the RPC can't be reached
+func (r *rpcHealth) markDown(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return
+	}
+	r.down = true
+	r.since = time.Now()
+	r.logger.Error("Starknet RPC unreachable, refusing faucet requests until it recovers", zap.Error(err))
+}
+
+// markUp records that the RPC answered
+func (r *rpcHealth) markUp() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.down {
+		return
+	}
+	r.down = false
+	r.logger.Info("Starknet RPC reachable again", zap.Duration("outage", time.Since(r.since)))
+}
+
+// available reports whether the RPC was reachable when last checked
+func (r *rpcHealth) available() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.down
+}
+
+// retryAfter is how long clients should wait before trying again: until the
+// next check, which may find the RPC back
+func (r *rpcHealth) retryAfter() time.Duration {
+	return r.interval
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet/starknettest"
+)
+
+func TestRPCHealth(t *testing.T) {
+	mock := starknettest.NewMockClient()
+	health := newRPCHealth(mock, zap.NewNop(), 10*time.Second)
+	ctx := context.Background()
+
+	assert.True(t, health.available(), "the RPC is assumed up before the first check")
+	assert.Equal(t, 10*time.Second, health.retryAfter())
+
+	mock.PingErr = errors.New("connection refused")
+	health.check(ctx)
+	assert.False(t, health.available())
+
+	// A check cut short by shutdown says nothing about the RPC
+	mock.PingErr = nil
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	health.check(canceled)
+	assert.False(t, health.available())
+
+	health.check(ctx)
+	assert.True(t, health.available())
+
+	health.markDown(errors.New("connection reset"))
+	assert.False(t, health.available(), "failed calls mark the RPC down without waiting for a check")
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+)
+
+// validate checks request models against their validate struct tags
+var validate = newValidator()
+
+// newValidator creates a validator that reports fields by their JSON names,
+// since those are the names clients send
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// validateFaucetRequest runs the tag rules on a faucet request. Requests
+// authenticated with an API key skip PoW, so their challenge fields are
+// optional. It returns nil or a validator.ValidationErrors.
+func validateFaucetRequest(req models.FaucetRequest, keyed bool) error {
+	if keyed {
+		return validate.StructExcept(req, "ChallengeID", "Nonce")
+	}
+	return validate.Struct(req)
+}
+
+// fieldErrors maps each invalid field's JSON name to what is wrong with it
+func fieldErrors(err error) map[string]string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make(map[string]string, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields[fe.Field()] = fieldMessage(fe)
+	}
+	return fields
+}
+
+// fieldMessage describes a failed rule in words
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
+
+// validationErrorResponse builds the 400 body for failed tag validation. The
+// code matches the custom checks for address and token, so clients see the
+// same code whichever check caught the problem.
+func validationErrorResponse(fields map[string]string) models.ErrorResponse {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = fmt.Sprintf("%s %s", name, fields[name])
+	}
+
+	code := models.ErrCodeInvalidRequest
+	if len(names) == 1 {
+		switch names[0] {
+		case "address":
+			code = models.ErrCodeInvalidAddress
+		case "token":
+			code = models.ErrCodeInvalidToken
+		}
+	}
+
+	return models.ErrorResponse{
+		Error:       "Invalid request: " + strings.Join(messages, "; "),
+		Code:        code,
+		FieldErrors: fields,
+	}
+}
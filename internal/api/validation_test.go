@@ -0,0 +1,85 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+)
+
+func TestRequestTokensValidationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    models.FaucetRequest
+		code   string
+		fields map[string]string
+	}{
+		{
+			name:   "missing address",
+			req:    models.FaucetRequest{Token: "STRK", ChallengeID: "c1", Nonce: noncePtr(1)},
+			code:   models.ErrCodeInvalidAddress,
+			fields: map[string]string{"address": "is required"},
+		},
+		{
+			name:   "missing token",
+			req:    models.FaucetRequest{Address: "0x0742d469482a89e7", ChallengeID: "c1", Nonce: noncePtr(1)},
+			code:   models.ErrCodeInvalidToken,
+			fields: map[string]string{"token": "is required"},
+		},
+		{
+			name:   "unsupported token",
+			req:    models.FaucetRequest{Address: "0x0742d469482a89e7", Token: "doge", ChallengeID: "c1", Nonce: noncePtr(1)},
+			code:   models.ErrCodeInvalidToken,
+			fields: map[string]string{"token": "must be one of ETH, STRK, BOTH, ALL"},
+		},
+		{
+			name:   "missing challenge_id",
+			req:    models.FaucetRequest{Address: "0x0742d469482a89e7", Token: "STRK", Nonce: noncePtr(1)},
+			code:   models.ErrCodeInvalidRequest,
+			fields: map[string]string{"challenge_id": "is required"},
+		},
+		{
+			name:   "missing nonce",
+			req:    models.FaucetRequest{Address: "0x0742d469482a89e7", Token: "STRK", ChallengeID: "c1"},
+			code:   models.ErrCodeInvalidRequest,
+			fields: map[string]string{"nonce": "is required"},
+		},
+		{
+			name: "several fields",
+			req:  models.FaucetRequest{Token: "STRK"},
+			code: models.ErrCodeInvalidRequest,
+			fields: map[string]string{
+				"address":      "is required",
+				"challenge_id": "is required",
+				"nonce":        "is required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, mock := newTestHandler(t)
+			app := fiber.New()
+			SetupRoutes(app, h)
+
+			var errResp models.ErrorResponse
+			status := postFaucet(t, app, tt.req, &errResp)
+			require.Equal(t, fiber.StatusBadRequest, status)
+			assert.Equal(t, tt.code, errResp.Code)
+			assert.Equal(t, tt.fields, errResp.FieldErrors)
+			assert.Zero(t, mock.TransferCount())
+		})
+	}
+}
+
+func TestValidationErrorResponseMessage(t *testing.T) {
+	resp := validationErrorResponse(map[string]string{
+		"nonce":        "is required",
+		"challenge_id": "is required",
+	})
+	assert.Equal(t, "Invalid request: challenge_id is required; nonce is required", resp.Error)
+	assert.Equal(t, models.ErrCodeInvalidRequest, resp.Code)
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/cache"
+	"github.com/Giri-Aayush/starknet-faucet/internal/config"
+	"go.uber.org/zap"
+)
+
+// velocityEvalInterval is how often the request rate is compared with the
+// baseline. Requests in between are only counted.
+const velocityEvalInterval = 10 * time.Second
+
+// velocityClearRatio is the fraction of the alert threshold the rate must fall
+// below to clear an alert, so a rate hovering at the threshold doesn't flap
+const velocityClearRatio = 0.5
+
+// alertWebhookTimeout bounds each alert webhook delivery
+const alertWebhookTimeout = 5 * time.Second
+
+// velocityAlert is the JSON body POSTed to ALERT_WEBHOOK_URL
+type velocityAlert struct {
+	Event            string  `json:"event"` // Always "velocity_alert"
+	State            string  `json:"state"` // "started" or "cleared"
+	RecentRequests   float64 `json:"recent_requests"`
+	BaselineRequests float64 `json:"baseline_requests"` // Average over the same length of time
+	WindowMinutes    int     `json:"window_minutes"`
+	PoWDifficulty    int     `json:"pow_difficulty"` // Difficulty of new challenges from now on
+	Timestamp        int64   `json:"timestamp"`
+}
+
+// velocityMonitor watches the faucet request rate for spikes, such as many IPs
+// draining the faucet at once. Counts are shared through Redis, but each
+// server instance keeps its own alert state, so with several instances expect
+// one alert from each.
+type velocityMonitor struct {
+	config *config.Config
+	logger *zap.Logger
+	redis  *cache.RedisClient
+	client *http.Client
+
+	mu       sync.Mutex
+	alerting bool
+	lastEval time.Time
+}
+
+// newVelocityMonitor creates a monitor for the configured thresholds
+func newVelocityMonitor(cfg *config.Config, logger *zap.Logger, redis *cache.RedisClient) *velocityMonitor {
+	return &velocityMonitor{
+		config: cfg,
+		logger: logger,
+		redis:  redis,
+		client: &http.Client{Timeout: alertWebhookTimeout},
+	}
+}
+
+// enabled reports whether velocity alerts are configured
+func (m *velocityMonitor) enabled() bool {
+	return m.config.VelocityAlertMultiple > 0
+}
+
+// observe counts a request and, at most every velocityEvalInterval, compares
+// the recent rate with the baseline, alerting when the state changes
+func (m *velocityMonitor) observe(ctx context.Context, now time.Time) {
+	if !m.enabled() {
+		return
+	}
+
+	window, baseline := m.config.VelocityWindowMinutes, m.config.VelocityBaselineMinutes
+	retention := time.Duration(window+baseline+1) * time.Minute
+	if err := m.redis.RecordRequestVelocity(ctx, now, retention); err != nil {
+		m.logger.Error("Failed to record request velocity", zap.Error(err))
+		return
+	}
+
+	m.mu.Lock()
+	if now.Sub(m.lastEval) < velocityEvalInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.lastEval = now
+	m.mu.Unlock()
+
+	counts, err := m.redis.RequestVelocity(ctx, now, window+baseline)
+	if err != nil {
+		m.logger.Error("Failed to read request velocity", zap.Error(err))
+		return
+	}
+	recent, average := velocityRates(counts, window, baseline)
+
+	m.mu.Lock()
+	was := m.alerting
+	m.alerting = nextVelocityState(was, recent, average, m.config.VelocityAlertMultiple, m.config.VelocityMinRequests)
+	changed, alerting := was != m.alerting, m.alerting
+	m.mu.Unlock()
+
+	if changed {
+		m.alert(alerting, recent, average, now)
+	}
+}
+
+// velocityRates splits per-minute counts (newest first) into the requests of
+// the recent window and the baseline's average for a window of that length
+func velocityRates(counts []int64, window, baseline int) (recent, average float64) {
+	for i, n := range counts {
+		if i < window {
+			recent += float64(n)
+		} else if i < window+baseline {
+			average += float64(n)
+		}
+	}
+	return recent, average * float64(window) / float64(baseline)
+}
+
+// nextVelocityState decides whether an alert is active after seeing the
+// recent and baseline rates. An alert starts above multiple times the
+// baseline (treating a quiet baseline as 1 request) once there are at least
+// minRequests, and clears only below velocityClearRatio of that threshold.
+func nextVelocityState(alerting bool, recent, average, multiple float64, minRequests int) bool {
+	threshold := multiple * max(average, 1)
+	if alerting {
+		return recent >= threshold*velocityClearRatio
+	}
+	return recent >= float64(minRequests) && recent > threshold
+}
+
+// difficulty returns the PoW difficulty for new challenges, raised while an
+// alert is active
+func (m *velocityMonitor) difficulty() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.alerting {
+		return m.config.PoWDifficulty + m.config.VelocityDifficultyBump
+	}
+	return m.config.PoWDifficulty
+}
+
+// alert logs a change of alert state and sends it to the webhook, if any
+func (m *velocityMonitor) alert(alerting bool, recent, average float64, now time.Time) {
+	payload := velocityAlert{
+		Event:            "velocity_alert",
+		State:            "cleared",
+		RecentRequests:   recent,
+		BaselineRequests: average,
+		WindowMinutes:    m.config.VelocityWindowMinutes,
+		PoWDifficulty:    m.difficulty(),
+		Timestamp:        now.Unix(),
+	}
+	fields := []zap.Field{
+		zap.Float64("recent_requests", recent),
+		zap.Float64("baseline_requests", average),
+		zap.Int("window_minutes", payload.WindowMinutes),
+		zap.Int("pow_difficulty", payload.PoWDifficulty),
+	}
+	if alerting {
+		payload.State = "started"
+		m.logger.Warn("Request velocity alert: unusual spike in faucet requests", fields...)
+	} else {
+		m.logger.Info("Request velocity alert cleared", fields...)
+	}
+
+	if m.config.AlertWebhookURL != "" {
+		go m.sendWebhook(payload)
+	}
+}
+
+// sendWebhook POSTs an alert to ALERT_WEBHOOK_URL
+func (m *velocityMonitor) sendWebhook(payload velocityAlert) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		m.logger.Error("Failed to encode alert", zap.Error(err))
+		return
+	}
+
+	resp, err := m.client.Post(m.config.AlertWebhookURL, "application/json", bytes.NewReader(body))
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			err = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+	}
+	if err != nil {
+		m.logger.Error("Failed to deliver alert webhook", zap.Error(err))
+	}
+}
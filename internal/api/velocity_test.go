@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/pow"
+)
+
+func TestVelocityRates(t *testing.T) {
+	// 2-minute window against a 4-minute baseline; older minutes are ignored
+	recent, average := velocityRates([]int64{5, 3, 1, 2, 1, 0, 99}, 2, 4)
+	assert.Equal(t, 8.0, recent)
+	assert.Equal(t, 2.0, average, "4 baseline requests over 4 minutes is 2 per 2-minute window")
+}
+
+func TestNextVelocityState(t *testing.T) {
+	tests := []struct {
+		name     string
+		alerting bool
+		recent   float64
+		average  float64
+		want     bool
+	}{
+		{name: "normal traffic", recent: 20, average: 10, want: false},
+		{name: "spike", recent: 31, average: 10, want: true},
+		{name: "spike below the minimum", recent: 8, average: 1, want: false},
+		{name: "spike after a quiet baseline", recent: 12, average: 0, want: true},
+		{name: "still above half the threshold", alerting: true, recent: 16, average: 10, want: true},
+		{name: "back below half the threshold", alerting: true, recent: 14, average: 10, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nextVelocityState(tt.alerting, tt.recent, tt.average, 3, 10))
+		})
+	}
+}
+
+func TestVelocityAlert(t *testing.T) {
+	alerts := make(chan velocityAlert, 2)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert velocityAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	defer webhook.Close()
+
+	h, _, _ := newTestHandler(t)
+	h.config.VelocityAlertMultiple = 3
+	h.config.VelocityWindowMinutes = 2
+	h.config.VelocityBaselineMinutes = 10
+	h.config.VelocityMinRequests = 10
+	h.config.VelocityDifficultyBump = 2
+	h.config.AlertWebhookURL = webhook.URL
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	// A baseline of 1 request a minute
+	for i := 2; i < 12; i++ {
+		require.NoError(t, h.redis.RecordRequestVelocity(ctx, now.Add(-time.Duration(i)*time.Minute), time.Hour))
+	}
+
+	// 12 requests now; the rate is only evaluated every velocityEvalInterval
+	for range 12 {
+		h.velocity.observe(ctx, now)
+	}
+	assert.Equal(t, 1, h.velocity.difficulty(), "the first evaluation saw a single request")
+	h.velocity.observe(ctx, now.Add(velocityEvalInterval))
+	assert.Equal(t, 3, h.velocity.difficulty())
+
+	select {
+	case alert := <-alerts:
+		assert.Equal(t, "started", alert.State)
+		assert.Equal(t, 13.0, alert.RecentRequests)
+		assert.Equal(t, 2.0, alert.BaselineRequests)
+		assert.Equal(t, 3, alert.PoWDifficulty)
+	case <-time.After(time.Second):
+		t.Fatal("no alert webhook")
+	}
+
+	// Once the spike leaves the window, the alert clears
+	h.velocity.observe(ctx, now.Add(3*time.Minute))
+	assert.Equal(t, 1, h.velocity.difficulty())
+	select {
+	case alert := <-alerts:
+		assert.Equal(t, "cleared", alert.State)
+	case <-time.After(time.Second):
+		t.Fatal("no clearing webhook")
+	}
+}
+
+func TestChallengeDifficultyBump(t *testing.T) {
+	h, _, mock := newTestHandler(t)
+	h.config.VelocityDifficultyBump = 2
+	h.velocity.alerting = true
+	app := fiber.New()
+	SetupRoutes(app, h)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/challenge", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var challenge models.ChallengeResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&challenge))
+	require.Equal(t, 3, challenge.Difficulty)
+
+	// A solution meeting only the normal difficulty is not enough
+	var nonce uint64
+	for pow.Solves(challenge.Challenge, nonce, 3) || !pow.Solves(challenge.Challenge, nonce, 1) {
+		nonce++
+	}
+	var errResp models.ErrorResponse
+	postFaucet(t, app, models.FaucetRequest{
+		Address:     "0x0742d469482a89e7",
+		Token:       "STRK",
+		ChallengeID: challenge.ChallengeID,
+		Nonce:       &nonce,
+	}, &errResp)
+	assert.Equal(t, models.ErrCodePoWInvalid, errResp.Code)
+	assert.Zero(t, mock.TransferCount())
+}
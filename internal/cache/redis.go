@@ -2,7 +2,11 @@ package cache
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -13,6 +17,7 @@ type RedisClient struct {
 	client                *redis.Client
 	maxDailyRequestsIP    int // Max requests per IP per day (5)
 	maxChallengesPerHour  int // Max PoW challenges per IP per hour (8)
+	dailyResetHour        int // UTC hour when IP daily quotas reset, -1 for a rolling 24h window
 }
 
 // NewRedisClient creates a new Redis client
@@ -36,9 +41,39 @@ func NewRedisClient(redisURL string, maxDailyRequestsIP, maxChallengesPerHour in
 		client:                client,
 		maxDailyRequestsIP:    maxDailyRequestsIP,
 		maxChallengesPerHour:  maxChallengesPerHour,
+		dailyResetHour:        -1,
 	}, nil
 }
 
+// SetDailyResetHour makes IP daily quotas reset at a fixed UTC hour (0-23)
+// instead of 24 hours after they were last used. Pass -1 for the rolling window.
+func (r *RedisClient) SetDailyResetHour(hour int) error {
+	if hour < -1 || hour > 23 {
+		return fmt.Errorf("daily reset hour must be between 0 and 23, or -1 for rolling (got %d)", hour)
+	}
+	r.dailyResetHour = hour
+	return nil
+}
+
+// nextDailyReset returns the first time after now that the clock reads hour:00 UTC
+func nextDailyReset(now time.Time, hour int) time.Time {
+	now = now.UTC()
+	reset := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !reset.After(now) {
+		reset = reset.Add(24 * time.Hour)
+	}
+	return reset
+}
+
+// dailyWindowEnd returns when the current daily quota window (or a cooldown
+// starting now) ends
+func (r *RedisClient) dailyWindowEnd(now time.Time) time.Time {
+	if r.dailyResetHour < 0 {
+		return now.Add(24 * time.Hour)
+	}
+	return nextDailyReset(now, r.dailyResetHour)
+}
+
 // Close closes the Redis connection
 func (r *RedisClient) Close() error {
 	return r.client.Close()
@@ -46,16 +81,88 @@ func (r *RedisClient) Close() error {
 
 // Challenge-related operations
 
-// StoreChallenge stores a challenge in Redis with TTL
-func (r *RedisClient) StoreChallenge(ctx context.Context, challengeID, challenge string, ttl time.Duration) error {
+// StoreChallenge stores a challenge with the difficulty it was issued at, the
+// token it is bound to ("" for any) and whether it is a bonus challenge in
+// Redis with TTL, as "difficulty:token:bonus:challenge"
+func (r *RedisClient) StoreChallenge(ctx context.Context, challengeID, challenge string, difficulty int, token string, bonus bool, ttl time.Duration) error {
 	key := fmt.Sprintf("challenge:%s", challengeID)
-	return r.client.Set(ctx, key, challenge, ttl).Err()
+	return r.client.Set(ctx, key, fmt.Sprintf("%d:%s:%t:%s", difficulty, token, bonus, challenge), ttl).Err()
 }
 
-// GetChallenge retrieves a challenge from Redis
-func (r *RedisClient) GetChallenge(ctx context.Context, challengeID string) (string, error) {
+// GetChallenge retrieves a challenge, its difficulty and bound token from Redis
+func (r *RedisClient) GetChallenge(ctx context.Context, challengeID string) (string, int, string, error) {
 	key := fmt.Sprintf("challenge:%s", challengeID)
-	return r.client.Get(ctx, key).Result()
+	value, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		return "", 0, "", err
+	}
+	challenge, difficulty, token, _ := parseChallenge(value)
+	return challenge, difficulty, token, nil
+}
+
+// IsBonusChallenge reports whether a challenge was issued as a bonus
+// challenge. A missing challenge returns redis.Nil.
+func (r *RedisClient) IsBonusChallenge(ctx context.Context, challengeID string) (bool, error) {
+	key := fmt.Sprintf("challenge:%s", challengeID)
+	value, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	_, _, _, bonus := parseChallenge(value)
+	return bonus, nil
+}
+
+// ConsumeChallenge atomically retrieves and deletes a challenge (GETDEL), so two
+// concurrent requests can never both redeem the same solved challenge
+func (r *RedisClient) ConsumeChallenge(ctx context.Context, challengeID string) (string, int, string, error) {
+	key := fmt.Sprintf("challenge:%s", challengeID)
+	value, err := r.client.GetDel(ctx, key).Result()
+	if err != nil {
+		return "", 0, "", err
+	}
+	challenge, difficulty, token, _ := parseChallenge(value)
+	return challenge, difficulty, token, nil
+}
+
+// parseChallenge splits a stored challenge from its difficulty, bound token
+// and bonus flag. Challenges are hex, so never contain a colon. Challenges
+// stored before difficulties were recorded report difficulty 0, those stored
+// before tokens were bound report no token, and those stored before the bonus
+// flag are not bonus challenges.
+func parseChallenge(value string) (string, int, string, bool) {
+	prefix, rest, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, 0, "", false
+	}
+	difficulty, err := strconv.Atoi(prefix)
+	if err != nil {
+		return value, 0, "", false
+	}
+	token, rest, ok := strings.Cut(rest, ":")
+	if !ok {
+		return token, difficulty, "", false
+	}
+	if flag, challenge, ok := strings.Cut(rest, ":"); ok {
+		bonus, _ := strconv.ParseBool(flag)
+		return challenge, difficulty, token, bonus
+	}
+	return rest, difficulty, token, false
+}
+
+// ChallengeAge returns how long ago a challenge stored with ttl was issued. It
+// is derived from the key's remaining TTL, so only the Redis clock is involved.
+// A missing challenge returns redis.Nil.
+func (r *RedisClient) ChallengeAge(ctx context.Context, challengeID string, ttl time.Duration) (time.Duration, error) {
+	key := fmt.Sprintf("challenge:%s", challengeID)
+	remaining, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if remaining < 0 {
+		// -2 means the key is gone; -1 (no expiry) never happens for challenges
+		return 0, redis.Nil
+	}
+	return max(ttl-remaining, 0), nil
 }
 
 // DeleteChallenge removes a challenge from Redis (prevents reuse)
@@ -66,59 +173,161 @@ func (r *RedisClient) DeleteChallenge(ctx context.Context, challengeID string) e
 
 // New Simplified Rate Limiting Operations
 
-// CheckIPDailyLimit checks if IP has exceeded daily request limit (5/day) or is in 24h cooldown
-// Returns (canRequest, currentCount, cooldownEnd, error)
-func (r *RedisClient) CheckIPDailyLimit(ctx context.Context, ip string) (bool, int, *time.Time, error) {
-	// First check if IP is in 24h cooldown (after hitting 5 requests)
-	cooldownKey := fmt.Sprintf("cooldown:ip:%s", ip)
-	cooldownEnd, err := r.client.Get(ctx, cooldownKey).Result()
-	if err == nil {
-		// Cooldown exists, parse the end time
-		endTime, parseErr := time.Parse(time.RFC3339, cooldownEnd)
-		if parseErr == nil && time.Now().Before(endTime) {
-			return false, r.maxDailyRequestsIP, &endTime, nil
-		}
-		// Cooldown expired, delete it
-		r.client.Del(ctx, cooldownKey)
+// reserveIPDailyLimitScript checks an IP's cooldown and daily count and, if
+// the request fits, counts it, all in one step so concurrent requests can't
+// both pass the check. Reaching the limit clears the count and starts a
+// cooldown until the daily window ends, keeping the final count for quota
+// reporting. It returns {allowed, count, cooldown end or ""}.
+var reserveIPDailyLimitScript = redis.NewScript(`
+local cooldown = redis.call("GET", KEYS[2])
+if cooldown then
+	return {0, tonumber(redis.call("GET", KEYS[3]) or ARGV[2]), cooldown}
+end
+local n, max = tonumber(ARGV[1]), tonumber(ARGV[2])
+local count = tonumber(redis.call("GET", KEYS[1]) or "0")
+if count + n > max then
+	return {0, count, ""}
+end
+count = count + n
+if count >= max then
+	redis.call("SET", KEYS[2], ARGV[3], "PX", ARGV[4])
+	redis.call("SET", KEYS[3], count, "PX", ARGV[4])
+	redis.call("DEL", KEYS[1])
+else
+	redis.call("SET", KEYS[1], count, "PX", ARGV[4])
+end
+return {1, count, ""}
+`)
+
+// ReserveIPDailyLimit counts n requests against an IP's daily limit if they
+// fit, atomically. It returns whether they did and the IP's count afterwards,
+// plus the cooldown end when the IP was refused for being in cooldown.
+// Requests that end up not being served are given back with ReleaseIPDailyLimit.
+func (r *RedisClient) ReserveIPDailyLimit(ctx context.Context, ip string, n int) (bool, int, *time.Time, error) {
+	keys := []string{
+		fmt.Sprintf("ratelimit:ip:day:%s", ip),
+		fmt.Sprintf("cooldown:ip:%s", ip),
+		fmt.Sprintf("cooldown:ip:used:%s", ip),
 	}
+	now := time.Now()
+	windowEnd := r.dailyWindowEnd(now)
 
-	// Check current request count
-	key := fmt.Sprintf("ratelimit:ip:day:%s", ip)
-	count, err := r.client.Get(ctx, key).Int()
-	if err != nil && err != redis.Nil {
+	res, err := reserveIPDailyLimitScript.Run(ctx, r.client, keys,
+		n, r.maxDailyRequestsIP, windowEnd.Format(time.RFC3339), windowEnd.Sub(now).Milliseconds()).Slice()
+	if err != nil {
 		return false, 0, nil, err
 	}
-	if count >= r.maxDailyRequestsIP {
-		return false, count, nil, nil
+	if len(res) != 3 {
+		return false, 0, nil, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	count, _ := res[1].(int64)
+	var cooldownEnd *time.Time
+	if s, _ := res[2].(string); s != "" {
+		if end, err := time.Parse(time.RFC3339, s); err == nil {
+			cooldownEnd = &end
+		}
 	}
-	return true, count, nil, nil
+	return allowed == 1, int(count), cooldownEnd, nil
 }
 
-// IncrementIPDailyLimit increments IP daily counter by specified amount (1 for single token, 2 for BOTH)
-// If this increment reaches the max limit (5), it sets a 24-hour cooldown
-func (r *RedisClient) IncrementIPDailyLimit(ctx context.Context, ip string, incrementBy int) error {
-	key := fmt.Sprintf("ratelimit:ip:day:%s", ip)
-
-	// Increment counter
-	newCount, err := r.client.IncrBy(ctx, key, int64(incrementBy)).Result()
-	if err != nil {
-		return err
+// releaseIPDailyLimitScript takes n requests back off an IP's daily count,
+// lifting the cooldown if they were what started it. The count keeps what
+// was left of the cooldown as its expiry.
+var releaseIPDailyLimitScript = redis.NewScript(`
+local n = tonumber(ARGV[1])
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	local used = tonumber(redis.call("GET", KEYS[3]) or ARGV[2])
+	local ttl = redis.call("PTTL", KEYS[2])
+	redis.call("DEL", KEYS[2], KEYS[3])
+	if used - n > 0 and ttl > 0 then
+		redis.call("SET", KEYS[1], used - n, "PX", ttl)
+	end
+	return 1
+end
+if redis.call("EXISTS", KEYS[1]) == 1 and redis.call("DECRBY", KEYS[1], n) <= 0 then
+	redis.call("DEL", KEYS[1])
+end
+return 1
+`)
+
+// ReleaseIPDailyLimit gives back n requests counted by ReserveIPDailyLimit
+func (r *RedisClient) ReleaseIPDailyLimit(ctx context.Context, ip string, n int) error {
+	keys := []string{
+		fmt.Sprintf("ratelimit:ip:day:%s", ip),
+		fmt.Sprintf("cooldown:ip:%s", ip),
+		fmt.Sprintf("cooldown:ip:used:%s", ip),
 	}
+	return releaseIPDailyLimitScript.Run(ctx, r.client, keys, n, r.maxDailyRequestsIP).Err()
+}
 
-	// If we've reached the limit, set 24h cooldown
-	if newCount >= int64(r.maxDailyRequestsIP) {
-		cooldownKey := fmt.Sprintf("cooldown:ip:%s", ip)
-		cooldownEnd := time.Now().Add(24 * time.Hour)
+// Burst allowance
+
+// burstRefillScript is shared by the burst scripts: it refills an IP's bucket
+// for the time since it was last touched. A missing bucket is full.
+const burstRefillScript = `
+local n, burst, rate, now = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local elapsed = math.max(now - (tonumber(state[2]) or now), 0)
+tokens = math.min(burst, tokens + elapsed * rate)
+`
+
+// burstStoreScript saves the bucket until it would be full again, after
+// which a missing bucket means the same
+const burstStoreScript = `
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate) + 1)
+`
+
+// takeBurstScript takes n requests from a bucket if it holds them and
+// returns {allowed, milliseconds until it would}
+var takeBurstScript = redis.NewScript(burstRefillScript + `
+if tokens < n then
+	return {0, math.ceil((n - tokens) / rate)}
+end
+tokens = tokens - n
+` + burstStoreScript + `
+return {1, 0}
+`)
+
+// returnBurstScript puts n requests back in a bucket, up to its size
+var returnBurstScript = redis.NewScript(burstRefillScript + `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+tokens = math.min(burst, tokens + n)
+` + burstStoreScript + `
+return 1
+`)
+
+// burstArgs are the arguments of the burst scripts. The refill rate is
+// converted from requests per hour to requests per millisecond.
+func burstArgs(n, burst int, perHour float64, now time.Time) []interface{} {
+	rate := strconv.FormatFloat(perHour/float64(time.Hour/time.Millisecond), 'g', -1, 64)
+	return []interface{}{n, burst, rate, now.UnixMilli()}
+}
 
-		pipe := r.client.Pipeline()
-		pipe.Set(ctx, cooldownKey, cooldownEnd.Format(time.RFC3339), 24*time.Hour)
-		pipe.Del(ctx, key) // Clear the counter since we're in cooldown now
-		_, err = pipe.Exec(ctx)
-		return err
+// TakeBurst takes n requests from an IP's leaky bucket, which holds up to
+// burst requests and refills at perHour requests per hour. It returns whether
+// the bucket held them, and otherwise how long until it will. Requests that
+// end up not being served are given back with ReturnBurst.
+func (r *RedisClient) TakeBurst(ctx context.Context, ip string, n, burst int, perHour float64, now time.Time) (bool, time.Duration, error) {
+	key := fmt.Sprintf("ratelimit:ip:burst:%s", ip)
+	res, err := takeBurstScript.Run(ctx, r.client, []string{key}, burstArgs(n, burst, perHour, now)...).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected burst script result: %v", res)
 	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
 
-	// Set/refresh expiry on counter (in case cooldown wasn't triggered)
-	return r.client.Expire(ctx, key, 24*time.Hour).Err()
+// ReturnBurst gives back n requests taken by TakeBurst
+func (r *RedisClient) ReturnBurst(ctx context.Context, ip string, n, burst int, perHour float64, now time.Time) error {
+	key := fmt.Sprintf("ratelimit:ip:burst:%s", ip)
+	return returnBurstScript.Run(ctx, r.client, []string{key}, burstArgs(n, burst, perHour, now)...).Err()
 }
 
 // CheckTokenHourlyThrottle checks if a specific token was requested in the last hour
@@ -152,8 +361,25 @@ func (r *RedisClient) SetTokenHourlyThrottle(ctx context.Context, ip, token stri
 	return r.client.Set(ctx, key, time.Now().Unix(), time.Hour).Err()
 }
 
-// GetIPDailyQuota returns current usage, remaining quota, and cooldown end time for an IP
-func (r *RedisClient) GetIPDailyQuota(ctx context.Context, ip string) (used, remaining int, cooldownEnd *time.Time, err error) {
+// GetTokenLastRequest returns when ip last requested token, or nil when that
+// was longer ago than the hourly throttle remembers
+func (r *RedisClient) GetTokenLastRequest(ctx context.Context, ip, token string) (*time.Time, error) {
+	key := fmt.Sprintf("throttle:ip:token:%s:%s", ip, token)
+	unix, err := r.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	last := time.Unix(unix, 0)
+	return &last, nil
+}
+
+// GetIPDailyQuota returns current usage, remaining quota, cooldown end time and
+// when the quota resets for an IP. resetAt is nil when a rolling-window IP
+// has nothing to reset.
+func (r *RedisClient) GetIPDailyQuota(ctx context.Context, ip string) (used, remaining int, cooldownEnd, resetAt *time.Time, err error) {
 	// Check if in cooldown
 	cooldownKey := fmt.Sprintf("cooldown:ip:%s", ip)
 	cooldownEndStr, err := r.client.Get(ctx, cooldownKey).Result()
@@ -161,7 +387,7 @@ func (r *RedisClient) GetIPDailyQuota(ctx context.Context, ip string) (used, rem
 		// Parse cooldown end time
 		endTime, parseErr := time.Parse(time.RFC3339, cooldownEndStr)
 		if parseErr == nil && time.Now().Before(endTime) {
-			return r.maxDailyRequestsIP, 0, &endTime, nil
+			return r.cooldownUsed(ctx, ip), 0, &endTime, &endTime, nil
 		}
 	}
 
@@ -169,7 +395,7 @@ func (r *RedisClient) GetIPDailyQuota(ctx context.Context, ip string) (used, rem
 	key := fmt.Sprintf("ratelimit:ip:day:%s", ip)
 	count, err := r.client.Get(ctx, key).Int()
 	if err != nil && err != redis.Nil {
-		return 0, 0, nil, err
+		return 0, 0, nil, nil, err
 	}
 	if err == redis.Nil {
 		count = 0
@@ -178,9 +404,97 @@ func (r *RedisClient) GetIPDailyQuota(ctx context.Context, ip string) (used, rem
 	if remaining < 0 {
 		remaining = 0
 	}
-	return count, remaining, nil, nil
+
+	// Work out when the quota resets
+	if r.dailyResetHour >= 0 {
+		reset := nextDailyReset(time.Now(), r.dailyResetHour)
+		resetAt = &reset
+	} else if count > 0 {
+		ttl, err := r.client.TTL(ctx, key).Result()
+		if err != nil {
+			return 0, 0, nil, nil, err
+		}
+		if ttl > 0 {
+			reset := time.Now().Add(ttl)
+			resetAt = &reset
+		}
+	}
+
+	return count, remaining, nil, resetAt, nil
+}
+
+// GetAPIKeyDailyUsage returns how many requests an API key has made in the
+// current daily window
+func (r *RedisClient) GetAPIKeyDailyUsage(ctx context.Context, keyID string) (int, error) {
+	key := fmt.Sprintf("ratelimit:apikey:day:%s", keyID)
+	count, err := r.client.Get(ctx, key).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return count, err
 }
 
+// IncrementAPIKeyDailyUsage charges an API key incrementBy requests. The count
+// resets when the daily window ends, like IP quotas, but keys have no cooldown.
+func (r *RedisClient) IncrementAPIKeyDailyUsage(ctx context.Context, keyID string, incrementBy int) error {
+	key := fmt.Sprintf("ratelimit:apikey:day:%s", keyID)
+
+	count, err := r.client.IncrBy(ctx, key, int64(incrementBy)).Result()
+	if err != nil {
+		return err
+	}
+
+	// Start the window on the first request
+	if count == int64(incrementBy) {
+		now := time.Now()
+		return r.client.Expire(ctx, key, r.dailyWindowEnd(now).Sub(now)).Err()
+	}
+	return nil
+}
+
+// TrackRecipient records that ip funded address in the current daily window.
+// The set expires when the window that started with its first address ends.
+func (r *RedisClient) TrackRecipient(ctx context.Context, ip, address string) error {
+	key := fmt.Sprintf("recipients:ip:day:%s", ip)
+
+	added, err := r.client.SAdd(ctx, key, address).Result()
+	if err != nil {
+		return err
+	}
+
+	// Start the window on the first address
+	if added > 0 {
+		now := time.Now()
+		return r.client.ExpireNX(ctx, key, r.dailyWindowEnd(now).Sub(now)).Err()
+	}
+	return nil
+}
+
+// CountDistinctRecipients returns how many different addresses ip has funded
+// in the current daily window
+func (r *RedisClient) CountDistinctRecipients(ctx context.Context, ip string) (int, error) {
+	key := fmt.Sprintf("recipients:ip:day:%s", ip)
+	count, err := r.client.SCard(ctx, key).Result()
+	return int(count), err
+}
+
+// IsKnownRecipient reports whether ip has already funded address in the
+// current daily window
+func (r *RedisClient) IsKnownRecipient(ctx context.Context, ip, address string) (bool, error) {
+	key := fmt.Sprintf("recipients:ip:day:%s", ip)
+	return r.client.SIsMember(ctx, key, address).Result()
+}
+
+// cooldownUsed returns the request count that triggered an IP's cooldown.
+// Cooldowns set before the count was stored report the daily maximum.
+func (r *RedisClient) cooldownUsed(ctx context.Context, ip string) int {
+	usedKey := fmt.Sprintf("cooldown:ip:used:%s", ip)
+	used, err := r.client.Get(ctx, usedKey).Int()
+	if err != nil {
+		return r.maxDailyRequestsIP
+	}
+	return used
+}
 
 // Global distribution tracking (anti-drain protection)
 
@@ -254,6 +568,112 @@ func (r *RedisClient) GetGlobalDistribution(ctx context.Context, tokenType strin
 	return hourly, daily, err
 }
 
+// Distribution time series, for charting drip volume
+
+// Retention of the distribution buckets. Minute buckets cover short windows
+// in detail, hour buckets a week.
+const (
+	DistributionMinuteRetention = 2 * time.Hour
+	DistributionHourRetention   = 7 * 24 * time.Hour
+)
+
+// DistributionBucket is what was sent of a token in one time bucket
+type DistributionBucket struct {
+	Start  time.Time
+	Amount float64 // Tokens sent
+	Drips  int64   // Transfers that sent them
+}
+
+// distributionKey is the key of the bucket of size bucket holding t
+func distributionKey(token string, bucket time.Duration, t time.Time) string {
+	size := int64(bucket / time.Second)
+	return fmt.Sprintf("distribution:%ds:%s:%d", size, token, t.Unix()/size)
+}
+
+// RecordDistribution adds a drip of amount to the minute and hour buckets
+// holding now
+func (r *RedisClient) RecordDistribution(ctx context.Context, token string, amount float64, now time.Time) error {
+	pipe := r.client.Pipeline()
+	for bucket, retention := range map[time.Duration]time.Duration{
+		time.Minute: DistributionMinuteRetention,
+		time.Hour:   DistributionHourRetention,
+	} {
+		key := distributionKey(token, bucket, now)
+		pipe.HIncrByFloat(ctx, key, "amount", amount)
+		pipe.HIncrBy(ctx, key, "drips", 1)
+		// Kept a bucket longer, so the oldest one in range is still whole
+		pipe.Expire(ctx, key, retention+bucket)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// DistributionSeries returns the last count buckets of size bucket
+// (time.Minute or time.Hour) for token, oldest first. The last one holds now
+// and is still filling. Buckets without drips are included as zero.
+func (r *RedisClient) DistributionSeries(ctx context.Context, token string, bucket time.Duration, count int, now time.Time) ([]DistributionBucket, error) {
+	if bucket != time.Minute && bucket != time.Hour {
+		return nil, fmt.Errorf("unsupported bucket size %s", bucket)
+	}
+
+	current := now.Truncate(bucket)
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, count)
+	series := make([]DistributionBucket, count)
+	for i := range series {
+		series[i].Start = current.Add(-time.Duration(count-1-i) * bucket)
+		cmds[i] = pipe.HGetAll(ctx, distributionKey(token, bucket, series[i].Start))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		series[i].Amount, _ = strconv.ParseFloat(fields["amount"], 64)
+		series[i].Drips, _ = strconv.ParseInt(fields["drips"], 10, 64)
+	}
+	return series, nil
+}
+
+// Balance reservations (committed but unconfirmed transfers)
+
+// reservationTTL bounds how long a reservation can outlive its transfer, so a
+// crash between reserve and release can't hold balance back forever
+const reservationTTL = 10 * time.Minute
+
+// ReserveBalance atomically adds amount to the token's reserved total and
+// returns the new total, including this reservation
+func (r *RedisClient) ReserveBalance(ctx context.Context, tokenType string, amount float64) (float64, error) {
+	key := fmt.Sprintf("reserved:balance:%s", tokenType)
+
+	pipe := r.client.TxPipeline()
+	total := pipe.IncrByFloat(ctx, key, amount)
+	pipe.Expire(ctx, key, reservationTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return total.Val(), nil
+}
+
+// releaseBalanceScript decrements a reservation total and deletes the key once
+// nothing is reserved, clearing float drift and totals left negative when the
+// key expired mid-transfer. It runs atomically so concurrent reservations survive.
+var releaseBalanceScript = redis.NewScript(`
+local total = tonumber(redis.call("INCRBYFLOAT", KEYS[1], -tonumber(ARGV[1])))
+if total < 1e-9 then
+	redis.call("DEL", KEYS[1])
+end
+return 1
+`)
+
+// ReleaseBalance removes amount from the token's reserved total
+func (r *RedisClient) ReleaseBalance(ctx context.Context, tokenType string, amount float64) error {
+	key := fmt.Sprintf("reserved:balance:%s", tokenType)
+	return releaseBalanceScript.Run(ctx, r.client, []string{key}, amount).Err()
+}
+
 // Challenge rate limiting
 
 // CheckChallengeRateLimit checks if an IP has exceeded challenge request limits
@@ -269,16 +689,200 @@ func (r *RedisClient) CheckChallengeRateLimit(ctx context.Context, ip string) (b
 	return true, nil
 }
 
+// RemainingChallenges returns how many more challenges an IP may request this hour
+func (r *RedisClient) RemainingChallenges(ctx context.Context, ip string) (int, error) {
+	key := fmt.Sprintf("ratelimit:challenge:hour:%s", ip)
+	count, err := r.client.Get(ctx, key).Int()
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	return max(r.maxChallengesPerHour-count, 0), nil
+}
+
 // IncrementChallengeRateLimit increments the challenge rate limit counter for an IP
 func (r *RedisClient) IncrementChallengeRateLimit(ctx context.Context, ip string) error {
+	return r.IncrementChallengeRateLimitBy(ctx, ip, 1)
+}
+
+// IncrementChallengeRateLimitBy charges n challenges to an IP's hourly counter
+func (r *RedisClient) IncrementChallengeRateLimitBy(ctx context.Context, ip string, n int) error {
 	key := fmt.Sprintf("ratelimit:challenge:hour:%s", ip)
 	pipe := r.client.Pipeline()
-	pipe.Incr(ctx, key)
+	pipe.IncrBy(ctx, key, int64(n))
 	pipe.Expire(ctx, key, time.Hour)
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
+// Bonus requests
+
+// BonusRequestsUsed returns how many bonus requests an IP has made in its
+// current daily window
+func (r *RedisClient) BonusRequestsUsed(ctx context.Context, ip string) (int, error) {
+	key := fmt.Sprintf("bonus:ip:day:%s", ip)
+	count, err := r.client.Get(ctx, key).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return count, err
+}
+
+// IncrementBonusRequests counts a bonus request. The count expires at the end
+// of the daily window that starts with the IP's first bonus request.
+func (r *RedisClient) IncrementBonusRequests(ctx context.Context, ip string) error {
+	key := fmt.Sprintf("bonus:ip:day:%s", ip)
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		return r.client.Expire(ctx, key, time.Until(r.dailyWindowEnd(time.Now()))).Err()
+	}
+	return nil
+}
+
+// Request velocity
+
+// RecordRequestVelocity counts a request in the bucket for now's minute,
+// kept for retention
+func (r *RedisClient) RecordRequestVelocity(ctx context.Context, now time.Time, retention time.Duration) error {
+	key := fmt.Sprintf("velocity:minute:%d", now.Unix()/60)
+	pipe := r.client.Pipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, retention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RequestVelocity returns the request counts of the last minutes, newest
+// (the current, partial minute) first
+func (r *RedisClient) RequestVelocity(ctx context.Context, now time.Time, minutes int) ([]int64, error) {
+	current := now.Unix() / 60
+	keys := make([]string, minutes)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("velocity:minute:%d", current-int64(i))
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, minutes)
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			counts[i], _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return counts, nil
+}
+
+// In-flight request locks
+
+// AcquireRequestLock takes the IP's in-flight request lock for up to ttl. It
+// returns a token for ReleaseRequestLock, or ok=false if another request from
+// the IP holds the lock. API-key requests pass "apikey:<id>" as the IP.
+func (r *RedisClient) AcquireRequestLock(ctx context.Context, ip string, ttl time.Duration) (token string, ok bool, err error) {
+	key := fmt.Sprintf("lock:request:ip:%s", ip)
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, err
+	}
+	token = hex.EncodeToString(b)
+
+	ok, err = r.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// releaseLockScript deletes a lock only if it still holds the caller's token,
+// so a request that outlived its lock can't release the next request's lock
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// ReleaseRequestLock releases an IP's in-flight request lock taken with token
+func (r *RedisClient) ReleaseRequestLock(ctx context.Context, ip, token string) error {
+	key := fmt.Sprintf("lock:request:ip:%s", ip)
+	return releaseLockScript.Run(ctx, r.client, []string{key}, token).Err()
+}
+
+// Signed authorizations
+
+// UseAuthorizationNonce marks a signed authorization's nonce as used by
+// address, remembering it for ttl. It returns false if the nonce was already
+// used, so each authorization can only be redeemed once.
+func (r *RedisClient) UseAuthorizationNonce(ctx context.Context, address, nonce string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("authorization:nonce:%s:%s", address, nonce)
+	return r.client.SetNX(ctx, key, 1, ttl).Result()
+}
+
+// Audit log
+
+// auditKey is the sorted set holding the audit log, scored by the time each
+// entry was recorded in Unix milliseconds
+const auditKey = "audit:requests"
+
+// AppendAudit records an entry in the audit log at time at and drops entries
+// older than retention. Entries are stored as given, so each must be unique.
+func (r *RedisClient) AppendAudit(ctx context.Context, at time.Time, entry string, retention time.Duration) error {
+	cutoff := at.Add(-retention).UnixMilli()
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, auditKey, redis.Z{Score: float64(at.UnixMilli()), Member: entry})
+	pipe.ZRemRangeByScore(ctx, auditKey, "-inf", fmt.Sprintf("(%d", cutoff))
+	pipe.Expire(ctx, auditKey, retention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// AuditEntry is an audit log entry and when it was recorded
+type AuditEntry struct {
+	At    int64 // Unix milliseconds
+	Entry string
+}
+
+// AuditEntries returns up to count audit log entries recorded between from
+// and to (inclusive, zero times leave that end open), newest first, skipping
+// the first offset of them
+func (r *RedisClient) AuditEntries(ctx context.Context, from, to time.Time, offset, count int) ([]AuditEntry, error) {
+	rng := auditRange(from, to)
+	rng.Offset, rng.Count = int64(offset), int64(count)
+	zs, err := r.client.ZRevRangeByScoreWithScores(ctx, auditKey, rng).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]AuditEntry, len(zs))
+	for i, z := range zs {
+		entries[i] = AuditEntry{At: int64(z.Score), Entry: z.Member.(string)}
+	}
+	return entries, nil
+}
+
+// CountAuditEntries counts the audit log entries recorded between from and
+// to (inclusive, zero times leave that end open)
+func (r *RedisClient) CountAuditEntries(ctx context.Context, from, to time.Time) (int, error) {
+	rng := auditRange(from, to)
+	n, err := r.client.ZCount(ctx, auditKey, rng.Min, rng.Max).Result()
+	return int(n), err
+}
+
+// auditRange is the score range of audit log entries between from and to
+func auditRange(from, to time.Time) *redis.ZRangeBy {
+	rng := &redis.ZRangeBy{Min: "-inf", Max: "+inf"}
+	if !from.IsZero() {
+		rng.Min = strconv.FormatInt(from.UnixMilli(), 10)
+	}
+	if !to.IsZero() {
+		rng.Max = strconv.FormatInt(to.UnixMilli(), 10)
+	}
+	return rng
+}
 
 // Health check
 
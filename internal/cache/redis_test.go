@@ -0,0 +1,771 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedisClient creates a RedisClient backed by an in-memory Redis
+func newTestRedisClient(t *testing.T) (*RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client, err := NewRedisClient("redis://"+mr.Addr(), 5, 8)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client, mr
+}
+
+// reserveDaily counts n requests against ip's daily limit, failing the test if they don't fit
+func reserveDaily(t *testing.T, r *RedisClient, ip string, n int) {
+	t.Helper()
+
+	allowed, _, _, err := r.ReserveIPDailyLimit(context.Background(), ip, n)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestConsumeChallenge(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.StoreChallenge(ctx, "id1", "challenge1", 4, "", false, time.Minute))
+
+	value, difficulty, token, err := r.ConsumeChallenge(ctx, "id1")
+	require.NoError(t, err)
+	assert.Equal(t, "challenge1", value)
+	assert.Equal(t, 4, difficulty)
+	assert.Empty(t, token)
+
+	// Second consumption fails - challenge is single-use
+	_, _, _, err = r.ConsumeChallenge(ctx, "id1")
+	assert.ErrorIs(t, err, redis.Nil)
+}
+
+func TestConsumeChallengeConcurrent(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.StoreChallenge(ctx, "race", "solved", 4, "", false, time.Minute))
+
+	const racers = 2
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successes := 0
+
+	start := make(chan struct{})
+	for i := 0; i < racers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if _, _, _, err := r.ConsumeChallenge(ctx, "race"); err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, 1, successes, "exactly one racing request may redeem the challenge")
+}
+
+func TestReserveAndReleaseBalance(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+
+	total, err := r.ReserveBalance(ctx, "STRK", 10)
+	require.NoError(t, err)
+	assert.InDelta(t, 10, total, 1e-9)
+
+	total, err = r.ReserveBalance(ctx, "STRK", 10)
+	require.NoError(t, err)
+	assert.InDelta(t, 20, total, 1e-9)
+	assert.True(t, mr.TTL("reserved:balance:STRK") > 0, "reservations must expire")
+
+	require.NoError(t, r.ReleaseBalance(ctx, "STRK", 10))
+	reserved, err := mr.Get("reserved:balance:STRK")
+	require.NoError(t, err)
+	assert.Equal(t, "10", reserved)
+
+	// Releasing the last reservation removes the key
+	require.NoError(t, r.ReleaseBalance(ctx, "STRK", 10))
+	assert.False(t, mr.Exists("reserved:balance:STRK"))
+}
+
+func TestReleaseBalanceAfterExpiry(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+
+	_, err := r.ReserveBalance(ctx, "ETH", 0.01)
+	require.NoError(t, err)
+
+	// The reservation expired before the transfer was confirmed
+	mr.FastForward(reservationTTL + time.Second)
+
+	require.NoError(t, r.ReleaseBalance(ctx, "ETH", 0.01))
+	assert.False(t, mr.Exists("reserved:balance:ETH"), "a negative total must not linger")
+}
+
+func TestReserveIPDailyLimit(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.20"
+
+	allowed, count, cooldownEnd, err := r.ReserveIPDailyLimit(ctx, ip, 2)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 2, count)
+	assert.Nil(t, cooldownEnd)
+	assert.Equal(t, 24*time.Hour, mr.TTL("ratelimit:ip:day:"+ip))
+
+	// A request that doesn't fit isn't counted
+	allowed, count, cooldownEnd, err = r.ReserveIPDailyLimit(ctx, ip, 4)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 2, count)
+	assert.Nil(t, cooldownEnd)
+
+	// Reaching the limit starts the cooldown
+	allowed, count, _, err = r.ReserveIPDailyLimit(ctx, ip, 3)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 5, count)
+
+	allowed, count, cooldownEnd, err = r.ReserveIPDailyLimit(ctx, ip, 1)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 5, count)
+	require.NotNil(t, cooldownEnd)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *cooldownEnd, 5*time.Second)
+	assert.False(t, mr.Exists("ratelimit:ip:day:"+ip), "the cooldown replaces the count")
+	assert.Equal(t, 24*time.Hour, mr.TTL("cooldown:ip:used:"+ip))
+
+	// Once the cooldown expires the IP starts fresh
+	mr.FastForward(24*time.Hour + time.Second)
+	allowed, count, cooldownEnd, err = r.ReserveIPDailyLimit(ctx, ip, 1)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 1, count)
+	assert.Nil(t, cooldownEnd)
+}
+
+func TestReserveIPDailyLimitExpires(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.3"
+
+	reserveDaily(t, r, ip, 4)
+	mr.FastForward(24*time.Hour + time.Second)
+
+	// The count from the previous window is gone, so the full quota fits
+	allowed, count, _, err := r.ReserveIPDailyLimit(ctx, ip, 5)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 5, count)
+}
+
+func TestReserveIPDailyLimitConcurrent(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.21"
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, _, _, err := r.ReserveIPDailyLimit(ctx, ip, 1)
+			assert.NoError(t, err)
+			if ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Exactly the quota gets through, and the count doesn't overshoot it
+	assert.Equal(t, 5, allowed)
+	used, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 5, used)
+	assert.Equal(t, 0, remaining)
+	assert.NotNil(t, cooldownEnd)
+}
+
+func TestReleaseIPDailyLimit(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.22"
+
+	_, _, _, err := r.ReserveIPDailyLimit(ctx, ip, 3)
+	require.NoError(t, err)
+	require.NoError(t, r.ReleaseIPDailyLimit(ctx, ip, 1))
+	used, _, _, _, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 2, used)
+
+	// Giving back the requests that started the cooldown lifts it
+	_, _, _, err = r.ReserveIPDailyLimit(ctx, ip, 3)
+	require.NoError(t, err)
+	mr.FastForward(time.Hour)
+	require.NoError(t, r.ReleaseIPDailyLimit(ctx, ip, 3))
+
+	used, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 2, used)
+	assert.Equal(t, 3, remaining)
+	assert.Nil(t, cooldownEnd)
+	assert.Equal(t, 23*time.Hour, mr.TTL("ratelimit:ip:day:"+ip), "the count keeps the rest of the window")
+
+	// Releasing everything clears the count
+	require.NoError(t, r.ReleaseIPDailyLimit(ctx, ip, 2))
+	assert.False(t, mr.Exists("ratelimit:ip:day:"+ip))
+}
+
+func TestTakeBurst(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.23"
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A burst of 2, refilling at 6 per hour (one every 10 minutes)
+	take := func(n int, at time.Time) (bool, time.Duration) {
+		t.Helper()
+		ok, wait, err := r.TakeBurst(ctx, ip, n, 2, 6, at)
+		require.NoError(t, err)
+		return ok, wait
+	}
+
+	ok, _ := take(1, now)
+	assert.True(t, ok)
+	ok, _ = take(1, now)
+	assert.True(t, ok)
+	ok, wait := take(1, now)
+	assert.False(t, ok, "the burst is spent")
+	assert.Equal(t, 10*time.Minute, wait)
+
+	ok, wait = take(1, now.Add(4*time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, 6*time.Minute, wait, "refused requests don't reset the refill")
+	ok, _ = take(1, now.Add(10*time.Minute))
+	assert.True(t, ok)
+	ok, wait = take(2, now.Add(15*time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, 15*time.Minute, wait)
+
+	// The bucket is dropped once it would be full again
+	assert.Equal(t, 20*time.Minute+time.Millisecond, mr.TTL("ratelimit:ip:burst:"+ip))
+
+	// Returned requests go back in, up to the burst
+	require.NoError(t, r.ReturnBurst(ctx, ip, 5, 2, 6, now.Add(10*time.Minute)))
+	ok, _ = take(2, now.Add(10*time.Minute))
+	assert.True(t, ok)
+
+	// Returning to a full bucket does nothing
+	other := "198.51.100.24"
+	require.NoError(t, r.ReturnBurst(ctx, other, 1, 2, 6, now))
+	assert.False(t, mr.Exists("ratelimit:ip:burst:"+other))
+}
+
+func TestCheckTokenHourlyThrottle(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.4"
+
+	canRequest, next, err := r.CheckTokenHourlyThrottle(ctx, ip, "STRK")
+	require.NoError(t, err)
+	assert.True(t, canRequest)
+	assert.Nil(t, next)
+
+	last, err := r.GetTokenLastRequest(ctx, ip, "STRK")
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	require.NoError(t, r.SetTokenHourlyThrottle(ctx, ip, "STRK"))
+
+	last, err = r.GetTokenLastRequest(ctx, ip, "STRK")
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.WithinDuration(t, time.Now(), *last, 2*time.Second)
+
+	// 20 minutes later the next request is ~40 minutes away
+	mr.FastForward(20 * time.Minute)
+	canRequest, next, err = r.CheckTokenHourlyThrottle(ctx, ip, "STRK")
+	require.NoError(t, err)
+	assert.False(t, canRequest)
+	require.NotNil(t, next)
+	assert.WithinDuration(t, time.Now().Add(40*time.Minute), *next, 5*time.Second)
+
+	// Throttles are per token
+	canRequest, _, err = r.CheckTokenHourlyThrottle(ctx, ip, "ETH")
+	require.NoError(t, err)
+	assert.True(t, canRequest)
+
+	// And lift after an hour
+	mr.FastForward(40*time.Minute + time.Second)
+	canRequest, _, err = r.CheckTokenHourlyThrottle(ctx, ip, "STRK")
+	require.NoError(t, err)
+	assert.True(t, canRequest)
+}
+
+func TestTrackGlobalDistribution(t *testing.T) {
+	t.Run("zero limits disable tracking", func(t *testing.T) {
+		r, mr := newTestRedisClient(t)
+		ctx := context.Background()
+
+		ok, err := r.TrackGlobalDistribution(ctx, "STRK", 10, 0, 0)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.False(t, mr.Exists("global:distributed:hour:STRK"))
+		assert.False(t, mr.Exists("global:distributed:day:STRK"))
+	})
+
+	t.Run("hourly limit", func(t *testing.T) {
+		r, mr := newTestRedisClient(t)
+		ctx := context.Background()
+
+		for i := 0; i < 3; i++ {
+			ok, err := r.TrackGlobalDistribution(ctx, "STRK", 10, 30, 0)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		ok, err := r.TrackGlobalDistribution(ctx, "STRK", 10, 30, 0)
+		require.NoError(t, err)
+		assert.False(t, ok, "a 4th drip would exceed the hourly cap")
+		assert.False(t, mr.Exists("global:distributed:day:STRK"), "disabled daily limit isn't tracked")
+
+		hourly, daily, err := r.GetGlobalDistribution(ctx, "STRK")
+		require.NoError(t, err)
+		assert.InDelta(t, 30, hourly, 1e-9, "rejected drips aren't counted")
+		assert.Zero(t, daily)
+
+		mr.FastForward(time.Hour + time.Second)
+		ok, err = r.TrackGlobalDistribution(ctx, "STRK", 10, 30, 0)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("daily limit", func(t *testing.T) {
+		r, _ := newTestRedisClient(t)
+		ctx := context.Background()
+
+		ok, err := r.TrackGlobalDistribution(ctx, "ETH", 0.01, 0, 0.02)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = r.TrackGlobalDistribution(ctx, "ETH", 0.01, 0, 0.02)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = r.TrackGlobalDistribution(ctx, "ETH", 0.01, 0, 0.02)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestGetIPDailyQuota(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+
+	t.Run("unknown IP", func(t *testing.T) {
+		used, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, "198.51.100.10")
+		require.NoError(t, err)
+		assert.Equal(t, 0, used)
+		assert.Equal(t, 5, remaining)
+		assert.Nil(t, cooldownEnd)
+	})
+
+	t.Run("partial usage", func(t *testing.T) {
+		ip := "198.51.100.11"
+		reserveDaily(t, r, ip, 2)
+
+		used, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, ip)
+		require.NoError(t, err)
+		assert.Equal(t, 2, used)
+		assert.Equal(t, 3, remaining)
+		assert.Nil(t, cooldownEnd)
+	})
+
+	t.Run("in cooldown after the counter is cleared", func(t *testing.T) {
+		ip := "198.51.100.12"
+		reserveDaily(t, r, ip, 5)
+
+		used, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, ip)
+		require.NoError(t, err)
+		assert.Equal(t, 5, used, "cooldown must report the quota as used up, not the cleared counter")
+		assert.Equal(t, 0, remaining)
+		assert.NotNil(t, cooldownEnd)
+	})
+
+	t.Run("expired cooldown value", func(t *testing.T) {
+		ip := "198.51.100.13"
+		past := time.Now().Add(-time.Minute).Format(time.RFC3339)
+		require.NoError(t, mr.Set("cooldown:ip:"+ip, past))
+
+		used, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, ip)
+		require.NoError(t, err)
+		assert.Equal(t, 0, used)
+		assert.Equal(t, 5, remaining)
+		assert.Nil(t, cooldownEnd)
+	})
+
+	t.Run("malformed cooldown value", func(t *testing.T) {
+		ip := "198.51.100.14"
+		require.NoError(t, mr.Set("cooldown:ip:"+ip, "not-a-time"))
+
+		_, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, ip)
+		require.NoError(t, err)
+		assert.Equal(t, 5, remaining)
+		assert.Nil(t, cooldownEnd)
+	})
+
+	t.Run("counter over the limit", func(t *testing.T) {
+		ip := "198.51.100.15"
+		require.NoError(t, mr.Set("ratelimit:ip:day:"+ip, "7"))
+
+		used, remaining, _, _, err := r.GetIPDailyQuota(ctx, ip)
+		require.NoError(t, err)
+		assert.Equal(t, 7, used)
+		assert.Equal(t, 0, remaining, "remaining never goes negative")
+	})
+}
+
+func TestGetIPDailyQuotaAcrossCooldown(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.20"
+
+	// 3 singles then a BOTH request reach the limit
+	for i := 0; i < 3; i++ {
+		reserveDaily(t, r, ip, 1)
+	}
+	reserveDaily(t, r, ip, 2)
+
+	// During cooldown the stored final count is reported
+	used, remaining, cooldownEnd, _, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 5, used)
+	assert.Equal(t, 0, remaining)
+	assert.NotNil(t, cooldownEnd)
+	final, err := mr.Get("cooldown:ip:used:" + ip)
+	require.NoError(t, err)
+	assert.Equal(t, "5", final)
+
+	// After cooldown the quota is fresh
+	mr.FastForward(24*time.Hour + time.Second)
+	used, remaining, cooldownEnd, _, err = r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 0, used)
+	assert.Equal(t, 5, remaining)
+	assert.Nil(t, cooldownEnd)
+	assert.False(t, mr.Exists("cooldown:ip:used:"+ip))
+}
+
+func TestGetIPDailyQuotaLegacyCooldown(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.21"
+
+	// A cooldown set before final counts were stored
+	end := time.Now().Add(time.Hour).Format(time.RFC3339)
+	require.NoError(t, mr.Set("cooldown:ip:"+ip, end))
+
+	used, remaining, _, _, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 5, used)
+	assert.Equal(t, 0, remaining)
+}
+
+func TestNextDailyReset(t *testing.T) {
+	now := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), nextDailyReset(now, 0))
+	assert.Equal(t, time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC), nextDailyReset(now, 18))
+	assert.Equal(t, time.Date(2025, 3, 11, 14, 0, 0, 0, time.UTC), nextDailyReset(now, 14), "the current hour has already passed")
+
+	// Non-UTC input is converted first
+	est := time.FixedZone("EST", -5*3600)
+	assert.Equal(t, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), nextDailyReset(time.Date(2025, 3, 10, 18, 0, 0, 0, est), 0))
+}
+
+func TestFixedDailyReset(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.30"
+
+	require.Error(t, r.SetDailyResetHour(24))
+	require.NoError(t, r.SetDailyResetHour(0))
+
+	reserveDaily(t, r, ip, 1)
+	nextReset := nextDailyReset(time.Now(), 0)
+
+	// The counter lives until the next reset, not for 24 hours
+	assert.InDelta(t, time.Until(nextReset).Seconds(), mr.TTL("ratelimit:ip:day:"+ip).Seconds(), 2)
+
+	_, _, _, resetAt, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	require.NotNil(t, resetAt)
+	assert.Equal(t, nextReset, *resetAt)
+
+	// Hitting the limit cools down only until the reset
+	reserveDaily(t, r, ip, 4)
+	_, _, cooldownEnd, resetAt, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	require.NotNil(t, cooldownEnd)
+	assert.WithinDuration(t, nextReset, *cooldownEnd, time.Second)
+	assert.Equal(t, cooldownEnd, resetAt)
+}
+
+func TestRollingResetAt(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.31"
+
+	// Nothing used yet, nothing to reset
+	_, _, _, resetAt, err := r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	assert.Nil(t, resetAt)
+
+	reserveDaily(t, r, ip, 1)
+	_, _, _, resetAt, err = r.GetIPDailyQuota(ctx, ip)
+	require.NoError(t, err)
+	require.NotNil(t, resetAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *resetAt, 5*time.Second)
+}
+
+func TestTrackRecipient(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "203.0.113.9"
+
+	require.NoError(t, r.TrackRecipient(ctx, ip, "0xa"))
+	mr.FastForward(time.Hour)
+	require.NoError(t, r.TrackRecipient(ctx, ip, "0xb"))
+	require.NoError(t, r.TrackRecipient(ctx, ip, "0xa"))
+
+	count, err := r.CountDistinctRecipients(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	known, err := r.IsKnownRecipient(ctx, ip, "0xb")
+	require.NoError(t, err)
+	assert.True(t, known)
+	known, err = r.IsKnownRecipient(ctx, ip, "0xc")
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	// The window started with the first address, not the latest one
+	assert.InDelta(t, (23 * time.Hour).Seconds(), mr.TTL("recipients:ip:day:"+ip).Seconds(), 1)
+	mr.FastForward(23 * time.Hour)
+	count, err = r.CountDistinctRecipients(ctx, ip)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestRequestLock(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+
+	token, ok, err := r.AcquireRequestLock(ctx, "1.2.3.4", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// A second request from the same IP is turned away; other IPs are not
+	_, ok, err = r.AcquireRequestLock(ctx, "1.2.3.4", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = r.AcquireRequestLock(ctx, "5.6.7.8", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Only the holder's token releases the lock
+	require.NoError(t, r.ReleaseRequestLock(ctx, "1.2.3.4", "not-the-token"))
+	assert.True(t, mr.Exists("lock:request:ip:1.2.3.4"))
+	require.NoError(t, r.ReleaseRequestLock(ctx, "1.2.3.4", token))
+	assert.False(t, mr.Exists("lock:request:ip:1.2.3.4"))
+
+	// An abandoned lock expires
+	_, ok, err = r.AcquireRequestLock(ctx, "1.2.3.4", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	mr.FastForward(time.Minute)
+	_, ok, err = r.AcquireRequestLock(ctx, "1.2.3.4", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestChallengeAge(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.StoreChallenge(ctx, "id1", "challenge1", 4, "", false, 5*time.Minute))
+	mr.FastForward(12 * time.Second)
+
+	age, err := r.ChallengeAge(ctx, "id1", 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Second, age)
+
+	_, err = r.ChallengeAge(ctx, "missing", 5*time.Minute)
+	assert.ErrorIs(t, err, redis.Nil)
+}
+
+func TestConsumeChallengeWithoutDifficulty(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+
+	// Stored by a server that didn't record difficulties
+	require.NoError(t, mr.Set("challenge:old", "abc123"))
+
+	value, difficulty, token, err := r.ConsumeChallenge(context.Background(), "old")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", value)
+	assert.Zero(t, difficulty)
+	assert.Empty(t, token)
+
+	// Stored before the bonus flag
+	require.NoError(t, mr.Set("challenge:flagless", "4:STRK:abc123"))
+	value, difficulty, token, err = r.GetChallenge(context.Background(), "flagless")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", value)
+	assert.Equal(t, 4, difficulty)
+	assert.Equal(t, "STRK", token)
+	bonus, err := r.IsBonusChallenge(context.Background(), "flagless")
+	require.NoError(t, err)
+	assert.False(t, bonus)
+
+	// Stored with a difficulty, before tokens were bound
+	require.NoError(t, mr.Set("challenge:unbound", "4:abc123"))
+	value, difficulty, token, err = r.ConsumeChallenge(context.Background(), "unbound")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", value)
+	assert.Equal(t, 4, difficulty)
+	assert.Empty(t, token)
+}
+
+func TestChallengeBoundToken(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, r.StoreChallenge(ctx, "id1", "challenge1", 4, "STRK", true, time.Minute))
+
+	value, difficulty, token, err := r.GetChallenge(ctx, "id1")
+	require.NoError(t, err)
+	assert.Equal(t, "challenge1", value)
+	assert.Equal(t, 4, difficulty)
+	assert.Equal(t, "STRK", token)
+
+	bonus, err := r.IsBonusChallenge(ctx, "id1")
+	require.NoError(t, err)
+	assert.True(t, bonus)
+}
+
+func TestRequestVelocity(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 30, 15, 0, time.UTC)
+
+	for _, at := range []time.Time{now, now.Add(-10 * time.Second), now.Add(-2 * time.Minute)} {
+		require.NoError(t, r.RecordRequestVelocity(ctx, at, time.Hour))
+	}
+
+	counts, err := r.RequestVelocity(ctx, now, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 0, 1, 0}, counts)
+}
+
+func TestAuditEntries(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, r.AppendAudit(ctx, now.Add(-3*time.Hour), "old", 2*time.Hour))
+	require.NoError(t, r.AppendAudit(ctx, now.Add(-time.Hour), "earlier", 2*time.Hour))
+	require.NoError(t, r.AppendAudit(ctx, now, "latest", 2*time.Hour))
+
+	entries, err := r.AuditEntries(ctx, time.Time{}, time.Time{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []AuditEntry{
+		{At: now.UnixMilli(), Entry: "latest"},
+		{At: now.Add(-time.Hour).UnixMilli(), Entry: "earlier"},
+	}, entries, "newest first, entries past retention dropped")
+
+	entries, err = r.AuditEntries(ctx, time.Time{}, time.Time{}, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []AuditEntry{{At: now.Add(-time.Hour).UnixMilli(), Entry: "earlier"}}, entries)
+
+	entries, err = r.AuditEntries(ctx, now.Add(-2*time.Hour), now.Add(-time.Minute), 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []AuditEntry{{At: now.Add(-time.Hour).UnixMilli(), Entry: "earlier"}}, entries)
+
+	count, err := r.CountAuditEntries(ctx, time.Time{}, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestBonusRequests(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	ip := "198.51.100.9"
+
+	used, err := r.BonusRequestsUsed(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 0, used)
+
+	require.NoError(t, r.IncrementBonusRequests(ctx, ip))
+	require.NoError(t, r.IncrementBonusRequests(ctx, ip))
+	used, err = r.BonusRequestsUsed(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 2, used)
+	assert.InDelta(t, 24*time.Hour, mr.TTL("bonus:ip:day:"+ip), float64(time.Minute))
+
+	mr.FastForward(24*time.Hour + time.Second)
+	used, err = r.BonusRequestsUsed(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, 0, used)
+}
+
+func TestDistributionSeries(t *testing.T) {
+	r, mr := newTestRedisClient(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 30, 20, 0, time.UTC)
+
+	require.NoError(t, r.RecordDistribution(ctx, "STRK", 10, now.Add(-2*time.Minute)))
+	require.NoError(t, r.RecordDistribution(ctx, "STRK", 10, now))
+	require.NoError(t, r.RecordDistribution(ctx, "STRK", 2.5, now))
+	require.NoError(t, r.RecordDistribution(ctx, "ETH", 0.01, now))
+
+	minutes, err := r.DistributionSeries(ctx, "STRK", time.Minute, 3, now)
+	require.NoError(t, err)
+	assert.Equal(t, []DistributionBucket{
+		{Start: time.Date(2026, 10, 16, 12, 28, 0, 0, time.UTC), Amount: 10, Drips: 1},
+		{Start: time.Date(2026, 10, 16, 12, 29, 0, 0, time.UTC)},
+		{Start: time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC), Amount: 12.5, Drips: 2},
+	}, minutes)
+
+	hours, err := r.DistributionSeries(ctx, "STRK", time.Hour, 2, now)
+	require.NoError(t, err)
+	assert.Equal(t, []DistributionBucket{
+		{Start: time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), Amount: 22.5, Drips: 3},
+	}, hours)
+
+	// Buckets expire once past their retention
+	assert.Equal(t, DistributionMinuteRetention+time.Minute, mr.TTL(distributionKey("STRK", time.Minute, now)))
+	assert.Equal(t, DistributionHourRetention+time.Hour, mr.TTL(distributionKey("STRK", time.Hour, now)))
+
+	_, err = r.DistributionSeries(ctx, "STRK", 5*time.Minute, 3, now)
+	assert.Error(t, err)
+}
@@ -1,13 +1,71 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"net"
+	"net/http"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/Giri-Aayush/starknet-faucet/internal/receipt"
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
 	"github.com/joho/godotenv"
 )
 
+// NetworkDevnet selects the preset for a local starknet-devnet or katana node
+const NetworkDevnet = "devnet"
+
+// Token addresses shared by mainnet, Sepolia and local devnets
+const (
+	defaultETHTokenAddress  = "0x049d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"
+	defaultSTRKTokenAddress = "0x04718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d"
+)
+
+// devnetPrivateKey is the key of the first predeployed account of
+// starknet-devnet started with --seed 0. It is public knowledge.
+const devnetPrivateKey = "0x71d7bb07b9a64f6f78ac4c816aff4da9"
+
+// minAPIKeyLength keeps trusted API keys long enough that they can't be guessed
+const minAPIKeyLength = 16
+
+// networkPreset holds defaults supplied for a network. Environment variables
+// always override them.
+type networkPreset struct {
+	RPCURL           string
+	FaucetAddress    string
+	FaucetPrivateKey string
+	ETHTokenAddress  string
+	STRKTokenAddress string
+}
+
+// networkPresets lists networks with defaults beyond the token addresses
+var networkPresets = map[string]networkPreset{
+	NetworkDevnet: {
+		RPCURL:           "http://127.0.0.1:5050",
+		FaucetAddress:    "0x064b48806902a367c8598f4f95c305e8c1a1acba5f082d294a43793113115691",
+		FaucetPrivateKey: devnetPrivateKey,
+		ETHTokenAddress:  defaultETHTokenAddress,
+		STRKTokenAddress: defaultSTRKTokenAddress,
+	},
+}
+
+// presetFor returns the defaults for network
+func presetFor(network string) networkPreset {
+	if preset, ok := networkPresets[network]; ok {
+		return preset
+	}
+	return networkPreset{
+		ETHTokenAddress:  defaultETHTokenAddress,
+		STRKTokenAddress: defaultSTRKTokenAddress,
+	}
+}
+
 // Config holds all configuration for the application
 type Config struct {
 	// Server
@@ -15,12 +73,57 @@ type Config struct {
 	LogLevel string
 	Network  string
 
+	// HTTP server tuning
+	ReadTimeout    int // Max time to read a request, in seconds
+	WriteTimeout   int // Max time to write a response, in seconds (must exceed RPCTimeout)
+	IdleTimeout    int // Max time to keep an idle keep-alive connection open, in seconds
+	MaxConcurrency int // Max concurrent connections
+	GlobalRPS      int // Max challenge+faucet requests per second across all IPs (0 = disabled)
+
+	// Per-route handler deadlines, in seconds (0 = none). A request that fails
+	// past its deadline is answered with 503.
+	RouteTimeout       int // /api/v1 routes other than the faucet ones
+	FaucetRouteTimeout int // /faucet and /faucet/authorized, which wait on transfers
+
+	// Logging output
+	LogFormat     string   // "json" or "console" (empty = based on level)
+	LogFile       string   // Log file path (empty = stderr)
+	LogMaxSizeMB  int      // Rotate log file after this size
+	LogMaxBackups int      // Rotated log files to keep
+	LogMaxAgeDays int      // Days to keep rotated log files
+	LogSkipPaths  []string // Request paths, and paths below them, left out of the access log
+
 	// Starknet
-	FaucetPrivateKey string
-	FaucetAddress    string
-	StarknetRPCURL   string
-	ETHTokenAddress  string
-	STRKTokenAddress string
+	FaucetPrivateKey  string
+	FaucetAddress     string
+	FaucetAccounts    []FaucetAccount // Accounts transfers rotate across (defaults to FaucetAddress alone)
+	StarknetRPCURL    string
+	RPCFallbackURLs   []string          // Tried in order when StarknetRPCURL can't be reached
+	RPCHeaders        map[string]string // Sent with every RPC request, e.g. a provider API key; values are secret
+	ETHTokenAddress   string
+	STRKTokenAddress  string
+	Explorer          string // Block explorer for transaction links: "voyager" or "starkscan"
+	ExplorerBaseURL   string // Overrides Explorer, e.g. for a private explorer (empty = use Explorer)
+	TxVersion         int    // Invoke transaction version (only 3, with fees in STRK, is accepted)
+	FeeToken          string // Token used to pay transaction fees (must match TxVersion)
+	ConfirmationLevel string // Finality status ?wait=true requests wait for: RECEIVED, PRE_CONFIRMED or ACCEPTED_ON_L2
+	RPCTimeout        int    // Deadline for Starknet RPC calls per request, in seconds
+	StarknetWSURL     string // Websocket RPC endpoint for transaction status updates (empty = poll over HTTP)
+	CheckDeployment   bool   // Warn in the response when the recipient has no deployed contract (one extra RPC call)
+
+	// Only fund deployed account contracts, checked with SNIP-6 supports_interface
+	RequireAccountRecipient bool // Refuse recipients that aren't accounts with 400
+
+	// Reported in /info so clients can tell users when to expect their tokens
+	EstimatedArrivalSeconds int // Typical seconds from a response to the tokens arriving (0 = not reported)
+
+	// The RPC is checked in the background so requests fail fast while it is down
+	RPCHealthInterval int // Seconds between reachability checks
+
+	// RPC connection pool, 0 keeps Go's default
+	RPCMaxIdleConns        int // Idle connections kept open
+	RPCMaxIdleConnsPerHost int // Idle connections kept to the RPC host
+	RPCMaxConnsPerHost     int // Max connections to the RPC host (0 = unlimited)
 
 	// Redis
 	RedisURL string
@@ -29,18 +132,97 @@ type Config struct {
 	PoWDifficulty   int
 	DripAmountSTRK  string
 	DripAmountETH   string
-	ChallengeTTL    int // in seconds
+	DripJitterPct   float64 // Each drip is randomized within ±this % of its amount (0 = exact amounts)
+	ChallengeTTL    int     // in seconds
+	MinSolveSeconds int     // Submissions sooner than this after the challenge was issued are rejected (0 = off)
+
+	// A challenge requested with ?token= can only be redeemed for that token
+	RequireChallengeToken bool // Every challenge must be requested for a token
+
+	// Bonus requests, bought past the daily limit with a harder challenge
+	BonusDifficulty        int // Difficulty added to bonus challenges (0 = no bonus requests)
+	MaxBonusRequestsPerDay int // Bonus requests one IP can make per daily window
 
 	// Rate Limiting (Simplified)
-	MaxRequestsPerDayIP  int // Max requests per IP per day (5) - single token=1, BOTH=2
-	MaxChallengesPerHour int // Max PoW challenges per IP per hour (8)
+	MaxRequestsPerDayIP        int // Max requests per IP per day (5) - single token=1, BOTH=2
+	MaxChallengesPerHour       int // Max PoW challenges per IP per hour (8)
+	DailyResetHour             int // UTC hour (0-23) when IP daily quotas reset, -1 for a rolling 24h window
+	MaxDistinctAddressesPerDay int // Max different recipient addresses one IP can fund per day (0 = unlimited)
+
+	// Leaky bucket per IP, on top of the daily limit and hourly throttle
+	BurstSize  int     // Requests an IP can make back to back (0 = off), 1 per token
+	RefillRate float64 // Requests per hour the bucket refills at
+
+	// Trusted integrations, authenticated with an X-API-Key header
+	TrustedAPIKeys          []string // Keys that skip PoW and IP rate limits
+	MaxRequestsPerDayAPIKey int      // Daily requests per key (1 per token), shared by all its callers
+
+	// Audit log of transfers, queried at /api/v1/admin/requests
+	AdminAPIKey        string // Key for admin endpoints, sent in an X-Admin-Key header (empty = admin endpoints disabled)
+	AuditRetentionDays int    // Days transfers stay in the audit log (0 = no audit log)
+	AuditIPSecret      string // HMAC key client IPs are hashed with in the audit log
+
+	// Discord /faucet slash command, served at /api/v1/discord/interactions
+	DiscordPublicKey string // Application public key (hex) for verifying interaction signatures
+	DiscordBotToken  string // Bot token for registering the command (both empty = Discord disabled)
+
+	// Requests signed by the recipient's account and submitted by a relayer,
+	// served at /api/v1/faucet/authorized
+	SignedRequestsEnabled bool // Limits apply per signing address; no PoW is asked for
+
+	// Receipts signed by the faucet for every transfer, checked with the
+	// public key served at /api/v1/info
+	SignReceipts bool               // Attach a signed receipt to every transfer
+	ReceiptKey   ed25519.PrivateKey // From RECEIPT_SIGNING_KEY, a hex Ed25519 seed or private key
+
+	// IP access lists, parsed from comma-separated IPs/CIDRs
+	IPBlocklist []*net.IPNet // Always rejected with 403
+	IPAllowlist []*net.IPNet // Bypass rate limits (PoW and validation still apply)
 
 	// Global Distribution Limits (prevents drain attacks)
-	MaxTokensPerHourSTRK  float64 // Max STRK distributed per hour globally
-	MaxTokensPerDaySTRK   float64 // Max STRK per day globally
-	MaxTokensPerHourETH   float64 // Max ETH distributed per hour globally
-	MaxTokensPerDayETH    float64 // Max ETH per day globally
-	MinBalanceProtectPct  int     // Stop distributing when balance drops to this % (e.g., 20 = stop at 20%)
+	MaxTokensPerHourSTRK     float64 // Max STRK distributed per hour globally
+	MaxTokensPerDaySTRK      float64 // Max STRK per day globally
+	MaxTokensPerHourETH      float64 // Max ETH distributed per hour globally
+	MaxTokensPerDayETH       float64 // Max ETH per day globally
+	MinBalanceProtectPct     int     // Stop distributing when balance drops to this % (e.g., 20 = stop at 20%)
+	MinBalanceProtectPctSTRK int     // STRK override of MinBalanceProtectPct
+	MinBalanceProtectPctETH  int     // ETH override of MinBalanceProtectPct
+	MinBalanceFloorSTRK      float64 // Never distribute STRK below this balance (0 = disabled)
+	MinBalanceFloorETH       float64 // Never distribute ETH below this balance (0 = disabled)
+
+	// Per-token kill switches, e.g. to pause ETH while it runs low
+	EnableSTRK bool // Distribute STRK at all
+	EnableETH  bool // Distribute ETH at all
+
+	// Velocity alerts: early warning of a request spike (draining attempt)
+	VelocityAlertMultiple   float64 // Alert when the recent request rate exceeds this multiple of the baseline (0 = off)
+	VelocityWindowMinutes   int     // Recent window compared with the baseline
+	VelocityBaselineMinutes int     // Window before it that sets the baseline rate
+	VelocityMinRequests     int     // Requests the recent window needs before it can alert
+	VelocityDifficultyBump  int     // Extra PoW difficulty for new challenges while an alert is active
+	AlertWebhookURL         string  // Receives alerts as JSON POSTs (empty = log only)
+
+	// Tokens the faucet can distribute, keyed by symbol
+	Tokens map[string]TokenConfig
+}
+
+// FaucetAccount is an account the faucet sends transfers from
+type FaucetAccount struct {
+	Address    string
+	PrivateKey string
+}
+
+// TokenConfig describes a token the faucet can distribute
+type TokenConfig struct {
+	Symbol               string
+	Address              string
+	Decimals             int
+	DripAmount           string
+	MaxPerHour           float64 // Max distributed per hour globally (0 = disabled)
+	MaxPerDay            float64 // Max distributed per day globally (0 = disabled)
+	MinBalanceProtectPct int     // Stop distributing when balance drops to this % of itself
+	MinBalanceFloor      float64 // Stop distributing when balance would drop below this amount
+	Paused               bool    // Refused, or skipped by BOTH and ALL (ENABLE_<SYMBOL>=false)
 }
 
 // Load loads configuration from environment variables
@@ -48,33 +230,125 @@ func Load() (*Config, error) {
 	// Try to load .env file (optional)
 	_ = godotenv.Load()
 
+	// NETWORK=devnet fills in local defaults for the settings below
+	network := getEnv("NETWORK", "sepolia")
+	preset := presetFor(network)
+
 	config := &Config{
 		// Server defaults
 		Port:     getEnv("PORT", "3000"),
 		LogLevel: getEnv("LOG_LEVEL", "info"),
-		Network:  getEnv("NETWORK", "sepolia"),
+		Network:  network,
+
+		// HTTP server tuning - sized for slow clients and RPC-bound transfers
+		ReadTimeout:    getEnvAsInt("READ_TIMEOUT", 30),
+		WriteTimeout:   getEnvAsInt("WRITE_TIMEOUT", 90),
+		IdleTimeout:    getEnvAsInt("IDLE_TIMEOUT", 120),
+		MaxConcurrency: getEnvAsInt("MAX_CONCURRENCY", 256*1024), // Fiber's default
+		GlobalRPS:      getEnvAsInt("GLOBAL_RPS", 0),             // 0 = disabled
+
+		// Per-route deadline for lookups; FAUCET_ROUTE_TIMEOUT is set below
+		// since its default depends on RPC_TIMEOUT and WRITE_TIMEOUT
+		RouteTimeout: getEnvAsInt("ROUTE_TIMEOUT", 10),
+
+		// Logging output - stderr unless LOG_FILE is set
+		LogFormat:     getEnv("LOG_FORMAT", ""),
+		LogFile:       getEnv("LOG_FILE", ""),
+		LogMaxSizeMB:  getEnvAsInt("LOG_MAX_SIZE_MB", 100),
+		LogMaxBackups: getEnvAsInt("LOG_MAX_BACKUPS", 5),
+		LogMaxAgeDays: getEnvAsInt("LOG_MAX_AGE_DAYS", 30),
+		LogSkipPaths:  splitList(getEnv("LOG_SKIP_PATHS", "/health,/metrics")),
+
+		// Starknet (required unless the network preset supplies them)
+		FaucetPrivateKey: getEnv("FAUCET_PRIVATE_KEY", preset.FaucetPrivateKey),
+		FaucetAddress:    getEnv("FAUCET_ADDRESS", preset.FaucetAddress),
+		StarknetRPCURL:   getEnv("STARKNET_RPC_URL", preset.RPCURL),
+
+		// Token addresses - same on Sepolia, mainnet and devnet
+		ETHTokenAddress:  getEnv("ETH_TOKEN_ADDRESS", preset.ETHTokenAddress),
+		STRKTokenAddress: getEnv("STRK_TOKEN_ADDRESS", preset.STRKTokenAddress),
+
+		// Block explorer used for transaction links
+		Explorer:        strings.ToLower(getEnv("EXPLORER", "voyager")),
+		ExplorerBaseURL: strings.TrimRight(getEnv("EXPLORER_BASE_URL", ""), "/"),
+
+		// Transaction settings - v3 transactions pay fees in STRK
+		TxVersion: getEnvAsInt("TX_VERSION", 3),
+		FeeToken:  strings.ToUpper(getEnv("FEE_TOKEN", "STRK")),
+
+		// How confirmed a transfer must be before a ?wait=true request responds
+		ConfirmationLevel: strings.ToUpper(getEnv("CONFIRMATION_LEVEL", "ACCEPTED_ON_L2")),
 
-		// Starknet (required)
-		FaucetPrivateKey: getEnv("FAUCET_PRIVATE_KEY", ""),
-		FaucetAddress:    getEnv("FAUCET_ADDRESS", ""),
-		StarknetRPCURL:   getEnv("STARKNET_RPC_URL", ""),
+		// Depends on the network's block time, so it is left to the operator
+		EstimatedArrivalSeconds: getEnvAsInt("ESTIMATED_ARRIVAL_SECONDS", 0),
 
-		// Token addresses - Sepolia defaults
-		ETHTokenAddress:  getEnv("ETH_TOKEN_ADDRESS", "0x049d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"),
-		STRKTokenAddress: getEnv("STRK_TOKEN_ADDRESS", "0x04718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d"),
+		// RPC calls made while handling a request share this deadline
+		RPCTimeout: getEnvAsInt("RPC_TIMEOUT", 30),
+
+		// Requests fail fast with 503 while the last check found the RPC down
+		RPCHealthInterval: getEnvAsInt("RPC_HEALTH_INTERVAL", 15),
+
+		// Websocket RPC is optional; receipts are polled over HTTP without it
+		StarknetWSURL: getEnv("STARKNET_WS_URL", ""),
+
+		// Recipient deployment check is off to save an RPC call per request
+		CheckDeployment: getEnvAsBool("CHECK_DEPLOYMENT", false),
+
+		// Any address can be funded unless recipients must be accounts
+		RequireAccountRecipient: getEnvAsBool("REQUIRE_ACCOUNT_RECIPIENT", false),
+
+		// RPC connection pool - enough idle connections to the one RPC host
+		// that concurrent transfers don't reconnect every time
+		RPCMaxIdleConns:        getEnvAsInt("RPC_MAX_IDLE_CONNS", 100),
+		RPCMaxIdleConnsPerHost: getEnvAsInt("RPC_MAX_IDLE_CONNS_PER_HOST", 32),
+		RPCMaxConnsPerHost:     getEnvAsInt("RPC_MAX_CONNS_PER_HOST", 0), // 0 = unlimited
 
 		// Redis (required)
 		RedisURL: getEnv("REDIS_URL", "redis://localhost:6379"),
 
 		// Faucet settings
-		PoWDifficulty:  getEnvAsInt("POW_DIFFICULTY", 4),
-		DripAmountSTRK: getEnv("DRIP_AMOUNT_STRK", "10"),
-		DripAmountETH:  getEnv("DRIP_AMOUNT_ETH", "0.01"),
-		ChallengeTTL:   getEnvAsInt("CHALLENGE_TTL", 300), // 5 minutes
+		PoWDifficulty:   getEnvAsInt("POW_DIFFICULTY", 4),
+		DripAmountSTRK:  getEnv("DRIP_AMOUNT_STRK", "10"),
+		DripAmountETH:   getEnv("DRIP_AMOUNT_ETH", "0.01"),
+		DripJitterPct:   getEnvAsFloat("DRIP_JITTER_PCT", 0),
+		ChallengeTTL:    getEnvAsInt("CHALLENGE_TTL", 300), // 5 minutes
+		MinSolveSeconds: getEnvAsInt("MIN_SOLVE_SECONDS", 0),
+
+		// Challenges may always be bound to a token; this makes it mandatory
+		RequireChallengeToken: getEnvAsBool("REQUIRE_CHALLENGE_TOKEN", false),
+
+		// Bonus requests (off unless BONUS_DIFFICULTY is set)
+		BonusDifficulty:        getEnvAsInt("BONUS_DIFFICULTY", 0),
+		MaxBonusRequestsPerDay: getEnvAsInt("MAX_BONUS_REQUESTS_PER_DAY", 1),
 
 		// Rate limiting (simplified)
-		MaxRequestsPerDayIP:  getEnvAsInt("MAX_REQUESTS_PER_DAY_IP", 5), // 5 requests/day per IP
-		MaxChallengesPerHour: getEnvAsInt("MAX_CHALLENGES_PER_HOUR", 8), // 8 challenges/hour per IP
+		MaxRequestsPerDayIP:        getEnvAsInt("MAX_REQUESTS_PER_DAY_IP", 5),        // 5 requests/day per IP
+		MaxChallengesPerHour:       getEnvAsInt("MAX_CHALLENGES_PER_HOUR", 8),        // 8 challenges/hour per IP
+		DailyResetHour:             getEnvAsInt("DAILY_RESET_HOUR", -1),              // -1 = rolling 24h window
+		MaxDistinctAddressesPerDay: getEnvAsInt("MAX_DISTINCT_ADDRESSES_PER_DAY", 0), // 0 = unlimited
+
+		// Burst allowance (off unless BURST_SIZE is set)
+		BurstSize:  getEnvAsInt("BURST_SIZE", 0),
+		RefillRate: getEnvAsFloat("REFILL_RATE", 1),
+
+		// Trusted API keys (none by default, so every request solves PoW)
+		TrustedAPIKeys:          splitList(getEnv("TRUSTED_API_KEYS", "")),
+		MaxRequestsPerDayAPIKey: getEnvAsInt("MAX_REQUESTS_PER_DAY_API_KEY", 100),
+
+		// Audit log (admin endpoints stay disabled until ADMIN_API_KEY is set)
+		AdminAPIKey:        getEnv("ADMIN_API_KEY", ""),
+		AuditRetentionDays: getEnvAsInt("AUDIT_RETENTION_DAYS", 0),
+		AuditIPSecret:      getEnv("AUDIT_IP_SECRET", ""),
+
+		// Discord integration (disabled unless both are set)
+		DiscordPublicKey: getEnv("DISCORD_PUBLIC_KEY", ""),
+		DiscordBotToken:  getEnv("DISCORD_BOT_TOKEN", ""),
+
+		// Relayed requests signed by the recipient (off by default)
+		SignedRequestsEnabled: getEnvAsBool("SIGNED_REQUESTS_ENABLED", false),
+
+		// Signed receipts (off by default)
+		SignReceipts: getEnvAsBool("SIGN_RECEIPTS", false),
 
 		// Global distribution limits (anti-drain protection) - set to 0 to disable
 		MaxTokensPerHourSTRK: getEnvAsFloat("MAX_TOKENS_PER_HOUR_STRK", 0), // 0 = disabled
@@ -82,6 +356,68 @@ func Load() (*Config, error) {
 		MaxTokensPerHourETH:  getEnvAsFloat("MAX_TOKENS_PER_HOUR_ETH", 0),  // 0 = disabled
 		MaxTokensPerDayETH:   getEnvAsFloat("MAX_TOKENS_PER_DAY_ETH", 0),   // 0 = disabled
 		MinBalanceProtectPct: getEnvAsInt("MIN_BALANCE_PROTECT_PCT", 5),    // Stop at 5% remaining
+		MinBalanceFloorSTRK:  getEnvAsFloat("MIN_BALANCE_FLOOR_STRK", 0),   // 0 = percentage only
+		MinBalanceFloorETH:   getEnvAsFloat("MIN_BALANCE_FLOOR_ETH", 0),    // 0 = percentage only
+
+		// Per-token kill switches (both tokens on by default)
+		EnableSTRK: getEnvAsBool("ENABLE_STRK", true),
+		EnableETH:  getEnvAsBool("ENABLE_ETH", true),
+
+		// Velocity alerts (off unless VELOCITY_ALERT_MULTIPLE is set)
+		VelocityAlertMultiple:   getEnvAsFloat("VELOCITY_ALERT_MULTIPLE", 0),
+		VelocityWindowMinutes:   getEnvAsInt("VELOCITY_WINDOW_MINUTES", 5),
+		VelocityBaselineMinutes: getEnvAsInt("VELOCITY_BASELINE_MINUTES", 60),
+		VelocityMinRequests:     getEnvAsInt("VELOCITY_MIN_REQUESTS", 20),
+		VelocityDifficultyBump:  getEnvAsInt("VELOCITY_DIFFICULTY_BUMP", 0),
+		AlertWebhookURL:         getEnv("ALERT_WEBHOOK_URL", ""),
+	}
+
+	// The faucet deadline defaults to between RPC_TIMEOUT and WRITE_TIMEOUT,
+	// long enough for a transfer plus a ?wait=true confirmation
+	config.FaucetRouteTimeout = getEnvAsInt("FAUCET_ROUTE_TIMEOUT", defaultFaucetRouteTimeout(config.RPCTimeout, config.WriteTimeout))
+
+	// Per-token balance protection falls back to the global percentage
+	config.MinBalanceProtectPctSTRK = getEnvAsInt("MIN_BALANCE_PROTECT_PCT_STRK", config.MinBalanceProtectPct)
+	config.MinBalanceProtectPctETH = getEnvAsInt("MIN_BALANCE_PROTECT_PCT_ETH", config.MinBalanceProtectPct)
+
+	config.Tokens = config.buildTokens()
+
+	// FAUCET_ACCOUNTS spreads transfers across several accounts and replaces
+	// FAUCET_ADDRESS/FAUCET_PRIVATE_KEY, which then refer to its first entry
+	var err error
+	if config.FaucetAccounts, err = parseFaucetAccounts(getEnv("FAUCET_ACCOUNTS", "")); err != nil {
+		return nil, fmt.Errorf("invalid FAUCET_ACCOUNTS: %w", err)
+	}
+	if len(config.FaucetAccounts) > 0 {
+		config.FaucetAddress = config.FaucetAccounts[0].Address
+		config.FaucetPrivateKey = config.FaucetAccounts[0].PrivateKey
+	} else if config.FaucetAddress != "" {
+		config.FaucetAccounts = []FaucetAccount{{Address: config.FaucetAddress, PrivateKey: config.FaucetPrivateKey}}
+	}
+
+	// STARKNET_RPC_URLS lists the endpoints in failover order and takes precedence
+	if urls := splitList(getEnv("STARKNET_RPC_URLS", "")); len(urls) > 0 {
+		config.StarknetRPCURL, config.RPCFallbackURLs = urls[0], urls[1:]
+	}
+
+	// Keeps provider API keys out of STARKNET_RPC_URL, which ends up in logs
+	if config.RPCHeaders, err = parseRPCHeaders(getEnv("STARKNET_RPC_HEADERS", "")); err != nil {
+		return nil, fmt.Errorf("invalid STARKNET_RPC_HEADERS: %w", err)
+	}
+
+	// IP access lists
+	if config.IPBlocklist, err = utils.ParseIPList(getEnv("IP_BLOCKLIST", "")); err != nil {
+		return nil, fmt.Errorf("invalid IP_BLOCKLIST: %w", err)
+	}
+	if config.IPAllowlist, err = utils.ParseIPList(getEnv("IP_ALLOWLIST", "")); err != nil {
+		return nil, fmt.Errorf("invalid IP_ALLOWLIST: %w", err)
+	}
+
+	// The receipt key is only needed, and required, when receipts are signed
+	if config.SignReceipts {
+		if config.ReceiptKey, err = receipt.ParseKey(getEnv("RECEIPT_SIGNING_KEY", "")); err != nil {
+			return nil, fmt.Errorf("invalid RECEIPT_SIGNING_KEY (required by SIGN_RECEIPTS): %w", err)
+		}
 	}
 
 	// Validate required fields
@@ -101,20 +437,330 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("FAUCET_ADDRESS is required")
 	}
 	if c.StarknetRPCURL == "" {
-		return fmt.Errorf("STARKNET_RPC_URL is required")
+		return fmt.Errorf("STARKNET_RPC_URL or STARKNET_RPC_URLS is required")
 	}
 	if c.RedisURL == "" {
 		return fmt.Errorf("REDIS_URL is required")
 	}
+	for _, symbol := range c.TokenSymbols() {
+		if _, err := ParseDripAmount(c.Tokens[symbol].DripAmount); err != nil {
+			return fmt.Errorf("DRIP_AMOUNT_%s must be a positive number (got %q)", symbol, c.Tokens[symbol].DripAmount)
+		}
+	}
+	if c.DailyResetHour < -1 || c.DailyResetHour > 23 {
+		return fmt.Errorf("DAILY_RESET_HOUR must be between 0 and 23, or -1 for rolling (got %d)", c.DailyResetHour)
+	}
+	for name, pct := range map[string]int{
+		"MIN_BALANCE_PROTECT_PCT":      c.MinBalanceProtectPct,
+		"MIN_BALANCE_PROTECT_PCT_STRK": c.MinBalanceProtectPctSTRK,
+		"MIN_BALANCE_PROTECT_PCT_ETH":  c.MinBalanceProtectPctETH,
+	} {
+		if pct < 0 || pct >= 100 {
+			return fmt.Errorf("%s must be between 0 and 99 (got %d)", name, pct)
+		}
+	}
+	if c.MinBalanceFloorSTRK < 0 || c.MinBalanceFloorETH < 0 {
+		return fmt.Errorf("MIN_BALANCE_FLOOR_STRK and MIN_BALANCE_FLOOR_ETH must not be negative")
+	}
+	if _, ok := explorerBaseURLs[c.Explorer]; !ok {
+		return fmt.Errorf("EXPLORER must be voyager or starkscan (got %s)", c.Explorer)
+	}
+	if c.VelocityAlertMultiple < 0 {
+		return fmt.Errorf("VELOCITY_ALERT_MULTIPLE must not be negative (got %g)", c.VelocityAlertMultiple)
+	}
+	if c.VelocityAlertMultiple > 0 {
+		if c.VelocityWindowMinutes <= 0 || c.VelocityBaselineMinutes <= 0 {
+			return fmt.Errorf("VELOCITY_WINDOW_MINUTES and VELOCITY_BASELINE_MINUTES must be positive (got %d and %d)",
+				c.VelocityWindowMinutes, c.VelocityBaselineMinutes)
+		}
+		if c.VelocityMinRequests < 0 || c.VelocityDifficultyBump < 0 {
+			return fmt.Errorf("VELOCITY_MIN_REQUESTS and VELOCITY_DIFFICULTY_BUMP must not be negative (got %d and %d)",
+				c.VelocityMinRequests, c.VelocityDifficultyBump)
+		}
+	}
+	if c.AlertWebhookURL != "" && !strings.HasPrefix(c.AlertWebhookURL, "http://") && !strings.HasPrefix(c.AlertWebhookURL, "https://") {
+		return fmt.Errorf("ALERT_WEBHOOK_URL must be an http(s) URL (got %s)", c.AlertWebhookURL)
+	}
+	if c.DripJitterPct < 0 || c.DripJitterPct >= 100 {
+		return fmt.Errorf("DRIP_JITTER_PCT must be at least 0 and below 100 (got %g)", c.DripJitterPct)
+	}
+	if c.BonusDifficulty < 0 {
+		return fmt.Errorf("BONUS_DIFFICULTY must not be negative (got %d)", c.BonusDifficulty)
+	}
+	if c.BonusDifficulty > 0 && c.MaxBonusRequestsPerDay <= 0 {
+		return fmt.Errorf("MAX_BONUS_REQUESTS_PER_DAY must be positive when BONUS_DIFFICULTY is set (got %d)", c.MaxBonusRequestsPerDay)
+	}
+	if c.MinSolveSeconds < 0 || (c.MinSolveSeconds > 0 && c.MinSolveSeconds >= c.ChallengeTTL) {
+		return fmt.Errorf("MIN_SOLVE_SECONDS must be between 0 and CHALLENGE_TTL (got %d, CHALLENGE_TTL=%d)", c.MinSolveSeconds, c.ChallengeTTL)
+	}
+	if !starknet.IsConfirmationLevel(c.ConfirmationLevel) {
+		return fmt.Errorf("CONFIRMATION_LEVEL must be RECEIVED, PRE_CONFIRMED or ACCEPTED_ON_L2 (got %s)", c.ConfirmationLevel)
+	}
+	if c.EstimatedArrivalSeconds < 0 {
+		return fmt.Errorf("ESTIMATED_ARRIVAL_SECONDS must not be negative (got %d)", c.EstimatedArrivalSeconds)
+	}
+	if c.RPCTimeout <= 0 {
+		return fmt.Errorf("RPC_TIMEOUT must be positive (got %d)", c.RPCTimeout)
+	}
+	if c.RPCHealthInterval <= 0 {
+		return fmt.Errorf("RPC_HEALTH_INTERVAL must be positive (got %d)", c.RPCHealthInterval)
+	}
+	if c.ReadTimeout <= 0 || c.IdleTimeout <= 0 || c.MaxConcurrency <= 0 {
+		return fmt.Errorf("READ_TIMEOUT, IDLE_TIMEOUT and MAX_CONCURRENCY must be positive")
+	}
+	for _, key := range c.TrustedAPIKeys {
+		if len(key) < minAPIKeyLength {
+			return fmt.Errorf("TRUSTED_API_KEYS entries must be at least %d characters", minAPIKeyLength)
+		}
+	}
+	if len(c.TrustedAPIKeys) > 0 && c.MaxRequestsPerDayAPIKey <= 0 {
+		return fmt.Errorf("MAX_REQUESTS_PER_DAY_API_KEY must be positive (got %d)", c.MaxRequestsPerDayAPIKey)
+	}
+	if c.AdminAPIKey != "" && len(c.AdminAPIKey) < minAPIKeyLength {
+		return fmt.Errorf("ADMIN_API_KEY must be at least %d characters", minAPIKeyLength)
+	}
+	if (c.DiscordPublicKey == "") != (c.DiscordBotToken == "") {
+		return fmt.Errorf("DISCORD_PUBLIC_KEY and DISCORD_BOT_TOKEN must be set together")
+	}
+	if key, err := hex.DecodeString(c.DiscordPublicKey); err != nil || (c.DiscordPublicKey != "" && len(key) != ed25519.PublicKeySize) {
+		return fmt.Errorf("DISCORD_PUBLIC_KEY must be a %d-byte hex key", ed25519.PublicKeySize)
+	}
+	if c.AuditRetentionDays < 0 {
+		return fmt.Errorf("AUDIT_RETENTION_DAYS must not be negative (got %d)", c.AuditRetentionDays)
+	}
+	if c.AuditRetentionDays > 0 && len(c.AuditIPSecret) < minAPIKeyLength {
+		return fmt.Errorf("AUDIT_IP_SECRET must be at least %d characters when AUDIT_RETENTION_DAYS is set", minAPIKeyLength)
+	}
+	if c.RPCMaxIdleConns < 0 || c.RPCMaxIdleConnsPerHost < 0 || c.RPCMaxConnsPerHost < 0 {
+		return fmt.Errorf("RPC_MAX_IDLE_CONNS, RPC_MAX_IDLE_CONNS_PER_HOST and RPC_MAX_CONNS_PER_HOST must not be negative")
+	}
+	if c.MaxDistinctAddressesPerDay < 0 {
+		return fmt.Errorf("MAX_DISTINCT_ADDRESSES_PER_DAY must not be negative (got %d)", c.MaxDistinctAddressesPerDay)
+	}
+	if c.BurstSize < 0 {
+		return fmt.Errorf("BURST_SIZE must not be negative (got %d)", c.BurstSize)
+	}
+	if c.BurstSize > 0 && c.RefillRate <= 0 {
+		return fmt.Errorf("REFILL_RATE must be positive when BURST_SIZE is set (got %g)", c.RefillRate)
+	}
+	if c.GlobalRPS < 0 {
+		return fmt.Errorf("GLOBAL_RPS must not be negative (got %d)", c.GlobalRPS)
+	}
+	if c.WriteTimeout <= c.RPCTimeout {
+		return fmt.Errorf("WRITE_TIMEOUT (%ds) must be greater than RPC_TIMEOUT (%ds) or transfer responses get cut off", c.WriteTimeout, c.RPCTimeout)
+	}
+	if c.RouteTimeout < 0 || c.FaucetRouteTimeout < 0 {
+		return fmt.Errorf("ROUTE_TIMEOUT and FAUCET_ROUTE_TIMEOUT must not be negative")
+	}
+	if c.RouteTimeout >= c.WriteTimeout || c.FaucetRouteTimeout >= c.WriteTimeout {
+		return fmt.Errorf("ROUTE_TIMEOUT (%ds) and FAUCET_ROUTE_TIMEOUT (%ds) must be less than WRITE_TIMEOUT (%ds) so the timeout response gets written",
+			c.RouteTimeout, c.FaucetRouteTimeout, c.WriteTimeout)
+	}
+	if c.FaucetRouteTimeout > 0 && c.FaucetRouteTimeout <= c.RPCTimeout {
+		return fmt.Errorf("FAUCET_ROUTE_TIMEOUT (%ds) must be greater than RPC_TIMEOUT (%ds) or transfers get cut off", c.FaucetRouteTimeout, c.RPCTimeout)
+	}
+	switch c.TxVersion {
+	case 3:
+		if c.FeeToken != "STRK" {
+			return fmt.Errorf("FEE_TOKEN must be STRK for TX_VERSION=3 (got %s)", c.FeeToken)
+		}
+	case 1:
+		// RPC v0.8+ providers only accept v3 invoke transactions
+		return fmt.Errorf("TX_VERSION=1 is no longer accepted by Starknet RPC providers; use TX_VERSION=3 with FEE_TOKEN=STRK")
+	default:
+		return fmt.Errorf("TX_VERSION must be 3 (got %d)", c.TxVersion)
+	}
 	return nil
 }
 
-// GetExplorerURL returns the block explorer URL for the configured network
+// buildTokens builds the token map from the per-token settings
+func (c *Config) buildTokens() map[string]TokenConfig {
+	return map[string]TokenConfig{
+		"STRK": {
+			Symbol:               "STRK",
+			Address:              c.STRKTokenAddress,
+			Decimals:             18,
+			DripAmount:           c.DripAmountSTRK,
+			MaxPerHour:           c.MaxTokensPerHourSTRK,
+			MaxPerDay:            c.MaxTokensPerDaySTRK,
+			MinBalanceProtectPct: c.MinBalanceProtectPctSTRK,
+			MinBalanceFloor:      c.MinBalanceFloorSTRK,
+			Paused:               !c.EnableSTRK,
+		},
+		"ETH": {
+			Symbol:               "ETH",
+			Address:              c.ETHTokenAddress,
+			Decimals:             18,
+			DripAmount:           c.DripAmountETH,
+			MaxPerHour:           c.MaxTokensPerHourETH,
+			MaxPerDay:            c.MaxTokensPerDayETH,
+			MinBalanceProtectPct: c.MinBalanceProtectPctETH,
+			MinBalanceFloor:      c.MinBalanceFloorETH,
+			Paused:               !c.EnableETH,
+		},
+	}
+}
+
+// ParseDripAmount parses a drip amount such as "0.01", which must be a
+// positive number
+func ParseDripAmount(amount string) (float64, error) {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil || math.IsInf(value, 0) || !(value > 0) {
+		return 0, fmt.Errorf("invalid drip amount %q", amount)
+	}
+	return value, nil
+}
+
+// defaultFaucetRouteTimeout puts the faucet deadline three quarters of the
+// way from the RPC deadline to the write timeout (75s with the 30s and 90s
+// defaults). It's 0 (off) when no whole second fits between the two.
+func defaultFaucetRouteTimeout(rpcTimeout, writeTimeout int) int {
+	if writeTimeout-rpcTimeout < 2 {
+		return 0
+	}
+	return rpcTimeout + (writeTimeout-rpcTimeout)*3/4
+}
+
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseFaucetAccounts parses a comma-separated list of address:privatekey pairs
+func parseFaucetAccounts(list string) ([]FaucetAccount, error) {
+	var accounts []FaucetAccount
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		address, key, ok := strings.Cut(entry, ":")
+		address, key = strings.TrimSpace(address), strings.TrimSpace(key)
+		if !ok || address == "" || key == "" {
+			return nil, fmt.Errorf("entry %d must be address:private_key", len(accounts)+1)
+		}
+		if seen[strings.ToLower(address)] {
+			return nil, fmt.Errorf("account %s is listed more than once", address)
+		}
+		seen[strings.ToLower(address)] = true
+		accounts = append(accounts, FaucetAccount{Address: address, PrivateKey: key})
+	}
+	return accounts, nil
+}
+
+// parseRPCHeaders parses a comma-separated list of Name:value headers. Errors
+// never repeat a value, since values are usually API keys.
+func parseRPCHeaders(list string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for i, entry := range splitList(list) {
+		name, value, ok := strings.Cut(entry, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("entry %d must be Name:value", i+1)
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers, nil
+}
+
+// RPCHeaderNames returns the names of the RPC headers in sorted order, for
+// logging without their secret values
+func (c *Config) RPCHeaderNames() []string {
+	names := make([]string, 0, len(c.RPCHeaders))
+	for name := range c.RPCHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DiscordEnabled reports whether the Discord slash command is configured
+func (c *Config) DiscordEnabled() bool {
+	return c.DiscordPublicKey != "" && c.DiscordBotToken != ""
+}
+
+// TokenSymbols returns the symbols of all supported tokens in sorted order
+func (c *Config) TokenSymbols() []string {
+	symbols := make([]string, 0, len(c.Tokens))
+	for symbol := range c.Tokens {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// lowBalanceProtectPct is the balance protection below which a drain would
+// leave the faucet almost empty
+const lowBalanceProtectPct = 5
+
+// ProtectionWarnings describes the protections that are off or weak, so an
+// operator running the defaults knows how exposed the faucet is
+func (c *Config) ProtectionWarnings() []string {
+	var warnings []string
+	for _, symbol := range c.TokenSymbols() {
+		token := c.Tokens[symbol]
+		if token.MaxPerHour <= 0 && token.MaxPerDay <= 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"No global distribution limit for %s: nothing caps how much can be drained per hour or day. Set MAX_TOKENS_PER_HOUR_%s or MAX_TOKENS_PER_DAY_%s.",
+				symbol, symbol, symbol))
+		}
+		if token.MinBalanceProtectPct < lowBalanceProtectPct && token.MinBalanceFloor <= 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s balance protection stops at %d%% with no absolute floor, so the faucet can be drained almost empty. Raise MIN_BALANCE_PROTECT_PCT_%s or set MIN_BALANCE_FLOOR_%s.",
+				symbol, token.MinBalanceProtectPct, symbol, symbol))
+		}
+	}
+	if c.AuditRetentionDays > 0 && c.AdminAPIKey == "" {
+		warnings = append(warnings, "Transfers are written to the audit log, but ADMIN_API_KEY is unset, so it can't be queried.")
+	}
+	return warnings
+}
+
+// UsesPublicDevnetKey reports whether the faucet runs with the well-known
+// devnet account key, which must never hold real funds
+func (c *Config) UsesPublicDevnetKey() bool {
+	if c.FaucetPrivateKey == devnetPrivateKey {
+		return true
+	}
+	for _, acc := range c.FaucetAccounts {
+		if acc.PrivateKey == devnetPrivateKey {
+			return true
+		}
+	}
+	return false
+}
+
+// explorerBaseURLs maps each supported explorer to its base URL per network
+var explorerBaseURLs = map[string]map[string]string{
+	"voyager": {
+		"mainnet": "https://voyager.online",
+		"sepolia": "https://sepolia.voyager.online",
+	},
+	"starkscan": {
+		"mainnet": "https://starkscan.co",
+		"sepolia": "https://sepolia.starkscan.co",
+	},
+}
+
+// GetExplorerURL returns the block explorer URL for a transaction, or "" when
+// no explorer covers the configured network (e.g. a local devnet). The hash is
+// zero-padded to 64 hex characters, which some explorers require.
 func (c *Config) GetExplorerURL(txHash string) string {
-	if c.Network == "mainnet" {
-		return fmt.Sprintf("https://voyager.online/tx/%s", txHash)
+	baseURL := c.ExplorerBaseURL
+	if baseURL == "" {
+		baseURL = explorerBaseURLs[c.Explorer][c.Network]
+	}
+	if baseURL == "" {
+		return ""
 	}
-	return fmt.Sprintf("https://sepolia.voyager.online/tx/%s", txHash)
+	return fmt.Sprintf("%s/tx/%s", baseURL, utils.NormalizeTxHash(txHash))
 }
 
 // Helper functions
@@ -138,6 +784,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvAsFloat(key string, defaultValue float64) float64 {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExplorerURL(t *testing.T) {
+	const (
+		txHash = "0xabc"
+		padded = "0x0000000000000000000000000000000000000000000000000000000000000abc"
+	)
+
+	tests := []struct {
+		name     string
+		network  string
+		explorer string
+		baseURL  string
+		want     string
+	}{
+		{"voyager mainnet", "mainnet", "voyager", "", "https://voyager.online/tx/" + padded},
+		{"voyager sepolia", "sepolia", "voyager", "", "https://sepolia.voyager.online/tx/" + padded},
+		{"starkscan mainnet", "mainnet", "starkscan", "", "https://starkscan.co/tx/" + padded},
+		{"starkscan sepolia", "sepolia", "starkscan", "", "https://sepolia.starkscan.co/tx/" + padded},
+		{"devnet has no explorer", "devnet", "voyager", "", ""},
+		{"custom base URL", "devnet", "voyager", "http://localhost:4000", "http://localhost:4000/tx/" + padded},
+		{"custom base URL overrides network", "sepolia", "starkscan", "https://explorer.example.com", "https://explorer.example.com/tx/" + padded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Network: tt.network, Explorer: tt.explorer, ExplorerBaseURL: tt.baseURL}
+			assert.Equal(t, tt.want, c.GetExplorerURL(txHash))
+		})
+	}
+}
+
+func TestLoadDevnetPreset(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://127.0.0.1:5050", cfg.StarknetRPCURL)
+	assert.Equal(t, devnetPrivateKey, cfg.FaucetPrivateKey)
+	assert.NotEmpty(t, cfg.FaucetAddress)
+	assert.Equal(t, defaultSTRKTokenAddress, cfg.STRKTokenAddress)
+	assert.True(t, cfg.UsesPublicDevnetKey())
+	assert.Empty(t, cfg.GetExplorerURL("0xabc"))
+}
+
+func TestLoadDevnetPresetOverrides(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+	t.Setenv("STARKNET_RPC_URL", "http://katana:5050")
+	t.Setenv("FAUCET_PRIVATE_KEY", "0x1234")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://katana:5050", cfg.StarknetRPCURL)
+	assert.Equal(t, "0x1234", cfg.FaucetPrivateKey)
+	assert.False(t, cfg.UsesPublicDevnetKey())
+}
+
+func TestLoadSepoliaRequiresKey(t *testing.T) {
+	t.Setenv("NETWORK", "sepolia")
+	t.Setenv("FAUCET_PRIVATE_KEY", "")
+
+	_, err := Load()
+	assert.ErrorContains(t, err, "FAUCET_PRIVATE_KEY is required")
+}
+
+func TestLoadInvalidDripAmount(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+
+	for _, amount := range []string{"abc", "0", "-1", "NaN", "Inf"} {
+		t.Run(amount, func(t *testing.T) {
+			t.Setenv("DRIP_AMOUNT_STRK", amount)
+			_, err := Load()
+			assert.EqualError(t, err, fmt.Sprintf("DRIP_AMOUNT_STRK must be a positive number (got %q)", amount))
+		})
+	}
+
+	t.Setenv("DRIP_AMOUNT_STRK", "10")
+	t.Setenv("DRIP_AMOUNT_ETH", "0.0")
+	_, err := Load()
+	assert.ErrorContains(t, err, "DRIP_AMOUNT_ETH must be a positive number")
+}
+
+func TestLoadTokenKillSwitch(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Tokens["STRK"].Paused)
+	assert.False(t, cfg.Tokens["ETH"].Paused)
+
+	t.Setenv("ENABLE_ETH", "false")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.Tokens["STRK"].Paused)
+	assert.True(t, cfg.Tokens["ETH"].Paused)
+}
+
+func TestLoadTxVersion(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+
+	t.Setenv("TX_VERSION", "1")
+	t.Setenv("FEE_TOKEN", "ETH")
+	_, err := Load()
+	assert.ErrorContains(t, err, "TX_VERSION=1 is no longer accepted")
+
+	t.Setenv("TX_VERSION", "3")
+	_, err = Load()
+	assert.ErrorContains(t, err, "FEE_TOKEN must be STRK")
+}
+
+func TestLoadRouteTimeouts(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.RouteTimeout)
+	assert.Equal(t, 75, cfg.FaucetRouteTimeout)
+
+	t.Setenv("FAUCET_ROUTE_TIMEOUT", "0")
+	_, err = Load()
+	assert.NoError(t, err, "0 disables the deadline")
+
+	t.Setenv("FAUCET_ROUTE_TIMEOUT", "90")
+	_, err = Load()
+	assert.ErrorContains(t, err, "must be less than WRITE_TIMEOUT")
+
+	t.Setenv("FAUCET_ROUTE_TIMEOUT", "30")
+	_, err = Load()
+	assert.ErrorContains(t, err, "must be greater than RPC_TIMEOUT")
+
+	t.Setenv("FAUCET_ROUTE_TIMEOUT", "75")
+	t.Setenv("ROUTE_TIMEOUT", "-1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "must not be negative")
+}
+
+func TestLoadFaucetRouteTimeoutFollowsWriteTimeout(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+
+	// A shorter WRITE_TIMEOUT pulls the default down with it
+	t.Setenv("WRITE_TIMEOUT", "60")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 52, cfg.FaucetRouteTimeout)
+
+	t.Setenv("WRITE_TIMEOUT", "32")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 31, cfg.FaucetRouteTimeout)
+
+	// No room between RPC_TIMEOUT and WRITE_TIMEOUT turns it off
+	t.Setenv("WRITE_TIMEOUT", "31")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.FaucetRouteTimeout)
+}
+
+func TestParseDripAmount(t *testing.T) {
+	amount, err := ParseDripAmount("0.01")
+	require.NoError(t, err)
+	assert.Equal(t, 0.01, amount)
+
+	_, err = ParseDripAmount("1e400")
+	assert.Error(t, err, "out of range")
+}
+
+func TestLoadReceiptKey(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+	t.Setenv("RECEIPT_SIGNING_KEY", strings.Repeat("01", 32))
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Nil(t, cfg.ReceiptKey, "the key is ignored while receipts are off")
+
+	t.Setenv("SIGN_RECEIPTS", "true")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Len(t, cfg.ReceiptKey, 64)
+
+	t.Setenv("RECEIPT_SIGNING_KEY", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "invalid RECEIPT_SIGNING_KEY (required by SIGN_RECEIPTS)")
+}
+
+func TestLoadFaucetAccounts(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+	t.Setenv("FAUCET_ACCOUNTS", "0x111:0xaaa, 0x222:0xbbb")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, []FaucetAccount{
+		{Address: "0x111", PrivateKey: "0xaaa"},
+		{Address: "0x222", PrivateKey: "0xbbb"},
+	}, cfg.FaucetAccounts)
+	assert.Equal(t, "0x111", cfg.FaucetAddress)
+	assert.False(t, cfg.UsesPublicDevnetKey())
+}
+
+func TestParseFaucetAccountsInvalid(t *testing.T) {
+	for _, list := range []string{"0x111", "0x111:", ":0xaaa", "0x111:0xaaa,0x111:0xbbb"} {
+		_, err := parseFaucetAccounts(list)
+		assert.Error(t, err, list)
+	}
+}
+
+func TestLoadRPCURLs(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+	t.Setenv("STARKNET_RPC_URLS", "http://node-1:5050, http://node-2:5050")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "http://node-1:5050", cfg.StarknetRPCURL)
+	assert.Equal(t, []string{"http://node-2:5050"}, cfg.RPCFallbackURLs)
+}
+
+func TestLoadRPCHeaders(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+	t.Setenv("STARKNET_RPC_HEADERS", "x-api-key: abc:123 , Authorization:Bearer tok")
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Api-Key": "abc:123", "Authorization": "Bearer tok"}, cfg.RPCHeaders)
+	assert.Equal(t, []string{"Authorization", "X-Api-Key"}, cfg.RPCHeaderNames())
+
+	// Errors don't leak the value
+	t.Setenv("STARKNET_RPC_HEADERS", "x-api-key:abc,secretvalue")
+	_, err = Load()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secretvalue")
+}
+
+func TestLoadAuditLog(t *testing.T) {
+	t.Setenv("NETWORK", NetworkDevnet)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.AuditRetentionDays, "off unless enabled")
+
+	t.Setenv("AUDIT_RETENTION_DAYS", "7")
+	_, err = Load()
+	assert.ErrorContains(t, err, "AUDIT_IP_SECRET must be at least")
+
+	t.Setenv("AUDIT_IP_SECRET", "audit-secret-0123456789")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "audit-secret-0123456789", cfg.AuditIPSecret)
+}
+
+func TestProtectionWarnings(t *testing.T) {
+	// The defaults run without distribution limits
+	t.Setenv("NETWORK", NetworkDevnet)
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	warnings := cfg.ProtectionWarnings()
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "No global distribution limit for ETH")
+	assert.Contains(t, warnings[1], "No global distribution limit for STRK")
+
+	// An audit log no one can query
+	t.Setenv("AUDIT_RETENTION_DAYS", "7")
+	t.Setenv("AUDIT_IP_SECRET", "audit-secret-0123456789")
+	cfg, err = Load()
+	require.NoError(t, err)
+	warnings = cfg.ProtectionWarnings()
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[2], "ADMIN_API_KEY is unset")
+
+	t.Setenv("MAX_TOKENS_PER_DAY_STRK", "1000")
+	t.Setenv("MAX_TOKENS_PER_HOUR_ETH", "1")
+	t.Setenv("MIN_BALANCE_PROTECT_PCT_ETH", "1")
+	t.Setenv("AUDIT_RETENTION_DAYS", "0")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ETH balance protection stops at 1% with no absolute floor, so the faucet can be drained almost empty. Raise MIN_BALANCE_PROTECT_PCT_ETH or set MIN_BALANCE_FLOOR_ETH.",
+	}, cfg.ProtectionWarnings())
+
+	// An absolute floor protects the balance on its own
+	t.Setenv("MIN_BALANCE_FLOOR_ETH", "0.5")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.ProtectionWarnings())
+}
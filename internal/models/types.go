@@ -7,43 +7,131 @@ type ChallengeRequest struct{}
 
 // ChallengeResponse represents the response containing a PoW challenge
 type ChallengeResponse struct {
-	ChallengeID string `json:"challenge_id"`
-	Challenge   string `json:"challenge"`
-	Difficulty  int    `json:"difficulty"`
+	ChallengeID     string     `json:"challenge_id"`
+	Challenge       string     `json:"challenge"`
+	Difficulty      int        `json:"difficulty"`
+	TTLSeconds      int        `json:"ttl_seconds,omitempty"`       // How long the challenge stays valid after issue
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`        // Server time after which the challenge is rejected
+	MinSolveSeconds int        `json:"min_solve_seconds,omitempty"` // Earliest a solution may be submitted, in seconds after issue
+	Bonus           bool       `json:"bonus,omitempty"`             // Solving it buys a request past the daily limit
+	Token           string     `json:"token,omitempty"`             // Token the challenge can be redeemed for (omitted = any)
+}
+
+// ChallengeBatchResponse holds several challenges issued in one call. Each is
+// single-use and solved independently.
+type ChallengeBatchResponse struct {
+	Challenges []ChallengeResponse `json:"challenges"`
 }
 
 // FaucetRequest represents a request for tokens from the faucet
 type FaucetRequest struct {
-	Address     string `json:"address" validate:"required"`
-	Token       string `json:"token" validate:"required,oneof=ETH STRK BOTH"`
-	ChallengeID string `json:"challenge_id" validate:"required"`
-	Nonce       int64  `json:"nonce" validate:"required"`
+	Address     string  `json:"address" validate:"required"`
+	Token       string  `json:"token" validate:"required,oneof=ETH STRK BOTH ALL"`
+	ChallengeID string  `json:"challenge_id" validate:"required"`
+	Nonce       *uint64 `json:"nonce" validate:"required"` // Pointer so a missing nonce is distinguishable from a solution of 0
+}
+
+// AuthorizedFaucetRequest is a faucet request signed by the recipient's
+// account, which a relayer submits on their behalf. The signature covers the
+// SNIP-12 typed data built from address, token, nonce and deadline.
+type AuthorizedFaucetRequest struct {
+	Address   string   `json:"address" validate:"required"`
+	Token     string   `json:"token" validate:"required,oneof=ETH STRK BOTH ALL"`
+	Nonce     string   `json:"nonce" validate:"required"`                  // Felt chosen by the signer, usable once
+	Deadline  int64    `json:"deadline" validate:"required"`               // Unix time the authorization expires
+	Signature []string `json:"signature" validate:"required,min=1,max=64"` // Felts, as the account's signer produced them
 }
 
-// FaucetResponse represents the successful response from a faucet request
+// FaucetResponse represents the successful response from a faucet request.
+// Transactions always lists every transfer made, one entry per token, so
+// clients can read it whatever they asked for. The top-level TxHash, Amount,
+// Token and ExplorerURL repeat the single entry of a one-token request and are
+// empty for BOTH and ALL; they remain for older clients.
 type FaucetResponse struct {
-	Success      bool               `json:"success"`
-	TxHash       string             `json:"tx_hash,omitempty"`        // Single token transaction
-	Amount       string             `json:"amount,omitempty"`         // Single token amount
-	Token        string             `json:"token,omitempty"`          // Single token type
-	ExplorerURL  string             `json:"explorer_url,omitempty"`   // Single token explorer URL
-	Message      string             `json:"message"`
-	Transactions []TransactionInfo  `json:"transactions,omitempty"`   // Multiple tokens (when token=BOTH)
+	Success            bool              `json:"success"`
+	TxHash             string            `json:"tx_hash,omitempty"`      // Single token transaction
+	Amount             string            `json:"amount,omitempty"`       // Single token amount
+	Token              string            `json:"token,omitempty"`        // Single token type
+	ExplorerURL        string            `json:"explorer_url,omitempty"` // Single token explorer URL
+	Message            string            `json:"message"`
+	Transactions       []TransactionInfo `json:"transactions,omitempty"`        // One entry per token sent
+	ConfirmationStatus string            `json:"confirmation_status,omitempty"` // Single token finality status when responding
+	BlockNumber        uint64            `json:"block_number,omitempty"`        // Single token block, from the receipt of a ?wait=true request
+	FinalityStatus     string            `json:"finality_status,omitempty"`     // Single token finality, from the receipt of a ?wait=true request
+	Warning            string            `json:"warning,omitempty"`             // Non-fatal notice, e.g. the recipient account isn't deployed yet
+	PausedTokens       []string          `json:"paused_tokens,omitempty"`       // Tokens BOTH or ALL skipped because the operator paused them
 }
 
 // TransactionInfo represents info about a single token transfer
 type TransactionInfo struct {
-	Token       string `json:"token"`
-	Amount      string `json:"amount"`
-	TxHash      string `json:"tx_hash"`
-	ExplorerURL string `json:"explorer_url"`
+	Token              string   `json:"token"`
+	Amount             string   `json:"amount"`
+	TxHash             string   `json:"tx_hash"`
+	ExplorerURL        string   `json:"explorer_url,omitempty"`        // Empty when no explorer covers the network
+	ConfirmationStatus string   `json:"confirmation_status,omitempty"` // Finality status when responding, e.g. RECEIVED
+	BlockNumber        uint64   `json:"block_number,omitempty"`        // Block the transfer was executed in, from the receipt of a ?wait=true request
+	FinalityStatus     string   `json:"finality_status,omitempty"`     // Finality status from the receipt of a ?wait=true request
+	Receipt            *Receipt `json:"receipt,omitempty"`             // Signed by the faucet when SIGN_RECEIPTS is on
+}
+
+// Receipt is the faucet's signed statement that it sent a transfer, so
+// integrations can trust a reported drip without reading the chain
+type Receipt struct {
+	Recipient string `json:"recipient"` // Normalized to 0x + 64 hex digits
+	Token     string `json:"token"`
+	Amount    string `json:"amount"`
+	TxHash    string `json:"tx_hash"`
+	Timestamp int64  `json:"timestamp"` // Unix seconds when the transfer was submitted
+	Signature string `json:"signature"` // Hex Ed25519 signature, checked with InfoResponse.ReceiptPublicKey
+}
+
+// Error codes returned in ErrorResponse.Code so clients can branch without
+// matching on the human-readable message
+const (
+	ErrCodeInvalidRequest    = "INVALID_REQUEST"     // Malformed request body or parameters
+	ErrCodeInvalidAddress    = "INVALID_ADDRESS"     // Recipient address is not a valid Starknet address
+	ErrCodeInvalidToken      = "INVALID_TOKEN"       // Unsupported token symbol
+	ErrCodeRateLimited       = "RATE_LIMITED"        // Per-IP daily limit, cooldown or hourly throttle hit
+	ErrCodeForbidden         = "FORBIDDEN"           // Client IP is blocklisted
+	ErrCodeUnauthorized      = "UNAUTHORIZED"        // X-API-Key header holds an unknown key
+	ErrCodeChallengeInvalid  = "CHALLENGE_INVALID"   // PoW challenge unknown, expired or already used
+	ErrCodePoWInvalid        = "POW_INVALID"         // Submitted nonce does not solve the challenge
+	ErrCodeSignatureInvalid  = "SIGNATURE_INVALID"   // Signed authorization is expired, already used or not signed by the recipient
+	ErrCodeSolvedTooFast     = "SOLVED_TOO_FAST"     // Solution submitted before the minimum solve time
+	ErrCodeInProgress        = "REQUEST_IN_PROGRESS" // Another request from the same IP is still being handled
+	ErrCodeDistributionLimit = "DISTRIBUTION_LIMIT"  // Global hourly/daily distribution cap reached
+	ErrCodeFaucetEmpty       = "FAUCET_EMPTY"        // Faucet balance is below its protection threshold
+	ErrCodeTokenDisabled     = "TOKEN_DISABLED"      // Operator paused distribution of the token
+	ErrCodeFeeInsufficient   = "FEE_INSUFFICIENT"    // Faucet cannot cover the transaction fee
+	ErrCodeTransferFailed    = "TRANSFER_FAILED"     // Transfer transaction could not be submitted
+	ErrCodeRPCTimeout        = "RPC_TIMEOUT"         // Starknet RPC did not respond before the deadline
+	ErrCodeRPCUnavailable    = "RPC_UNAVAILABLE"     // Starknet RPC can't be reached; retry after retry_after_seconds
+	ErrCodeUnavailable       = "SERVICE_UNAVAILABLE" // A backing service (e.g. Redis) is unavailable
+	ErrCodeServerBusy        = "SERVER_BUSY"         // Global request ceiling reached, independent of IP
+	ErrCodeRequestTimeout    = "REQUEST_TIMEOUT"     // Request failed after running past its endpoint's deadline
+	ErrCodeInternal          = "INTERNAL_ERROR"      // Unexpected server-side failure
+)
+
+// ErrorCodes lists every ErrCode* value, for the API description
+var ErrorCodes = []string{
+	ErrCodeInvalidRequest, ErrCodeInvalidAddress, ErrCodeInvalidToken, ErrCodeRateLimited,
+	ErrCodeForbidden, ErrCodeUnauthorized, ErrCodeChallengeInvalid, ErrCodePoWInvalid, ErrCodeSignatureInvalid,
+	ErrCodeSolvedTooFast, ErrCodeInProgress, ErrCodeDistributionLimit,
+	ErrCodeFaucetEmpty, ErrCodeTokenDisabled, ErrCodeFeeInsufficient, ErrCodeTransferFailed,
+	ErrCodeRPCTimeout, ErrCodeRPCUnavailable, ErrCodeUnavailable, ErrCodeServerBusy, ErrCodeRequestTimeout,
+	ErrCodeInternal,
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error           string     `json:"error"`
-	NextRequestTime *time.Time `json:"next_request_time,omitempty"`
-	RemainingHours  *float64   `json:"remaining_hours,omitempty"`
+	Error           string            `json:"error"`
+	Code            string            `json:"code,omitempty"` // One of the ErrCode* constants
+	NextRequestTime *time.Time        `json:"next_request_time,omitempty"`
+	RemainingHours  *float64          `json:"remaining_hours,omitempty"`
+	RequestID       string            `json:"request_id,omitempty"`          // Correlation ID, also sent as X-Request-ID
+	AvailableTokens []string          `json:"available_tokens,omitempty"`    // Tokens that can be requested instead
+	FieldErrors     map[string]string `json:"field_errors,omitempty"`        // Invalid request fields by JSON name
+	RetryAfter      int               `json:"retry_after_seconds,omitempty"` // Also sent as Retry-After
 }
 
 // StatusResponse represents the status of an address
@@ -53,28 +141,129 @@ type StatusResponse struct {
 	LastRequest     *time.Time `json:"last_request,omitempty"`
 	NextRequestTime *time.Time `json:"next_request_time,omitempty"`
 	RemainingHours  *float64   `json:"remaining_hours,omitempty"`
+	ResetAt         *time.Time `json:"reset_at,omitempty"` // When the IP's daily quota resets
+}
+
+// QuotaResponse reports the requesting IP's daily quota and token throttles
+type QuotaResponse struct {
+	DailyLimit     DailyLimitInfo     `json:"daily_limit"`
+	HourlyThrottle HourlyThrottleInfo `json:"hourly_throttle"`
+}
+
+// DailyLimitInfo is the state of an IP's daily quota
+type DailyLimitInfo struct {
+	Total       int        `json:"total"`
+	Used        int        `json:"used"`
+	Remaining   int        `json:"remaining"`
+	CooldownEnd *time.Time `json:"cooldown_end"` // Null unless in cooldown
+	InCooldown  bool       `json:"in_cooldown"`
+	ResetAt     *time.Time `json:"reset_at"` // When the quota resets, null for a rolling window with nothing used
+}
+
+// HourlyThrottleInfo is the hourly throttle of each token for an IP
+type HourlyThrottleInfo struct {
+	STRK TokenThrottle `json:"strk"`
+	ETH  TokenThrottle `json:"eth"`
+}
+
+// TokenThrottle is the hourly throttle of one token
+type TokenThrottle struct {
+	Available     bool       `json:"available"`
+	NextRequestAt *time.Time `json:"next_request_at"` // Null when available
 }
 
 // InfoResponse represents information about the faucet
 type InfoResponse struct {
-	Network      string         `json:"network"`
-	Limits       LimitInfo      `json:"limits"`
-	PoW          PoWInfo        `json:"pow"`
-	FaucetBalance BalanceInfo   `json:"faucet_balance"`
+	Network                 string       `json:"network"`
+	FaucetAddress           string       `json:"faucet_address"`   // Account transfers are sent from, for on-chain verification
+	FaucetAddresses         []string     `json:"faucet_addresses"` // Every account transfers rotate across, FaucetAddress first
+	Limits                  LimitInfo    `json:"limits"`
+	PoW                     PoWInfo      `json:"pow"`
+	FaucetBalance           BalanceInfo  `json:"faucet_balance"`
+	AvailableTokens         []string     `json:"available_tokens"`                    // Tokens that can currently be dispensed
+	EstimatedArrivalSeconds int          `json:"estimated_arrival_seconds,omitempty"` // Typical seconds until tokens arrive, omitted when not configured
+	ReceiptPublicKey        string       `json:"receipt_public_key,omitempty"`        // Hex Ed25519 key that signs transfer receipts, omitted when SIGN_RECEIPTS is off
+	PausedTokens            []string     `json:"paused_tokens,omitempty"`             // Tokens the operator paused, omitted when none are
+	EstimatedFee            *FeeEstimate `json:"estimated_fee,omitempty"`             // Network fee of one drip, omitted when it can't be estimated
+}
+
+// FeeEstimate is the network fee the faucet pays for one transfer, as
+// estimated by its RPC node
+type FeeEstimate struct {
+	Amount string `json:"amount"` // In whole tokens, e.g. "0.000412"
+	Token  string `json:"token"`  // Token the fee is paid in
 }
 
 // LimitInfo contains information about faucet limits
 type LimitInfo struct {
-	StrkPerRequest     string `json:"strk_per_request"`
-	EthPerRequest      string `json:"eth_per_request"`
-	DailyRequestsPerIP int    `json:"daily_requests_per_ip"`
-	TokenThrottleHours int    `json:"token_throttle_hours"`
+	StrkPerRequest      string           `json:"strk_per_request"`
+	EthPerRequest       string           `json:"eth_per_request"`
+	DailyRequestsPerIP  int              `json:"daily_requests_per_ip"` // Shared by all tokens, 1 per token requested
+	TokenThrottleHours  int              `json:"token_throttle_hours"`
+	ChallengesPerHour   int              `json:"challenges_per_hour"`              // PoW challenges one IP can request per hour
+	BonusRequestsPerDay int              `json:"bonus_requests_per_day,omitempty"` // Extra requests bought with bonus challenges
+	Tokens              []TokenLimitInfo `json:"tokens,omitempty"`                 // Per-token limits, in symbol order
+}
+
+// TokenLimitInfo is how often one IP can request a token
+type TokenLimitInfo struct {
+	Symbol           string `json:"symbol"`
+	AmountPerRequest string `json:"amount_per_request"`
+	ThrottleHours    int    `json:"throttle_hours"`   // One request per this many hours
+	RequestsPerDay   int    `json:"requests_per_day"` // At most the IP's daily limit, which all tokens share
+}
+
+// PoWInfoResponse describes the proof of work new challenges need, with an
+// estimate for a typical CPU. Clients that measured their own hash rate can
+// scale it: expected_attempts / hash rate.
+type PoWInfoResponse struct {
+	Difficulty            int     `json:"difficulty"`
+	BonusDifficulty       int     `json:"bonus_difficulty,omitempty"` // Difficulty of bonus challenges (omitted when disabled)
+	Algorithm             string  `json:"algorithm"`                  // Always "sha256", of challenge + decimal nonce
+	ExpectedAttempts      uint64  `json:"expected_attempts"`          // Average hashes to solve, 16^difficulty
+	AssumedHashRate       float64 `json:"assumed_hashrate"`           // Hashes per second of the typical CPU
+	EstimatedSolveSeconds int64   `json:"estimated_solve_seconds"`    // Typical CPU solve time, with a 20% buffer
+}
+
+// PoWVerifyRequest asks whether a nonce solves a challenge at a difficulty
+type PoWVerifyRequest struct {
+	Challenge  string  `json:"challenge" validate:"required"`
+	Nonce      *uint64 `json:"nonce" validate:"required"`
+	Difficulty int     `json:"difficulty" validate:"min=1,max=64"` // Leading hex zeros required
+}
+
+// PoWVerifyResponse reports the hash of a solution and whether it is valid
+type PoWVerifyResponse struct {
+	Valid      bool   `json:"valid"`
+	Hash       string `json:"hash"` // Hex sha256(challenge + decimal nonce)
+	Difficulty int    `json:"difficulty"`
+}
+
+// AuditRecord is one transfer in the audit log
+type AuditRecord struct {
+	Timestamp int64  `json:"timestamp"` // Unix milliseconds
+	RequestID string `json:"request_id"`
+	Token     string `json:"token"`
+	Amount    string `json:"amount"`
+	Recipient string `json:"recipient"`
+	TxHash    string `json:"tx_hash"`
+	IPHash    string `json:"ip_hash"`              // HMAC-SHA256 of the client IP keyed by AUDIT_IP_SECRET, in hex
+	APIKeyID  string `json:"api_key_id,omitempty"` // Set when a trusted API key was used
+}
+
+// AdminRequestsResponse is one page of the audit log, newest first
+type AdminRequestsResponse struct {
+	Requests   []AuditRecord `json:"requests"`
+	Total      int           `json:"total"`                 // Records in the from/to range, before the token and IP filters
+	NextCursor string        `json:"next_cursor,omitempty"` // Pass as ?cursor= for the next page (empty on the last page)
 }
 
 // PoWInfo contains information about PoW requirements
 type PoWInfo struct {
-	Enabled    bool `json:"enabled"`
-	Difficulty int  `json:"difficulty"`
+	Enabled         bool `json:"enabled"`
+	Difficulty      int  `json:"difficulty"`
+	BonusDifficulty int  `json:"bonus_difficulty,omitempty"` // Difficulty of bonus challenges (omitted when disabled)
+	TokenRequired   bool `json:"token_required,omitempty"`   // Challenges must be requested with ?token=
 }
 
 // BalanceInfo contains information about faucet balances
@@ -83,8 +272,58 @@ type BalanceInfo struct {
 	ETH  string `json:"eth"`
 }
 
+// TokensResponse lists the tokens the faucet can distribute
+type TokensResponse struct {
+	Tokens []TokenInfo `json:"tokens"`
+}
+
+// TokenInfo describes a supported token and its limits
+type TokenInfo struct {
+	Symbol          string  `json:"symbol"`
+	ContractAddress string  `json:"contract_address"`
+	Decimals        int     `json:"decimals"`
+	DripAmount      string  `json:"drip_amount"`
+	ThrottleHours   int     `json:"throttle_hours"`
+	MaxPerHour      float64 `json:"max_per_hour"` // Global hourly cap (0 = unlimited)
+	MaxPerDay       float64 `json:"max_per_day"`  // Global daily cap (0 = unlimited)
+	LimitExhausted  bool    `json:"limit_exhausted"`
+	Dispensable     bool    `json:"dispensable"` // Not paused, within distribution limits and above balance protection
+	Paused          bool    `json:"paused"`      // The operator paused the token (ENABLE_<SYMBOL>=false)
+}
+
+// DistributionSeriesResponse is how much of a token the faucet sent over a
+// window, in equal buckets for charting
+type DistributionSeriesResponse struct {
+	Token         string              `json:"token"`
+	Window        string              `json:"window"`         // e.g. 24h0m0s
+	BucketSeconds int                 `json:"bucket_seconds"` // 60 for windows up to 2h, else 3600
+	Buckets       []DistributionPoint `json:"buckets"`        // Oldest first; the last is still filling
+}
+
+// DistributionPoint is what was sent in one bucket of a distribution series
+type DistributionPoint struct {
+	Start  time.Time `json:"start"`
+	Amount float64   `json:"amount"` // Tokens sent
+	Drips  int64     `json:"drips"`  // Transfers that sent them
+}
+
 // HealthResponse represents the health status of the API
 type HealthResponse struct {
 	Status    string `json:"status"`
 	Timestamp int64  `json:"timestamp"`
 }
+
+// FullHealthResponse represents the health of the API and each of its dependencies
+type FullHealthResponse struct {
+	Status    string                      `json:"status"` // "ok" or "degraded"
+	Timestamp int64                       `json:"timestamp"`
+	Checks    map[string]DependencyStatus `json:"checks"`
+}
+
+// DependencyStatus represents the result of checking a single dependency
+type DependencyStatus struct {
+	Status    string `json:"status"` // "ok" or "error"
+	LatencyMs int64  `json:"latency_ms"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
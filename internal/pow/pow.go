@@ -5,6 +5,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,6 +37,12 @@ func NewGenerator(difficulty int, ttlSeconds int) *Generator {
 
 // GenerateChallenge creates a new PoW challenge
 func (g *Generator) GenerateChallenge() (*models.ChallengeResponse, *Challenge, error) {
+	return g.GenerateChallengeAt(g.difficulty)
+}
+
+// GenerateChallengeAt creates a new PoW challenge at a difficulty other than
+// the generator's, e.g. raised while the faucet is under load
+func (g *Generator) GenerateChallengeAt(difficulty int) (*models.ChallengeResponse, *Challenge, error) {
 	// Generate random challenge string
 	challengeBytes := make([]byte, 32)
 	if _, err := rand.Read(challengeBytes); err != nil {
@@ -50,34 +58,54 @@ func (g *Generator) GenerateChallenge() (*models.ChallengeResponse, *Challenge,
 	challenge := &Challenge{
 		ID:         hex.EncodeToString(idBytes),
 		Challenge:  hex.EncodeToString(challengeBytes),
-		Difficulty: g.difficulty,
+		Difficulty: difficulty,
 		CreatedAt:  time.Now(),
 	}
 
+	expiresAt := challenge.CreatedAt.Add(g.ttl)
 	response := &models.ChallengeResponse{
 		ChallengeID: challenge.ID,
 		Challenge:   challenge.Challenge,
 		Difficulty:  challenge.Difficulty,
+		TTLSeconds:  int(g.ttl.Seconds()),
+		ExpiresAt:   &expiresAt,
 	}
 
 	return response, challenge, nil
 }
 
+// maxAttemptsFactor is how many times the expected number of attempts a solve
+// may take before giving up. Missing a solution in that many is vanishingly unlikely.
+const maxAttemptsFactor = 64
+
 // VerifyPoW verifies a PoW solution
-func (g *Generator) VerifyPoW(challenge string, nonce int64, difficulty int) bool {
+func (g *Generator) VerifyPoW(challenge string, nonce uint64, difficulty int) bool {
 	// Ensure difficulty matches
 	if difficulty != g.difficulty {
 		return false
 	}
 
-	// Compute hash
-	data := fmt.Sprintf("%s%d", challenge, nonce)
-	hash := sha256.Sum256([]byte(data))
-	hashHex := hex.EncodeToString(hash[:])
+	return Solves(challenge, nonce, difficulty)
+}
 
-	// Check leading zeros
+// Solves reports whether nonce solves challenge at difficulty, i.e. whether
+// Hash(challenge, nonce) starts with difficulty zeros
+func Solves(challenge string, nonce uint64, difficulty int) bool {
 	prefix := strings.Repeat("0", difficulty)
-	return strings.HasPrefix(hashHex, prefix)
+	return strings.HasPrefix(hashHex(challenge, nonce), prefix)
+}
+
+// Hash returns the hex SHA256 a solution is judged by:
+// sha256(challenge + decimal nonce), e.g. sha256("ab12" + "42")
+func Hash(challenge string, nonce uint64) string {
+	return hashHex(challenge, nonce)
+}
+
+// hashHex returns the hex SHA256 of the challenge followed by the nonce in
+// decimal. The CLI solver hashes the same input.
+func hashHex(challenge string, nonce uint64) string {
+	hash := sha256.Sum256([]byte(challenge + strconv.FormatUint(nonce, 10)))
+	return hex.EncodeToString(hash[:])
 }
 
 // IsExpired checks if a challenge has expired
@@ -86,48 +114,67 @@ func (g *Generator) IsExpired(createdAt time.Time) bool {
 }
 
 // SolveChallenge solves a PoW challenge (used by CLI)
-func SolveChallenge(challenge string, difficulty int, progressCallback func(int64)) (int64, error) {
+func SolveChallenge(challenge string, difficulty int, progressCallback func(uint64)) (uint64, error) {
 	prefix := strings.Repeat("0", difficulty)
+	maxAttempts := MaxAttempts(difficulty)
 
-	var nonce int64
-	for {
-		data := fmt.Sprintf("%s%d", challenge, nonce)
-		hash := sha256.Sum256([]byte(data))
-		hashHex := hex.EncodeToString(hash[:])
-
-		if strings.HasPrefix(hashHex, prefix) {
+	for nonce := uint64(0); nonce < maxAttempts; nonce++ {
+		if strings.HasPrefix(hashHex(challenge, nonce), prefix) {
 			return nonce, nil
 		}
 
-		nonce++
-
 		// Call progress callback every 10000 iterations
-		if progressCallback != nil && nonce%10000 == 0 {
-			progressCallback(nonce)
+		if progressCallback != nil && (nonce+1)%10000 == 0 {
+			progressCallback(nonce + 1)
 		}
+	}
 
-		// Safety check - shouldn't happen with difficulty 4
-		if nonce > 100000000 {
-			return 0, fmt.Errorf("failed to solve challenge after %d attempts", nonce)
-		}
+	return 0, fmt.Errorf("failed to solve challenge after %d attempts", maxAttempts)
+}
+
+// MaxAttempts returns how many nonces a solver tries before giving up:
+// maxAttemptsFactor times the expected attempts, so the cap grows with
+// difficulty. It saturates at math.MaxUint64. The CLI solver uses it too.
+func MaxAttempts(difficulty int) uint64 {
+	expected := ExpectedAttempts(difficulty)
+	if expected > math.MaxUint64/maxAttemptsFactor {
+		return math.MaxUint64
 	}
+	return expected * maxAttemptsFactor
 }
 
-// EstimateSolveTime estimates how long it will take to solve a challenge
-func EstimateSolveTime(difficulty int) time.Duration {
-	// Rough estimate: 16^difficulty attempts on average
-	// Assuming ~1M hashes per second on average CPU
-	attempts := 1
+// Algorithm names the hash a solution is judged by, see Hash
+const Algorithm = "sha256"
+
+// AssumedHashRate is the hashes per second of the typical CPU
+// EstimateSolveTime assumes. It is conservative, so most machines are faster.
+const AssumedHashRate = 500000
+
+// ExpectedAttempts returns the average number of hashes needed to solve a
+// challenge: each leading hex zero is a 1 in 16 chance. It saturates at
+// math.MaxUint64 for difficulties of 16 and above.
+func ExpectedAttempts(difficulty int) uint64 {
+	attempts := uint64(1)
 	for i := 0; i < difficulty; i++ {
+		if attempts > math.MaxUint64/16 {
+			return math.MaxUint64
+		}
 		attempts *= 16
 	}
+	return attempts
+}
 
-	// Assume 500k hashes per second (conservative)
-	hashesPerSecond := 500000
-	seconds := attempts / hashesPerSecond
-
+// EstimateSolveTime estimates how long it will take to solve a challenge on a
+// typical CPU, rounded down to the second and never under one. It saturates
+// at the longest time.Duration.
+func EstimateSolveTime(difficulty int) time.Duration {
 	// Add 20% buffer
-	seconds = int(float64(seconds) * 1.2)
-
-	return time.Duration(seconds) * time.Second
+	seconds := float64(ExpectedAttempts(difficulty)) / AssumedHashRate * 1.2
+	if seconds < 1 {
+		return time.Second
+	}
+	if seconds >= float64(math.MaxInt64/int64(time.Second)) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second)).Truncate(time.Second)
 }
@@ -1,6 +1,10 @@
 package pow
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +36,9 @@ func TestGenerateChallenge(t *testing.T) {
 	assert.NotEmpty(t, resp.ChallengeID)
 	assert.NotEmpty(t, resp.Challenge)
 	assert.Equal(t, 4, resp.Difficulty)
+	assert.Equal(t, 300, resp.TTLSeconds)
+	require.NotNil(t, resp.ExpiresAt)
+	assert.Equal(t, challenge.CreatedAt.Add(300*time.Second), *resp.ExpiresAt)
 
 	// Check challenge
 	assert.NotEmpty(t, challenge.ID)
@@ -52,7 +59,7 @@ func TestVerifyPoW(t *testing.T) {
 	tests := []struct {
 		name       string
 		challenge  string
-		nonce      int64
+		nonce      uint64
 		difficulty int
 		want       bool
 	}{
@@ -127,7 +134,7 @@ func TestSolveChallenge(t *testing.T) {
 	nonce, err := SolveChallenge(challenge, difficulty, nil)
 
 	require.NoError(t, err)
-	assert.Greater(t, nonce, int64(0))
+	assert.Greater(t, nonce, uint64(0))
 
 	// Verify the solution
 	gen := NewGenerator(difficulty, 300)
@@ -139,18 +146,48 @@ func TestSolveChallengeWithCallback(t *testing.T) {
 	difficulty := 2
 
 	callbackCalled := false
-	callback := func(n int64) {
+	callback := func(n uint64) {
 		callbackCalled = true
-		assert.Greater(t, n, int64(0))
+		assert.Greater(t, n, uint64(0))
 	}
 
 	nonce, err := SolveChallenge(challenge, difficulty, callback)
 
 	require.NoError(t, err)
-	assert.Greater(t, nonce, int64(0))
+	assert.Greater(t, nonce, uint64(0))
 	assert.True(t, callbackCalled, "Callback should have been called")
 }
 
+func TestVerifyPoWLargeNonce(t *testing.T) {
+	// Nonces above MaxInt64 are hashed as unsigned decimal, not wrapped negative
+	const nonce = uint64(math.MaxUint64)
+	hash := sha256.Sum256([]byte("test123" + "18446744073709551615"))
+	hashHex := hex.EncodeToString(hash[:])
+	zeros := len(hashHex) - len(strings.TrimLeft(hashHex, "0"))
+
+	assert.True(t, NewGenerator(zeros, 300).VerifyPoW("test123", nonce, zeros))
+	assert.False(t, NewGenerator(zeros+1, 300).VerifyPoW("test123", nonce, zeros+1))
+}
+
+func TestHash(t *testing.T) {
+	// The hashing contract: sha256 of the challenge followed by the decimal nonce
+	assert.Equal(t, "8216ac77f5c9ed66907b03f1d78b795e7cdd315cecd13df42cd7245d45e16b06", Hash("abc", 42))
+
+	nonce, err := SolveChallenge("abc", 2, nil)
+	require.NoError(t, err)
+	assert.True(t, Solves("abc", nonce, 2))
+	assert.True(t, strings.HasPrefix(Hash("abc", nonce), "00"))
+	assert.False(t, Solves("abc", 42, 1), "8216... has no leading zero")
+}
+
+func TestMaxAttempts(t *testing.T) {
+	assert.Equal(t, uint64(64), MaxAttempts(0))
+	assert.Equal(t, uint64(64*65536), MaxAttempts(4))
+	assert.Greater(t, MaxAttempts(10), uint64(100000000))
+	assert.Equal(t, uint64(math.MaxUint64), MaxAttempts(15))
+	assert.Equal(t, uint64(math.MaxUint64), MaxAttempts(20))
+}
+
 func TestEstimateSolveTime(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -168,10 +205,20 @@ func TestEstimateSolveTime(t *testing.T) {
 			assert.Greater(t, estimate, time.Duration(0))
 		})
 	}
+
+	// 16^6 hashes at 500k/s, plus 20%
+	assert.Equal(t, 40*time.Second, EstimateSolveTime(6))
+	assert.Equal(t, time.Duration(math.MaxInt64), EstimateSolveTime(20))
+}
+
+func TestExpectedAttempts(t *testing.T) {
+	assert.Equal(t, uint64(1), ExpectedAttempts(0))
+	assert.Equal(t, uint64(65536), ExpectedAttempts(4))
+	assert.Equal(t, uint64(math.MaxUint64), ExpectedAttempts(16))
 }
 
 // Helper function to find a valid nonce for testing
-func findValidNonce(challenge string, difficulty int) int64 {
+func findValidNonce(challenge string, difficulty int) uint64 {
 	nonce, _ := SolveChallenge(challenge, difficulty, nil)
 	return nonce
 }
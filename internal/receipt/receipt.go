@@ -0,0 +1,80 @@
+// Package receipt signs and verifies the receipts the faucet attaches to
+// transfers when SIGN_RECEIPTS is on
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+)
+
+// messagePrefix starts every signed message, so a receipt signature can't be
+// mistaken for a signature over anything else
+const messagePrefix = "starknet-faucet receipt v1"
+
+// ErrInvalidSignature means a receipt wasn't signed by the key, or was
+// changed after signing
+var ErrInvalidSignature = errors.New("receipt signature is invalid")
+
+// Message returns the bytes a receipt's signature covers: the prefix and each
+// field on its own line
+func Message(r models.Receipt) []byte {
+	return []byte(strings.Join([]string{
+		messagePrefix,
+		r.Recipient,
+		r.Token,
+		r.Amount,
+		r.TxHash,
+		strconv.FormatInt(r.Timestamp, 10),
+	}, "\n"))
+}
+
+// Sign sets the signature of r with key
+func Sign(key ed25519.PrivateKey, r *models.Receipt) {
+	r.Signature = hex.EncodeToString(ed25519.Sign(key, Message(*r)))
+}
+
+// Verify checks the signature of r against a hex Ed25519 public key, as
+// served in InfoResponse.ReceiptPublicKey
+func Verify(publicKey string, r models.Receipt) error {
+	key, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("public key must be %d hex-encoded bytes", ed25519.PublicKeySize)
+	}
+	signature, err := hex.DecodeString(r.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: signature must be %d hex-encoded bytes", ErrInvalidSignature, ed25519.SignatureSize)
+	}
+	if !ed25519.Verify(key, Message(r), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseKey parses a hex Ed25519 private key, given as its 32-byte seed or the
+// full 64-byte key
+func ParseKey(s string) (ed25519.PrivateKey, error) {
+	key, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, errors.New("key must be hex-encoded")
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("key must be a %d-byte seed or a %d-byte private key (got %d bytes)",
+			ed25519.SeedSize, ed25519.PrivateKeySize, len(key))
+	}
+}
+
+// PublicKey returns the hex public key of key, for clients to verify with
+func PublicKey(key ed25519.PrivateKey) string {
+	return hex.EncodeToString(key.Public().(ed25519.PublicKey))
+}
@@ -0,0 +1,70 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+)
+
+func TestSignVerify(t *testing.T) {
+	key, err := ParseKey(strings.Repeat("01", ed25519.SeedSize))
+	require.NoError(t, err)
+	publicKey := PublicKey(key)
+
+	r := models.Receipt{
+		Recipient: "0x0000000000000000000000000000000000000000000000000742d469482a89e7",
+		Token:     "STRK",
+		Amount:    "10",
+		TxHash:    "0xabc",
+		Timestamp: 1767225600,
+	}
+	Sign(key, &r)
+	require.NoError(t, Verify(publicKey, r))
+	require.NoError(t, Verify("0x"+publicKey, r))
+
+	// Every field is covered
+	changed := map[string]func(r *models.Receipt){
+		"recipient": func(r *models.Receipt) { r.Recipient = "0x1" },
+		"token":     func(r *models.Receipt) { r.Token = "ETH" },
+		"amount":    func(r *models.Receipt) { r.Amount = "100" },
+		"tx hash":   func(r *models.Receipt) { r.TxHash = "0xabd" },
+		"timestamp": func(r *models.Receipt) { r.Timestamp++ },
+	}
+	for name, change := range changed {
+		t.Run(name, func(t *testing.T) {
+			tampered := r
+			change(&tampered)
+			assert.ErrorIs(t, Verify(publicKey, tampered), ErrInvalidSignature)
+		})
+	}
+
+	other, err := ParseKey(strings.Repeat("02", ed25519.SeedSize))
+	require.NoError(t, err)
+	assert.ErrorIs(t, Verify(PublicKey(other), r), ErrInvalidSignature, "signed by another key")
+
+	malformed := r
+	malformed.Signature = "zz"
+	assert.ErrorIs(t, Verify(publicKey, malformed), ErrInvalidSignature)
+	assert.Error(t, Verify("abcd", r))
+}
+
+func TestParseKey(t *testing.T) {
+	seed := strings.Repeat("01", ed25519.SeedSize)
+	fromSeed, err := ParseKey(seed)
+	require.NoError(t, err)
+
+	full, err := ParseKey(hex.EncodeToString(fromSeed))
+	require.NoError(t, err)
+	assert.Equal(t, fromSeed, full)
+
+	for _, bad := range []string{"", "xyz", "0102"} {
+		_, err := ParseKey(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,125 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/typeddata"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// Authorization is a user's signed request for faucet tokens, which a relayer
+// can submit on their behalf. The recipient's account signs it as SNIP-12
+// typed data (revision 1), Starknet's counterpart of EIP-712.
+type Authorization struct {
+	Recipient string // Account that signs the authorization and receives the tokens
+	Token     string // STRK, ETH, BOTH or ALL
+	Nonce     string // Felt chosen by the signer, usable once
+	Deadline  int64  // Unix time after which the authorization is refused
+}
+
+// AuthorizationDomain is the SNIP-12 domain name authorizations are signed under
+const AuthorizationDomain = "Starknet Faucet"
+
+// ErrAccountNotDeployed is returned by IsValidSignature when no account
+// contract is deployed at the signer's address to check the signature
+var ErrAccountNotDeployed = errors.New("no account is deployed at the address")
+
+// snip6Valid is what SNIP-6 accounts return from is_valid_signature for a
+// valid signature: the short string 'VALID'
+var snip6Valid = new(felt.Felt).SetBytes([]byte("VALID"))
+
+// AuthorizationTypedData returns the SNIP-12 typed data a wallet signs for
+// auth on the chain with the given ID, e.g. SN_SEPOLIA
+func AuthorizationTypedData(chainID string, auth Authorization) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"types": map[string]any{
+			"StarknetDomain": []map[string]string{
+				{"name": "name", "type": "shortstring"},
+				{"name": "version", "type": "shortstring"},
+				{"name": "chainId", "type": "shortstring"},
+				{"name": "revision", "type": "shortstring"},
+			},
+			"FaucetAuthorization": []map[string]string{
+				{"name": "recipient", "type": "ContractAddress"},
+				{"name": "token", "type": "shortstring"},
+				{"name": "nonce", "type": "felt"},
+				{"name": "deadline", "type": "timestamp"},
+			},
+		},
+		"primaryType": "FaucetAuthorization",
+		"domain": map[string]string{
+			"name":     AuthorizationDomain,
+			"version":  "1",
+			"chainId":  chainID,
+			"revision": "1",
+		},
+		"message": map[string]string{
+			"recipient": auth.Recipient,
+			"token":     auth.Token,
+			"nonce":     auth.Nonce,
+			"deadline":  strconv.FormatInt(auth.Deadline, 10),
+		},
+	})
+}
+
+// AuthorizationHash returns the SNIP-12 message hash the recipient's account
+// signs for auth
+func AuthorizationHash(chainID string, auth Authorization) (*felt.Felt, error) {
+	raw, err := AuthorizationTypedData(chainID, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	var td typeddata.TypedData
+	if err := json.Unmarshal(raw, &td); err != nil {
+		return nil, fmt.Errorf("invalid authorization: %w", err)
+	}
+	hash, err := td.GetMessageHash(auth.Recipient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization: %w", err)
+	}
+	return hash, nil
+}
+
+// IsValidSignature asks the account contract at address whether signature
+// signs hash, through the SNIP-6 is_valid_signature entrypoint, so any account
+// type (OpenZeppelin, Argent, Braavos) is checked by its own rules. A signature
+// the account rejects is not an error. It returns ErrAccountNotDeployed when
+// there is no account to ask.
+func (fc *FaucetClient) IsValidSignature(ctx context.Context, address string, hash *felt.Felt, signature []*felt.Felt) (bool, error) {
+	addr, err := utils.HexToFelt(address)
+	if err != nil {
+		return false, fmt.Errorf("invalid address: %w", err)
+	}
+
+	calldata := []*felt.Felt{hash, new(felt.Felt).SetUint64(uint64(len(signature)))}
+	calldata = append(calldata, signature...)
+
+	result, err := fc.provider.Call(ctx, rpc.FunctionCall{
+		ContractAddress:    addr,
+		EntryPointSelector: utils.GetSelectorFromNameFelt("is_valid_signature"),
+		Calldata:           calldata,
+	}, rpc.BlockID{Tag: "latest"})
+	if err != nil {
+		var rpcErr *rpc.RPCError
+		if errors.As(err, &rpcErr) {
+			switch rpcErr.Code {
+			case rpc.ErrContractNotFound.Code:
+				return false, ErrAccountNotDeployed
+			case rpc.ErrContractError.Code, rpc.ErrEntrypointNotFound.Code:
+				// Accounts may revert on a bad signature rather than answer
+				return false, nil
+			}
+		}
+		return false, wrapRPCError(ctx, "failed to check signature", err)
+	}
+
+	// Older accounts answer 1 instead of 'VALID'
+	return len(result) > 0 && (result[0].Equal(snip6Valid) || result[0].IsOne()), nil
+}
@@ -0,0 +1,140 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuthorizationHash(t *testing.T) {
+	auth := Authorization{
+		Recipient: "0x0742d469482a89e7",
+		Token:     "STRK",
+		Nonce:     "0x1",
+		Deadline:  1767225600,
+	}
+
+	hash, err := AuthorizationHash("SN_SEPOLIA", auth)
+	require.NoError(t, err)
+	again, err := AuthorizationHash("SN_SEPOLIA", auth)
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	// Every field and the network are signed
+	changed := map[string]func(a *Authorization) string{
+		"recipient": func(a *Authorization) string { a.Recipient = "0x0742d469482a89e8"; return "SN_SEPOLIA" },
+		"token":     func(a *Authorization) string { a.Token = "ETH"; return "SN_SEPOLIA" },
+		"nonce":     func(a *Authorization) string { a.Nonce = "0x2"; return "SN_SEPOLIA" },
+		"deadline":  func(a *Authorization) string { a.Deadline++; return "SN_SEPOLIA" },
+		"chain":     func(a *Authorization) string { return "SN_MAIN" },
+	}
+	for name, change := range changed {
+		t.Run(name, func(t *testing.T) {
+			other := auth
+			chainID := change(&other)
+			otherHash, err := AuthorizationHash(chainID, other)
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, otherHash)
+		})
+	}
+
+	_, err = AuthorizationHash("SN_SEPOLIA", Authorization{Recipient: "not-an-address", Token: "STRK", Nonce: "0x1"})
+	assert.Error(t, err)
+}
+
+func TestAuthorizationTypedData(t *testing.T) {
+	raw, err := AuthorizationTypedData("SN_SEPOLIA", Authorization{Recipient: "0x123", Token: "ETH", Nonce: "0x7", Deadline: 1767225600})
+	require.NoError(t, err)
+
+	var doc struct {
+		PrimaryType string            `json:"primaryType"`
+		Domain      map[string]string `json:"domain"`
+		Message     map[string]string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	assert.Equal(t, "FaucetAuthorization", doc.PrimaryType)
+	assert.Equal(t, map[string]string{"name": AuthorizationDomain, "version": "1", "chainId": "SN_SEPOLIA", "revision": "1"}, doc.Domain)
+	assert.Equal(t, map[string]string{"recipient": "0x123", "token": "ETH", "nonce": "0x7", "deadline": "1767225600"}, doc.Message)
+}
+
+func TestIsValidSignature(t *testing.T) {
+	// The account answers with the result or error for its address
+	answers := map[string]string{
+		"0x1": `"result":["0x56414c4944"]`,
+		"0x2": `"result":["0x1"]`,
+		"0x3": `"result":["0x0"]`,
+		"0x4": `"error":{"code":40,"message":"Contract error","data":{"revert_error":"argent/invalid-signature"}}`,
+		"0x5": `"error":{"code":20,"message":"Contract not found"}`,
+		"0x6": `"error":{"code":-32603,"message":"Internal error"}`,
+	}
+	var calldata []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []json.RawMessage
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		if req.Method != "starknet_call" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0.9.0"}`, req.ID)
+			return
+		}
+		var call struct {
+			ContractAddress string   `json:"contract_address"`
+			Calldata        []string `json:"calldata"`
+		}
+		require.NoError(t, json.Unmarshal(req.Params[0], &call))
+		calldata = call.Calldata
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,%s}`, req.ID, answers[call.ContractAddress])
+	}))
+	defer server.Close()
+
+	provider, err := rpc.NewProvider(context.Background(), server.URL)
+	require.NoError(t, err)
+	fc := &FaucetClient{provider: provider, logger: zap.NewNop()}
+	ctx := context.Background()
+	hash := new(felt.Felt).SetUint64(0xabc)
+	sig := func(parts ...uint64) []*felt.Felt {
+		felts := make([]*felt.Felt, len(parts))
+		for i, p := range parts {
+			felts[i] = new(felt.Felt).SetUint64(p)
+		}
+		return felts
+	}
+
+	valid, err := fc.IsValidSignature(ctx, "0x1", hash, sig(0x11, 0x22))
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, []string{"0xabc", "0x2", "0x11", "0x22"}, calldata, "hash, then the signature as an array")
+
+	valid, err = fc.IsValidSignature(ctx, "0x2", hash, sig(0x11))
+	require.NoError(t, err)
+	assert.True(t, valid, "older accounts answer 1")
+
+	valid, err = fc.IsValidSignature(ctx, "0x3", hash, sig(0x11))
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = fc.IsValidSignature(ctx, "0x4", hash, sig(0x11))
+	require.NoError(t, err)
+	assert.False(t, valid, "a revert rejects the signature")
+
+	_, err = fc.IsValidSignature(ctx, "0x5", hash, sig(0x11))
+	assert.ErrorIs(t, err, ErrAccountNotDeployed)
+
+	_, err = fc.IsValidSignature(ctx, "0x6", hash, sig(0x11))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrAccountNotDeployed)
+
+}
@@ -2,54 +2,203 @@ package starknet
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/starknet.go/account"
+	rpcclient "github.com/NethermindEth/starknet.go/client"
+	"github.com/NethermindEth/starknet.go/client/rpcerr"
+	"github.com/NethermindEth/starknet.go/curve"
 	"github.com/NethermindEth/starknet.go/rpc"
 	"github.com/NethermindEth/starknet.go/utils"
+	"go.uber.org/zap"
 )
 
-// FaucetClient handles Starknet blockchain interactions
+// TxVersionV3 is the only invoke transaction version RPC providers accept;
+// its fees are paid in STRK
+const TxVersionV3 = 3
+
+// feeMultiplier is the safety margin applied to estimated resource bounds
+// so fee spikes between estimation and inclusion don't revert the transfer
+const feeMultiplier = 1.5
+
+// publicKeySelectors are the entrypoints account contracts commonly use to
+// expose their signer's public key (OpenZeppelin, legacy OpenZeppelin, Argent)
+var publicKeySelectors = []string{"get_public_key", "getPublicKey", "get_owner"}
+
+// ErrNoFundedAccount is returned by TransferTokens when no faucet account can
+// pay for the transfer and its fee and still keep its protected balance
+var ErrNoFundedAccount = errors.New("no faucet account has enough balance for the transfer")
+
+// errAccountUnderfunded means one faucet account can't afford a transfer, so
+// the next one is tried
+var errAccountUnderfunded = errors.New("faucet account balance too low")
+
+// BalanceProtection is the balance of a token each faucet account keeps: the
+// larger of Pct percent of its balance and Floor
+type BalanceProtection struct {
+	Pct   int
+	Floor *big.Int // nil for none
+}
+
+// ErrPublicKeyUnavailable is returned by VerifyAccount when the account contract
+// doesn't expose its public key, so the private key could not be checked
+var ErrPublicKeyUnavailable = errors.New("account contract does not expose its public key")
+
+// TransportConfig tunes the HTTP connection pool used for RPC calls. Zero
+// values keep Go's defaults.
+type TransportConfig struct {
+	MaxIdleConns        int // Idle connections kept across all hosts
+	MaxIdleConnsPerHost int // Idle connections kept per host (Go's default of 2 throttles a busy faucet)
+	MaxConnsPerHost     int // Cap on connections per host, 0 = unlimited
+
+	// Headers are sent with every RPC request to the RPC URL, e.g. a provider
+	// API key. Fallback URLs don't get them.
+	Headers map[string]string
+
+	// FallbackURLs are tried in order when the RPC URL can't be reached
+	FallbackURLs []string
+}
+
+// headerTransport adds fixed headers to each request it sends
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Confirmation levels a transfer can be waited for, in increasing finality
+const (
+	ConfirmationReceived     = string(rpc.TxnStatusReceived)     // Accepted into the mempool
+	ConfirmationPreConfirmed = string(rpc.TxnStatusPreConfirmed) // Executed in the block being built
+	ConfirmationAcceptedOnL2 = string(rpc.TxnStatusAcceptedOnL2) // Included in an L2 block
+)
+
+// finalityRank orders finality statuses; unknown statuses rank 0
+var finalityRank = map[string]int{
+	string(rpc.TxnStatusReceived):     1,
+	string(rpc.TxnStatusCandidate):    2,
+	string(rpc.TxnStatusPreConfirmed): 3,
+	string(rpc.TxnStatusAcceptedOnL2): 4,
+	string(rpc.TxnStatusAcceptedOnL1): 5,
+}
+
+// IsConfirmationLevel reports whether level is one of the Confirmation* levels
+func IsConfirmationLevel(level string) bool {
+	switch level {
+	case ConfirmationReceived, ConfirmationPreConfirmed, ConfirmationAcceptedOnL2:
+		return true
+	}
+	return false
+}
+
+// HasReceipt reports whether a transaction with finality status has been
+// executed, so its receipt can be fetched
+func HasReceipt(status string) bool {
+	return reachesConfirmation(status, ConfirmationPreConfirmed)
+}
+
+// reachesConfirmation reports whether a transaction with finality status has
+// reached level
+func reachesConfirmation(status, level string) bool {
+	return finalityRank[status] > 0 && finalityRank[status] >= finalityRank[level]
+}
+
+// AccountCredentials identifies a faucet account and the key that signs for it
+type AccountCredentials struct {
+	Address    string
+	PrivateKey string
+}
+
+// faucetAccount is one of the accounts transfers are sent from
+type faucetAccount struct {
+	account   *account.Account
+	publicKey *felt.Felt // Derived from the configured private key
+
+	// mu serializes transactions from this account. Each one reads the
+	// account nonce, so two in flight at once would collide.
+	mu sync.Mutex
+}
+
+// FaucetClient handles Starknet blockchain interactions. Transfers are spread
+// round-robin across its accounts so each account's nonce only has to keep up
+// with a share of the traffic.
 type FaucetClient struct {
-	account     *account.Account
+	accounts    []*faucetAccount
+	next        atomic.Uint64 // Round-robin position in accounts
 	provider    *rpc.Provider
+	wsProvider  *rpc.WsProvider    // Set by ConnectWebsocket, nil when polling for receipts
+	endpoints   *failoverTransport // nil without fallback RPC URLs
+	rpcHeaders  http.Header        // Also sent when the websocket connects
 	ethAddress  *felt.Felt
 	strkAddress *felt.Felt
+	txVersion   int
+	feeToken    string
+	protection  map[string]BalanceProtection // Per token, kept by every account
+	logger      *zap.Logger
+
+	accountCache accountCache // IsAccount answers per address
 }
 
-// NewFaucetClient creates a new Starknet faucet client
+// NewFaucetClient creates a new Starknet faucet client with a single account
 func NewFaucetClient(rpcURL, privateKey, accountAddress, ethTokenAddr, strkTokenAddr string) (*FaucetClient, error) {
+	return NewMultiAccountFaucetClient(rpcURL, []AccountCredentials{
+		{Address: accountAddress, PrivateKey: privateKey},
+	}, ethTokenAddr, strkTokenAddr, TransportConfig{})
+}
+
+// NewMultiAccountFaucetClient creates a Starknet faucet client that sends
+// transfers from each of accounts in turn, over a connection pool tuned by transport
+func NewMultiAccountFaucetClient(rpcURL string, accounts []AccountCredentials, ethTokenAddr, strkTokenAddr string, transport TransportConfig) (*FaucetClient, error) {
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("at least one faucet account is required")
+	}
+
 	ctx := context.Background()
 
-	// Initialize RPC provider
-	provider, err := rpc.NewProvider(ctx, rpcURL)
+	httpClient, err := newHTTPClient(transport)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create provider: %w", err)
+		return nil, err
 	}
 
-	// Parse private key
-	privKeyBI, ok := new(big.Int).SetString(privateKey, 0)
-	if !ok {
-		return nil, fmt.Errorf("invalid private key format")
+	var endpoints *failoverTransport
+	if len(transport.FallbackURLs) > 0 {
+		if endpoints, err = newFailoverTransport(httpClient.Transport, rpcURL, transport.FallbackURLs, rpcHeaders(transport.Headers)); err != nil {
+			return nil, err
+		}
+		httpClient.Transport = endpoints
 	}
 
-	// Setup keystore
-	ks := account.NewMemKeystore()
-	ks.Put(accountAddress, privKeyBI)
-
-	// Parse account address
-	accAddress, err := utils.HexToFelt(accountAddress)
+	// Initialize RPC provider
+	provider, err := rpc.NewProvider(ctx, rpcURL, rpcclient.WithHTTPClient(httpClient))
 	if err != nil {
-		return nil, fmt.Errorf("invalid account address: %w", err)
+		return nil, fmt.Errorf("failed to create provider: %w", err)
 	}
 
-	// Create account (Cairo 2 - latest version)
-	accnt, err := account.NewAccount(provider, accAddress, accountAddress, ks, 2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create account: %w", err)
+	faucetAccounts := make([]*faucetAccount, 0, len(accounts))
+	for _, creds := range accounts {
+		fa, err := newFaucetAccount(provider, creds)
+		if err != nil {
+			return nil, err
+		}
+		faucetAccounts = append(faucetAccounts, fa)
 	}
 
 	// Parse token addresses
@@ -64,13 +213,178 @@ func NewFaucetClient(rpcURL, privateKey, accountAddress, ethTokenAddr, strkToken
 	}
 
 	return &FaucetClient{
-		account:     accnt,
+		accounts:    faucetAccounts,
 		provider:    provider,
+		endpoints:   endpoints,
+		rpcHeaders:  rpcHeaders(transport.Headers),
 		ethAddress:  ethAddr,
 		strkAddress: strkAddr,
+		txVersion:   TxVersionV3,
+		feeToken:    "STRK",
+		logger:      zap.NewNop(),
+	}, nil
+}
+
+// newHTTPClient builds the HTTP client RPC calls are made with. Like the
+// provider's default client, it keeps cookies so sticky load balancers work.
+func newHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+
+	// With fallbacks, the failover transport sends headers to the primary only
+	if len(cfg.Headers) > 0 && len(cfg.FallbackURLs) == 0 {
+		return &http.Client{Jar: jar, Transport: &headerTransport{base: transport, headers: rpcHeaders(cfg.Headers)}}, nil
+	}
+	return &http.Client{Jar: jar, Transport: transport}, nil
+}
+
+// rpcHeaders converts configured headers to an http.Header
+func rpcHeaders(headers map[string]string) http.Header {
+	h := make(http.Header, len(headers))
+	for name, value := range headers {
+		h.Set(name, value)
+	}
+	return h
+}
+
+// ConnectWebsocket opens a websocket RPC connection at wsURL. WaitForTransaction
+// then subscribes to transaction status instead of polling for receipts.
+func (fc *FaucetClient) ConnectWebsocket(ctx context.Context, wsURL string) error {
+	var opts []rpcclient.ClientOption
+	if len(fc.rpcHeaders) > 0 {
+		opts = append(opts, rpcclient.WithHeaders(fc.rpcHeaders))
+	}
+	ws, err := rpc.NewWebsocketProvider(ctx, wsURL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to websocket provider: %w", err)
+	}
+	fc.wsProvider = ws
+	return nil
+}
+
+// Close closes the websocket connection, if one was opened
+func (fc *FaucetClient) Close() {
+	if fc.wsProvider != nil {
+		fc.wsProvider.Close()
+	}
+}
+
+// newFaucetAccount sets up signing for a single faucet account
+func newFaucetAccount(provider *rpc.Provider, creds AccountCredentials) (*faucetAccount, error) {
+	// Parse private key
+	privKeyBI, ok := new(big.Int).SetString(creds.PrivateKey, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key format for account %s", creds.Address)
+	}
+
+	// Derive the public key so VerifyAccount can compare it with the account's signer
+	pubX, _ := curve.PrivateKeyToPoint(privKeyBI)
+
+	// Setup keystore
+	ks := account.NewMemKeystore()
+	ks.Put(creds.Address, privKeyBI)
+
+	// Parse account address
+	accAddress, err := utils.HexToFelt(creds.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %w", err)
+	}
+
+	// Create account (Cairo 2 - latest version)
+	accnt, err := account.NewAccount(provider, accAddress, creds.Address, ks, 2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+
+	return &faucetAccount{
+		account:   accnt,
+		publicKey: new(felt.Felt).SetBigInt(pubX),
 	}, nil
 }
 
+// AccountAddresses returns the addresses of the faucet accounts in rotation order
+func (fc *FaucetClient) AccountAddresses() []string {
+	addresses := make([]string, len(fc.accounts))
+	for i, fa := range fc.accounts {
+		addresses[i] = fa.account.Address.String()
+	}
+	return addresses
+}
+
+// SetBalanceProtection sets the balance of token each faucet account keeps.
+// Transfers skip accounts that would drop below it.
+func (fc *FaucetClient) SetBalanceProtection(token string, p BalanceProtection) {
+	if fc.protection == nil {
+		fc.protection = make(map[string]BalanceProtection)
+	}
+	fc.protection[token] = p
+}
+
+// canSpend reports whether an account holding balance of token can spend
+// amount and keep its protected balance
+func (fc *FaucetClient) canSpend(token string, balance, amount *big.Int) bool {
+	left := new(big.Int).Sub(balance, amount)
+	p := fc.protection[token]
+	keep := new(big.Int).Div(new(big.Int).Mul(balance, big.NewInt(int64(p.Pct))), big.NewInt(100))
+	if p.Floor != nil && p.Floor.Cmp(keep) > 0 {
+		keep = p.Floor
+	}
+	return left.Sign() >= 0 && left.Cmp(keep) >= 0
+}
+
+// SetTxVersion sets the invoke transaction version and the token used to pay
+// fees. Only v3 with STRK fees is accepted.
+func (fc *FaucetClient) SetTxVersion(version int, feeToken string) error {
+	if version != TxVersionV3 || feeToken != "STRK" {
+		return fmt.Errorf("unsupported transaction version %d with fee token %s, use v3 with STRK fees", version, feeToken)
+	}
+
+	fc.txVersion = version
+	fc.feeToken = feeToken
+	return nil
+}
+
+// SetLogger sets the logger used for RPC diagnostics
+func (fc *FaucetClient) SetLogger(logger *zap.Logger) {
+	fc.logger = logger
+	if fc.endpoints != nil {
+		fc.endpoints.logger = logger
+	}
+}
+
+// RPCEndpoint returns which RPC endpoint requests currently go to, counting
+// from 1 for the primary, and how many are configured
+func (fc *FaucetClient) RPCEndpoint() (active, total int) {
+	if fc.endpoints == nil {
+		return 1, 1
+	}
+	return fc.endpoints.activeEndpoint() + 1, len(fc.endpoints.endpoints)
+}
+
+// FeeToken returns the token used to pay transaction fees
+func (fc *FaucetClient) FeeToken() string {
+	return fc.feeToken
+}
+
+// txnOptions returns the options used to build v3 invoke transactions
+func (fc *FaucetClient) txnOptions() *account.TxnOptions {
+	// Resource bounds are estimated in FRI (STRK) and padded by the fee multiplier
+	return &account.TxnOptions{
+		FeeMultiplier: feeMultiplier,
+	}
+}
+
 // TransferTokens transfers tokens to a recipient
 func (fc *FaucetClient) TransferTokens(
 	ctx context.Context,
@@ -78,10 +392,149 @@ func (fc *FaucetClient) TransferTokens(
 	token string,
 	amount *big.Int,
 ) (string, error) {
+	call, err := fc.transferCall(recipient, token, amount)
+	if err != nil {
+		return "", err
+	}
+
+	// Build and send invoke transaction
+	opts := fc.txnOptions()
+
+	// Start at the next account in rotation and pass over those that can't
+	// afford the transfer
+	start := fc.next.Add(1) - 1
+	for i := range uint64(len(fc.accounts)) {
+		fa := fc.accounts[(start+i)%uint64(len(fc.accounts))]
+		txHash, err := fc.transferFrom(ctx, fa, call, token, amount, opts)
+		if errors.Is(err, errAccountUnderfunded) {
+			fc.debugLogger().Debug("Skipping faucet account", zap.Stringer("account", fa.account.Address), zap.Error(err))
+			continue
+		}
+		return txHash, err
+	}
+	return "", ErrNoFundedAccount
+}
+
+// transferFrom sends call, a transfer of amount of token, from fa. It returns
+// errAccountUnderfunded when fa can't pay for the transfer and the most its
+// fee can come to while keeping its protected balances.
+func (fc *FaucetClient) transferFrom(ctx context.Context, fa *faucetAccount, call rpc.InvokeFunctionCall, token string, amount *big.Int, opts *account.TxnOptions) (string, error) {
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+
+	tip, err := rpc.EstimateTip(ctx, fc.provider, opts.FmtTipMultiplier())
+	if err != nil {
+		return "", wrapRPCError(ctx, "failed to estimate tip", err)
+	}
+	tx, fee, err := fc.estimateInvoke(ctx, fa, []rpc.InvokeFunctionCall{call}, tip, opts)
+	if err != nil {
+		return "", err
+	}
+
+	// Pay for L1 gas, L1 data gas and L2 gas in FRI up to the estimate padded
+	// by the fee multiplier
+	tx.ResourceBounds = utils.FeeEstToResBoundsMap(fee, opts.FmtFeeMultiplier())
+	maxFee, err := maxTransactionFee(tx.ResourceBounds, tip)
+	if err != nil {
+		return "", err
+	}
+
+	spend := map[string]*big.Int{token: new(big.Int).Set(amount)}
+	if spent, ok := spend[fc.feeToken]; ok {
+		spent.Add(spent, maxFee)
+	} else {
+		spend[fc.feeToken] = maxFee
+	}
+	for spentToken, spent := range spend {
+		balance, err := fc.GetBalance(ctx, fa.account.Address.String(), spentToken)
+		if err != nil {
+			return "", err
+		}
+		if !fc.canSpend(spentToken, balance, spent) {
+			return "", fmt.Errorf("%w: %s balance %s can't cover %s", errAccountUnderfunded, spentToken, balance, spent)
+		}
+	}
+
+	// Sign the transaction again for submission
+	tx.Version = rpc.TransactionV3
+	if err := fa.account.SignInvokeTransaction(ctx, tx); err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	resp, err := fa.account.Provider.AddInvokeTransaction(ctx, tx)
+	if err != nil {
+		return "", wrapRPCError(ctx, "transaction failed", err)
+	}
+
+	// Return transaction hash
+	return resp.Hash.String(), nil
+}
+
+// maxTransactionFee returns the most a transaction with bounds and tip can
+// be charged, in FRI
+func maxTransactionFee(bounds *rpc.ResourceBoundsMapping, tip rpc.U64) (*big.Int, error) {
+	tipPerGas, err := tip.ToUint64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tip %q: %w", tip, err)
+	}
+
+	total := big.NewInt(0)
+	for _, b := range []rpc.ResourceBounds{bounds.L1Gas, bounds.L1DataGas, bounds.L2Gas} {
+		maxAmount, err := b.MaxAmount.ToUint64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource bound %q: %w", b.MaxAmount, err)
+		}
+		price, err := b.MaxPricePerUnit.ToBigInt()
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource bound %q: %w", b.MaxPricePerUnit, err)
+		}
+		total.Add(total, new(big.Int).Mul(new(big.Int).SetUint64(maxAmount), price))
+	}
+
+	// The tip is paid per unit of L2 gas
+	l2Gas, _ := bounds.L2Gas.MaxAmount.ToUint64()
+	total.Add(total, new(big.Int).Mul(new(big.Int).SetUint64(l2Gas), new(big.Int).SetUint64(tipPerGas)))
+	return total, nil
+}
+
+// estimateInvoke builds the invoke transaction of calls from fa, signs it
+// with the query version and returns it with the node's fee estimate. The
+// transaction has zero resource bounds and can't be submitted as is.
+func (fc *FaucetClient) estimateInvoke(ctx context.Context, fa *faucetAccount, calls []rpc.InvokeFunctionCall, tip rpc.U64, opts *account.TxnOptions) (*rpc.BroadcastInvokeTxnV3, rpc.FeeEstimation, error) {
+	nonce, err := fa.account.Nonce(ctx)
+	if err != nil {
+		return nil, rpc.FeeEstimation{}, wrapRPCError(ctx, "failed to get nonce", err)
+	}
+	callData, err := fa.account.FmtCalldata(utils.InvokeFuncCallsToFunctionCalls(calls))
+	if err != nil {
+		return nil, rpc.FeeEstimation{}, fmt.Errorf("failed to format calldata: %w", err)
+	}
+
+	// The fee isn't known yet, so the resource bounds start at zero
+	zero := rpc.ResourceBounds{MaxAmount: "0x0", MaxPricePerUnit: "0x0"}
+	tx := utils.BuildInvokeTxn(fa.account.Address, nonce, callData,
+		&rpc.ResourceBoundsMapping{L1Gas: zero, L1DataGas: zero, L2Gas: zero},
+		&utils.TxnOptions{Tip: tip, UseQueryBit: true})
+	if err := fa.account.SignInvokeTransaction(ctx, tx); err != nil {
+		return nil, rpc.FeeEstimation{}, fmt.Errorf("failed to sign fee estimate: %w", err)
+	}
+
+	estimates, err := fa.account.Provider.EstimateFee(ctx, []rpc.BroadcastTxn{tx}, opts.SimulationFlags(), opts.BlockID())
+	if err != nil {
+		return nil, rpc.FeeEstimation{}, wrapRPCError(ctx, "failed to estimate fee", err)
+	}
+	if len(estimates) == 0 {
+		return nil, rpc.FeeEstimation{}, fmt.Errorf("failed to estimate fee: empty estimate")
+	}
+	return tx, estimates[0], nil
+}
+
+// transferCall builds the ERC-20 transfer of amount of token to recipient
+func (fc *FaucetClient) transferCall(recipient, token string, amount *big.Int) (rpc.InvokeFunctionCall, error) {
 	// Parse recipient address
 	recipientFelt, err := utils.HexToFelt(recipient)
 	if err != nil {
-		return "", fmt.Errorf("invalid recipient address: %w", err)
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("invalid recipient address: %w", err)
 	}
 
 	// Determine token address
@@ -92,7 +545,7 @@ func (fc *FaucetClient) TransferTokens(
 	case "STRK":
 		tokenAddress = fc.strkAddress
 	default:
-		return "", fmt.Errorf("invalid token: %s", token)
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("invalid token: %s", token)
 	}
 
 	// Convert amount to Cairo uint256 format (low, high)
@@ -103,7 +556,7 @@ func (fc *FaucetClient) TransferTokens(
 	highFelt := new(felt.Felt).SetBigInt(high)
 
 	// Build transfer call
-	call := rpc.InvokeFunctionCall{
+	return rpc.InvokeFunctionCall{
 		ContractAddress: tokenAddress,
 		FunctionName:    "transfer",
 		CallData: []*felt.Felt{
@@ -111,16 +564,29 @@ func (fc *FaucetClient) TransferTokens(
 			lowFelt,
 			highFelt,
 		},
-	}
+	}, nil
+}
 
-	// Build and send invoke transaction
-	tx, err := fc.account.BuildAndSendInvokeTxn(ctx, []rpc.InvokeFunctionCall{call}, nil)
+// EstimateTransferFee estimates the fee, in wei of the fee token, of a
+// transfer of amount of token from the next faucet account to itself. It is
+// estimated the way TransferTokens estimates, without the fee multiplier or
+// tip, and nothing is submitted.
+func (fc *FaucetClient) EstimateTransferFee(ctx context.Context, token string, amount *big.Int) (*big.Int, error) {
+	fa := fc.accounts[fc.next.Load()%uint64(len(fc.accounts))]
+	call, err := fc.transferCall(fa.account.Address.String(), token, amount)
 	if err != nil {
-		return "", fmt.Errorf("transaction failed: %w", err)
+		return nil, err
 	}
+	opts := fc.txnOptions()
 
-	// Return transaction hash
-	return tx.Hash.String(), nil
+	_, fee, err := fc.estimateInvoke(ctx, fa, []rpc.InvokeFunctionCall{call}, "0x0", opts)
+	if err != nil {
+		return nil, err
+	}
+	if fee.OverallFee == nil {
+		return nil, fmt.Errorf("failed to estimate fee: empty estimate")
+	}
+	return fee.OverallFee.BigInt(new(big.Int)), nil
 }
 
 // GetBalance gets the token balance of an address
@@ -152,55 +618,376 @@ func (fc *FaucetClient) GetBalance(ctx context.Context, address string, token st
 	}, rpc.BlockID{Tag: "latest"})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get balance: %w", err)
+		return nil, wrapRPCError(ctx, "failed to get balance", err)
 	}
 
-	if len(result) < 2 {
-		return nil, fmt.Errorf("unexpected balance result length")
+	balance, err := parseUint256(result)
+	if err != nil || len(result) != 2 {
+		fc.debugLogger().Debug("Unexpected balanceOf result shape",
+			zap.String("token", token),
+			zap.String("address", address),
+			zap.Stringers("result", result),
+			zap.Error(err),
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s balance result: %w", token, err)
 	}
 
-	// Convert from uint256 (low, high) to big.Int
-	low := result[0].BigInt(big.NewInt(0))
-	high := result[1].BigInt(big.NewInt(0))
+	return balance, nil
+}
 
-	balance := new(big.Int).Add(
-		low,
-		new(big.Int).Lsh(high, 128),
-	)
+// debugLogger returns the client's logger, or a no-op logger for clients
+// built without the constructor
+func (fc *FaucetClient) debugLogger() *zap.Logger {
+	if fc.logger == nil {
+		return zap.NewNop()
+	}
+	return fc.logger
+}
 
-	return balance, nil
+// parseUint256 converts a balanceOf result to a big.Int. Cairo uint256 values
+// come back as (low, high) felts of 128 bits each; some tokens return a single
+// felt instead, which is treated as the low half.
+func parseUint256(result []*felt.Felt) (*big.Int, error) {
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty result")
+	}
+
+	low := result[0].BigInt(new(big.Int))
+	if len(result) == 1 {
+		return low, nil
+	}
+
+	high := result[1].BigInt(new(big.Int))
+	if low.BitLen() > 128 || high.BitLen() > 128 {
+		return nil, fmt.Errorf("uint256 halves must fit in 128 bits (low %s, high %s)", result[0], result[1])
+	}
+
+	return new(big.Int).Add(low, new(big.Int).Lsh(high, 128)), nil
+}
+
+// FaucetBalance returns the combined token balance of all faucet accounts
+func (fc *FaucetClient) FaucetBalance(ctx context.Context, token string) (*big.Int, error) {
+	total := big.NewInt(0)
+	for _, fa := range fc.accounts {
+		balance, err := fc.GetBalance(ctx, fa.account.Address.String(), token)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, balance)
+	}
+	return total, nil
+}
+
+// ChainID returns the chain ID reported by the RPC node. It is a cheap call
+// used to check that the provider is reachable.
+func (fc *FaucetClient) ChainID(ctx context.Context) (string, error) {
+	chainID, err := fc.provider.ChainID(ctx)
+	if err != nil {
+		return "", wrapRPCError(ctx, "failed to get chain ID", err)
+	}
+	return chainID, nil
+}
+
+// Ping checks that the RPC node answers by fetching the latest block number.
+// Unlike ChainID, which the provider caches, it always reaches the node.
+func (fc *FaucetClient) Ping(ctx context.Context) error {
+	if _, err := fc.provider.BlockNumber(ctx); err != nil {
+		return wrapRPCError(ctx, "failed to get block number", err)
+	}
+	return nil
+}
+
+// IsDeployed reports whether a contract is deployed at address, using the
+// cheap starknet_getClassHashAt call. An address without a contract is not an
+// error.
+func (fc *FaucetClient) IsDeployed(ctx context.Context, address string) (bool, error) {
+	addr, err := utils.HexToFelt(address)
+	if err != nil {
+		return false, fmt.Errorf("invalid address: %w", err)
+	}
+
+	if _, err := fc.provider.ClassHashAt(ctx, rpc.BlockID{Tag: "latest"}, addr); err != nil {
+		var rpcErr *rpc.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == rpc.ErrContractNotFound.Code {
+			return false, nil
+		}
+		return false, wrapRPCError(ctx, "failed to get class hash", err)
+	}
+	return true, nil
+}
+
+// VerifyAccount checks that every faucet account is deployed and that its
+// configured private key belongs to its signer, so a bad key or address fails
+// at startup instead of on the first transfer. It returns ErrPublicKeyUnavailable
+// if the accounts are deployed but a public key can't be read.
+func (fc *FaucetClient) VerifyAccount(ctx context.Context) error {
+	var unavailable error
+	for _, fa := range fc.accounts {
+		err := fc.verifyAccount(ctx, fa)
+		if errors.Is(err, ErrPublicKeyUnavailable) {
+			unavailable = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return unavailable
+}
+
+// verifyAccount runs the VerifyAccount checks for a single account
+func (fc *FaucetClient) verifyAccount(ctx context.Context, fa *faucetAccount) error {
+	if _, err := fa.account.Nonce(ctx); err != nil {
+		return wrapRPCError(ctx, fmt.Sprintf("faucet account %s not found (is it deployed on this network?)", fa.account.Address), err)
+	}
+
+	for _, name := range publicKeySelectors {
+		result, err := fc.provider.Call(ctx, rpc.FunctionCall{
+			ContractAddress:    fa.account.Address,
+			EntryPointSelector: utils.GetSelectorFromNameFelt(name),
+			Calldata:           []*felt.Felt{},
+		}, rpc.BlockID{Tag: "latest"})
+		if err != nil || len(result) == 0 {
+			if ctx.Err() != nil {
+				return wrapRPCError(ctx, "failed to read account public key", err)
+			}
+			// Entrypoint not implemented by this account, try the next one
+			continue
+		}
+
+		if !result[0].Equal(fa.publicKey) {
+			return fmt.Errorf("private key does not match faucet account %s (account signer is %s, key gives %s)",
+				fa.account.Address, result[0], fa.publicKey)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("faucet account %s: %w", fa.account.Address, ErrPublicKeyUnavailable)
 }
 
-// WaitForTransaction waits for a transaction to be accepted
-func (fc *FaucetClient) WaitForTransaction(ctx context.Context, txHash string) error {
+// WaitForTransaction waits until the transaction reaches the confirmation
+// level (one of the Confirmation* constants). It returns the latest finality
+// status seen, which is set even when ctx ends the wait first, and the
+// receipt once the transaction is seen executed (nil before that).
+func (fc *FaucetClient) WaitForTransaction(ctx context.Context, txHash, level string) (string, *Receipt, error) {
 	txHashFelt, err := utils.HexToFelt(txHash)
 	if err != nil {
-		return fmt.Errorf("invalid tx hash: %w", err)
+		return "", nil, fmt.Errorf("invalid tx hash: %w", err)
+	}
+
+	// With a websocket, prefer status notifications, then a check on every
+	// new block for nodes without status subscriptions. Poll if both fail.
+	var status string
+	var receipt *Receipt
+	if fc.wsProvider != nil {
+		status, err = fc.waitForTransactionStatus(ctx, txHashFelt, level)
+		if err == nil {
+			// Notifications carry no block, so that comes from the receipt
+			if HasReceipt(status) {
+				receipt = fc.executedReceipt(ctx, txHashFelt)
+			}
+			return status, receipt, nil
+		}
+		if ctx.Err() == nil {
+			fc.debugLogger().Debug("Transaction status subscription failed, watching new blocks",
+				zap.String("tx_hash", txHash),
+				zap.Error(err),
+			)
+			status, receipt, err = fc.waitForNewHeads(ctx, txHashFelt, level, status)
+		}
+		if err == nil || ctx.Err() != nil {
+			return status, receipt, err
+		}
+		fc.debugLogger().Debug("New block subscription failed, polling for the status",
+			zap.String("tx_hash", txHash),
+			zap.Error(err),
+		)
 	}
 
-	// Poll for transaction receipt
+	// Poll for the transaction status
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
 	for {
+		status, receipt = fc.transactionProgress(ctx, txHashFelt, level, status)
+		if reachesConfirmation(status, level) {
+			return status, receipt, nil
+		}
+
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return status, receipt, ctx.Err()
 		case <-ticker.C:
-			// Check transaction receipt
-			receipt, err := fc.provider.TransactionReceipt(ctx, txHashFelt)
-			if err != nil {
-				continue
+		}
+	}
+}
+
+// Receipt is the part of a transaction receipt reported to clients
+type Receipt struct {
+	BlockNumber    uint64 // Block the transaction was executed in
+	FinalityStatus string // e.g. ACCEPTED_ON_L2
+}
+
+// executedReceipt fetches the block and finality status of an executed
+// transaction from its receipt, or returns nil if the node has none
+func (fc *FaucetClient) executedReceipt(ctx context.Context, txHash *felt.Felt) *Receipt {
+	receipt, err := fc.provider.TransactionReceipt(ctx, txHash)
+	if err != nil || receipt.FinalityStatus == "" {
+		return nil
+	}
+	return &Receipt{
+		BlockNumber:    uint64(receipt.BlockNumber),
+		FinalityStatus: string(receipt.FinalityStatus),
+	}
+}
+
+// transactionProgress checks how far the transaction has got, keeping last
+// when the node can't tell. Waiting for a level only executed transactions
+// reach checks the receipt, so the block comes with it, and falls back to
+// the status until there is a receipt.
+func (fc *FaucetClient) transactionProgress(ctx context.Context, txHash *felt.Felt, level, last string) (string, *Receipt) {
+	if HasReceipt(level) {
+		if receipt := fc.executedReceipt(ctx, txHash); receipt != nil {
+			return receipt.FinalityStatus, receipt
+		}
+	}
+	return fc.transactionStatus(ctx, txHash, last), nil
+}
+
+// transactionStatus fetches the transaction's finality status, keeping last
+// when the node doesn't know the transaction yet or the call fails
+func (fc *FaucetClient) transactionStatus(ctx context.Context, txHash *felt.Felt, last string) string {
+	result, err := fc.provider.TransactionStatus(ctx, txHash)
+	if err != nil || result.FinalityStatus == "" {
+		return last
+	}
+	return string(result.FinalityStatus)
+}
+
+// waitForTransactionStatus waits on a websocket subscription until the
+// transaction reaches level
+func (fc *FaucetClient) waitForTransactionStatus(ctx context.Context, txHash *felt.Felt, level string) (string, error) {
+	statuses := make(chan *rpc.NewTxnStatus)
+	sub, err := fc.wsProvider.SubscribeTransactionStatus(ctx, statuses, txHash)
+	if err != nil {
+		return "", err
+	}
+	defer sub.Unsubscribe()
+
+	var status string
+	for {
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
 			}
+			return status, err
+		case update := <-statuses:
+			status = string(update.Status.FinalityStatus)
+			if reachesConfirmation(status, level) {
+				return status, nil
+			}
+		}
+	}
+}
+
+// waitForNewHeads checks the transaction's status each time a block is added,
+// for nodes that don't support transaction status subscriptions
+func (fc *FaucetClient) waitForNewHeads(ctx context.Context, txHash *felt.Felt, level, status string) (string, *Receipt, error) {
+	heads := make(chan *rpc.BlockHeader)
+	sub, err := fc.wsProvider.SubscribeNewHeads(ctx, heads, rpc.SubscriptionBlockID{})
+	if err != nil {
+		return status, nil, err
+	}
+	defer sub.Unsubscribe()
 
-			// Check if transaction is accepted
-			if receipt != nil {
-				return nil
+	var receipt *Receipt
+	for {
+		// Checked once up front, since the level may already be reached
+		status, receipt = fc.transactionProgress(ctx, txHash, level, status)
+		if reachesConfirmation(status, level) {
+			return status, receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, receipt, ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
 			}
+			return status, receipt, err
+		case <-heads:
 		}
 	}
 }
 
+// wrapRPCError wraps an RPC error, preferring the context error when the context
+// is done. The RPC library flattens transport errors, so without this callers
+// could not detect deadlines with errors.Is.
+func wrapRPCError(ctx context.Context, msg string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s: %w", msg, ctxErr)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// unavailableMessages are found in the errors of RPC calls that never got an
+// answer from the node. The RPC library flattens transport errors into
+// internal RPC errors, so only their text tells them apart.
+var unavailableMessages = []string{
+	"connection refused",
+	"connection reset",
+	"no such host",
+	"i/o timeout",
+	"tls: ",
+	"EOF",
+	"429 Too Many Requests",
+	"502 Bad Gateway",
+	"503 Service Unavailable",
+	"504 Gateway Timeout",
+}
+
+// IsUnavailableError reports whether err means the RPC node could not be
+// reached or was unable to serve the call, as opposed to the node rejecting
+// the call itself. Deadlines are not included; check those with errors.Is.
+func IsUnavailableError(err error) bool {
+	if err == nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var rpcErr *rpc.RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code != rpcerr.InternalError {
+		return false
+	}
+	msg := err.Error()
+	for _, m := range unavailableMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInsufficientFeeError reports whether err was caused by the faucet account
+// being unable to cover the transaction fee
+func IsInsufficientFeeError(err error) bool {
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return rpcErr.Code == rpc.ErrInsufficientAccountBalance.Code ||
+		rpcErr.Code == rpc.ErrInsufficientResourcesForValidate.Code
+}
+
 // AmountToWei converts a float amount to wei (10^18)
 func AmountToWei(amount float64) *big.Int {
 	// 1 token = 10^18 wei
@@ -0,0 +1,696 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/starknet.go/curve"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSetTxVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		feeToken string
+		wantErr  bool
+	}{
+		{"v3 with STRK fees", TxVersionV3, "STRK", false},
+		{"v3 with ETH fees", TxVersionV3, "ETH", true},
+		{"v1 with ETH fees", 1, "ETH", true},
+		{"v1 with STRK fees", 1, "STRK", true},
+		{"unknown version", 2, "STRK", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := &FaucetClient{txVersion: TxVersionV3, feeToken: "STRK"}
+			err := fc.SetTxVersion(tt.version, tt.feeToken)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, TxVersionV3, fc.txVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, fc.txVersion)
+			assert.Equal(t, tt.feeToken, fc.FeeToken())
+		})
+	}
+}
+
+func TestTxnOptions(t *testing.T) {
+	fc := &FaucetClient{}
+
+	require.NoError(t, fc.SetTxVersion(TxVersionV3, "STRK"))
+	assert.Equal(t, feeMultiplier, fc.txnOptions().FeeMultiplier)
+}
+
+func TestGetBalanceRPCTimeout(t *testing.T) {
+	// Mock RPC node: answers the spec version handshake, hangs on everything else
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "starknet_specVersion" {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0.9.0"}`, req.ID)
+	}))
+	defer server.Close()
+
+	provider, err := rpc.NewProvider(context.Background(), server.URL)
+	require.NoError(t, err)
+
+	strkAddr, err := utils.HexToFelt("0x4718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d")
+	require.NoError(t, err)
+	fc := &FaucetClient{provider: provider, strkAddress: strkAddr}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = fc.GetBalance(ctx, "0x123", "STRK")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestGetBalanceResultShapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  string
+		want    string
+		wantErr bool
+	}{
+		{name: "low and high", result: `["0x5","0x1"]`, want: "340282366920938463463374607431768211461"},
+		{name: "single felt", result: `["0x2a"]`, want: "42"},
+		{name: "empty", result: `[]`, wantErr: true},
+		{name: "high out of range", result: `["0x1","0x100000000000000000000000000000000"]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mock RPC node answering balanceOf calls with tt.result
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					ID     json.RawMessage `json:"id"`
+					Method string          `json:"method"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				result := `"0.9.0"`
+				if req.Method == "starknet_call" {
+					result = tt.result
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+			}))
+			defer server.Close()
+
+			provider, err := rpc.NewProvider(context.Background(), server.URL)
+			require.NoError(t, err)
+			strkAddr, err := utils.HexToFelt("0x4718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d")
+			require.NoError(t, err)
+			fc := &FaucetClient{provider: provider, strkAddress: strkAddr}
+
+			balance, err := fc.GetBalance(context.Background(), "0x123", "STRK")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, balance.String())
+		})
+	}
+}
+
+func TestChainID(t *testing.T) {
+	// Mock RPC node answering the spec version handshake and chainId
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		result := `"0.9.0"`
+		if req.Method == "starknet_chainId" {
+			result = `"0x534e5f5345504f4c4941"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer server.Close()
+
+	provider, err := rpc.NewProvider(context.Background(), server.URL)
+	require.NoError(t, err)
+	fc := &FaucetClient{provider: provider}
+
+	chainID, err := fc.ChainID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "SN_SEPOLIA", chainID)
+}
+
+// newAccountMockServer starts a mock RPC node for VerifyAccount. nonceErr makes
+// starknet_getNonce fail as for an undeployed contract, and signer is returned
+// from starknet_call (an empty signer makes every call fail).
+func newAccountMockServer(t *testing.T, nonceErr bool, signer string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		result := `"0.9.0"`
+		switch req.Method {
+		case "starknet_chainId":
+			result = `"0x534e5f5345504f4c4941"`
+		case "starknet_getNonce":
+			if nonceErr {
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":20,"message":"Contract not found"}}`, req.ID)
+				return
+			}
+			result = `"0x5"`
+		case "starknet_call":
+			if signer == "" {
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":21,"message":"Invalid message selector"}}`, req.ID)
+				return
+			}
+			result = fmt.Sprintf(`["%s"]`, signer)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestVerifyAccount(t *testing.T) {
+	const privateKey = "0x1234"
+	privKey, _ := new(big.Int).SetString(privateKey, 0)
+	pubX, _ := curve.PrivateKeyToPoint(privKey)
+	signer := "0x" + pubX.Text(16)
+
+	tests := []struct {
+		name     string
+		nonceErr bool
+		signer   string
+		wantErr  error
+		errMsg   string
+	}{
+		{name: "key matches signer", signer: signer},
+		{name: "account not deployed", nonceErr: true, signer: signer, errMsg: "not found"},
+		{name: "key does not match signer", signer: "0x999", errMsg: "does not match"},
+		{name: "public key not exposed", wantErr: ErrPublicKeyUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAccountMockServer(t, tt.nonceErr, tt.signer)
+			fc, err := NewFaucetClient(server.URL, privateKey, "0x0123", "0x049d", "0x0471")
+			require.NoError(t, err)
+
+			err = fc.VerifyAccount(context.Background())
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.errMsg != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// submittedTxn is the part of a submitted invoke transaction tests check
+type submittedTxn struct {
+	SenderAddress  string                    `json:"sender_address"`
+	Version        string                    `json:"version"`
+	ResourceBounds rpc.ResourceBoundsMapping `json:"resource_bounds"`
+}
+
+// newTransferMockServer starts a mock RPC node that accepts invoke transactions
+// and sends each one to submitted. Accounts hold the balance in balances, of
+// every token, or plenty if they aren't listed.
+func newTransferMockServer(t *testing.T, submitted chan<- submittedTxn, balances map[string]int64) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		result := `"0.9.0"`
+		switch req.Method {
+		case "starknet_call":
+			var call struct {
+				Calldata []string `json:"calldata"`
+			}
+			_ = json.Unmarshal(req.Params[0], &call)
+			balance, ok := balances[call.Calldata[0]]
+			if !ok {
+				balance = 1 << 60
+			}
+			result = fmt.Sprintf(`["0x%x","0x0"]`, balance)
+		case "starknet_chainId":
+			result = `"0x534e5f5345504f4c4941"`
+		case "starknet_getNonce":
+			result = `"0x1"`
+		case "starknet_getBlockWithTxs":
+			result = `{"status":"ACCEPTED_ON_L2","block_hash":"0x1","parent_hash":"0x0","block_number":1,` +
+				`"new_root":"0x0","timestamp":1,"sequencer_address":"0x1",` +
+				`"l1_gas_price":{"price_in_fri":"0x1","price_in_wei":"0x1"},` +
+				`"l2_gas_price":{"price_in_fri":"0x1","price_in_wei":"0x1"},` +
+				`"l1_data_gas_price":{"price_in_fri":"0x1","price_in_wei":"0x1"},` +
+				`"l1_da_mode":"BLOB","starknet_version":"0.14.0","transactions":[]}`
+		case "starknet_estimateFee":
+			result = `[{"l1_gas_consumed":"0x1","l1_gas_price":"0x1","l2_gas_consumed":"0x1","l2_gas_price":"0x1",` +
+				`"l1_data_gas_consumed":"0x1","l1_data_gas_price":"0x1","overall_fee":"0x3","unit":"FRI"}]`
+		case "starknet_addInvokeTransaction":
+			var tx submittedTxn
+			_ = json.Unmarshal(req.Params[0], &tx)
+			submitted <- tx
+			result = `{"transaction_hash":"0xabc"}`
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestTransferTokensRotatesAccounts(t *testing.T) {
+	submitted := make(chan submittedTxn, 10)
+	server := newTransferMockServer(t, submitted, nil)
+
+	fc, err := NewMultiAccountFaucetClient(server.URL, []AccountCredentials{
+		{Address: "0x111", PrivateKey: "0x1234"},
+		{Address: "0x222", PrivateKey: "0x5678"},
+		{Address: "0x333", PrivateKey: "0x9abc"},
+	}, "0x049d", "0x0471", TransportConfig{})
+	require.NoError(t, err)
+
+	for i := 0; i < 6; i++ {
+		_, err := fc.TransferTokens(context.Background(), "0x999", "STRK", big.NewInt(1))
+		require.NoError(t, err)
+	}
+	close(submitted)
+
+	var got []string
+	for tx := range submitted {
+		got = append(got, tx.SenderAddress)
+	}
+	assert.Equal(t, []string{"0x111", "0x222", "0x333", "0x111", "0x222", "0x333"}, got)
+}
+
+func TestTransferTokensSkipsUnderfundedAccounts(t *testing.T) {
+	submitted := make(chan submittedTxn, 10)
+	// 0x111 can't cover the amount itself
+	server := newTransferMockServer(t, submitted, map[string]int64{"0x111": 5, "0x222": 1000, "0x333": 100})
+
+	fc, err := NewMultiAccountFaucetClient(server.URL, []AccountCredentials{
+		{Address: "0x111", PrivateKey: "0x1234"},
+		{Address: "0x222", PrivateKey: "0x5678"},
+		{Address: "0x333", PrivateKey: "0x9abc"},
+	}, "0x049d", "0x0471", TransportConfig{})
+	require.NoError(t, err)
+	// 0x333 would drop below its 50% protection
+	fc.SetBalanceProtection("STRK", BalanceProtection{Pct: 50})
+
+	for i := 0; i < 3; i++ {
+		_, err := fc.TransferTokens(context.Background(), "0x999", "STRK", big.NewInt(60))
+		require.NoError(t, err)
+	}
+	close(submitted)
+
+	var got []string
+	for tx := range submitted {
+		got = append(got, tx.SenderAddress)
+	}
+	assert.Equal(t, []string{"0x222", "0x222", "0x222"}, got)
+
+	// None can send more than they hold
+	_, err = fc.TransferTokens(context.Background(), "0x999", "STRK", big.NewInt(2000))
+	assert.ErrorIs(t, err, ErrNoFundedAccount)
+}
+
+func TestTransferTokensResourceBounds(t *testing.T) {
+	submitted := make(chan submittedTxn, 1)
+	server := newTransferMockServer(t, submitted, nil)
+
+	fc, err := NewFaucetClient(server.URL, "0x1234", "0x111", "0x049d", "0x0471")
+	require.NoError(t, err)
+
+	_, err = fc.TransferTokens(context.Background(), "0x999", "STRK", big.NewInt(1))
+	require.NoError(t, err)
+
+	tx := <-submitted
+	assert.Equal(t, "0x3", tx.Version, "submitted without the query bit")
+	for _, bounds := range []rpc.ResourceBounds{tx.ResourceBounds.L1Gas, tx.ResourceBounds.L1DataGas, tx.ResourceBounds.L2Gas} {
+		assert.NotEqual(t, rpc.U64("0x0"), bounds.MaxAmount)
+		assert.NotEqual(t, rpc.U128("0x0"), bounds.MaxPricePerUnit)
+	}
+}
+
+func TestEstimateTransferFee(t *testing.T) {
+	submitted := make(chan submittedTxn, 1)
+	server := newTransferMockServer(t, submitted, nil)
+
+	fc, err := NewFaucetClient(server.URL, "0x1234", "0x111", "0x049d", "0x0471")
+	require.NoError(t, err)
+
+	fee, err := fc.EstimateTransferFee(context.Background(), "STRK", big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(3), fee, "the node's overall_fee, without the multiplier")
+	assert.Empty(t, submitted, "nothing is submitted")
+
+	_, err = fc.EstimateTransferFee(context.Background(), "DOGE", big.NewInt(1))
+	assert.ErrorContains(t, err, "invalid token")
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	httpClient, err := newHTTPClient(TransportConfig{MaxIdleConns: 50, MaxIdleConnsPerHost: 20, MaxConnsPerHost: 40})
+	require.NoError(t, err)
+	transport := httpClient.Transport.(*http.Transport)
+	assert.Equal(t, 50, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 40, transport.MaxConnsPerHost)
+	assert.NotNil(t, httpClient.Jar)
+
+	// Zero values keep Go's defaults
+	httpClient, err = newHTTPClient(TransportConfig{})
+	require.NoError(t, err)
+	transport = httpClient.Transport.(*http.Transport)
+	assert.Equal(t, http.DefaultTransport.(*http.Transport).MaxIdleConns, transport.MaxIdleConns)
+	assert.Zero(t, transport.MaxConnsPerHost)
+}
+
+func TestRPCHeaders(t *testing.T) {
+	var apiKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKeys = append(apiKeys, r.Header.Get("X-Api-Key"))
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		result := `"0.9.0"`
+		if req.Method == "starknet_chainId" {
+			result = `"0x534e5f5345504f4c4941"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer server.Close()
+
+	fc, err := NewMultiAccountFaucetClient(server.URL, []AccountCredentials{
+		{Address: "0x111", PrivateKey: "0x1234"},
+	}, "0x049d", "0x0471", TransportConfig{Headers: map[string]string{"x-api-key": "secret"}})
+	require.NoError(t, err)
+
+	_, err = fc.provider.SpecVersion(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, apiKeys)
+	for _, key := range apiKeys {
+		assert.Equal(t, "secret", key)
+	}
+	assert.Equal(t, "secret", fc.rpcHeaders.Get("X-Api-Key"), "the websocket connects with the same headers")
+}
+
+// newWebsocketMockServer serves websocket subscriptions. Each method in
+// notifications is acknowledged and then sends its results in order; any other
+// subscribe method fails as unsupported.
+func newWebsocketMockServer(t *testing.T, notifications map[string][]any) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var req struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+
+			results, ok := notifications[req.Method]
+			switch {
+			case ok:
+				conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": "1"})
+				for _, result := range results {
+					conn.WriteJSON(map[string]any{
+						"jsonrpc": "2.0",
+						"method":  "starknet_subscription" + strings.TrimPrefix(req.Method, "starknet_subscribe"),
+						"params":  map[string]any{"subscription_id": "1", "result": result},
+					})
+				}
+			case strings.HasPrefix(req.Method, "starknet_subscribe"):
+				conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": req.ID, "error": map[string]any{"code": -32601, "message": "Method not found"}})
+			default:
+				conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": true})
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// txnStatus is a starknet_subscribeTransactionStatus notification result
+func txnStatus(finality string) map[string]any {
+	return map[string]any{
+		"transaction_hash": "0x123",
+		"status":           map[string]any{"finality_status": finality},
+	}
+}
+
+// newReceiptMockServer serves an RPC node that knows the receipt of 0x123
+// in block 812345, counting the lookups of each method
+func newReceiptMockServer(t *testing.T, calls map[string]*atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if count := calls[req.Method]; count != nil {
+			count.Add(1)
+		}
+
+		result := `"0.9.0"`
+		switch req.Method {
+		case "starknet_getTransactionReceipt":
+			result = `{"type":"INVOKE","transaction_hash":"0x123","actual_fee":{"amount":"0x1","unit":"FRI"},
+				"finality_status":"ACCEPTED_ON_L2","execution_status":"SUCCEEDED","messages_sent":[],"events":[],
+				"execution_resources":{"l1_gas":0,"l1_data_gas":0,"l2_gas":0},"block_hash":"0x1","block_number":812345}`
+		case "starknet_getTransactionStatus":
+			result = `{"finality_status":"ACCEPTED_ON_L2","execution_status":"SUCCEEDED"}`
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWaitForTransactionWebsocket(t *testing.T) {
+	server := newWebsocketMockServer(t, map[string][]any{
+		"starknet_subscribeTransactionStatus": {txnStatus("RECEIVED"), txnStatus("ACCEPTED_ON_L2")},
+	})
+	provider, err := rpc.NewProvider(context.Background(), newReceiptMockServer(t, nil).URL)
+	require.NoError(t, err)
+
+	fc := &FaucetClient{provider: provider, logger: zap.NewNop()}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, fc.ConnectWebsocket(ctx, "ws"+strings.TrimPrefix(server.URL, "http")))
+	defer fc.Close()
+
+	// Returns on the notification, well before the first 5 second poll
+	start := time.Now()
+	status, receipt, err := fc.WaitForTransaction(ctx, "0x123", ConfirmationAcceptedOnL2)
+	require.NoError(t, err)
+	assert.Equal(t, ConfirmationAcceptedOnL2, status)
+	assert.Less(t, time.Since(start), time.Second)
+	require.NotNil(t, receipt, "the block comes from the receipt")
+	assert.EqualValues(t, 812345, receipt.BlockNumber)
+}
+
+func TestWaitForTransactionWebsocketTimeout(t *testing.T) {
+	// The transaction never gets past RECEIVED
+	server := newWebsocketMockServer(t, map[string][]any{
+		"starknet_subscribeTransactionStatus": {txnStatus("RECEIVED")},
+	})
+
+	fc := &FaucetClient{logger: zap.NewNop()}
+	require.NoError(t, fc.ConnectWebsocket(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")))
+	defer fc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	status, receipt, err := fc.WaitForTransaction(ctx, "0x123", ConfirmationPreConfirmed)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, ConfirmationReceived, status, "the level reached so far is still reported")
+	assert.Nil(t, receipt)
+
+	// A lower level is met by the same notification
+	status, receipt, err = fc.WaitForTransaction(context.Background(), "0x123", ConfirmationReceived)
+	require.NoError(t, err)
+	assert.Equal(t, ConfirmationReceived, status)
+	assert.Nil(t, receipt, "nothing is executed yet")
+}
+
+func TestWaitForTransactionNewHeads(t *testing.T) {
+	// The first status lookup, made before any new block, finds nothing
+	var lookups atomic.Int32
+	rpcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		result := `"0.9.0"`
+		switch req.Method {
+		case "starknet_getTransactionReceipt":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":29,"message":"Transaction hash not found"}}`, req.ID)
+			return
+		case "starknet_getTransactionStatus":
+			if lookups.Add(1) == 1 {
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":29,"message":"Transaction hash not found"}}`, req.ID)
+				return
+			}
+			result = `{"finality_status":"ACCEPTED_ON_L2","execution_status":"SUCCEEDED"}`
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer rpcServer.Close()
+
+	// No status subscriptions on this node, only new heads
+	wsServer := newWebsocketMockServer(t, map[string][]any{
+		"starknet_subscribeNewHeads": {map[string]any{"block_hash": "0x1", "block_number": 1}},
+	})
+
+	provider, err := rpc.NewProvider(context.Background(), rpcServer.URL)
+	require.NoError(t, err)
+	fc := &FaucetClient{provider: provider, logger: zap.NewNop()}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, fc.ConnectWebsocket(ctx, "ws"+strings.TrimPrefix(wsServer.URL, "http")))
+	defer fc.Close()
+
+	start := time.Now()
+	status, receipt, err := fc.WaitForTransaction(ctx, "0x123", ConfirmationAcceptedOnL2)
+	require.NoError(t, err)
+	assert.Equal(t, ConfirmationAcceptedOnL2, status)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Nil(t, receipt, "the node had no receipt to give")
+}
+
+func TestWaitForTransactionReceipt(t *testing.T) {
+	calls := map[string]*atomic.Int32{
+		"starknet_getTransactionReceipt": new(atomic.Int32),
+		"starknet_getTransactionStatus":  new(atomic.Int32),
+	}
+	provider, err := rpc.NewProvider(context.Background(), newReceiptMockServer(t, calls).URL)
+	require.NoError(t, err)
+	fc := &FaucetClient{provider: provider, logger: zap.NewNop()}
+
+	// Waiting for execution polls the receipt, which brings the block
+	status, receipt, err := fc.WaitForTransaction(context.Background(), "0x123", ConfirmationPreConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, ConfirmationAcceptedOnL2, status)
+	assert.Equal(t, &Receipt{BlockNumber: 812345, FinalityStatus: ConfirmationAcceptedOnL2}, receipt)
+	assert.EqualValues(t, 1, calls["starknet_getTransactionReceipt"].Load())
+	assert.Zero(t, calls["starknet_getTransactionStatus"].Load())
+
+	// Waiting for RECEIVED only needs the status
+	status, receipt, err = fc.WaitForTransaction(context.Background(), "0x123", ConfirmationReceived)
+	require.NoError(t, err)
+	assert.Equal(t, ConfirmationAcceptedOnL2, status)
+	assert.Nil(t, receipt)
+	assert.EqualValues(t, 1, calls["starknet_getTransactionStatus"].Load())
+
+	_, _, err = fc.WaitForTransaction(context.Background(), "not-a-hash", ConfirmationReceived)
+	assert.Error(t, err)
+}
+
+func TestReachesConfirmation(t *testing.T) {
+	assert.True(t, reachesConfirmation("ACCEPTED_ON_L2", ConfirmationReceived))
+	assert.True(t, reachesConfirmation("ACCEPTED_ON_L1", ConfirmationAcceptedOnL2))
+	assert.True(t, reachesConfirmation("PRE_CONFIRMED", ConfirmationPreConfirmed))
+	assert.False(t, reachesConfirmation("CANDIDATE", ConfirmationPreConfirmed))
+	assert.False(t, reachesConfirmation("", ConfirmationReceived))
+}
+
+func TestIsUnavailableError(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code := int(status.Load()); code != 0 {
+			w.WriteHeader(code)
+			return
+		}
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0.9.0"}`, req.ID)
+	}))
+	provider, err := rpc.NewProvider(context.Background(), server.URL)
+	require.NoError(t, err)
+	fc := &FaucetClient{provider: provider, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	status.Store(http.StatusServiceUnavailable)
+	err = fc.Ping(ctx)
+	require.Error(t, err)
+	assert.True(t, IsUnavailableError(err), err.Error())
+
+	// The library flattens connection errors into RPC errors
+	server.Close()
+	err = fc.Ping(ctx)
+	require.Error(t, err)
+	assert.True(t, IsUnavailableError(err), err.Error())
+
+	// Errors the node answered with, and deadlines, are not outages
+	assert.False(t, IsUnavailableError(nil))
+	assert.False(t, IsUnavailableError(fmt.Errorf("transfer: %w", rpc.ErrInsufficientAccountBalance)))
+	assert.False(t, IsUnavailableError(fmt.Errorf("failed to get nonce: %w", context.DeadlineExceeded)))
+}
@@ -0,0 +1,212 @@
+package starknet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rpcFailbackAfter is how long requests stay on a fallback endpoint before
+// the primary is tried again
+const rpcFailbackAfter = 5 * time.Minute
+
+// failoverTransport sends each RPC request to the active endpoint and moves
+// on to the next one, in order, when an endpoint can't be reached or answers
+// that it is unavailable. The provider and accounts keep the primary URL;
+// only the request is redirected. Transactions are only ever sent once.
+type failoverTransport struct {
+	base      http.RoundTripper
+	endpoints []*url.URL  // Primary first
+	headers   http.Header // Sent to the primary only, as they usually hold its API key
+	logger    *zap.Logger
+
+	mu       sync.Mutex
+	active   int       // Index of the endpoint requests go to
+	failedAt time.Time // When requests last moved off the primary
+}
+
+// newFailoverTransport returns a transport over primary and then fallbacks,
+// adding headers to requests sent to primary. Errors don't repeat the URLs,
+// which may embed API keys.
+func newFailoverTransport(base http.RoundTripper, primary string, fallbacks []string, headers http.Header) (*failoverTransport, error) {
+	endpoints := make([]*url.URL, 0, len(fallbacks)+1)
+	for i, raw := range append([]string{primary}, fallbacks...) {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("RPC endpoint %d must be an http(s) URL", i+1)
+		}
+		endpoints = append(endpoints, u)
+	}
+	return &failoverTransport{base: base, endpoints: endpoints, headers: headers, logger: zap.NewNop()}, nil
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.startEndpoint()
+
+	// A body that can't be replayed can only be sent once
+	if req.Body != nil && req.GetBody == nil {
+		return t.base.RoundTrip(t.redirect(req, start, req.Body))
+	}
+	if req.Body != nil {
+		defer req.Body.Close()
+
+		// An endpoint that failed may still have broadcast a transaction, so
+		// resending it elsewhere could submit it twice
+		write, err := isWriteRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		if write {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			return t.base.RoundTrip(t.redirect(req, start, body))
+		}
+	}
+
+	for i := 0; ; i++ {
+		idx := (start + i) % len(t.endpoints)
+
+		var body io.ReadCloser
+		if req.Body != nil {
+			var err error
+			if body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.base.RoundTrip(t.redirect(req, idx, body))
+		if err == nil && !endpointUnavailable(resp.StatusCode) {
+			t.use(start, idx)
+			return resp, nil
+		}
+		// Stop once none are left, or when the caller gave up, which says
+		// nothing about the endpoint
+		if req.Context().Err() != nil || i == len(t.endpoints)-1 {
+			return resp, err
+		}
+
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		t.logger.Warn("RPC endpoint unavailable, trying the next one",
+			zap.Int("endpoint", idx+1),
+			zap.String("host", t.endpoints[idx].Host), // Paths and queries may hold API keys
+			zap.Error(err),
+		)
+	}
+}
+
+// redirect copies req with its URL pointing at endpoint idx
+func (t *failoverTransport) redirect(req *http.Request, idx int, body io.ReadCloser) *http.Request {
+	out := req.Clone(req.Context())
+	u := *t.endpoints[idx]
+	out.URL = &u
+	out.Host = ""
+	out.Body = body
+	if idx == 0 {
+		for name, values := range t.headers {
+			out.Header[name] = values
+		}
+	}
+	return out
+}
+
+// rpcCall is the part of a JSON-RPC request failover looks at
+type rpcCall struct {
+	Method string `json:"method"`
+}
+
+// isWriteRequest reports whether req calls a JSON-RPC method that submits a
+// transaction. Bodies that aren't JSON-RPC count as writes, so they are
+// never replayed.
+func isWriteRequest(req *http.Request) (bool, error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return false, err
+	}
+
+	var calls []rpcCall
+	if data = bytes.TrimSpace(data); bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &calls)
+	} else {
+		var call rpcCall
+		err = json.Unmarshal(data, &call)
+		calls = []rpcCall{call}
+	}
+	if err != nil {
+		return true, nil
+	}
+	for _, call := range calls {
+		if call.Method == "" || strings.HasPrefix(call.Method, "starknet_add") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// startEndpoint returns the endpoint to try first: the active one, or the
+// primary again once requests have been off it for rpcFailbackAfter
+func (t *failoverTransport) startEndpoint() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active != 0 && time.Since(t.failedAt) >= rpcFailbackAfter {
+		return 0
+	}
+	return t.active
+}
+
+// use records that endpoint idx answered a request that was first tried on
+// endpoint start
+func (t *failoverTransport) use(start, idx int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if idx != start && idx != 0 {
+		t.failedAt = time.Now()
+	}
+	if idx == t.active {
+		return
+	}
+	t.active = idx
+	if idx == 0 {
+		t.logger.Info("Primary RPC endpoint recovered", zap.String("host", t.endpoints[idx].Host))
+		return
+	}
+	t.logger.Warn("Failed over to a fallback RPC endpoint",
+		zap.Int("endpoint", idx+1),
+		zap.String("host", t.endpoints[idx].Host),
+	)
+}
+
+// activeEndpoint returns the index of the endpoint requests go to
+func (t *failoverTransport) activeEndpoint() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active
+}
+
+// endpointUnavailable reports whether status means the endpoint itself can't
+// serve requests right now, as opposed to an error in the request
+func endpointUnavailable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFeltServer is an RPC node that answers the version handshake and then
+// every call with the same felt, or 503 while down is set
+func newFeltServer(t *testing.T, down *atomic.Bool, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		result := `"0x534e5f5345504f4c4941"`
+		if req.Method == "starknet_specVersion" {
+			result = `"0.9.0"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRPCFailover(t *testing.T) {
+	var primaryDown, secondaryDown atomic.Bool
+	var primaryCalls, secondaryCalls atomic.Int32
+	primary := newFeltServer(t, &primaryDown, &primaryCalls)
+	secondary := newFeltServer(t, &secondaryDown, &secondaryCalls)
+
+	// A closed server refuses connections
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	fc, err := NewMultiAccountFaucetClient(primary.URL, []AccountCredentials{
+		{Address: "0x111", PrivateKey: "0x1234"},
+	}, "0x049d", "0x0471", TransportConfig{FallbackURLs: []string{unreachable.URL, secondary.URL}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	active, total := fc.RPCEndpoint()
+	assert.Equal(t, 1, active)
+	assert.Equal(t, 3, total)
+
+	// The primary fails and the unreachable endpoint is skipped. The chain ID
+	// is cached by the provider, so class hashes are fetched instead.
+	primaryDown.Store(true)
+	deployed, err := fc.IsDeployed(ctx, "0x123")
+	require.NoError(t, err)
+	assert.True(t, deployed)
+	active, _ = fc.RPCEndpoint()
+	assert.Equal(t, 3, active)
+	assert.Positive(t, secondaryCalls.Load())
+
+	// Requests stay on the secondary without retrying the primary first
+	primaryDown.Store(false)
+	before := primaryCalls.Load()
+	_, err = fc.IsDeployed(ctx, "0x123")
+	require.NoError(t, err)
+	assert.Equal(t, before, primaryCalls.Load())
+
+	// Until the failback period is over
+	fc.endpoints.failedAt = time.Now().Add(-rpcFailbackAfter)
+	_, err = fc.IsDeployed(ctx, "0x123")
+	require.NoError(t, err)
+	active, _ = fc.RPCEndpoint()
+	assert.Equal(t, 1, active)
+
+	// With every endpoint down the last error is returned
+	primaryDown.Store(true)
+	secondaryDown.Store(true)
+	_, err = fc.IsDeployed(ctx, "0x123")
+	assert.Error(t, err)
+}
+
+func TestNewFailoverTransportInvalidURL(t *testing.T) {
+	_, err := newFailoverTransport(http.DefaultTransport, "http://node:9545", []string{"node-2:9545?key=secret"}, nil)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestFailoverTransportWritesAndHeaders(t *testing.T) {
+	var primaryKey, secondaryKey atomic.Value
+	var secondaryCalls atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryKey.Store(r.Header.Get("X-Api-Key"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(primary.Close)
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondaryCalls.Add(1)
+		secondaryKey.Store(r.Header.Get("X-Api-Key"))
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+	}))
+	t.Cleanup(secondary.Close)
+
+	ft, err := newFailoverTransport(http.DefaultTransport, primary.URL, []string{secondary.URL},
+		http.Header{"X-Api-Key": []string{"primary-key"}})
+	require.NoError(t, err)
+	client := &http.Client{Transport: ft}
+	post := func(body string) int {
+		resp, err := client.Post(primary.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// A transaction that failed on the primary isn't resent to the fallback
+	status := post(`{"jsonrpc":"2.0","id":1,"method":"starknet_addInvokeTransaction","params":[]}`)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "primary-key", primaryKey.Load())
+	assert.Zero(t, secondaryCalls.Load())
+
+	// Reads fail over, without the primary's headers
+	status = post(`{"jsonrpc":"2.0","id":1,"method":"starknet_getNonce","params":[]}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int32(1), secondaryCalls.Load())
+	assert.Empty(t, secondaryKey.Load())
+}
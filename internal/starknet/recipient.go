@@ -0,0 +1,101 @@
+package starknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// snip6InterfaceID is the SNIP-6 (SRC-6) account interface ID
+var snip6InterfaceID, _ = new(felt.Felt).SetString("0x2ceccef7f994940b3962a6c67e0ba4fcd37df7d131417c604f91e03caecc1cd")
+
+// legacyAccountInterfaceID is the ERC-165 style ID Cairo 0 accounts report
+var legacyAccountInterfaceID = new(felt.Felt).SetUint64(0xa66bd575)
+
+// accountInterfaceChecks are the calls IsAccount tries in turn. Current
+// accounts answer the first; older ones only have the camel-case entrypoint.
+var accountInterfaceChecks = []struct {
+	entrypoint  string
+	interfaceID *felt.Felt
+}{
+	{"supports_interface", snip6InterfaceID},
+	{"supportsInterface", snip6InterfaceID},
+	{"supportsInterface", legacyAccountInterfaceID},
+}
+
+// maxCachedAccounts bounds the IsAccount cache. It is cleared when full, so
+// requests for arbitrary addresses can't grow it without limit.
+const maxCachedAccounts = 10000
+
+// accountCache remembers which deployed addresses are accounts. The zero
+// value is ready to use.
+type accountCache struct {
+	mu      sync.Mutex
+	answers map[string]bool
+}
+
+func (c *accountCache) get(address string) (isAccount, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	isAccount, ok = c.answers[address]
+	return isAccount, ok
+}
+
+func (c *accountCache) put(address string, isAccount bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.answers == nil || len(c.answers) >= maxCachedAccounts {
+		c.answers = make(map[string]bool)
+	}
+	c.answers[address] = isAccount
+}
+
+// IsAccount reports whether the contract at address implements the SNIP-6
+// account interface, as reported by its supports_interface. It returns
+// ErrAccountNotDeployed when nothing is deployed there. Answers for deployed
+// contracts are cached per address; undeployed addresses are asked again,
+// since they may be deployed later.
+func (fc *FaucetClient) IsAccount(ctx context.Context, address string) (bool, error) {
+	addr, err := utils.HexToFelt(address)
+	if err != nil {
+		return false, fmt.Errorf("invalid address: %w", err)
+	}
+	key := addr.String()
+	if isAccount, ok := fc.accountCache.get(key); ok {
+		return isAccount, nil
+	}
+
+	isAccount := false
+	for _, check := range accountInterfaceChecks {
+		result, err := fc.provider.Call(ctx, rpc.FunctionCall{
+			ContractAddress:    addr,
+			EntryPointSelector: utils.GetSelectorFromNameFelt(check.entrypoint),
+			Calldata:           []*felt.Felt{check.interfaceID},
+		}, rpc.BlockID{Tag: "latest"})
+		if err != nil {
+			var rpcErr *rpc.RPCError
+			if errors.As(err, &rpcErr) {
+				switch rpcErr.Code {
+				case rpc.ErrContractNotFound.Code:
+					return false, ErrAccountNotDeployed
+				case rpc.ErrContractError.Code, rpc.ErrEntrypointNotFound.Code:
+					// No such entrypoint, or it rejects the ID: try the next
+					continue
+				}
+			}
+			return false, wrapRPCError(ctx, "failed to check account interface", err)
+		}
+		if len(result) > 0 && result[0].IsOne() {
+			isAccount = true
+			break
+		}
+	}
+
+	fc.accountCache.put(key, isAccount)
+	return isAccount, nil
+}
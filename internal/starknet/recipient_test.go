@@ -0,0 +1,118 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIsAccount(t *testing.T) {
+	snake := utils.GetSelectorFromNameFelt("supports_interface").String()
+	camel := utils.GetSelectorFromNameFelt("supportsInterface").String()
+	const (
+		entrypointNotFound = `"error":{"code":21,"message":"Requested entrypoint does not exist in the contract"}`
+		contractNotFound   = `"error":{"code":20,"message":"Contract not found"}`
+	)
+
+	// Each contract answers per entrypoint and interface ID
+	answers := map[string]func(selector, id string) string{
+		// A current account
+		"0x1": func(selector, id string) string {
+			if selector == snake && id == snip6InterfaceID.String() {
+				return `"result":["0x1"]`
+			}
+			return entrypointNotFound
+		},
+		// A Cairo 0 account with only the legacy interface
+		"0x2": func(selector, id string) string {
+			if selector == camel && id == "0xa66bd575" {
+				return `"result":["0x1"]`
+			}
+			if selector == camel {
+				return `"result":["0x0"]`
+			}
+			return entrypointNotFound
+		},
+		// A token contract
+		"0x3": func(string, string) string { return entrypointNotFound },
+		// Nothing deployed
+		"0x4": func(string, string) string { return contractNotFound },
+		"0x5": func(string, string) string { return `"error":{"code":-32603,"message":"Internal error"}` },
+	}
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []json.RawMessage
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		if req.Method != "starknet_call" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0.9.0"}`, req.ID)
+			return
+		}
+		var call struct {
+			ContractAddress    string   `json:"contract_address"`
+			EntryPointSelector string   `json:"entry_point_selector"`
+			Calldata           []string `json:"calldata"`
+		}
+		require.NoError(t, json.Unmarshal(req.Params[0], &call))
+		mu.Lock()
+		calls[call.ContractAddress]++
+		mu.Unlock()
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,%s}`, req.ID, answers[call.ContractAddress](call.EntryPointSelector, call.Calldata[0]))
+	}))
+	defer server.Close()
+
+	provider, err := rpc.NewProvider(context.Background(), server.URL)
+	require.NoError(t, err)
+	fc := &FaucetClient{provider: provider, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	isAccount, err := fc.IsAccount(ctx, "0x1")
+	require.NoError(t, err)
+	assert.True(t, isAccount)
+	assert.Equal(t, 1, calls["0x1"], "one call for current accounts")
+
+	isAccount, err = fc.IsAccount(ctx, "0x2")
+	require.NoError(t, err)
+	assert.True(t, isAccount, "legacy accounts count")
+
+	isAccount, err = fc.IsAccount(ctx, "0x3")
+	require.NoError(t, err)
+	assert.False(t, isAccount)
+
+	_, err = fc.IsAccount(ctx, "0x4")
+	assert.ErrorIs(t, err, ErrAccountNotDeployed)
+
+	_, err = fc.IsAccount(ctx, "0x5")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrAccountNotDeployed)
+
+	// Deployed contracts are answered from the cache, under any spelling
+	before := calls["0x3"]
+	for _, address := range []string{"0x1", "0x0001", "0x3"} {
+		_, err := fc.IsAccount(ctx, address)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls["0x1"])
+	assert.Equal(t, before, calls["0x3"])
+
+	// Undeployed addresses are asked again
+	_, err = fc.IsAccount(ctx, "0x4")
+	assert.ErrorIs(t, err, ErrAccountNotDeployed)
+	assert.Equal(t, 2, calls["0x4"])
+}
@@ -0,0 +1,232 @@
+// Package starknettest provides an in-memory Starknet client for testing code
+// that talks to the chain, such as the API handlers.
+package starknettest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/starknet"
+)
+
+// Transfer records a call to MockClient.TransferTokens
+type Transfer struct {
+	Recipient string
+	Token     string
+	Amount    *big.Int
+	TxHash    string
+}
+
+// MockClient is an in-memory stand-in for starknet.FaucetClient. Balances are
+// per token (the queried address is ignored) and successful transfers are
+// deducted from them. Set the *Err fields to simulate RPC failures.
+type MockClient struct {
+	mu sync.Mutex
+
+	Balances    map[string]*big.Int
+	Transfers   []Transfer
+	ChainIDName string
+	FeeTokenSym string
+	Undeployed  map[string]bool // Addresses IsDeployed reports as having no contract
+	NonAccounts map[string]bool // Deployed addresses IsAccount reports as not being accounts
+
+	AccountChecks int // Calls to IsAccount
+
+	Fee          *big.Int // Fee EstimateTransferFee reports (nil = 0)
+	FeeEstimates int      // Calls to EstimateTransferFee
+
+	BalanceErr   error
+	FeeErr       error
+	TransferErr  error
+	ChainIDErr   error
+	DeployErr    error
+	WaitErr      error
+	WaitStatus   string // Status WaitForTransaction reports (empty = the requested level)
+	PingErr      error
+	SignatureErr error
+	AccountErr   error
+	Block        uint64 // Block WaitForTransaction reports transactions in
+	NoReceipt    bool   // WaitForTransaction reports no receipt
+
+	ActiveEndpoint, Endpoints int // Reported by RPCEndpoint (zero = 1 of 1)
+
+	Accounts []string // Reported by AccountAddresses
+}
+
+// NewMockClient creates a mock client on Sepolia that pays fees in STRK
+func NewMockClient() *MockClient {
+	return &MockClient{
+		Balances:    make(map[string]*big.Int),
+		ChainIDName: "SN_SEPOLIA",
+		FeeTokenSym: "STRK",
+	}
+}
+
+// SetBalance sets the faucet balance of a token
+func (m *MockClient) SetBalance(token string, balance *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Balances[token] = new(big.Int).Set(balance)
+}
+
+// TransferTokens records the transfer and deducts it from the token balance
+func (m *MockClient) TransferTokens(ctx context.Context, recipient string, token string, amount *big.Int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.TransferErr != nil {
+		return "", m.TransferErr
+	}
+
+	balance, ok := m.Balances[token]
+	if !ok || balance.Cmp(amount) < 0 {
+		return "", fmt.Errorf("insufficient %s balance", token)
+	}
+	balance.Sub(balance, amount)
+
+	txHash := fmt.Sprintf("0x%064x", len(m.Transfers)+1)
+	m.Transfers = append(m.Transfers, Transfer{
+		Recipient: recipient,
+		Token:     token,
+		Amount:    new(big.Int).Set(amount),
+		TxHash:    txHash,
+	})
+
+	return txHash, nil
+}
+
+// EstimateTransferFee returns Fee, or FeeErr if set
+func (m *MockClient) EstimateTransferFee(ctx context.Context, token string, amount *big.Int) (*big.Int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.FeeEstimates++
+	if m.FeeErr != nil {
+		return nil, m.FeeErr
+	}
+	if m.Fee == nil {
+		return big.NewInt(0), nil
+	}
+	return new(big.Int).Set(m.Fee), nil
+}
+
+// GetBalance returns the token balance, or zero if it was never set
+func (m *MockClient) GetBalance(ctx context.Context, address string, token string) (*big.Int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.BalanceErr != nil {
+		return nil, m.BalanceErr
+	}
+
+	balance, ok := m.Balances[token]
+	if !ok {
+		return big.NewInt(0), nil
+	}
+	return new(big.Int).Set(balance), nil
+}
+
+// FaucetBalance returns the token balance, like GetBalance for the faucet account
+func (m *MockClient) FaucetBalance(ctx context.Context, token string) (*big.Int, error) {
+	return m.GetBalance(ctx, "", token)
+}
+
+// WaitForTransaction returns WaitStatus and WaitErr immediately. Without
+// either, the transaction has reached the requested level. Executed
+// transactions come with a receipt in Block unless NoReceipt is set.
+func (m *MockClient) WaitForTransaction(ctx context.Context, txHash, level string) (string, *starknet.Receipt, error) {
+	status := level
+	if m.WaitStatus != "" || m.WaitErr != nil {
+		status = m.WaitStatus
+	}
+	var receipt *starknet.Receipt
+	if starknet.HasReceipt(status) && !m.NoReceipt {
+		receipt = &starknet.Receipt{BlockNumber: m.Block, FinalityStatus: status}
+	}
+	return status, receipt, m.WaitErr
+}
+
+// Ping returns PingErr
+func (m *MockClient) Ping(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.PingErr
+}
+
+// IsDeployed reports every address as deployed except those in Undeployed,
+// or returns DeployErr if set
+func (m *MockClient) IsDeployed(ctx context.Context, address string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.DeployErr != nil {
+		return false, m.DeployErr
+	}
+	return !m.Undeployed[address], nil
+}
+
+// IsValidSignature accepts a signature that is the signed hash itself, so
+// tests can sign with the hash. Addresses in Undeployed have no account to
+// ask. It returns SignatureErr if set.
+func (m *MockClient) IsValidSignature(ctx context.Context, address string, hash *felt.Felt, signature []*felt.Felt) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SignatureErr != nil {
+		return false, m.SignatureErr
+	}
+	if m.Undeployed[address] {
+		return false, starknet.ErrAccountNotDeployed
+	}
+	return len(signature) == 1 && signature[0].Equal(hash), nil
+}
+
+// IsAccount reports every address as an account except those in NonAccounts.
+// Addresses in Undeployed have no contract. It returns AccountErr if set.
+func (m *MockClient) IsAccount(ctx context.Context, address string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.AccountChecks++
+	if m.AccountErr != nil {
+		return false, m.AccountErr
+	}
+	if m.Undeployed[address] {
+		return false, starknet.ErrAccountNotDeployed
+	}
+	return !m.NonAccounts[address], nil
+}
+
+// ChainID returns ChainIDName, or ChainIDErr if set
+func (m *MockClient) ChainID(ctx context.Context) (string, error) {
+	if m.ChainIDErr != nil {
+		return "", m.ChainIDErr
+	}
+	return m.ChainIDName, nil
+}
+
+// RPCEndpoint returns the configured active endpoint and endpoint count
+func (m *MockClient) RPCEndpoint() (active, total int) {
+	if m.Endpoints == 0 {
+		return 1, 1
+	}
+	return m.ActiveEndpoint, m.Endpoints
+}
+
+// FeeToken returns the token used to pay transaction fees
+func (m *MockClient) FeeToken() string {
+	return m.FeeTokenSym
+}
+
+// AccountAddresses returns Accounts
+func (m *MockClient) AccountAddresses() []string {
+	return m.Accounts
+}
+
+// TransferCount returns the number of successful transfers
+func (m *MockClient) TransferCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.Transfers)
+}
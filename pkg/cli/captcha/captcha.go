@@ -0,0 +1,239 @@
+// Package captcha asks a short human-verification question before the CLI
+// requests tokens. It is a speed bump for naive scripts, not a security
+// boundary: the server's proof of work is what actually costs an abuser.
+package captcha
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Kind is a style of verification question
+type Kind int
+
+const (
+	Arithmetic  Kind = iota // "What is 7 + 5?"
+	WordProblem             // A sentence wrapping a small calculation
+	Logic                   // Sequences, comparisons and parity
+)
+
+// Difficulty scales the numbers and operations used in questions
+type Difficulty int
+
+const (
+	Easy   Difficulty = iota // Single-digit addition
+	Medium                   // Two-digit numbers, subtraction and multiplication
+	Hard                     // Mixed operations and longer sequences
+)
+
+// Config selects the questions asked
+type Config struct {
+	Difficulty Difficulty
+	Kinds      []Kind // Question styles to pick from at random (empty = all)
+}
+
+// DefaultConfig asks easy questions of every kind
+func DefaultConfig() Config {
+	return Config{Difficulty: Easy}
+}
+
+// Question is a prompt and its expected answer
+type Question struct {
+	Prompt string
+	Answer string
+}
+
+// Check reports whether input answers the question
+func (q Question) Check(input string) bool {
+	return CheckAnswer(input, q.Answer)
+}
+
+// Generator produces random questions for a Config
+type Generator struct {
+	config Config
+	rng    *rand.Rand
+}
+
+// NewGenerator creates a generator seeded from the system's randomness
+func NewGenerator(cfg Config) *Generator {
+	return newGenerator(cfg, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+}
+
+// newGenerator creates a generator with a fixed source, for tests
+func newGenerator(cfg Config, rng *rand.Rand) *Generator {
+	if len(cfg.Kinds) == 0 {
+		cfg.Kinds = []Kind{Arithmetic, WordProblem, Logic}
+	}
+	return &Generator{config: cfg, rng: rng}
+}
+
+// Next returns a new random question
+func (g *Generator) Next() Question {
+	switch g.config.Kinds[g.rng.IntN(len(g.config.Kinds))] {
+	case WordProblem:
+		return g.wordProblem()
+	case Logic:
+		return g.logic()
+	default:
+		return g.arithmetic()
+	}
+}
+
+// between returns a random integer in [lo, hi]
+func (g *Generator) between(lo, hi int) int {
+	return lo + g.rng.IntN(hi-lo+1)
+}
+
+// operands returns two random operands sized for the difficulty
+func (g *Generator) operands() (int, int) {
+	switch g.config.Difficulty {
+	case Easy:
+		return g.between(1, 9), g.between(1, 9)
+	case Medium:
+		return g.between(10, 50), g.between(2, 9)
+	default:
+		return g.between(20, 99), g.between(3, 12)
+	}
+}
+
+func (g *Generator) arithmetic() Question {
+	a, b := g.operands()
+	if g.config.Difficulty == Easy {
+		return numeric(fmt.Sprintf("What is %d + %d?", a, b), a+b)
+	}
+
+	switch g.rng.IntN(3) {
+	case 0:
+		return numeric(fmt.Sprintf("What is %d - %d?", a, b), a-b)
+	case 1:
+		if g.config.Difficulty == Hard {
+			c := g.between(1, 20)
+			return numeric(fmt.Sprintf("What is %d × %d + %d?", b, b+1, c), b*(b+1)+c)
+		}
+		return numeric(fmt.Sprintf("What is %d × %d?", b, b+1), b*(b+1))
+	default:
+		return numeric(fmt.Sprintf("What is %d + %d?", a, b), a+b)
+	}
+}
+
+func (g *Generator) wordProblem() Question {
+	a, b := g.operands()
+	switch g.rng.IntN(3) {
+	case 0:
+		return numeric(fmt.Sprintf("A wallet holds %d STRK and receives %d more. How many STRK does it hold?", a, b), a+b)
+	case 1:
+		return numeric(fmt.Sprintf("A faucet has %d tokens and sends out %d. How many are left?", a+b, b), a)
+	default:
+		return numeric(fmt.Sprintf("There are %d blocks with %d transactions each. How many transactions is that?", b, a), a*b)
+	}
+}
+
+func (g *Generator) logic() Question {
+	a, b := g.operands()
+	switch g.rng.IntN(3) {
+	case 0:
+		// Harder sequences use a larger step and hide a later term
+		step, shown := g.between(1, 3), 3
+		if g.config.Difficulty != Easy {
+			step, shown = g.between(3, 9), 4
+		}
+		terms := make([]string, shown)
+		for i := range terms {
+			terms[i] = strconv.Itoa(a + i*step)
+		}
+		return numeric(fmt.Sprintf("What number comes next: %s, ?", strings.Join(terms, ", ")), a+shown*step)
+	case 1:
+		if a == b {
+			b++
+		}
+		return numeric(fmt.Sprintf("Which is larger: %d or %d?", a, b), max(a, b))
+	default:
+		n := a + b
+		answer := "odd"
+		if n%2 == 0 {
+			answer = "even"
+		}
+		return Question{Prompt: fmt.Sprintf("Is %d even or odd?", n), Answer: answer}
+	}
+}
+
+// numeric builds a question with an integer answer
+func numeric(prompt string, answer int) Question {
+	return Question{Prompt: prompt, Answer: strconv.Itoa(answer)}
+}
+
+// CheckAnswer compares a typed answer with the expected one, ignoring
+// surrounding whitespace, quotes, a trailing period and case. Numeric answers
+// match any spelling of the same number, so "12", "+12" and "12.0" all pass.
+func CheckAnswer(input, want string) bool {
+	got := strings.TrimSpace(input)
+	got = strings.TrimSuffix(got, ".")
+	got = strings.Trim(got, `"'`)
+	got = strings.TrimSpace(got)
+	if got == "" {
+		return false
+	}
+
+	if wantNum, err := strconv.ParseFloat(want, 64); err == nil {
+		gotNum, err := strconv.ParseFloat(got, 64)
+		return err == nil && gotNum == wantNum
+	}
+	return strings.EqualFold(got, want)
+}
+
+// AskQuestionWithRetries asks default questions on the terminal, a new one
+// per attempt, and reports whether one was answered correctly within
+// maxAttempts
+func AskQuestionWithRetries(maxAttempts int) (bool, error) {
+	return AskWithConfig(DefaultConfig(), maxAttempts)
+}
+
+// AskWithConfig is AskQuestionWithRetries with a chosen difficulty and kinds
+func AskWithConfig(cfg Config, maxAttempts int) (bool, error) {
+	return ask(os.Stdin, os.Stdout, NewGenerator(cfg), maxAttempts)
+}
+
+// ask runs the question loop over in and out
+func ask(in io.Reader, out io.Writer, gen *Generator, maxAttempts int) (bool, error) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	divider := strings.Repeat("═", 55)
+
+	fmt.Fprintln(out, divider)
+	fmt.Fprintln(out, "  Quick Verification (helps prevent bot abuse)")
+	fmt.Fprintln(out, divider)
+	fmt.Fprintln(out)
+
+	reader := bufio.NewReader(in)
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		q := gen.Next()
+		fmt.Fprintf(out, "  %s ", q.Prompt)
+
+		line, err := reader.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			fmt.Fprintln(out)
+			return false, fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		if q.Check(line) {
+			fmt.Fprintf(out, "\n  %s\n\n", green("✓ Correct!"))
+			return true, nil
+		}
+
+		if left := maxAttempts - attempt; left > 0 {
+			fmt.Fprintf(out, "\n  %s\n\n", red(fmt.Sprintf("✗ Incorrect, try another one (%d attempt(s) left)", left)))
+		} else {
+			fmt.Fprintf(out, "\n  %s\n\n", red("✗ Incorrect"))
+		}
+	}
+
+	return false, nil
+}
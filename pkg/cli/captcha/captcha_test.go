@@ -0,0 +1,119 @@
+package captcha
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAnswer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		ok    bool
+	}{
+		{"exact number", "12", "12", true},
+		{"surrounding whitespace", "  12 \n", "12", true},
+		{"windows line ending", "12\r\n", "12", true},
+		{"explicit sign", "+12", "12", true},
+		{"decimal form", "12.0", "12", true},
+		{"trailing period", "12.", "12", true},
+		{"negative number", "-3", "-3", true},
+		{"wrong number", "13", "12", false},
+		{"number with text", "12 apples", "12", false},
+		{"empty", "", "12", false},
+		{"only whitespace", "   ", "12", false},
+		{"word", "even", "even", true},
+		{"word in another case", "EVEN", "even", true},
+		{"quoted word", `"Odd"`, "odd", true},
+		{"wrong word", "odd", "even", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.ok, CheckAnswer(tt.input, tt.want))
+		})
+	}
+}
+
+func TestGeneratorAnswersAreConsistent(t *testing.T) {
+	for _, difficulty := range []Difficulty{Easy, Medium, Hard} {
+		for _, kind := range []Kind{Arithmetic, WordProblem, Logic} {
+			gen := newGenerator(Config{Difficulty: difficulty, Kinds: []Kind{kind}}, rand.New(rand.NewPCG(1, 2)))
+			for range 200 {
+				q := gen.Next()
+				require.NotEmpty(t, q.Prompt)
+				require.NotEmpty(t, q.Answer, q.Prompt)
+				assert.True(t, q.Check(q.Answer), q.Prompt)
+				assert.False(t, strings.HasPrefix(q.Answer, "-"), "answers stay positive: %s = %s", q.Prompt, q.Answer)
+			}
+		}
+	}
+}
+
+func TestGeneratorVariety(t *testing.T) {
+	gen := newGenerator(DefaultConfig(), rand.New(rand.NewPCG(3, 4)))
+
+	prompts := make(map[string]bool)
+	for range 50 {
+		prompts[gen.Next().Prompt] = true
+	}
+	assert.Greater(t, len(prompts), 10)
+}
+
+func TestEasyArithmetic(t *testing.T) {
+	gen := newGenerator(Config{Difficulty: Easy, Kinds: []Kind{Arithmetic}}, rand.New(rand.NewPCG(5, 6)))
+	for range 100 {
+		q := gen.Next()
+		assert.Regexp(t, `^What is [1-9] \+ [1-9]\?$`, q.Prompt)
+	}
+}
+
+func TestAsk(t *testing.T) {
+	newGen := func() *Generator {
+		return newGenerator(DefaultConfig(), rand.New(rand.NewPCG(7, 8)))
+	}
+	// The same seed yields the same questions, so answers can be scripted
+	answers := func(n int) []string {
+		gen := newGen()
+		out := make([]string, n)
+		for i := range out {
+			out[i] = gen.Next().Answer
+		}
+		return out
+	}(3)
+
+	t.Run("correct on the last attempt", func(t *testing.T) {
+		var out bytes.Buffer
+		in := strings.NewReader("wrong\nwrong\n" + answers[2] + "\n")
+		ok, err := ask(in, &out, newGen(), 3)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Contains(t, out.String(), "2 attempt(s) left")
+		assert.Contains(t, out.String(), "Correct!")
+	})
+
+	t.Run("answer without trailing newline", func(t *testing.T) {
+		ok, err := ask(strings.NewReader(answers[0]), io.Discard, newGen(), 3)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		ok, err := ask(strings.NewReader("x\nx\nx\nx\n"), io.Discard, newGen(), 3)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("input closed", func(t *testing.T) {
+		ok, err := ask(strings.NewReader("x\n"), io.Discard, newGen(), 3)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
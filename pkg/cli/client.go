@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -12,6 +15,70 @@ import (
 type APIClient struct {
 	baseURL string
 	client  *resty.Client
+	ctx     context.Context // Cancels in-flight requests and retry waits
+}
+
+// APIError is an error response returned by the faucet API
+type APIError struct {
+	StatusCode int
+	Code       string // One of the models.ErrCode* constants, empty for older servers
+	Message    string
+	RequestID  string        // Server correlation ID, quote it in bug reports
+	RetryAfter time.Duration // From the Retry-After header, 0 when absent
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("API error: %s (request ID: %s)", e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("API error: %s", e.Message)
+}
+
+// newAPIError builds an APIError from a decoded error response
+func newAPIError(resp *resty.Response, errResponse models.ErrorResponse) *APIError {
+	requestID := errResponse.RequestID
+	if requestID == "" {
+		requestID = resp.Header().Get("X-Request-ID")
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode(),
+		Code:       errResponse.Code,
+		Message:    errResponse.Error,
+		RequestID:  requestID,
+		RetryAfter: parseRetryAfter(resp.Header().Get("Retry-After"), time.Now()),
+	}
+}
+
+// checkResponse returns the error a response stands for, if any: an
+// UnexpectedResponseError when something other than the faucet API answered,
+// as a proxy error page does, or else the API's own error
+func checkResponse(resp *resty.Response, errResponse models.ErrorResponse) error {
+	if !isAPIResponse(resp.Header(), resp.Body()) {
+		return newUnexpectedResponseError(resp.StatusCode(), resp.Header(), resp.Body())
+	}
+	if !resp.IsError() {
+		return nil
+	}
+	if errResponse.Error != "" {
+		return newAPIError(resp, errResponse)
+	}
+	return fmt.Errorf("API returned status %d", resp.StatusCode())
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as
+// an HTTP date. Missing, malformed and past values yield 0.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
 }
 
 // NewAPIClient creates a new API client
@@ -23,11 +90,63 @@ func NewAPIClient(baseURL string) *APIClient {
 	return &APIClient{
 		baseURL: baseURL,
 		client:  client,
+		ctx:     context.Background(),
 	}
 }
 
-// GetChallenge fetches a new PoW challenge with retry on server wake-up
-func (c *APIClient) GetChallenge() (*models.ChallengeResponse, error) {
+// SetTimeout sets the timeout for each request made by the client
+func (c *APIClient) SetTimeout(timeout time.Duration) {
+	c.client.SetTimeout(timeout)
+}
+
+// SetContext sets the context for requests made by the client, so a deadline
+// spanning several requests can cut them short
+func (c *APIClient) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// newRequest starts a request bound to the client's context
+func (c *APIClient) newRequest() *resty.Request {
+	return c.client.R().SetContext(c.ctx)
+}
+
+// Health checks that the faucet API and its Redis backend are up
+func (c *APIClient) Health() (*models.HealthResponse, error) {
+	var response models.HealthResponse
+	var errResponse models.ErrorResponse
+
+	resp, err := c.newRequest().
+		SetResult(&response).
+		SetError(&errResponse).
+		Get(fmt.Sprintf("%s/health", c.baseURL))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach faucet: %w", err)
+	}
+
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetChallenge fetches a new PoW challenge with retry on server wake-up. A
+// non-empty token binds the challenge to that token, which faucets with
+// REQUIRE_CHALLENGE_TOKEN set insist on.
+func (c *APIClient) GetChallenge(token string) (*models.ChallengeResponse, error) {
+	return c.fetchChallenge(fmt.Sprintf("%s/api/v1/challenge", c.baseURL), token)
+}
+
+// GetBonusChallenge fetches a harder challenge whose solution pays for one
+// request past the daily limit, if the faucet offers bonus requests
+func (c *APIClient) GetBonusChallenge(token string) (*models.ChallengeResponse, error) {
+	return c.fetchChallenge(fmt.Sprintf("%s/api/v1/challenge?bonus=true", c.baseURL), token)
+}
+
+// fetchChallenge requests a challenge for token from url, retrying while the
+// server wakes up
+func (c *APIClient) fetchChallenge(url, token string) (*models.ChallengeResponse, error) {
 	var response models.ChallengeResponse
 	var errResponse models.ErrorResponse
 
@@ -35,30 +154,37 @@ func (c *APIClient) GetChallenge() (*models.ChallengeResponse, error) {
 	retryDelay := 60 * time.Second // 1 minute between retries
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		resp, err := c.client.R().
+		req := c.newRequest()
+		if token != "" {
+			req.SetQueryParam("token", token)
+		}
+		resp, err := req.
 			SetResult(&response).
 			SetError(&errResponse).
-			Post(fmt.Sprintf("%s/api/v1/challenge", c.baseURL))
+			Post(url)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to get challenge: %w", err)
 		}
 
-		// Check if server is waking up (502/503)
-		if resp.StatusCode() == 502 || resp.StatusCode() == 503 {
+		// Check if server is waking up (502/503). A busy or slow server also
+		// answers 503, but with an error code, and waiting a minute won't help it.
+		if (resp.StatusCode() == 502 || resp.StatusCode() == 503) &&
+			errResponse.Code != models.ErrCodeServerBusy && errResponse.Code != models.ErrCodeRequestTimeout {
 			if attempt < maxRetries {
 				fmt.Printf("\n⏳ Server is waking up... (attempt %d/%d, waiting %ds)\n", attempt, maxRetries, int(retryDelay.Seconds()))
-				time.Sleep(retryDelay)
+				select {
+				case <-time.After(retryDelay):
+				case <-c.ctx.Done():
+					return nil, fmt.Errorf("failed to get challenge: %w", c.ctx.Err())
+				}
 				continue
 			}
 			return nil, fmt.Errorf("server is still starting up after %d attempts. Please try again in a moment", maxRetries)
 		}
 
-		if resp.IsError() {
-			if errResponse.Error != "" {
-				return nil, fmt.Errorf("API error: %s", errResponse.Error)
-			}
-			return nil, fmt.Errorf("API returned status %d", resp.StatusCode())
+		if err := checkResponse(resp, errResponse); err != nil {
+			return nil, err
 		}
 
 		return &response, nil
@@ -67,12 +193,17 @@ func (c *APIClient) GetChallenge() (*models.ChallengeResponse, error) {
 	return nil, fmt.Errorf("max retries exceeded")
 }
 
-// RequestTokens requests tokens from the faucet
-func (c *APIClient) RequestTokens(req models.FaucetRequest) (*models.FaucetResponse, error) {
+// RequestTokens requests tokens from the faucet. With wait, the faucet only
+// responds once the transfers reach its confirmation level.
+func (c *APIClient) RequestTokens(req models.FaucetRequest, wait bool) (*models.FaucetResponse, error) {
 	var response models.FaucetResponse
 	var errResponse models.ErrorResponse
 
-	resp, err := c.client.R().
+	r := c.newRequest()
+	if wait {
+		r.SetQueryParam("wait", "true")
+	}
+	resp, err := r.
 		SetBody(req).
 		SetResult(&response).
 		SetError(&errResponse).
@@ -82,15 +213,11 @@ func (c *APIClient) RequestTokens(req models.FaucetRequest) (*models.FaucetRespo
 		return nil, fmt.Errorf("failed to request tokens: %w", err)
 	}
 
-	if resp.IsError() {
-		if errResponse.Error != "" {
-			msg := errResponse.Error
-			if errResponse.RemainingHours != nil {
-				msg = fmt.Sprintf("%s (%.1f hours remaining)", msg, *errResponse.RemainingHours)
-			}
-			return nil, fmt.Errorf("API error: %s", msg)
-		}
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode())
+	if errResponse.Error != "" && errResponse.RemainingHours != nil {
+		errResponse.Error = fmt.Sprintf("%s (%.1f hours remaining)", errResponse.Error, *errResponse.RemainingHours)
+	}
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
@@ -101,7 +228,7 @@ func (c *APIClient) GetStatus(address string) (*models.StatusResponse, error) {
 	var response models.StatusResponse
 	var errResponse models.ErrorResponse
 
-	resp, err := c.client.R().
+	resp, err := c.newRequest().
 		SetResult(&response).
 		SetError(&errResponse).
 		Get(fmt.Sprintf("%s/api/v1/status/%s", c.baseURL, address))
@@ -110,11 +237,8 @@ func (c *APIClient) GetStatus(address string) (*models.StatusResponse, error) {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
-	if resp.IsError() {
-		if errResponse.Error != "" {
-			return nil, fmt.Errorf("API error: %s", errResponse.Error)
-		}
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode())
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
@@ -125,7 +249,7 @@ func (c *APIClient) GetInfo() (*models.InfoResponse, error) {
 	var response models.InfoResponse
 	var errResponse models.ErrorResponse
 
-	resp, err := c.client.R().
+	resp, err := c.newRequest().
 		SetResult(&response).
 		SetError(&errResponse).
 		Get(fmt.Sprintf("%s/api/v1/info", c.baseURL))
@@ -134,11 +258,98 @@ func (c *APIClient) GetInfo() (*models.InfoResponse, error) {
 		return nil, fmt.Errorf("failed to get info: %w", err)
 	}
 
-	if resp.IsError() {
-		if errResponse.Error != "" {
-			return nil, fmt.Errorf("API error: %s", errResponse.Error)
-		}
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode())
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetPoWInfo fetches the faucet's proof-of-work difficulty and its solve time
+// estimate for a typical CPU
+func (c *APIClient) GetPoWInfo() (*models.PoWInfoResponse, error) {
+	var response models.PoWInfoResponse
+	var errResponse models.ErrorResponse
+
+	resp, err := c.newRequest().
+		SetResult(&response).
+		SetError(&errResponse).
+		Get(fmt.Sprintf("%s/api/v1/pow/info", c.baseURL))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PoW info: %w", err)
+	}
+
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetChallenges fetches count PoW challenges in one call, for scripts that
+// send several requests. Each challenge is single-use.
+func (c *APIClient) GetChallenges(count int) ([]models.ChallengeResponse, error) {
+	var response models.ChallengeBatchResponse
+	var errResponse models.ErrorResponse
+
+	resp, err := c.newRequest().
+		SetQueryParam("count", strconv.Itoa(count)).
+		SetResult(&response).
+		SetError(&errResponse).
+		Post(fmt.Sprintf("%s/api/v1/challenges", c.baseURL))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenges: %w", err)
+	}
+
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
+	}
+
+	return response.Challenges, nil
+}
+
+// GetTokens lists the tokens supported by the faucet
+func (c *APIClient) GetTokens() (*models.TokensResponse, error) {
+	var response models.TokensResponse
+	var errResponse models.ErrorResponse
+
+	resp, err := c.newRequest().
+		SetResult(&response).
+		SetError(&errResponse).
+		Get(fmt.Sprintf("%s/api/v1/tokens", c.baseURL))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tokens: %w", err)
+	}
+
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetDistributionSeries gets how much of a token the faucet sent over a window
+// such as 24h, in time buckets
+func (c *APIClient) GetDistributionSeries(token, window string) (*models.DistributionSeriesResponse, error) {
+	var response models.DistributionSeriesResponse
+	var errResponse models.ErrorResponse
+
+	resp, err := c.newRequest().
+		SetQueryParam("token", token).
+		SetQueryParam("window", window).
+		SetResult(&response).
+		SetError(&errResponse).
+		Get(fmt.Sprintf("%s/api/v1/distribution/series", c.baseURL))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distribution series: %w", err)
+	}
+
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
@@ -148,7 +359,7 @@ func (c *APIClient) GetInfo() (*models.InfoResponse, error) {
 func (c *APIClient) Get(path string) ([]byte, error) {
 	var errResponse models.ErrorResponse
 
-	resp, err := c.client.R().
+	resp, err := c.newRequest().
 		SetError(&errResponse).
 		Get(fmt.Sprintf("%s%s", c.baseURL, path))
 
@@ -156,11 +367,8 @@ func (c *APIClient) Get(path string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to GET %s: %w", path, err)
 	}
 
-	if resp.IsError() {
-		if errResponse.Error != "" {
-			return nil, fmt.Errorf("API error: %s", errResponse.Error)
-		}
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode())
+	if err := checkResponse(resp, errResponse); err != nil {
+		return nil, err
 	}
 
 	return resp.Body(), nil
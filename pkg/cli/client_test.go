@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter("-5", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now), "dates in the past mean retry now")
+}
+
+func TestUnexpectedResponse(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><head><title>502 Bad Gateway</title></head><body><h1>Bad Gateway</h1></body></html>"))
+	})
+	mux.HandleFunc("/api/v1/faucet", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Server", "cloudflare")
+		w.Header().Set("CF-Ray", "8a1b2c3d4e5f-AMS")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("<html><body><h1>Sorry, you have been blocked</h1></body></html>"))
+	})
+	mux.HandleFunc("/api/v1/status/", func(w http.ResponseWriter, r *http.Request) {
+		// A captive portal or misconfigured proxy can even answer 200
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<p>Please log in to the   network &amp; continue</p>"))
+	})
+	mux.HandleFunc("/api/v1/quota", func(w http.ResponseWriter, r *http.Request) {
+		// JSON without a content type is still the API
+		w.Header().Set("Content-Type", "")
+		w.Write([]byte(`{"ip":"127.0.0.1"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewAPIClient(server.URL)
+
+	_, err := client.GetInfo()
+	var unexpected *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpected)
+	assert.False(t, unexpected.Blocked)
+	assert.Equal(t, "text/html", unexpected.ContentType)
+	assert.Equal(t, `unexpected HTTP 502 response that is not from the faucet API (text/html): "502 Bad Gateway"`, err.Error())
+	assert.Contains(t, ErrorHint(err), "--api-url")
+
+	_, err = client.RequestTokens(models.FaucetRequest{Address: "0x1", Token: "STRK"}, false)
+	require.ErrorAs(t, err, &unexpected)
+	assert.True(t, unexpected.Blocked)
+	assert.Equal(t, "request blocked by Cloudflare (HTTP 403) (Ray ID: 8a1b2c3d4e5f-AMS)", err.Error())
+	assert.Contains(t, ErrorHint(err), "firewall")
+
+	_, err = client.GetStatus("0x1")
+	require.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, "Please log in to the network & continue", unexpected.Snippet)
+
+	body, err := client.Get("/api/v1/quota")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ip":"127.0.0.1"}`, string(body))
+}
+
+func TestBodySnippet(t *testing.T) {
+	assert.Equal(t, "Just a moment...", bodySnippet([]byte("<!DOCTYPE html><html><head><TITLE>\n  Just a moment...\n</TITLE>")))
+	assert.Equal(t, "plain text error", bodySnippet([]byte("plain text error\n")))
+
+	long := bodySnippet([]byte(strings.Repeat("x", 500)))
+	assert.Len(t, long, maxSnippetLength+len("..."))
+}
+
+func TestExplainErrorCode(t *testing.T) {
+	// Every code the server can send is explained
+	for _, code := range models.ErrorCodes {
+		e, ok := ExplainErrorCode(code)
+		assert.True(t, ok, code)
+		assert.NotEmpty(t, e.Meaning, code)
+		assert.NotEmpty(t, e.Hint, code)
+	}
+
+	e, ok := ExplainErrorCode("NEW_CODE")
+	assert.False(t, ok)
+	assert.Equal(t, ErrorExplanation{Code: "NEW_CODE"}, e)
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chartToken  string
+	chartWindow string
+)
+
+var chartCmd = &cobra.Command{
+	Use:   "chart",
+	Short: "Chart recent distribution",
+	Long: `Show how much of a token the faucet sent recently as a sparkline.
+
+Windows up to 2h use one bar per minute, longer ones (up to 168h) one bar
+per hour.
+
+Example:
+  starknet-faucet chart
+  starknet-faucet chart --token ETH --window 1h`,
+	RunE: runChart,
+}
+
+func init() {
+	chartCmd.Flags().StringVar(&chartToken, "token", "STRK", "Token to chart")
+	chartCmd.Flags().StringVar(&chartWindow, "window", "24h", "How far back to chart, e.g. 30m or 24h")
+}
+
+func runChart(cmd *cobra.Command, args []string) error {
+	// Create API client
+	client := cli.NewAPIClient(apiURL)
+
+	// Get the series
+	resp, err := client.GetDistributionSeries(utils.NormalizeToken(chartToken), chartWindow)
+	if err != nil {
+		return fmt.Errorf("failed to get distribution series: %w", err)
+	}
+
+	// Print response
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(resp, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		ui.PrintBanner()
+		ui.PrintDistributionSeries(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+// configEnvVars maps config keys to the environment variables that override them
+var configEnvVars = map[string]string{
+	"api-url": "STARKNET_FAUCET_API_URL",
+	"token":   "STARKNET_FAUCET_TOKEN",
+	"json":    "STARKNET_FAUCET_JSON",
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View or change CLI defaults",
+	Long: `View or change the defaults stored in ~/.starknet-faucet.yaml.
+
+Keys:
+  api-url   Faucet API URL
+  token     Default token for 'request' (ETH or STRK)
+  json      Output in JSON format (true or false)
+
+Settings are resolved as: flags > environment > config file > built-in default.
+
+Examples:
+  starknet-faucet config                                  # Show all settings
+  starknet-faucet config get api-url                      # Show one setting
+  starknet-faucet config set api-url https://my-faucet.dev
+  starknet-faucet config set token ETH
+  starknet-faucet config set token ""                     # Clear a setting`,
+	// The config file itself is being edited, so don't apply it to flags
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:              runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <KEY>",
+	Short: "Show a config value",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <KEY> <VALUE>",
+	Short: "Set a config value",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	path, cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Config file: %s\n\n", path)
+	for _, key := range cli.ConfigKeys {
+		value, _ := cfg.Get(key)
+		if value == "" {
+			value = "(not set)"
+		}
+		fmt.Printf("  %-8s %s\n", key, value)
+	}
+
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	_, cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	value, err := cfg.Get(strings.ToLower(args[0]))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	path, cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	key := strings.ToLower(args[0])
+	if err := cfg.Set(key, args[1]); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Saved %s to %s", key, path))
+	return nil
+}
+
+// loadConfigFile loads the config file from its default location
+func loadConfigFile() (string, *cli.FileConfig, error) {
+	path, err := cli.ConfigPath()
+	if err != nil {
+		return "", nil, err
+	}
+
+	cfg, err := cli.LoadConfig(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return path, cfg, nil
+}
+
+// applyConfigDefaults fills flags the user didn't set from the environment,
+// then from the config file, leaving built-in defaults for anything else
+func applyConfigDefaults(cmd *cobra.Command, args []string) error {
+	_, cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range cli.ConfigKeys {
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil || flag.Changed {
+			continue
+		}
+
+		value := os.Getenv(configEnvVars[key])
+		if value == "" {
+			value, _ = cfg.Get(key)
+		}
+		if value == "" {
+			continue
+		}
+
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s setting %q: %w", key, value, err)
+		}
+	}
+
+	return nil
+}
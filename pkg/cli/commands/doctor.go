@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+// doctorTimeout bounds each check so an unreachable server fails quickly
+// instead of waiting out the client's long transaction timeout
+const doctorTimeout = 30 * time.Second
+
+// slowResponseThreshold is the response time above which the server is
+// probably waking up from a cold start
+const slowResponseThreshold = 5 * time.Second
+
+// Check statuses
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorCheck is the result of a single diagnostic check
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // "pass", "warn" or "fail"
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"` // Suggested next step for warnings and failures
+}
+
+// doctorReport is the JSON output of the doctor command
+type doctorReport struct {
+	APIURL string        `json:"api_url"`
+	OK     bool          `json:"ok"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose connectivity to the faucet",
+	Long: `Check that the faucet is reachable and ready to serve your requests.
+
+Checks:
+  • The API URL is reachable
+  • The server reports itself healthy
+  • The server's PoW difficulty and limits
+  • Your current quota
+
+Exits with an error if any check fails, so it can be used in CI.
+
+Examples:
+  starknet-faucet doctor
+  starknet-faucet doctor --json
+  starknet-faucet doctor --api-url http://localhost:3000`,
+	RunE: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	client := cli.NewAPIClient(apiURL)
+	client.SetTimeout(doctorTimeout)
+
+	checks := runDoctorChecks(client)
+
+	failed := 0
+	for _, check := range checks {
+		if check.Status == checkFail {
+			failed++
+		}
+	}
+
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(doctorReport{
+			APIURL: apiURL,
+			OK:     failed == 0,
+			Checks: checks,
+		}, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		fmt.Println()
+		fmt.Printf("Checking %s\n\n", apiURL)
+		for _, check := range checks {
+			ui.PrintCheck(check.Status, check.Name, check.Detail, check.Fix)
+		}
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	if !jsonOut {
+		ui.PrintSuccess("Everything looks good")
+	}
+	return nil
+}
+
+// runDoctorChecks runs each diagnostic in order. Checks that need the server
+// are skipped once it is found to be unreachable.
+func runDoctorChecks(client *cli.APIClient) []doctorCheck {
+	start := time.Now()
+	_, err := client.Health()
+	elapsed := time.Since(start)
+
+	var apiErr *cli.APIError
+	if err != nil && !errors.As(err, &apiErr) {
+		return []doctorCheck{{
+			Name:   "Reachability",
+			Status: checkFail,
+			Detail: err.Error(),
+			Fix:    "Check your internet connection and that --api-url points to a running faucet.",
+		}}
+	}
+
+	checks := []doctorCheck{reachabilityCheck(elapsed)}
+
+	if err != nil {
+		checks = append(checks, doctorCheck{
+			Name:   "Server health",
+			Status: checkFail,
+			Detail: err.Error(),
+			Fix:    cli.ErrorHint(err),
+		})
+	} else {
+		checks = append(checks, doctorCheck{
+			Name:   "Server health",
+			Status: checkPass,
+			Detail: "ok",
+		})
+	}
+
+	checks = append(checks, configCheck(client), quotaCheck(client))
+	return checks
+}
+
+// reachabilityCheck reports how quickly the server answered
+func reachabilityCheck(elapsed time.Duration) doctorCheck {
+	check := doctorCheck{
+		Name:   "Reachability",
+		Status: checkPass,
+		Detail: fmt.Sprintf("responded in %s", elapsed.Round(time.Millisecond)),
+	}
+	if elapsed > slowResponseThreshold {
+		check.Status = checkWarn
+		check.Fix = "The server was slow to respond, it may have been waking up. Run doctor again to confirm."
+	}
+	return check
+}
+
+// configCheck reports the server's PoW difficulty and limits
+func configCheck(client *cli.APIClient) doctorCheck {
+	info, err := client.GetInfo()
+	if err != nil {
+		return doctorCheck{
+			Name:   "Faucet config",
+			Status: checkFail,
+			Detail: err.Error(),
+			Fix:    "The server may be an incompatible version. Make sure your CLI is up to date.",
+		}
+	}
+
+	return doctorCheck{
+		Name:   "Faucet config",
+		Status: checkPass,
+		Detail: fmt.Sprintf("%s, PoW difficulty %d, %d requests/day per IP, %dh token throttle",
+			info.Network, info.PoW.Difficulty, info.Limits.DailyRequestsPerIP, info.Limits.TokenThrottleHours),
+	}
+}
+
+// quotaCheck reports the caller's remaining daily quota
+func quotaCheck(client *cli.APIClient) doctorCheck {
+	resp, err := client.Get("/api/v1/quota")
+	if err != nil {
+		return doctorCheck{
+			Name:   "Your quota",
+			Status: checkFail,
+			Detail: err.Error(),
+			Fix:    cli.ErrorHint(err),
+		}
+	}
+
+	var quota struct {
+		DailyLimit struct {
+			Used       int  `json:"used"`
+			Total      int  `json:"total"`
+			Remaining  int  `json:"remaining"`
+			InCooldown bool `json:"in_cooldown"`
+		} `json:"daily_limit"`
+	}
+	if err := json.Unmarshal(resp, &quota); err != nil {
+		return doctorCheck{
+			Name:   "Your quota",
+			Status: checkFail,
+			Detail: fmt.Sprintf("failed to parse quota response: %v", err),
+		}
+	}
+
+	daily := quota.DailyLimit
+	check := doctorCheck{
+		Name:   "Your quota",
+		Status: checkPass,
+		Detail: fmt.Sprintf("%d/%d requests used, %d remaining", daily.Used, daily.Total, daily.Remaining),
+	}
+	if daily.InCooldown || daily.Remaining == 0 {
+		check.Status = checkWarn
+		if daily.InCooldown {
+			check.Detail += " (in cooldown)"
+		}
+		check.Fix = "Run 'starknet-faucet quota' to see when you can request again."
+	}
+	return check
+}
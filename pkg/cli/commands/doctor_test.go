@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+)
+
+// newFaucetServer starts a fake faucet API. healthStatus is the status code of
+// /health and quota is the /api/v1/quota body.
+func newFaucetServer(t *testing.T, healthStatus int, quota string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(healthStatus)
+		if healthStatus == http.StatusOK {
+			w.Write([]byte(`{"status":"ok","timestamp":1}`))
+			return
+		}
+		w.Write([]byte(`{"error":"Redis unavailable","code":"SERVICE_UNAVAILABLE"}`))
+	})
+	mux.HandleFunc("/api/v1/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"network":"sepolia","limits":{"daily_requests_per_ip":5,"token_throttle_hours":1},"pow":{"enabled":true,"difficulty":4}}`))
+	})
+	mux.HandleFunc("/api/v1/pow/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"difficulty":4,"algorithm":"sha256","expected_attempts":65536,"assumed_hashrate":500000,"estimated_solve_seconds":1}`))
+	})
+	mux.HandleFunc("/api/v1/quota", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(quota))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func checkStatuses(checks []doctorCheck) map[string]string {
+	statuses := make(map[string]string)
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestDoctorChecksHealthy(t *testing.T) {
+	server := newFaucetServer(t, http.StatusOK, `{"daily_limit":{"used":1,"total":5,"remaining":4,"in_cooldown":false}}`)
+
+	checks := runDoctorChecks(cli.NewAPIClient(server.URL))
+
+	assert.Equal(t, map[string]string{
+		"Reachability":  checkPass,
+		"Server health": checkPass,
+		"Faucet config": checkPass,
+		"Your quota":    checkPass,
+	}, checkStatuses(checks))
+	assert.Contains(t, checks[2].Detail, "PoW difficulty 4")
+	assert.Contains(t, checks[3].Detail, "1/5 requests used")
+}
+
+func TestDoctorChecksUnhealthyAndCooldown(t *testing.T) {
+	server := newFaucetServer(t, http.StatusServiceUnavailable, `{"daily_limit":{"used":5,"total":5,"remaining":0,"in_cooldown":true}}`)
+
+	checks := runDoctorChecks(cli.NewAPIClient(server.URL))
+
+	statuses := checkStatuses(checks)
+	assert.Equal(t, checkPass, statuses["Reachability"])
+	assert.Equal(t, checkFail, statuses["Server health"])
+	assert.Equal(t, checkWarn, statuses["Your quota"])
+	assert.NotEmpty(t, checks[1].Fix)
+}
+
+func TestDoctorChecksUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	checks := runDoctorChecks(cli.NewAPIClient(url))
+
+	require.Len(t, checks, 1)
+	assert.Equal(t, "Reachability", checks[0].Name)
+	assert.Equal(t, checkFail, checks[0].Status)
+	assert.NotEmpty(t, checks[0].Fix)
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	clipow "github.com/Giri-Aayush/starknet-faucet/pkg/cli/pow"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxDifficulty is the most leading zeros a SHA-256 hex digest can have
+const maxDifficulty = 64
+
+// calibrationTime is how long estimate hashes to measure the local hash rate
+var calibrationTime = time.Second
+
+var estimateCmd = &cobra.Command{
+	Use:     "estimate [DIFFICULTY]",
+	Aliases: []string{"pow-estimate"},
+	Short:   "Estimate how long the proof of work will take",
+	Long: `Measure this machine's hash rate and estimate how long solving a
+proof-of-work challenge takes, before spending time on a request.
+
+Without a difficulty, the faucet's current difficulty is fetched from the
+server, along with its estimate for a typical CPU. Solving is
+probabilistic: a single solve can take several times the estimate, or
+much less.
+
+Examples:
+  starknet-faucet estimate         # Use the faucet's current difficulty
+  starknet-faucet estimate 6       # Estimate a specific difficulty
+  starknet-faucet estimate --json  # {"difficulty", "est_seconds", "hashrate", "typical_seconds"}`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEstimate,
+}
+
+// estimateResult is the --json output of estimate
+type estimateResult struct {
+	Difficulty int     `json:"difficulty"`
+	EstSeconds float64 `json:"est_seconds"`
+	HashRate   float64 `json:"hashrate"` // Hashes per second
+
+	TypicalSeconds float64 `json:"typical_seconds,omitempty"` // The faucet's estimate for a typical CPU, omitted for a given difficulty
+}
+
+func runEstimate(cmd *cobra.Command, args []string) error {
+	difficulty, typical, err := estimateDifficulty(args)
+	if err != nil {
+		return err
+	}
+
+	var result estimateResult
+	if difficulty > 0 {
+		s := ui.NewSpinner("Measuring hash rate...")
+		if !jsonOut {
+			s.Start()
+		}
+		hashRate := clipow.MeasureHashRate(calibrationTime)
+		s.Stop()
+
+		result = estimateResult{
+			Difficulty:     difficulty,
+			EstSeconds:     clipow.EstimateSolveTimeAt(difficulty, hashRate).Seconds(),
+			HashRate:       hashRate,
+			TypicalSeconds: typical.Seconds(),
+		}
+	}
+
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	if difficulty == 0 {
+		ui.PrintSuccess("Proof of work is disabled on this faucet, requests need no solving")
+		return nil
+	}
+
+	ui.PrintStep(fmt.Sprintf("Hash rate: %.0f hashes/s", result.HashRate))
+	ui.PrintSuccess(fmt.Sprintf("Difficulty %d takes %d attempts on average, about %s on this machine",
+		difficulty, clipow.ExpectedAttempts(difficulty), formatEstimate(result.EstSeconds)))
+	if typical > 0 {
+		ui.PrintInfo(fmt.Sprintf("The faucet expects about %s on a typical CPU", formatEstimate(result.TypicalSeconds)))
+	}
+	return nil
+}
+
+// estimateDifficulty returns the difficulty given on the command line, or
+// else the faucet's current one with its estimate for a typical CPU. The
+// difficulty is 0 when the faucet has PoW disabled.
+func estimateDifficulty(args []string) (int, time.Duration, error) {
+	if len(args) == 1 {
+		difficulty, err := strconv.Atoi(args[0])
+		if err != nil || difficulty < 1 || difficulty > maxDifficulty {
+			return 0, 0, fmt.Errorf("difficulty must be a number from 1 to %d", maxDifficulty)
+		}
+		return difficulty, 0, nil
+	}
+
+	client := cli.NewAPIClient(apiURL)
+	if powInfo, err := client.GetPoWInfo(); err == nil {
+		return powInfo.Difficulty, time.Duration(powInfo.EstimatedSolveSeconds) * time.Second, nil
+	}
+
+	// Older faucets only report the difficulty in /info
+	info, err := client.GetInfo()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the faucet's difficulty: %w", err)
+	}
+	if !info.PoW.Enabled {
+		return 0, 0, nil
+	}
+	return info.PoW.Difficulty, 0, nil
+}
+
+// formatEstimate formats an estimated solve time for people
+func formatEstimate(seconds float64) string {
+	switch {
+	case seconds < 1:
+		return "under a second"
+	case seconds >= 365*24*3600:
+		return "more than a year"
+	default:
+		return (time.Duration(seconds) * time.Second).String()
+	}
+}
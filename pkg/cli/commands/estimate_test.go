@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/testutil"
+)
+
+func TestEstimate(t *testing.T) {
+	server := newFaucetServer(t, http.StatusOK, `{}`)
+
+	oldURL, oldJSON, oldCalibration := apiURL, jsonOut, calibrationTime
+	t.Cleanup(func() { apiURL, jsonOut, calibrationTime = oldURL, oldJSON, oldCalibration })
+	apiURL, jsonOut, calibrationTime = server.URL, true, 10*time.Millisecond
+
+	// Without an argument the server's difficulty is used
+	var runErr error
+	out := testutil.CaptureStdout(t, func() { runErr = runEstimate(estimateCmd, nil) })
+	require.NoError(t, runErr)
+
+	var result estimateResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	assert.Equal(t, 4, result.Difficulty)
+	assert.Greater(t, result.HashRate, 0.0)
+	assert.InDelta(t, 65536/result.HashRate, result.EstSeconds, 1e-6)
+	assert.Equal(t, 1.0, result.TypicalSeconds, "the faucet's own estimate")
+
+	out = testutil.CaptureStdout(t, func() { runErr = runEstimate(estimateCmd, []string{"2"}) })
+	require.NoError(t, runErr)
+	result = estimateResult{}
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	assert.Equal(t, 2, result.Difficulty)
+	assert.Zero(t, result.TypicalSeconds)
+
+	for _, arg := range []string{"0", "65", "six"} {
+		assert.Error(t, runEstimate(estimateCmd, []string{arg}), arg)
+	}
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [CODE | JSON | -]",
+	Short: "Explain an error code from the faucet",
+	Long:  explainHelp(),
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runExplain,
+}
+
+// explainHelp is the long help of explain, listing the codes it knows
+func explainHelp() string {
+	var b strings.Builder
+	b.WriteString(`Explain what a faucet error code means and what to do about it.
+
+Pass a code, or an error response or --json output holding "code" fields;
+"-" reads it from stdin. Without an argument, every code is described.
+
+Examples:
+  starknet-faucet explain RATE_LIMITED
+  starknet-faucet request --address-file addresses.txt --json --yes | starknet-faucet explain -
+
+Codes:
+`)
+	for _, code := range models.ErrorCodes {
+		fmt.Fprintf(&b, "  %s\n", code)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	codes := models.ErrorCodes
+	if len(args) == 1 {
+		input := args[0]
+		if input == "-" {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("failed to read stdin: %w", err)
+			}
+			input = string(data)
+		}
+
+		var err error
+		if codes, err = explainCodes(input); err != nil {
+			return err
+		}
+	}
+
+	explanations := make([]cli.ErrorExplanation, len(codes))
+	var unknown []string
+	for i, code := range codes {
+		var ok bool
+		if explanations[i], ok = cli.ExplainErrorCode(code); !ok {
+			unknown = append(unknown, code)
+		}
+	}
+
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(explanations, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		for i, e := range explanations {
+			if e.Meaning == "" {
+				continue
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(e.Code)
+			fmt.Printf("  What happened: %s\n", e.Meaning)
+			fmt.Printf("  What to do:    %s\n", e.Hint)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown error code %s; run 'starknet-faucet explain' to list them, or update your CLI",
+			strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// explainCodes returns the error codes in input: the "code" fields of JSON,
+// or else input itself as one code
+func explainCodes(input string) ([]string, error) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "{") && !strings.HasPrefix(input, "[") {
+		if input == "" {
+			return nil, fmt.Errorf("no error code given")
+		}
+		return []string{strings.ToUpper(input)}, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(input), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	var codes []string
+	seen := make(map[string]bool)
+	collectCodes(doc, func(code string) {
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	})
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no error codes found in the JSON")
+	}
+	return codes, nil
+}
+
+// collectCodes calls found with every string "code" field in a decoded JSON
+// document. Arrays are walked in order and objects by key, so the order is
+// stable.
+func collectCodes(v interface{}, found func(string)) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if code, ok := v["code"].(string); ok {
+			found(code)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectCodes(v[key], found)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectCodes(child, found)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/testutil"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+)
+
+func TestExplainCodes(t *testing.T) {
+	codes, err := explainCodes(" rate_limited\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.ErrCodeRateLimited}, codes)
+
+	// --count and --address-file results, deduplicated
+	codes, err = explainCodes(`[{"success":true},{"success":false,"code":"RATE_LIMITED"},{"code":"POW_INVALID"},{"code":"RATE_LIMITED"}]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.ErrCodeRateLimited, models.ErrCodePoWInvalid}, codes)
+
+	codes, err = explainCodes(`{"error":"Rate limit exceeded","code":"RATE_LIMITED"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.ErrCodeRateLimited}, codes)
+
+	_, err = explainCodes(`{"success":true}`)
+	assert.Error(t, err)
+	_, err = explainCodes(`{"code":`)
+	assert.Error(t, err)
+	_, err = explainCodes("")
+	assert.Error(t, err)
+}
+
+func TestExplain(t *testing.T) {
+	oldJSON := jsonOut
+	t.Cleanup(func() { jsonOut = oldJSON })
+
+	jsonOut = false
+	var runErr error
+	out := testutil.CaptureStdout(t, func() { runErr = runExplain(explainCmd, []string{"POW_INVALID"}) })
+	require.NoError(t, runErr)
+	assert.Contains(t, out, "POW_INVALID\n  What happened: The submitted nonce")
+
+	// From stdin, with an unknown code reported as an error
+	explainCmd.SetIn(strings.NewReader(`[{"code":"FAUCET_EMPTY"},{"code":"NEW_CODE"}]`))
+	t.Cleanup(func() { explainCmd.SetIn(nil) })
+	jsonOut = true
+	out = testutil.CaptureStdout(t, func() { runErr = runExplain(explainCmd, []string{"-"}) })
+	assert.ErrorContains(t, runErr, "unknown error code NEW_CODE")
+	var explanations []cli.ErrorExplanation
+	require.NoError(t, json.Unmarshal([]byte(out), &explanations), out)
+	require.Len(t, explanations, 2)
+	assert.Equal(t, models.ErrCodeFaucetEmpty, explanations[0].Code)
+	assert.NotEmpty(t, explanations[0].Hint)
+	assert.Empty(t, explanations[1].Meaning)
+
+	// Without an argument every code is described, as the help lists them
+	out = testutil.CaptureStdout(t, func() { runErr = runExplain(explainCmd, nil) })
+	require.NoError(t, runErr)
+	require.NoError(t, json.Unmarshal([]byte(out), &explanations), out)
+	assert.Len(t, explanations, len(models.ErrorCodes))
+	for _, code := range models.ErrorCodes {
+		assert.Contains(t, explainCmd.Long, "\n  "+code)
+	}
+}
@@ -36,6 +36,11 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	} else {
 		ui.PrintBanner()
 		ui.PrintInfoResponse(resp)
+
+		// Older servers don't expose the tokens endpoint
+		if tokens, err := client.GetTokens(); err == nil {
+			ui.PrintTokensResponse(tokens)
+		}
 	}
 
 	return nil
@@ -2,16 +2,24 @@ package commands
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
 	"github.com/spf13/cobra"
 )
 
+// limitsLookupTimeout bounds fetching the faucet's limits, so the rules still
+// print promptly when it can't be reached
+const limitsLookupTimeout = 5 * time.Second
+
 var limitsCmd = &cobra.Command{
 	Use:   "limits",
 	Short: "Show rate limit information",
 	Long: `Display detailed rate limiting rules for the faucet.
 
-Learn about daily limits, hourly throttles, and request costs.
+Learn about daily limits, hourly throttles, and request costs. When the
+faucet can be reached, its actual limits are shown first.
 
 Example:
   starknet-faucet limits`,
@@ -25,6 +33,16 @@ func runLimits(cmd *cobra.Command, args []string) error {
 	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
+	// The rules below describe the defaults; show what this faucet enforces
+	client := cli.NewAPIClient(apiURL)
+	client.SetTimeout(limitsLookupTimeout)
+	if info, err := client.GetInfo(); err == nil {
+		fmt.Println("📌 THIS FAUCET (per IP)")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		ui.PrintLimits(info.Limits)
+		fmt.Println()
+	}
+
 	fmt.Println("📊 DAILY LIMIT (Per IP)")
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Println("  • 5 requests per day")
@@ -66,18 +66,23 @@ func runQuota(cmd *cobra.Command, args []string) error {
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Printf("  Used:      %d/%d requests\n", used, total)
 	fmt.Printf("  Remaining: %d requests\n", remaining)
+	if resetAtData, ok := dailyLimit["reset_at"].(string); ok {
+		if resetAt, err := time.Parse(time.RFC3339, resetAtData); err == nil {
+			fmt.Printf("  Resets:    %s (in %s)\n", resetAt.Local().Format("Jan 02, 3:04 PM MST"), formatHoursUntil(resetAt))
+		}
+	}
 
 	if inCooldown {
 		if cooldownEndData := dailyLimit["cooldown_end"]; cooldownEndData != nil {
 			cooldownEndStr := cooldownEndData.(string)
 			if cooldownEnd, err := time.Parse(time.RFC3339, cooldownEndStr); err == nil {
 				hoursLeft := time.Until(cooldownEnd).Hours()
-				fmt.Printf("  🚫 IN 24-HOUR COOLDOWN (%.1f hours remaining)\n", hoursLeft)
+				fmt.Printf("  🚫 IN COOLDOWN (%.1f hours remaining)\n", hoursLeft)
 			} else {
-				fmt.Println("  🚫 IN 24-HOUR COOLDOWN")
+				fmt.Println("  🚫 IN COOLDOWN")
 			}
 		} else {
-			fmt.Println("  🚫 IN 24-HOUR COOLDOWN")
+			fmt.Println("  🚫 IN COOLDOWN")
 		}
 	} else if remaining == 0 {
 		fmt.Println("  ⚠️  Daily limit reached!")
@@ -144,12 +149,12 @@ func runQuota(cmd *cobra.Command, args []string) error {
 		if cooldownEndData := dailyLimit["cooldown_end"]; cooldownEndData != nil {
 			cooldownEndStr := cooldownEndData.(string)
 			if cooldownEnd, err := time.Parse(time.RFC3339, cooldownEndStr); err == nil {
-				fmt.Printf("💡 In 24h cooldown. Next request available at: %s\n", cooldownEnd.Format("Jan 02, 3:04 PM MST"))
+				fmt.Printf("💡 In cooldown. Next request available at: %s\n", cooldownEnd.Local().Format("Jan 02, 3:04 PM MST"))
 			} else {
-				fmt.Println("💡 In 24h cooldown after reaching daily limit")
+				fmt.Println("💡 In cooldown after reaching daily limit")
 			}
 		} else {
-			fmt.Println("💡 In 24h cooldown after reaching daily limit")
+			fmt.Println("💡 In cooldown after reaching daily limit")
 		}
 	} else if remaining > 0 {
 		if strkAvailable && ethAvailable {
@@ -165,7 +170,7 @@ func runQuota(cmd *cobra.Command, args []string) error {
 			fmt.Println("💡 Both tokens throttled. Please wait before requesting")
 		}
 	} else {
-		fmt.Println("💡 Daily limit reached. Will enter 24h cooldown on next attempt")
+		fmt.Println("💡 Daily limit reached. Will enter cooldown on next attempt")
 	}
 
 	fmt.Println()
@@ -174,3 +179,12 @@ func runQuota(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// formatHoursUntil formats the time left until t as hours and minutes
+func formatHoursUntil(t time.Time) string {
+	d := time.Until(t).Round(time.Minute)
+	if d < 0 {
+		d = 0
+	}
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
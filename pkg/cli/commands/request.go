@@ -1,8 +1,16 @@
 package commands
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/pprof"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,16 +20,77 @@ import (
 	clipow "github.com/Giri-Aayush/starknet-faucet/pkg/cli/pow"
 	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
 	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
 )
 
+// challengeSubmitMargin is time reserved for submitting a solution before its
+// challenge expires
+const challengeSubmitMargin = 5 * time.Second
+
+// maxChallengeAttempts is how many challenges to try before giving up on a
+// solve that keeps outrunning the challenge TTL
+const maxChallengeAttempts = 3
+
+// maxChallengeResubmits is how many times a request whose challenge the
+// faucet rejected is solved again and resubmitted
+const maxChallengeResubmits = 1
+
+// defaultChallengeTTL is assumed for servers that don't report a TTL. It
+// matches the server's default CHALLENGE_TTL.
+const defaultChallengeTTL = 300 * time.Second
+
+// defaultRequestTimeout bounds a whole request, from fetching the challenge to
+// submitting the solution
+const defaultRequestTimeout = 5 * time.Minute
+
+// maxAttemptsEnv sets the solver's attempt cap when --max-attempts isn't given
+const maxAttemptsEnv = "STARKNET_FAUCET_MAX_ATTEMPTS"
+
+// tokenCompletionTimeout bounds the server lookup behind --token completion,
+// so <TAB> stays responsive when the faucet is slow or unreachable
+const tokenCompletionTimeout = 2 * time.Second
+
+// Phases of a request, reported when --timeout cuts it short
+const (
+	phaseQuota      = "checking your quota"
+	phaseInfo       = "fetching the faucet's info"
+	phaseVerifying  = "answering the verification question"
+	phaseFetching   = "fetching the challenge"
+	phaseSolving    = "solving the proof of work"
+	phaseSubmitting = "submitting the request"
+)
+
 var (
-	token string
-	both  bool
+	token            string
+	both             bool
+	all              bool
+	skipVerification bool
+	requestTimeout   time.Duration
+	requestCount     int
+	bonus            bool
+	skipQuotaCheck   bool
+	addressFile      string
+	waitConfirmed    bool
+
+	// maxAttempts caps the nonces tried per solve, 0 scales the cap with
+	// the difficulty
+	maxAttempts uint64
+
+	// profilePath is where the hidden --profile flag writes a CPU profile of
+	// the proof-of-work solve, for work on the solver
+	profilePath string
+
+	// arrivalEstimate is how long the faucet says its transfers take to
+	// arrive, 0 when it doesn't say
+	arrivalEstimate time.Duration
+
+	// requestPhase is the phase of the request in progress
+	requestPhase string
 )
 
 var requestCmd = &cobra.Command{
-	Use:   "request <ADDRESS>",
+	Use:   "request <ADDRESS> | --address-file <PATH>",
 	Short: "Request testnet tokens",
 	Long: `Request testnet tokens (ETH or STRK) for a Starknet address.
 
@@ -44,53 +113,241 @@ Examples:
   starknet-faucet request 0x0742...8d9f --both
   starknet-faucet request 0x0742...8d9f --token both
 
+  # Request every token the faucet supports (costs 1 request per token)
+  starknet-faucet request 0x0742...8d9f --all
+  starknet-faucet request 0x0742...8d9f --token all
+
+  # Read the address from stdin
+  echo 0x0742...8d9f | starknet-faucet request -
+
+  # Scripted use: no prompts, machine-readable output
+  starknet-faucet request 0x0742...8d9f --json --yes
+
+  # Give up after 1 minute instead of the default 5
+  starknet-faucet request 0x0742...8d9f --timeout 1m
+
+  # Drip STRK 3 times, stopping early if the quota runs out
+  starknet-faucet request 0x0742...8d9f --count 3
+
+  # Out of daily requests? Solve a much harder challenge for one more
+  starknet-faucet request 0x0742...8d9f --bonus
+
+  # Request STRK for each address in a file (one per line, # for comments).
+  # Invalid lines are reported and skipped; the run stops at the rate limit.
+  # --timeout then applies to each address.
+  starknet-faucet request --address-file addresses.txt --yes
+
 Security:
   Each request requires:
   • Proof of Work challenge (computational work)
-  • CAPTCHA verification (human check)
+  • CAPTCHA verification (human check, skip with --yes)
 
 Note: Using --both counts toward your individual token limits
       AND sets a 24-hour cooldown for --both requests.`,
-	Args: cobra.ExactArgs(1),
+	Args: requestArgs,
 	RunE: runRequest,
 }
 
+// requestArgs takes the address, unless the addresses come from --address-file
+func requestArgs(cmd *cobra.Command, args []string) error {
+	if addressFile != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("give either an address or --address-file, not both")
+		}
+		return nil
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
 func init() {
 	requestCmd.Flags().StringVar(&token, "token", "STRK", "Token to request (ETH or STRK)")
+	_ = requestCmd.RegisterFlagCompletionFunc("token", completeToken)
 	requestCmd.Flags().BoolVar(&both, "both", false, "Request both ETH and STRK")
+	requestCmd.Flags().BoolVar(&all, "all", false, "Request every supported token")
+	requestCmd.Flags().BoolVarP(&skipVerification, "yes", "y", false, "Skip the interactive verification question (for scripts)")
+	requestCmd.Flags().BoolVar(&skipVerification, "no-captcha", false, "Alias for --yes")
+	requestCmd.Flags().DurationVar(&requestTimeout, "timeout", defaultRequestTimeout, "Give up on the whole request after this long (e.g. 90s, 10m)")
+	requestCmd.Flags().IntVar(&requestCount, "count", 1, "Repeat the request up to N times, stopping when the quota runs out")
+	requestCmd.Flags().BoolVar(&skipQuotaCheck, "skip-quota-check", false, "Don't check the quota before solving the proof of work")
+	requestCmd.Flags().BoolVar(&bonus, "bonus", false, "Solve a harder challenge to request past the daily limit (if the faucet offers bonus requests)")
+	requestCmd.Flags().StringVar(&addressFile, "address-file", "", "Request tokens for each address in a file, one per line")
+	requestCmd.Flags().BoolVar(&waitConfirmed, "wait", false, "Wait until the transfer is confirmed and report how long the tokens took to arrive")
+	requestCmd.Flags().Uint64Var(&maxAttempts, "max-attempts", 0, "Give up solving after this many attempts (0 scales with the difficulty; env "+maxAttemptsEnv+")")
+	requestCmd.Flags().StringVar(&profilePath, "profile", "", "Write a CPU profile of the proof-of-work solve to this file")
+	_ = requestCmd.Flags().MarkHidden("profile")
+}
+
+// completeToken suggests --token values: the tokens the faucet supports, plus
+// BOTH and ALL. Offline, it falls back to the built-in tokens.
+func completeToken(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Completion skips PersistentPreRunE, so pick up the configured API URL here
+	_ = applyConfigDefaults(cmd, args)
+
+	symbols := []string{"STRK", "ETH"}
+
+	client := cli.NewAPIClient(apiURL)
+	client.SetTimeout(tokenCompletionTimeout)
+	if resp, err := client.GetTokens(); err == nil && len(resp.Tokens) > 0 {
+		symbols = symbols[:0]
+		for _, t := range resp.Tokens {
+			symbols = append(symbols, t.Symbol)
+		}
+	}
+	symbols = append(symbols, "BOTH", "ALL")
+
+	var matches []string
+	for _, symbol := range symbols {
+		if strings.HasPrefix(symbol, strings.ToUpper(toComplete)) {
+			matches = append(matches, symbol)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
 }
 
 func runRequest(cmd *cobra.Command, args []string) error {
-	address := args[0]
+	// Solving the challenge takes a while, so the update check is usually
+	// done by the time the request finishes
+	notifyUpdate := startUpdateCheck()
+	defer notifyUpdate()
 
-	// Validate address
-	if err := utils.ValidateStarknetAddress(address); err != nil {
-		return fmt.Errorf("invalid address: %w", err)
+	var address string
+	if addressFile == "" {
+		address = args[0]
+
+		// "-" reads the address from stdin, for shell pipelines
+		if address == "-" {
+			// The verification question also reads stdin, which the pipe has consumed
+			if !jsonOut && !skipVerification {
+				return fmt.Errorf("reading the address from stdin needs --yes (or --json), since the verification question can't be answered")
+			}
+
+			var err error
+			address, err = readAddress(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+		}
+
+		// Validate address
+		if err := utils.ValidateStarknetAddress(address); err != nil {
+			return fmt.Errorf("invalid address: %w", err)
+		}
 	}
 
-	// Normalize token
-	token = strings.ToUpper(token)
+	// Normalize token, e.g. "ether" to ETH
+	token = utils.NormalizeToken(token)
 
-	// Handle "both" as token value
+	// Handle "both" and "all" as token values
 	if token == "BOTH" {
 		both = true
 	}
+	if token == "ALL" {
+		all = true
+	}
 
-	// Validate token (if not requesting both)
-	if !both {
+	// Validate token (if not requesting several)
+	if !both && !all {
 		if err := utils.ValidateToken(token); err != nil {
 			return err
 		}
 	}
 
+	if requestTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive (got %s)", requestTimeout)
+	}
+	if requestCount < 1 {
+		return fmt.Errorf("--count must be at least 1 (got %d)", requestCount)
+	}
+	if requestCount > 1 && (both || all) {
+		return fmt.Errorf("--count repeats a single token; it can't be combined with --both or --all")
+	}
+	if bonus && (both || all || requestCount > 1) {
+		return fmt.Errorf("--bonus buys a single request; it can't be combined with --both, --all or --count")
+	}
+	if addressFile != "" && (both || all || requestCount > 1 || bonus) {
+		return fmt.Errorf("--address-file makes one request per address; it can't be combined with --both, --all, --count or --bonus")
+	}
+
+	if addressFile != "" {
+		return requestAddressFile(addressFile)
+	}
+
+	// One deadline covers every phase, and each API call is bounded by it too
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
 	// Create API client
 	client := cli.NewAPIClient(apiURL)
+	client.SetTimeout(requestTimeout)
+	client.SetContext(ctx)
+
+	err := requestTokens(ctx, client, address)
+	if err != nil && timedOut(ctx) {
+		return fmt.Errorf("request timed out after %s while %s. Try again with a longer --timeout", requestTimeout, requestPhase)
+	}
+	return err
+}
+
+// requestTokens runs a request for the selected tokens, from the quota check
+// to printing the transactions
+func requestTokens(ctx context.Context, client *cli.APIClient, address string) error {
+	if err := prepareRequest(client); err != nil {
+		return err
+	}
+
+	// Request tokens
+	if all {
+		// A single ALL request drips every token in one round
+		if err := requestSingleToken(ctx, client, address, "ALL"); err != nil {
+			return err
+		}
+	} else if both {
+		// Request STRK first, then ETH
+		if err := requestSingleToken(ctx, client, address, "STRK"); err != nil {
+			return err
+		}
+		ui.PrintSpacer()
+		if err := requestSingleToken(ctx, client, address, "ETH"); err != nil {
+			return err
+		}
+	} else if requestCount > 1 {
+		if err := requestRepeatedly(ctx, client, address, token, requestCount); err != nil {
+			return err
+		}
+	} else {
+		if err := requestSingleToken(ctx, client, address, token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prepareRequest checks the quota, prints the banner and asks the
+// verification question, once before any tokens are requested
+func prepareRequest(client *cli.APIClient) error {
+	// Fail fast when the quota can't cover the request, before any PoW
+	if !skipQuotaCheck {
+		requestPhase = phaseQuota
+		if err := checkQuota(client); err != nil {
+			return err
+		}
+	}
+
+	// Only the human-readable output says when tokens arrive
+	if !jsonOut {
+		requestPhase = phaseInfo
+		arrivalEstimate = fetchArrivalEstimate(client)
+	}
 
 	// Print banner (unless JSON output)
 	if !jsonOut {
 		ui.PrintBanner()
+	}
 
-		// Ask verification question (3 attempts)
+	// Ask verification question (3 attempts), unless running non-interactively
+	if !jsonOut && !skipVerification {
+		requestPhase = phaseVerifying
 		correct, err := captcha.AskQuestionWithRetries(3)
 		if err != nil {
 			return fmt.Errorf("verification failed: %w", err)
@@ -100,131 +357,721 @@ func runRequest(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Request tokens
-	if both {
-		// Request STRK first, then ETH
-		if err := requestSingleToken(client, address, "STRK"); err != nil {
-			return err
+	return nil
+}
+
+// fetchArrivalEstimate returns how long the faucet says its transfers take to
+// arrive, or 0 when it doesn't say or can't be asked
+func fetchArrivalEstimate(client *cli.APIClient) time.Duration {
+	info, err := client.GetInfo()
+	if err != nil {
+		return 0
+	}
+	return time.Duration(info.EstimatedArrivalSeconds) * time.Second
+}
+
+// requestAddressFile requests token for each address in path. Invalid and
+// duplicate lines are reported without stopping the run, but once the rate
+// limit is reached the remaining addresses are skipped. With --json it prints
+// an array of per-address results.
+func requestAddressFile(path string) error {
+	addresses, err := readAddressFile(path)
+	if err != nil {
+		return err
+	}
+
+	client := cli.NewAPIClient(apiURL)
+	client.SetTimeout(requestTimeout)
+	if err := prepareRequest(client); err != nil {
+		return err
+	}
+
+	var results []map[string]interface{}
+	funded, invalid, skipped := 0, 0, 0
+	var stopErr error
+	seen := make(map[string]bool)
+
+	for i, address := range addresses {
+		if stopErr != nil {
+			skipped++
+			result := failedResult(fmt.Errorf("skipped: %w", stopErr))
+			result["address"] = address
+			results = append(results, result)
+			continue
 		}
-		fmt.Println() // Add spacing
-		if err := requestSingleToken(client, address, "ETH"); err != nil {
-			return err
+
+		if !jsonOut {
+			if i > 0 {
+				ui.PrintSpacer()
+			}
+			ui.PrintInfo(fmt.Sprintf("Address %d of %d", i+1, len(addresses)))
+		}
+
+		result, err := dripAddress(client, address, seen)
+		if errors.Is(err, errBatchAddress) {
+			invalid++
+			if !jsonOut {
+				ui.PrintError(err.Error())
+			}
 		}
+		if err != nil {
+			result = failedResult(err)
+			// Every other address would be turned away too
+			if isRateLimited(err) {
+				stopErr = err
+			}
+		} else {
+			funded++
+		}
+		result["address"] = address
+		results = append(results, result)
+	}
+
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonBytes))
 	} else {
-		if err := requestSingleToken(client, address, token); err != nil {
-			return err
+		ui.PrintSpacer()
+		summary := fmt.Sprintf("%d of %d addresses funded", funded, len(addresses))
+		if invalid > 0 {
+			summary += fmt.Sprintf(", %d invalid", invalid)
+		}
+		if skipped > 0 {
+			summary += fmt.Sprintf(", %d skipped (rate limit reached)", skipped)
+		}
+		if funded > 0 {
+			ui.PrintSuccess(summary)
+		} else {
+			ui.PrintError(summary)
 		}
 	}
 
+	if funded == 0 {
+		return fmt.Errorf("none of the %d addresses in %s were funded", len(addresses), path)
+	}
 	return nil
 }
 
-func requestSingleToken(client *cli.APIClient, address, token string) error {
-	if !jsonOut {
-		ui.PrintInfo(fmt.Sprintf("Requesting %s for %s", token, address))
-		fmt.Println()
+// errBatchAddress marks an address --address-file can't request for
+var errBatchAddress = errors.New("skipped")
+
+// dripAddress requests token for one address of an --address-file run, within
+// its own --timeout. It waits out a Retry-After from the server when one is
+// given and fits the deadline. seen holds the addresses already requested.
+func dripAddress(client *cli.APIClient, address string, seen map[string]bool) (map[string]interface{}, error) {
+	if err := utils.ValidateStarknetAddress(address); err != nil {
+		return nil, fmt.Errorf("%w: invalid address %q: %v", errBatchAddress, address, err)
 	}
+	key := strings.ToLower(address)
+	if seen[key] {
+		return nil, fmt.Errorf("%w: duplicate address %s", errBatchAddress, address)
+	}
+	seen[key] = true
 
-	// Step 1: Get challenge
-	var challengeResp *models.ChallengeResponse
-	if !jsonOut {
-		s := ui.NewSpinner("Fetching challenge...")
-		s.Start()
-		var err error
-		challengeResp, err = client.GetChallenge()
-		s.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	client.SetContext(ctx)
+
+	for {
+		result, err := dripToken(ctx, client, address, token)
+		if wait := retryAfter(err); wait > 0 && fitsDeadline(ctx, wait) {
+			if !jsonOut {
+				ui.PrintInfo(fmt.Sprintf("Server asked to wait %s before the next request", wait.Round(time.Second)))
+			}
+			select {
+			case <-time.After(wait):
+				continue
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+		if err != nil && timedOut(ctx) {
+			return nil, fmt.Errorf("request timed out after %s while %s", requestTimeout, requestPhase)
+		}
+		return result, err
+	}
+}
+
+// readAddressFile reads the addresses in path, one per line. Blank lines and
+// lines starting with # are ignored.
+func readAddressFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open address file: %w", err)
+	}
+	defer f.Close()
+
+	var addresses []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addresses = append(addresses, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read address file: %w", err)
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no addresses in %s", path)
+	}
+	return addresses, nil
+}
+
+// fetchAndSolveChallenge fetches a challenge and solves it. If the challenge
+// would expire before it is solved, a fresh one is fetched, up to
+// maxChallengeAttempts times. Solving stops early when ctx's deadline passes.
+// Challenges are bound to token.
+func fetchAndSolveChallenge(ctx context.Context, client *cli.APIClient, token string) (*models.ChallengeResponse, *clipow.SolveResult, error) {
+	for attempt := 1; ; attempt++ {
+		requestPhase = phaseFetching
+		challengeResp, received, err := fetchChallenge(client, token)
 		if err != nil {
-			ui.PrintError(fmt.Sprintf("Failed to get challenge: %v", err))
-			return err
+			return nil, nil, err
 		}
-		ui.PrintSuccess("Challenge received")
-		fmt.Println()
-	} else {
-		var err error
-		challengeResp, err = client.GetChallenge()
+
+		deadline := challengeDeadline(challengeResp, received)
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+
+		requestPhase = phaseSolving
+		result, err := solveChallenge(challengeResp, deadline)
+		if err == nil {
+			return challengeResp, result, nil
+		}
+
+		if timedOut(ctx) {
+			return nil, nil, context.DeadlineExceeded
+		}
+
+		if !errors.Is(err, clipow.ErrChallengeExpired) {
+			if !jsonOut {
+				ui.PrintError(fmt.Sprintf("Failed to solve challenge: %v", err))
+				if errors.Is(err, clipow.ErrMaxAttempts) {
+					ui.PrintInfo("Raise the cap with --max-attempts or " + maxAttemptsEnv + ", or set it to 0 to scale it with the difficulty")
+				}
+			}
+			return nil, nil, err
+		}
+
+		if attempt >= maxChallengeAttempts {
+			err = fmt.Errorf("could not solve the challenge within the server's %s window after %d attempts (difficulty %d). Close other CPU-heavy programs and try again",
+				challengeTTL(challengeResp), maxChallengeAttempts, challengeResp.Difficulty)
+			if !jsonOut {
+				ui.PrintError(err.Error())
+			}
+			return nil, nil, err
+		}
+
+		notice := fmt.Sprintf("Challenge expired after %s of solving, fetching a new one (attempt %d/%d)...",
+			time.Since(received).Round(time.Second), attempt+1, maxChallengeAttempts)
+		if jsonOut {
+			// Keep stdout valid JSON
+			fmt.Fprintln(os.Stderr, notice)
+		} else {
+			ui.PrintInfo(notice)
+			ui.PrintSpacer()
+		}
+	}
+}
+
+// fetchChallenge fetches a new challenge for token and returns it with the
+// time it was received
+func fetchChallenge(client *cli.APIClient, token string) (*models.ChallengeResponse, time.Time, error) {
+	getChallenge := client.GetChallenge
+	if bonus {
+		getChallenge = client.GetBonusChallenge
+	}
+
+	if jsonOut {
+		challengeResp, err := getChallenge(token)
+		return challengeResp, time.Now(), err
+	}
+
+	s := ui.NewSpinner("Fetching challenge...")
+	s.Start()
+	challengeResp, err := getChallenge(token)
+	s.Stop()
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to get challenge: %v", err))
+		if hint := cli.ErrorHint(err); hint != "" {
+			ui.PrintInfo(hint)
+		}
+		return nil, time.Time{}, err
+	}
+	ui.PrintStep("Challenge received")
+	ui.PrintSpacer()
+
+	return challengeResp, time.Now(), nil
+}
+
+// solveChallenge solves a challenge, giving up at deadline
+func solveChallenge(challengeResp *models.ChallengeResponse, deadline time.Time) (*clipow.SolveResult, error) {
+	if profilePath != "" {
+		stop, err := startCPUProfile(profilePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		defer stop()
+	}
+
+	limit, err := solveAttemptLimit()
+	if err != nil {
+		return nil, err
+	}
+	solver := clipow.NewSolverWithMaxAttempts(limit)
+	if jsonOut {
+		return solver.SolveBefore(challengeResp.Challenge, challengeResp.Difficulty, deadline, nil)
+	}
+
+	s := ui.NewSpinner(fmt.Sprintf("Solving proof of work (difficulty: %d)...", challengeResp.Difficulty))
+	s.Start()
+
+	result, err := solver.SolveBefore(challengeResp.Challenge, challengeResp.Difficulty, deadline, func(n uint64, d time.Duration) {
+		// Update spinner suffix with estimated progress
+		fraction, remaining := clipow.EstimateProgress(n, d, challengeResp.Difficulty)
+		eta := fmt.Sprintf("~%.0fs left", remaining.Seconds())
+		if remaining == 0 {
+			eta = "taking longer than usual"
+		}
+		s.Suffix = fmt.Sprintf(" Solving proof of work %s (%s, %.1fs elapsed)",
+			ui.ProgressBar(fraction, 20), eta, d.Seconds())
+	})
+
+	s.Stop()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ui.PrintStep(fmt.Sprintf("Challenge solved in %.1fs (nonce: %d)", result.Duration.Seconds(), result.Nonce))
+	ui.PrintSpacer()
+	return result, nil
+}
+
+// solveAttemptLimit returns the solver's attempt cap: --max-attempts, else
+// STARKNET_FAUCET_MAX_ATTEMPTS, else 0 for the default that scales with the
+// difficulty
+func solveAttemptLimit() (uint64, error) {
+	if maxAttempts != 0 {
+		return maxAttempts, nil
+	}
+	value := os.Getenv(maxAttemptsEnv)
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: must be a whole number of attempts", maxAttemptsEnv, value)
+	}
+	return limit, nil
+}
+
+// startCPUProfile starts writing a CPU profile to path and returns a function
+// that stops it. Every solve rewrites the file, so after several (--count,
+// --address-file or an expired challenge) it holds the last one.
+func startCPUProfile(path string) (stop func(), err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to start profiling: %w", err)
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			ui.PrintError(fmt.Sprintf("Failed to write profile: %v", err))
+			return
+		}
+		if !jsonOut {
+			ui.PrintInfo(fmt.Sprintf("CPU profile written to %s", path))
+		}
+	}, nil
+}
+
+// waitMinSolveTime holds a solution back until the server's minimum solve
+// time has passed since the challenge was received. The server counts from
+// when it issued the challenge, which is earlier, so this never submits early.
+func waitMinSolveTime(ctx context.Context, challengeResp *models.ChallengeResponse, solved time.Duration) error {
+	wait := time.Duration(challengeResp.MinSolveSeconds)*time.Second - solved
+	if wait <= 0 {
+		return nil
 	}
 
-	// Step 2: Solve PoW
-	var nonce int64
-	var solveDuration time.Duration
 	if !jsonOut {
-		s := ui.NewSpinner(fmt.Sprintf("Solving proof of work (difficulty: %d)...", challengeResp.Difficulty))
-		s.Start()
+		ui.PrintInfo(fmt.Sprintf("Waiting %.1fs for the faucet's minimum solve time...", wait.Seconds()))
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-		solver := clipow.NewSolver()
-		result, err := solver.Solve(challengeResp.Challenge, challengeResp.Difficulty, func(n int64, d time.Duration) {
-			// Update spinner suffix with progress
-			s.Suffix = fmt.Sprintf(" Solving proof of work (attempts: %d, time: %.1fs)...",
-				n, d.Seconds())
-		})
+// challengeDeadline returns when to stop solving a challenge so the solution
+// can still be submitted in time. The TTL is measured from when the challenge
+// was received, so clock skew between client and server doesn't matter.
+func challengeDeadline(challengeResp *models.ChallengeResponse, received time.Time) time.Time {
+	if challengeResp.TTLSeconds == 0 && challengeResp.ExpiresAt != nil {
+		return challengeResp.ExpiresAt.Add(-challengeSubmitMargin)
+	}
+	return received.Add(challengeTTL(challengeResp) - challengeSubmitMargin)
+}
 
-		s.Stop()
+// timedOut reports whether ctx's deadline has passed. The solver checks the
+// deadline on its own clock, so this doesn't wait for ctx to be cancelled.
+func timedOut(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
+
+// challengeTTL returns how long a challenge stays valid, assuming the server
+// default when it isn't reported
+func challengeTTL(challengeResp *models.ChallengeResponse) time.Duration {
+	if challengeResp.TTLSeconds > 0 {
+		return time.Duration(challengeResp.TTLSeconds) * time.Second
+	}
+	return defaultChallengeTTL
+}
+
+// requestSingleToken requests one token and prints the result
+func requestSingleToken(ctx context.Context, client *cli.APIClient, address, token string) error {
+	result, err := dripToken(ctx, client, address, token)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonBytes))
+	}
+	return nil
+}
+
+// dripToken solves a challenge and submits one request for token. Outside
+// JSON mode it prints progress and the transaction; either way it returns the
+// result as reported by --json.
+func dripToken(ctx context.Context, client *cli.APIClient, address, token string) (map[string]interface{}, error) {
+	if !jsonOut {
+		ui.PrintInfo(fmt.Sprintf("Requesting %s for %s", token, address))
+		ui.PrintSpacer()
+	}
 
+	var faucetResp *models.FaucetResponse
+	var solveDuration time.Duration
+	for attempt := 0; ; attempt++ {
+		// Steps 1 and 2: Get and solve a challenge
+		challengeResp, solveResult, err := fetchAndSolveChallenge(ctx, client, token)
 		if err != nil {
-			ui.PrintError(fmt.Sprintf("Failed to solve challenge: %v", err))
-			return err
+			return nil, err
+		}
+		nonce := solveResult.Nonce
+		solveDuration = solveResult.Duration
+
+		requestPhase = phaseSubmitting
+		if err := waitMinSolveTime(ctx, challengeResp, solveDuration); err != nil {
+			return nil, err
+		}
+
+		// Step 3: Request tokens
+		req := models.FaucetRequest{
+			Address:     address,
+			Token:       token,
+			ChallengeID: challengeResp.ChallengeID,
+			Nonce:       &nonce,
+		}
+		var elapsed time.Duration
+		faucetResp, elapsed, err = submitRequest(client, req)
+
+		// A challenge that expired on the way in is worth one fresh solve
+		if err != nil && attempt < maxChallengeResubmits && isChallengeRejected(err) && ctx.Err() == nil {
+			if !jsonOut {
+				ui.PrintInfo(fmt.Sprintf("The faucet rejected the challenge as expired (it is valid for %s). Solving a fresh one...",
+					challengeTTL(challengeResp)))
+				ui.PrintSpacer()
+			}
+			continue
 		}
 
-		nonce = result.Nonce
-		solveDuration = result.Duration
-		ui.PrintSuccess(fmt.Sprintf("Challenge solved in %.1fs (nonce: %d)", solveDuration.Seconds(), nonce))
-		fmt.Println()
-	} else {
-		solver := clipow.NewSolver()
-		result, err := solver.Solve(challengeResp.Challenge, challengeResp.Difficulty, nil)
 		if err != nil {
-			return err
+			if !jsonOut {
+				ui.PrintError(fmt.Sprintf("Failed to request tokens: %v", err))
+				if hint := cli.ErrorHint(err); hint != "" {
+					ui.PrintInfo(hint)
+				}
+			}
+			return nil, err
+		}
+		if !jsonOut {
+			ui.PrintStep("Transaction submitted!")
+			ui.PrintFaucetResponse(faucetResp, ui.Arrival{Estimate: arrivalEstimate, Elapsed: elapsed})
 		}
-		nonce = result.Nonce
-		solveDuration = result.Duration
+		break
 	}
 
-	// Step 3: Request tokens
-	req := models.FaucetRequest{
-		Address:     address,
-		Token:       token,
-		ChallengeID: challengeResp.ChallengeID,
-		Nonce:       nonce,
+	output := map[string]interface{}{
+		"success":        faucetResp.Success,
+		"tx_hash":        faucetResp.TxHash,
+		"amount":         faucetResp.Amount,
+		"token":          faucetResp.Token,
+		"explorer_url":   faucetResp.ExplorerURL,
+		"solve_duration": solveDuration.Seconds(),
 	}
+	if len(faucetResp.Transactions) > 0 {
+		output["transactions"] = faucetResp.Transactions
+		output["message"] = faucetResp.Message
+	}
+	if faucetResp.BlockNumber > 0 {
+		output["block_number"] = faucetResp.BlockNumber
+		output["finality_status"] = faucetResp.FinalityStatus
+	}
+	return output, nil
+}
 
-	var faucetResp *models.FaucetResponse
+// submitRequest submits a solved request, with a spinner outside JSON mode,
+// and returns how long the faucet took to answer
+func submitRequest(client *cli.APIClient, req models.FaucetRequest) (*models.FaucetResponse, time.Duration, error) {
+	var s *spinner.Spinner
 	if !jsonOut {
-		s := ui.NewSpinner("Submitting request...")
+		message := "Submitting request..."
+		if waitConfirmed {
+			message = "Submitting request and waiting for confirmation..."
+		}
+		s = ui.NewSpinner(message)
 		s.Start()
-		var err error
-		faucetResp, err = client.RequestTokens(req)
+	}
+
+	submitted := time.Now()
+	faucetResp, err := client.RequestTokens(req, waitConfirmed)
+	elapsed := time.Since(submitted)
+	if s != nil {
 		s.Stop()
-		if err != nil {
-			ui.PrintError(fmt.Sprintf("Failed to request tokens: %v", err))
-			return err
+	}
+	return faucetResp, elapsed, err
+}
+
+// isChallengeRejected reports whether the faucet refused a request's
+// challenge as unknown, expired or already used. Servers without error codes
+// are recognized by their message.
+func isChallengeRejected(err error) bool {
+	var apiErr *cli.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code != "" {
+		return apiErr.Code == models.ErrCodeChallengeInvalid
+	}
+	return strings.Contains(apiErr.Message, "Invalid or expired challenge")
+}
+
+// requestRepeatedly requests token up to count times, each with a fresh
+// challenge. It stops early once the rate limit is exhausted, waiting out a
+// Retry-After from the server instead when one is given and fits in the
+// deadline. With --json it prints an array of per-iteration results.
+func requestRepeatedly(ctx context.Context, client *cli.APIClient, address, token string, count int) error {
+	var results []map[string]interface{}
+	succeeded := 0
+	var stopErr error
+
+	for i := 1; i <= count; i++ {
+		if !jsonOut {
+			ui.PrintInfo(fmt.Sprintf("Request %d of %d", i, count))
 		}
-		ui.PrintSuccess("Transaction submitted!")
-	} else {
-		var err error
-		faucetResp, err = client.RequestTokens(req)
+
+		result, err := dripToken(ctx, client, address, token)
 		if err != nil {
-			return err
+			if wait := retryAfter(err); wait > 0 && fitsDeadline(ctx, wait) {
+				if !jsonOut {
+					ui.PrintInfo(fmt.Sprintf("Server asked to wait %s before the next request", wait.Round(time.Second)))
+				}
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				i-- // The server turned this iteration away, so it doesn't count
+				continue
+			}
+
+			results = append(results, failedIteration(i, err))
+			stopErr = err
+			break
+		}
+
+		result["iteration"] = i
+		results = append(results, result)
+		succeeded++
+		if !jsonOut && i < count {
+			ui.PrintSpacer()
 		}
 	}
 
-	// Print response
+	// Running out of quota after some drips is the expected way for a long
+	// --count to end, so only report it as an error if nothing was sent
+	rateLimited := isRateLimited(stopErr)
 	if jsonOut {
-		output := map[string]interface{}{
-			"success":        faucetResp.Success,
-			"tx_hash":        faucetResp.TxHash,
-			"amount":         faucetResp.Amount,
-			"token":          faucetResp.Token,
-			"explorer_url":   faucetResp.ExplorerURL,
-			"solve_duration": solveDuration.Seconds(),
-		}
-		jsonBytes, _ := json.MarshalIndent(output, "", "  ")
+		jsonBytes, _ := json.MarshalIndent(results, "", "  ")
 		fmt.Println(string(jsonBytes))
 	} else {
-		ui.PrintFaucetResponse(faucetResp)
+		ui.PrintSpacer()
+		summary := fmt.Sprintf("%d of %d requests succeeded", succeeded, count)
+		if rateLimited {
+			summary += " (stopped: rate limit reached)"
+		}
+		if succeeded > 0 {
+			ui.PrintSuccess(summary)
+		} else {
+			ui.PrintError(summary)
+		}
+	}
+
+	if stopErr != nil && (!rateLimited || succeeded == 0) {
+		return stopErr
+	}
+	return nil
+}
+
+// failedIteration is the --json entry for a request that failed
+func failedIteration(i int, err error) map[string]interface{} {
+	result := failedResult(err)
+	result["iteration"] = i
+	return result
+}
+
+// failedResult is the --json result of a request that failed
+func failedResult(err error) map[string]interface{} {
+	result := map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+	}
+	var apiErr *cli.APIError
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		result["code"] = apiErr.Code
+	}
+	return result
+}
+
+// retryAfter returns how long the server asked to wait before retrying, or 0
+func retryAfter(err error) time.Duration {
+	var apiErr *cli.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// isRateLimited reports whether err is the server refusing more requests
+func isRateLimited(err error) bool {
+	var apiErr *cli.APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.Code == models.ErrCodeRateLimited || apiErr.StatusCode == http.StatusTooManyRequests)
+}
+
+// fitsDeadline reports whether waiting d still leaves ctx time to run
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Now().Add(d).Before(deadline)
+}
+
+// checkQuota checks the IP's quota before a request so one that would be
+// rate limited fails before any PoW is solved. A quota that can't be read is
+// not an error: the server enforces the limits either way.
+func checkQuota(client *cli.APIClient) error {
+	tokens := []string{token}
+	if both {
+		tokens = []string{"STRK", "ETH"}
+	} else if all {
+		tokensResp, err := client.GetTokens()
+		if err != nil {
+			return nil
+		}
+		tokens = nil
+		for _, t := range tokensResp.Tokens {
+			tokens = append(tokens, t.Symbol)
+		}
+	}
+
+	body, err := client.Get("/api/v1/quota")
+	if err != nil {
+		return nil
+	}
+	var quota models.QuotaResponse
+	if err := json.Unmarshal(body, &quota); err != nil {
+		return nil
 	}
+	return quotaError(quota, tokens)
+}
 
+// quotaError explains why quota can't cover a request for tokens, each of
+// which costs one daily request, or returns nil if it can
+func quotaError(quota models.QuotaResponse, tokens []string) error {
+	daily := quota.DailyLimit
+	cost := len(tokens)
+
+	// A bonus request is paid for with extra work instead of the quota
+	if !bonus {
+		if daily.InCooldown && daily.CooldownEnd != nil {
+			return fmt.Errorf("daily limit reached and in cooldown. Next request in %s. Run 'starknet-faucet quota' for details",
+				formatHoursUntil(*daily.CooldownEnd))
+		}
+		if daily.Remaining < cost {
+			msg := fmt.Sprintf("daily limit reached (%d/%d requests used)", daily.Used, daily.Total)
+			if cost > 1 {
+				msg = fmt.Sprintf("this request costs %d daily requests (1 per token), but only %d of your %d remain", cost, daily.Remaining, daily.Total)
+			}
+			if daily.ResetAt != nil {
+				msg += fmt.Sprintf(". Quota resets in %s", formatHoursUntil(*daily.ResetAt))
+			}
+			return fmt.Errorf("%s. Run 'starknet-faucet quota' for details", msg)
+		}
+	}
+
+	for _, t := range tokens {
+		var throttle models.TokenThrottle
+		switch t {
+		case "STRK":
+			throttle = quota.HourlyThrottle.STRK
+		case "ETH":
+			throttle = quota.HourlyThrottle.ETH
+		default:
+			continue // The quota only reports STRK and ETH throttles
+		}
+		if !throttle.Available && throttle.NextRequestAt != nil {
+			return fmt.Errorf("%s hourly throttle active. Next %s request in %s", t, t, formatHoursUntil(*throttle.NextRequestAt))
+		}
+	}
 	return nil
 }
+
+// readAddress reads a single address from r, ignoring surrounding whitespace
+// and blank lines
+func readAddress(r io.Reader) (string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read address from stdin: %w", err)
+	}
+
+	switch len(lines) {
+	case 0:
+		return "", fmt.Errorf("no address on stdin")
+	case 1:
+		return lines[0], nil
+	default:
+		return "", fmt.Errorf("expected one address on stdin, got %d lines", len(lines))
+	}
+}
@@ -0,0 +1,496 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/testutil"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	clipow "github.com/Giri-Aayush/starknet-faucet/pkg/cli/pow"
+)
+
+func TestReadAddress(t *testing.T) {
+	address, err := readAddress(strings.NewReader("  0x0742d469482a89e7\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "0x0742d469482a89e7", address)
+
+	_, err = readAddress(strings.NewReader(""))
+	assert.ErrorContains(t, err, "no address")
+
+	_, err = readAddress(strings.NewReader("0x1\n0x2\n"))
+	assert.ErrorContains(t, err, "got 2 lines")
+}
+
+func TestChallengeDeadline(t *testing.T) {
+	received := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The TTL is measured from receipt, minus time to submit
+	deadline := challengeDeadline(&models.ChallengeResponse{TTLSeconds: 300}, received)
+	assert.Equal(t, received.Add(300*time.Second-challengeSubmitMargin), deadline)
+
+	// Without a TTL, fall back to the server's expiry time
+	expiresAt := received.Add(time.Minute)
+	deadline = challengeDeadline(&models.ChallengeResponse{ExpiresAt: &expiresAt}, received)
+	assert.Equal(t, expiresAt.Add(-challengeSubmitMargin), deadline)
+
+	// Older servers report neither, so assume the server default
+	deadline = challengeDeadline(&models.ChallengeResponse{}, received)
+	assert.Equal(t, received.Add(defaultChallengeTTL-challengeSubmitMargin), deadline)
+}
+
+func TestRequestTimeoutReportsPhase(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge http.HandlerFunc
+		wantPhase string
+	}{
+		{
+			name: "slow challenge endpoint",
+			challenge: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(2 * time.Second):
+				case <-r.Context().Done():
+				}
+			},
+			wantPhase: phaseFetching,
+		},
+		{
+			name: "challenge too hard to solve in time",
+			challenge: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"challenge_id":"c1","challenge":"abc","difficulty":12,"ttl_seconds":300}`))
+			},
+			wantPhase: phaseSolving,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/challenge", tt.challenge)
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate := apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck
+			t.Cleanup(func() {
+				apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck = oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate
+			})
+			apiURL, jsonOut, skipVerification, noUpdateCheck = server.URL, true, true, true
+			requestTimeout = 300 * time.Millisecond
+
+			start := time.Now()
+			err := runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "while "+tt.wantPhase)
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	}
+}
+
+func TestCompleteToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/tokens", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tokens":[{"symbol":"STRK"},{"symbol":"ETH"},{"symbol":"USDC"}]}`))
+	}))
+	defer server.Close()
+
+	// Keep the user's config file and environment out of the test
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(configEnvVars["api-url"], "")
+	oldAPIURL := apiURL
+	defer func() { apiURL = oldAPIURL }()
+
+	apiURL = server.URL
+	choices, directive := completeToken(requestCmd, nil, "")
+	assert.Equal(t, []string{"STRK", "ETH", "USDC", "BOTH", "ALL"}, choices)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	choices, _ = completeToken(requestCmd, nil, "u")
+	assert.Equal(t, []string{"USDC"}, choices)
+
+	// Unreachable faucet: fall back to the built-in tokens
+	server.Close()
+	choices, _ = completeToken(requestCmd, nil, "")
+	assert.Equal(t, []string{"STRK", "ETH", "BOTH", "ALL"}, choices)
+}
+
+func TestRequestCount(t *testing.T) {
+	// Succeed, ask to retry later, succeed, then run out of quota
+	var faucetCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/challenge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"challenge_id":"c1","challenge":"abc","difficulty":1,"ttl_seconds":300}`))
+	})
+	mux.HandleFunc("/api/v1/faucet", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch faucetCalls.Add(1) {
+		case 2:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"busy","code":"SERVER_BUSY"}`))
+		case 4:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"Rate limit exceeded","code":"RATE_LIMITED"}`))
+		default:
+			w.Write([]byte(`{"success":true,"tx_hash":"0xabc","amount":"10","token":"STRK","message":"ok"}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldCount := apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, requestCount
+	t.Cleanup(func() {
+		apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, requestCount = oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldCount
+	})
+	apiURL, jsonOut, skipVerification, noUpdateCheck = server.URL, true, true, true
+	requestTimeout = 30 * time.Second
+	requestCount = 5
+
+	var runErr error
+	out := testutil.CaptureStdout(t, func() {
+		runErr = runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+	})
+	require.NoError(t, runErr, "running out of quota after some drips is not an error")
+
+	var results []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &results), out)
+	require.Len(t, results, 3)
+	assert.Equal(t, true, results[0]["success"])
+	assert.Equal(t, true, results[1]["success"])
+	assert.EqualValues(t, 2, results[1]["iteration"])
+	assert.Equal(t, false, results[2]["success"])
+	assert.Equal(t, models.ErrCodeRateLimited, results[2]["code"])
+	assert.EqualValues(t, 4, faucetCalls.Load())
+}
+
+func TestRequestCountValidation(t *testing.T) {
+	oldCount, oldBoth, oldNoUpdate := requestCount, both, noUpdateCheck
+	t.Cleanup(func() { requestCount, both, noUpdateCheck = oldCount, oldBoth, oldNoUpdate })
+	noUpdateCheck = true
+
+	requestCount = 0
+	assert.ErrorContains(t, runRequest(requestCmd, []string{"0x0742d469482a89e7"}), "--count must be at least 1")
+
+	requestCount, both = 2, true
+	assert.ErrorContains(t, runRequest(requestCmd, []string{"0x0742d469482a89e7"}), "can't be combined")
+}
+
+func TestWaitMinSolveTime(t *testing.T) {
+	oldJSON := jsonOut
+	t.Cleanup(func() { jsonOut = oldJSON })
+	jsonOut = true
+
+	challenge := &models.ChallengeResponse{MinSolveSeconds: 1}
+
+	// Solving took longer than the floor: submit right away
+	start := time.Now()
+	require.NoError(t, waitMinSolveTime(context.Background(), challenge, 2*time.Second))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// Otherwise wait out the rest of it
+	start = time.Now()
+	require.NoError(t, waitMinSolveTime(context.Background(), challenge, 800*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// unless the request's deadline comes first
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, waitMinSolveTime(ctx, challenge, 0), context.DeadlineExceeded)
+}
+
+func TestSolveChallengeProfile(t *testing.T) {
+	oldJSON, oldProfile := jsonOut, profilePath
+	t.Cleanup(func() { jsonOut, profilePath = oldJSON, oldProfile })
+	jsonOut = true
+	profilePath = filepath.Join(t.TempDir(), "cpu.prof")
+
+	challenge := &models.ChallengeResponse{Challenge: "abc", Difficulty: 2}
+	_, err := solveChallenge(challenge, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	info, err := os.Stat(profilePath)
+	require.NoError(t, err)
+	assert.Greater(t, info.Size(), int64(0))
+
+	// Kept out of normal help
+	assert.True(t, requestCmd.Flags().Lookup("profile").Hidden)
+
+	profilePath = filepath.Join(t.TempDir(), "missing", "cpu.prof")
+	_, err = solveChallenge(challenge, time.Now().Add(time.Minute))
+	assert.ErrorContains(t, err, "failed to create profile")
+}
+
+func TestQuotaError(t *testing.T) {
+	oldBonus := bonus
+	t.Cleanup(func() { bonus = oldBonus })
+
+	soon := time.Now().Add(90 * time.Minute)
+	open := models.QuotaResponse{
+		DailyLimit: models.DailyLimitInfo{Total: 5, Used: 4, Remaining: 1},
+		HourlyThrottle: models.HourlyThrottleInfo{
+			STRK: models.TokenThrottle{Available: true},
+			ETH:  models.TokenThrottle{Available: true},
+		},
+	}
+	assert.NoError(t, quotaError(open, []string{"STRK"}))
+
+	// --both needs two slots
+	err := quotaError(open, []string{"STRK", "ETH"})
+	assert.ErrorContains(t, err, "costs 2 daily requests (1 per token), but only 1 of your 5 remain")
+
+	exhausted := open
+	exhausted.DailyLimit = models.DailyLimitInfo{Total: 5, Used: 5, Remaining: 0, ResetAt: &soon}
+	err = quotaError(exhausted, []string{"STRK"})
+	assert.ErrorContains(t, err, "daily limit reached (5/5 requests used). Quota resets in 1h 30m")
+
+	cooldown := exhausted
+	cooldown.DailyLimit.InCooldown = true
+	cooldown.DailyLimit.CooldownEnd = &soon
+	assert.ErrorContains(t, quotaError(cooldown, []string{"STRK"}), "in cooldown. Next request in 1h 30m")
+
+	throttled := open
+	throttled.HourlyThrottle.ETH = models.TokenThrottle{NextRequestAt: &soon}
+	assert.NoError(t, quotaError(throttled, []string{"STRK"}))
+	assert.ErrorContains(t, quotaError(throttled, []string{"ETH"}), "ETH hourly throttle active. Next ETH request in 1h 30m")
+
+	// A bonus request doesn't need quota, but the throttle still applies
+	bonus = true
+	assert.NoError(t, quotaError(cooldown, []string{"STRK"}))
+	assert.Error(t, quotaError(throttled, []string{"ETH"}))
+}
+
+func TestRequestChecksQuotaFirst(t *testing.T) {
+	var challengeCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/quota", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"daily_limit":{"total":5,"used":5,"remaining":0,"in_cooldown":false},` +
+			`"hourly_throttle":{"strk":{"available":true},"eth":{"available":true}}}`))
+	})
+	mux.HandleFunc("/api/v1/challenge", func(w http.ResponseWriter, r *http.Request) {
+		challengeCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"challenge_id":"c1","challenge":"abc","difficulty":1,"ttl_seconds":300}`))
+	})
+	mux.HandleFunc("/api/v1/faucet", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"Rate limit exceeded","code":"RATE_LIMITED"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip := apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck
+	t.Cleanup(func() {
+		apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck = oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip
+	})
+	apiURL, jsonOut, skipVerification, noUpdateCheck = server.URL, true, true, true
+	requestTimeout = 30 * time.Second
+
+	err := runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+	assert.ErrorContains(t, err, "daily limit reached (5/5 requests used)")
+	assert.EqualValues(t, 0, challengeCalls.Load(), "no PoW for a request that would be rejected")
+
+	// --skip-quota-check goes straight to the PoW
+	skipQuotaCheck = true
+	testutil.CaptureStdout(t, func() {
+		err = runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+	})
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, challengeCalls.Load())
+}
+
+func TestRequestAddressFile(t *testing.T) {
+	// Fund the first two requests, then hit the rate limit
+	var faucetCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/challenge", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "STRK", r.URL.Query().Get("token"), "challenges are bound to the requested token")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"challenge_id":"c1","challenge":"abc","difficulty":1,"ttl_seconds":300}`))
+	})
+	mux.HandleFunc("/api/v1/faucet", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if faucetCalls.Add(1) > 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"Rate limit exceeded","code":"RATE_LIMITED"}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"tx_hash":"0xabc","amount":"10","token":"STRK","message":"ok"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "addresses.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		"# team wallets",
+		"0x0742d469482a89e7",
+		"not-an-address",
+		"",
+		"0x0742D469482A89E7",
+		"0x0abc",
+		"0x0def",
+		"0x0123",
+	}, "\n")), 0o600))
+
+	oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip, oldFile := apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck, addressFile
+	t.Cleanup(func() {
+		apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck, addressFile = oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip, oldFile
+	})
+	apiURL, jsonOut, skipVerification, noUpdateCheck, skipQuotaCheck = server.URL, true, true, true, true
+	requestTimeout = 30 * time.Second
+	addressFile = path
+
+	require.Error(t, requestArgs(requestCmd, []string{"0x0742d469482a89e7"}), "an address and a file are exclusive")
+
+	all = true
+	assert.ErrorContains(t, runRequest(requestCmd, nil), "can't be combined")
+	all = false
+
+	var runErr error
+	out := testutil.CaptureStdout(t, func() {
+		runErr = runRequest(requestCmd, nil)
+	})
+	require.NoError(t, runErr, "invalid lines and the rate limit don't fail a run that funded some addresses")
+
+	var results []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &results), out)
+	require.Len(t, results, 6)
+
+	byAddress := make(map[string]map[string]interface{})
+	for _, result := range results[1:] {
+		byAddress[result["address"].(string)] = result
+	}
+	assert.Equal(t, "0x0742d469482a89e7", results[0]["address"])
+	assert.Equal(t, true, results[0]["success"])
+	assert.Contains(t, byAddress["not-an-address"]["error"], "invalid address")
+	assert.Contains(t, byAddress["0x0742D469482A89E7"]["error"], "duplicate address")
+	assert.Equal(t, true, byAddress["0x0abc"]["success"])
+	assert.Equal(t, models.ErrCodeRateLimited, byAddress["0x0def"]["code"])
+	assert.Contains(t, byAddress["0x0123"]["error"], "skipped")
+	assert.EqualValues(t, 3, faucetCalls.Load(), "nothing is requested after the rate limit")
+}
+
+func TestRequestResubmitsRejectedChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		rejects   int32
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"fresh challenge accepted", 1, false, 2},
+		{"gives up after one retry", 3, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var challengeCalls, faucetCalls atomic.Int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/challenge", func(w http.ResponseWriter, r *http.Request) {
+				challengeCalls.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"challenge_id":"c1","challenge":"abc","difficulty":1,"ttl_seconds":300}`))
+			})
+			mux.HandleFunc("/api/v1/faucet", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if faucetCalls.Add(1) <= tt.rejects {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error":"Invalid or expired challenge","code":"CHALLENGE_INVALID"}`))
+					return
+				}
+				w.Write([]byte(`{"success":true,"tx_hash":"0xabc","amount":"10","token":"STRK","message":"ok"}`))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip := apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck
+			t.Cleanup(func() {
+				apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck = oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip
+			})
+			apiURL, jsonOut, skipVerification, noUpdateCheck, skipQuotaCheck = server.URL, true, true, true, true
+			requestTimeout = 30 * time.Second
+
+			var runErr error
+			testutil.CaptureStdout(t, func() {
+				runErr = runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+			})
+			if tt.wantErr {
+				require.Error(t, runErr)
+				assert.True(t, isChallengeRejected(runErr), "the faucet's error is returned")
+			} else {
+				require.NoError(t, runErr)
+			}
+			assert.Equal(t, tt.wantCalls, faucetCalls.Load())
+			assert.Equal(t, tt.wantCalls, challengeCalls.Load(), "each submit gets a fresh challenge")
+		})
+	}
+}
+
+func TestIsChallengeRejected(t *testing.T) {
+	assert.True(t, isChallengeRejected(&cli.APIError{Code: models.ErrCodeChallengeInvalid, Message: "Challenge already used"}))
+	assert.True(t, isChallengeRejected(fmt.Errorf("submit: %w", &cli.APIError{Message: "Invalid or expired challenge"})),
+		"older servers are recognized by the message")
+	assert.False(t, isChallengeRejected(&cli.APIError{Code: models.ErrCodePoWInvalid, Message: "Invalid or expired challenge"}))
+	assert.False(t, isChallengeRejected(errors.New("Invalid or expired challenge")))
+}
+
+func TestRequestMaxAttempts(t *testing.T) {
+	var faucetCalls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/challenge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"challenge_id":"c1","challenge":"abc","difficulty":8,"ttl_seconds":300}`))
+	})
+	mux.HandleFunc("/api/v1/faucet", func(w http.ResponseWriter, r *http.Request) {
+		faucetCalls.Add(1)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip, oldMax := apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck, maxAttempts
+	t.Cleanup(func() {
+		apiURL, jsonOut, skipVerification, requestTimeout, noUpdateCheck, skipQuotaCheck, maxAttempts = oldURL, oldJSON, oldYes, oldTimeout, oldNoUpdate, oldSkip, oldMax
+	})
+	apiURL, jsonOut, skipVerification, noUpdateCheck, skipQuotaCheck = server.URL, true, true, true, true
+	requestTimeout = 30 * time.Second
+
+	// The flag wins over the environment
+	t.Setenv(maxAttemptsEnv, "lots")
+	maxAttempts = 10
+	var runErr error
+	testutil.CaptureStdout(t, func() {
+		runErr = runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+	})
+	require.ErrorIs(t, runErr, clipow.ErrMaxAttempts)
+	assert.ErrorContains(t, runErr, "difficulty 8 in 10 attempts")
+
+	maxAttempts = 0
+	t.Setenv(maxAttemptsEnv, "20")
+	testutil.CaptureStdout(t, func() {
+		runErr = runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+	})
+	assert.ErrorContains(t, runErr, "difficulty 8 in 20 attempts")
+
+	t.Setenv(maxAttemptsEnv, "lots")
+	testutil.CaptureStdout(t, func() {
+		runErr = runRequest(requestCmd, []string{"0x0742d469482a89e7"})
+	})
+	assert.ErrorContains(t, runErr, "invalid "+maxAttemptsEnv)
+	assert.Zero(t, faucetCalls.Load(), "nothing is submitted without a solution")
+}
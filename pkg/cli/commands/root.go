@@ -3,16 +3,44 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
 	"github.com/spf13/cobra"
 )
 
+// version is the CLI release, compared against GitHub releases by the update check
+const version = "1.0.16"
+
+// defaultAPIURL is the hosted faucet used when no other API URL is configured
+const defaultAPIURL = "https://intermediate-albertine-aayushgiri-e93ace53.koyeb.app"
+
 var (
 	apiURL  string
 	verbose bool
 	jsonOut bool
+	quiet   bool
+
+	colorMode = colorFlag(ui.ColorAuto)
 )
 
+// colorFlag is the value of --color, checked when flags are parsed
+type colorFlag string
+
+func (f *colorFlag) String() string { return string(*f) }
+
+func (f *colorFlag) Type() string { return "string" }
+
+func (f *colorFlag) Set(value string) error {
+	switch mode := strings.ToLower(value); mode {
+	case ui.ColorAuto, ui.ColorAlways, ui.ColorNever:
+		*f = colorFlag(mode)
+		return nil
+	default:
+		return fmt.Errorf("must be auto, always or never")
+	}
+}
+
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "starknet-faucet",
@@ -25,20 +53,51 @@ Commands:
   limits                     Show detailed rate limit rules
   status <ADDRESS>           Check request status
   info                       View faucet information
+  tokens                     List supported tokens and drip amounts
+  chart                      Chart recent distribution as a sparkline
+  verify-receipt <FILE>      Check the signature of a transfer receipt
+  explain [CODE]             Explain an error code and what to do about it
+  estimate [DIFFICULTY]      Estimate the proof-of-work solve time here
+  config [get|set]           View or change CLI defaults
+  doctor                     Diagnose connectivity to the faucet
 
 Examples:
   starknet-faucet request 0xYOUR_ADDRESS              # Request STRK tokens
   starknet-faucet request 0xYOUR_ADDRESS --token ETH  # Request ETH tokens
   starknet-faucet request 0xYOUR_ADDRESS --both       # Request both STRK and ETH
+  starknet-faucet request 0xYOUR_ADDRESS --all        # Request every supported token
   starknet-faucet quota                               # Check YOUR remaining quota
+  starknet-faucet estimate                            # Time the proof of work
   starknet-faucet limits                              # View rate limit rules
   starknet-faucet status 0xYOUR_ADDRESS               # Check status
+  starknet-faucet config set api-url https://...      # Save a default API URL
+
+Configuration:
+  Defaults for --api-url, --token and --json can be stored in
+  ~/.starknet-faucet.yaml (see 'starknet-faucet config'), or set with
+  STARKNET_FAUCET_API_URL, STARKNET_FAUCET_TOKEN and STARKNET_FAUCET_JSON.
+  Precedence: flags > environment > config file > built-in default.
+
+Output:
+  --quiet (-q) drops the banner, spinners and progress lines and prints only
+  the final result, still human-readable. --json prints machine-readable
+  output instead. Errors always go to stderr.
+
+  Colors are used only when stdout is a terminal and NO_COLOR is unset.
+  Override with --color=always or --color=never.
+
+Updates:
+  'request' checks GitHub for a newer release at most once a day and prints
+  a notice if one is available. Run 'starknet-faucet --check-update' to
+  check now, or opt out with --no-update-check or
+  STARKNET_FAUCET_NO_UPDATE_CHECK=1.
 
 Rate Limits (per IP):
   Daily Limit:
     • 5 requests per day
     • Single token (STRK or ETH) = 1 request
     • Both tokens (--both) = 2 requests
+    • All tokens (--all) = 1 request per token
     • After 5th request: 24-hour cooldown
 
   Hourly Throttle:
@@ -53,7 +112,9 @@ Security:
   • CAPTCHA verification (human check)
 
 Need help? Visit: https://github.com/Giri-Aayush/starknet-faucet`,
-	Version: "1.0.16",
+	Version:           version,
+	PersistentPreRunE: applyConfigDefaults,
+	RunE:              runRoot,
 }
 
 // Execute runs the root command
@@ -66,9 +127,19 @@ func Execute() {
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "https://intermediate-albertine-aayushgiri-e93ace53.koyeb.app", "Faucet API URL")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", apiURLDefault(), "Faucet API URL (env: STARKNET_FAUCET_API_URL)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	rootCmd.PersistentFlags().BoolVar(&jsonOut, "json", false, "Output in JSON format")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only final results and errors (no banner or spinners)")
+	rootCmd.PersistentFlags().Var(&colorMode, "color", "Colorize output: auto, always or never (auto honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&noUpdateCheck, "no-update-check", false, "Don't check for a newer CLI release (env: STARKNET_FAUCET_NO_UPDATE_CHECK)")
+	rootCmd.Flags().BoolVar(&checkUpdate, "check-update", false, "Check whether a newer CLI release is available")
+
+	// Runs after flags are parsed, before any command
+	cobra.OnInitialize(func() {
+		ui.SetQuiet(quiet)
+		ui.SetColorMode(string(colorMode))
+	})
 
 	// Add subcommands
 	rootCmd.AddCommand(requestCmd)
@@ -76,4 +147,19 @@ func init() {
 	rootCmd.AddCommand(infoCmd)
 	rootCmd.AddCommand(limitsCmd)
 	rootCmd.AddCommand(quotaCmd)
+	rootCmd.AddCommand(tokensCmd)
+	rootCmd.AddCommand(chartCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(verifyReceiptCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(estimateCmd)
+}
+
+// apiURLDefault returns the --api-url default, preferring STARKNET_FAUCET_API_URL
+func apiURLDefault() string {
+	if url := os.Getenv(configEnvVars["api-url"]); url != "" {
+		return url
+	}
+	return defaultAPIURL
 }
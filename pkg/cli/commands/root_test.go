@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolveAPIURL runs a command wired like the root command and returns the
+// API URL it ends up with
+func resolveAPIURL(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var resolved string
+	cmd := &cobra.Command{
+		Use:               "test",
+		PersistentPreRunE: applyConfigDefaults,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolved = apiURL
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&apiURL, "api-url", apiURLDefault(), "Faucet API URL")
+	cmd.SetArgs(args)
+
+	require.NoError(t, cmd.Execute())
+	return resolved
+}
+
+func TestAPIURLPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{
+			name: "built-in default",
+			want: defaultAPIURL,
+		},
+		{
+			name: "env overrides default",
+			env:  "https://env.example.com",
+			want: "https://env.example.com",
+		},
+		{
+			name: "flag overrides env",
+			env:  "https://env.example.com",
+			args: []string{"--api-url", "https://flag.example.com"},
+			want: "https://flag.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir()) // no config file
+			t.Setenv("STARKNET_FAUCET_API_URL", tt.env)
+
+			assert.Equal(t, tt.want, resolveAPIURL(t, tt.args...))
+		})
+	}
+}
+
+func TestAPIURLEnvOverridesConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("STARKNET_FAUCET_API_URL", "")
+
+	path, cfg, err := loadConfigFile()
+	require.NoError(t, err)
+	require.NoError(t, cfg.Set("api-url", "https://file.example.com"))
+	require.NoError(t, cfg.Save(path))
+
+	assert.Equal(t, "https://file.example.com", resolveAPIURL(t))
+
+	t.Setenv("STARKNET_FAUCET_API_URL", "https://env.example.com")
+	assert.Equal(t, "https://env.example.com", resolveAPIURL(t))
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "List supported tokens",
+	Long: `List the tokens the faucet distributes, with drip amounts, contract
+addresses and limits.
+
+Example:
+  starknet-faucet tokens`,
+	RunE: runTokens,
+}
+
+func runTokens(cmd *cobra.Command, args []string) error {
+	// Create API client
+	client := cli.NewAPIClient(apiURL)
+
+	// Get tokens
+	resp, err := client.GetTokens()
+	if err != nil {
+		return fmt.Errorf("failed to get tokens: %w", err)
+	}
+
+	// Print response
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(resp, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		ui.PrintBanner()
+		ui.PrintTokensResponse(resp)
+	}
+
+	return nil
+}
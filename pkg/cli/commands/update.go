@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+// noUpdateCheckEnv disables update checks when set, for offline use
+const noUpdateCheckEnv = "STARKNET_FAUCET_NO_UPDATE_CHECK"
+
+var (
+	checkUpdate   bool
+	noUpdateCheck bool
+)
+
+// updateCheckDisabled reports whether the user opted out of update checks
+func updateCheckDisabled() bool {
+	return noUpdateCheck || os.Getenv(noUpdateCheckEnv) != ""
+}
+
+// runRoot handles the root command when no subcommand is given
+func runRoot(cmd *cobra.Command, args []string) error {
+	if !checkUpdate {
+		return cmd.Help()
+	}
+
+	latest, err := latestVersion(true)
+	if err != nil {
+		return err
+	}
+
+	if cli.IsNewerVersion(version, latest) {
+		printUpdateNotice(latest)
+		return nil
+	}
+	ui.PrintSuccess(fmt.Sprintf("starknet-faucet %s is up to date", version))
+	return nil
+}
+
+// latestVersion returns the latest released version, using the result cached
+// in the config file unless it is stale or force is set
+func latestVersion(force bool) (string, error) {
+	path, cfg, err := loadConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	if !force && cfg.LatestVersion != "" && time.Since(cfg.LastUpdateCheck) < cli.UpdateCheckInterval {
+		return cfg.LatestVersion, nil
+	}
+
+	latest, err := cli.LatestRelease(cli.ReleasesURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Caching is best effort, a read-only home directory shouldn't fail the check
+	cfg.LastUpdateCheck = time.Now()
+	cfg.LatestVersion = latest
+	_ = cfg.Save(path)
+
+	return latest, nil
+}
+
+// startUpdateCheck checks for a newer release in the background. The returned
+// function prints a notice if one was found, without waiting for a check that
+// is still in flight. Quiet mode skips the check.
+func startUpdateCheck() func() {
+	if updateCheckDisabled() || checkUpdate || ui.Quiet() {
+		return func() {}
+	}
+
+	result := make(chan string, 1)
+	go func() {
+		latest, err := latestVersion(false)
+		if err != nil {
+			latest = ""
+		}
+		result <- latest
+	}()
+
+	return func() {
+		select {
+		case latest := <-result:
+			if cli.IsNewerVersion(version, latest) {
+				printUpdateNotice(latest)
+			}
+		default:
+		}
+	}
+}
+
+// printUpdateNotice tells the user a newer release is available. It goes to
+// stderr so it never corrupts --json output.
+func printUpdateNotice(latest string) {
+	fmt.Fprintf(os.Stderr, "\nA new version of starknet-faucet is available: %s (you have %s)\n", latest, version)
+	fmt.Fprintf(os.Stderr, "Update with 'npm install -g starknet-faucet' or see %s\n", cli.ReleasesPage)
+	fmt.Fprintf(os.Stderr, "Disable this check with --no-update-check or %s=1\n", noUpdateCheckEnv)
+}
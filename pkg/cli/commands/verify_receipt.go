@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/receipt"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli"
+	"github.com/Giri-Aayush/starknet-faucet/pkg/cli/ui"
+	"github.com/spf13/cobra"
+)
+
+// receiptPublicKey is the --public-key flag of verify-receipt
+var receiptPublicKey string
+
+var verifyReceiptCmd = &cobra.Command{
+	Use:   "verify-receipt <FILE>",
+	Short: "Check the signature of a transfer receipt",
+	Long: `Check that a receipt was signed by the faucet, without reading the chain.
+
+Faucets with SIGN_RECEIPTS on attach a signed receipt to every transfer.
+FILE holds a receipt, or a whole 'request --json' response whose receipts
+are all checked; "-" reads it from stdin. The faucet's public key is fetched
+from its /info unless --public-key is given.
+
+Examples:
+  starknet-faucet request 0xYOUR_ADDRESS --json > drip.json
+  starknet-faucet verify-receipt drip.json
+  starknet-faucet verify-receipt - --public-key 8a88e3dd... < receipt.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerifyReceipt,
+}
+
+// receiptCheck is one verified receipt in the --json output of verify-receipt
+type receiptCheck struct {
+	TxHash string `json:"tx_hash"`
+	Valid  bool   `json:"valid"`
+	Error  string `json:"error,omitempty"`
+}
+
+func init() {
+	verifyReceiptCmd.Flags().StringVar(&receiptPublicKey, "public-key", "", "Hex Ed25519 key to verify with (default: fetched from the faucet)")
+}
+
+func runVerifyReceipt(cmd *cobra.Command, args []string) error {
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read receipt: %w", err)
+	}
+
+	receipts, err := parseReceipts(data)
+	if err != nil {
+		return err
+	}
+
+	publicKey := receiptPublicKey
+	if publicKey == "" {
+		info, err := cli.NewAPIClient(apiURL).GetInfo()
+		if err != nil {
+			return fmt.Errorf("failed to get the faucet's public key: %w", err)
+		}
+		if info.ReceiptPublicKey == "" {
+			return fmt.Errorf("the faucet at %s doesn't sign receipts", apiURL)
+		}
+		publicKey = info.ReceiptPublicKey
+	}
+
+	checks := make([]receiptCheck, len(receipts))
+	invalid := 0
+	for i, r := range receipts {
+		checks[i] = receiptCheck{TxHash: r.TxHash, Valid: true}
+		if err := receipt.Verify(publicKey, r); err != nil {
+			checks[i] = receiptCheck{TxHash: r.TxHash, Error: err.Error()}
+			invalid++
+		}
+	}
+
+	if jsonOut {
+		jsonBytes, _ := json.MarshalIndent(checks, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		for i, r := range receipts {
+			if !checks[i].Valid {
+				ui.PrintError(fmt.Sprintf("Invalid receipt for %s: %s", r.TxHash, checks[i].Error))
+				continue
+			}
+			ui.PrintSuccess(fmt.Sprintf("Valid receipt: %s %s to %s", r.Amount, r.Token, r.Recipient))
+			fmt.Printf("  TX Hash: %s\n", r.TxHash)
+			fmt.Printf("  Sent:    %s\n", time.Unix(r.Timestamp, 0).Local().Format("January 02, 2006 at 3:04 PM MST"))
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d receipt(s) failed verification", invalid, len(receipts))
+	}
+	return nil
+}
+
+// parseReceipts reads the receipts in a faucet response, or a single receipt
+func parseReceipts(data []byte) ([]models.Receipt, error) {
+	var resp models.FaucetResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt: %w", err)
+	}
+	var receipts []models.Receipt
+	for _, tx := range resp.Transactions {
+		if tx.Receipt != nil {
+			receipts = append(receipts, *tx.Receipt)
+		}
+	}
+	if len(receipts) > 0 {
+		return receipts, nil
+	}
+
+	var single models.Receipt
+	if err := json.Unmarshal(data, &single); err == nil && single.Signature != "" {
+		return []models.Receipt{single}, nil
+	}
+	return nil, fmt.Errorf("no receipts found; the faucet only signs them when SIGN_RECEIPTS is on")
+}
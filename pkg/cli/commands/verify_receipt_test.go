@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/receipt"
+	"github.com/Giri-Aayush/starknet-faucet/internal/testutil"
+)
+
+func TestVerifyReceipt(t *testing.T) {
+	key, err := receipt.ParseKey(strings.Repeat("01", 32))
+	require.NoError(t, err)
+	signed := func(token, txHash string) *models.Receipt {
+		r := &models.Receipt{Recipient: "0x1", Token: token, Amount: "10", TxHash: txHash, Timestamp: 1767225600}
+		receipt.Sign(key, r)
+		return r
+	}
+
+	oldURL, oldJSON, oldKey := apiURL, jsonOut, receiptPublicKey
+	t.Cleanup(func() { apiURL, jsonOut, receiptPublicKey = oldURL, oldJSON, oldKey })
+	apiURL, jsonOut, receiptPublicKey = newFaucetServer(t, http.StatusOK, `{}`).URL, true, receipt.PublicKey(key)
+
+	write := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "receipt.json")
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+	verify := func(path string) ([]receiptCheck, error) {
+		var runErr error
+		out := testutil.CaptureStdout(t, func() { runErr = runVerifyReceipt(verifyReceiptCmd, []string{path}) })
+		var checks []receiptCheck
+		if out != "" {
+			require.NoError(t, json.Unmarshal([]byte(out), &checks), out)
+		}
+		return checks, runErr
+	}
+
+	// Every receipt in a request --json response is checked
+	tampered := signed("ETH", "0xdef")
+	tampered.Amount = "1000"
+	checks, err := verify(write(models.FaucetResponse{Transactions: []models.TransactionInfo{
+		{TxHash: "0xabc", Receipt: signed("STRK", "0xabc")},
+		{TxHash: "0xdef", Receipt: tampered},
+	}}))
+	assert.EqualError(t, err, "1 of 2 receipt(s) failed verification")
+	require.Len(t, checks, 2)
+	assert.True(t, checks[0].Valid)
+	assert.False(t, checks[1].Valid)
+	assert.Equal(t, "0xdef", checks[1].TxHash)
+
+	// A single receipt, with the key from the faucet's /info
+	receiptPublicKey = ""
+	_, err = verify(write(signed("STRK", "0xabc")))
+	assert.EqualError(t, err, "the faucet at "+apiURL+" doesn't sign receipts")
+
+	receiptPublicKey = receipt.PublicKey(key)
+	checks, err = verify(write(signed("STRK", "0xabc")))
+	require.NoError(t, err)
+	assert.Equal(t, []receiptCheck{{TxHash: "0xabc", Valid: true}}, checks)
+
+	_, err = parseReceipts([]byte(`{"success":true,"transactions":[{"tx_hash":"0xabc"}]}`))
+	assert.ErrorContains(t, err, "no receipts found")
+}
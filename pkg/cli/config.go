@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Giri-Aayush/starknet-faucet/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the CLI config file in the user's home directory
+const ConfigFileName = ".starknet-faucet.yaml"
+
+// ConfigKeys lists the settings that can be stored in the config file
+var ConfigKeys = []string{"api-url", "token", "json"}
+
+// FileConfig holds CLI defaults loaded from the config file
+type FileConfig struct {
+	APIURL string `yaml:"api-url,omitempty"`
+	Token  string `yaml:"token,omitempty"`
+	JSON   *bool  `yaml:"json,omitempty"` // nil when unset, so false can be stored explicitly
+
+	// Cached result of the last update check, managed by the CLI
+	LastUpdateCheck time.Time `yaml:"last-update-check,omitempty"`
+	LatestVersion   string    `yaml:"latest-version,omitempty"`
+}
+
+// ConfigPath returns the location of the CLI config file
+func ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ConfigFileName), nil
+}
+
+// LoadConfig reads the config file at path. A missing file yields an empty config.
+func LoadConfig(path string) (*FileConfig, error) {
+	cfg := &FileConfig{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Save writes the config to path
+func (c *FileConfig) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the value stored for key, or "" if it is unset
+func (c *FileConfig) Get(key string) (string, error) {
+	switch key {
+	case "api-url":
+		return c.APIURL, nil
+	case "token":
+		return c.Token, nil
+	case "json":
+		if c.JSON == nil {
+			return "", nil
+		}
+		return strconv.FormatBool(*c.JSON), nil
+	default:
+		return "", unknownConfigKeyError(key)
+	}
+}
+
+// Set stores value for key. An empty value clears the key.
+func (c *FileConfig) Set(key, value string) error {
+	switch key {
+	case "api-url":
+		c.APIURL = strings.TrimRight(value, "/")
+	case "token":
+		c.Token = utils.NormalizeToken(value)
+	case "json":
+		if value == "" {
+			c.JSON = nil
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for json: %q (use true or false)", value)
+		}
+		c.JSON = &b
+	default:
+		return unknownConfigKeyError(key)
+	}
+	return nil
+}
+
+func unknownConfigKeyError(key string) error {
+	return fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(ConfigKeys, ", "))
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), ConfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, &FileConfig{}, cfg)
+}
+
+func TestConfigSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+
+	cfg := &FileConfig{}
+	require.NoError(t, cfg.Set("api-url", "https://faucet.example.com/"))
+	require.NoError(t, cfg.Set("token", "eth"))
+	require.NoError(t, cfg.Set("json", "false"))
+	require.NoError(t, cfg.Save(path))
+
+	loaded, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	apiURL, _ := loaded.Get("api-url")
+	token, _ := loaded.Get("token")
+	jsonOut, _ := loaded.Get("json")
+	assert.Equal(t, "https://faucet.example.com", apiURL)
+	assert.Equal(t, "ETH", token)
+	assert.Equal(t, "false", jsonOut)
+}
+
+func TestConfigSetInvalid(t *testing.T) {
+	cfg := &FileConfig{}
+	assert.Error(t, cfg.Set("json", "maybe"))
+	assert.Error(t, cfg.Set("colour", "blue"))
+
+	_, err := cfg.Get("colour")
+	assert.Error(t, err)
+}
+
+func TestConfigSetEmptyClearsKey(t *testing.T) {
+	cfg := &FileConfig{}
+	require.NoError(t, cfg.Set("json", "true"))
+	require.NoError(t, cfg.Set("json", ""))
+	assert.Nil(t, cfg.JSON)
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+)
+
+// errorExplanations says what each API error code means and suggests a next
+// step. It backs both ErrorHint and the explain command.
+var errorExplanations = map[string]struct{ meaning, hint string }{
+	models.ErrCodeInvalidRequest: {
+		"The request body or its parameters were malformed.",
+		"The request was malformed. Make sure your CLI is up to date.",
+	},
+	models.ErrCodeInvalidAddress: {
+		"The recipient isn't a valid Starknet address, or the faucet won't fund it.",
+		"Check that the address is a 0x-prefixed hex Starknet address.",
+	},
+	models.ErrCodeInvalidToken: {
+		"The faucet doesn't dispense the requested token.",
+		"Use --token STRK, --token ETH or --both.",
+	},
+	models.ErrCodeRateLimited: {
+		"Your IP used up its daily requests, is in a cooldown, or requested this token too recently.",
+		"Run 'starknet-faucet quota' to see when you can request again.",
+	},
+	models.ErrCodeForbidden: {
+		"The faucet blocks requests from your IP.",
+		"Your IP is blocked by this faucet. Contact the faucet operator if this is a mistake.",
+	},
+	models.ErrCodeUnauthorized: {
+		"The X-API-Key header holds a key the faucet doesn't know.",
+		"The API key was rejected. Check it with the faucet operator, or request without one.",
+	},
+	models.ErrCodeChallengeInvalid: {
+		"The proof-of-work challenge is unknown, expired or was already used.",
+		"The challenge expired or was already used. Run the command again.",
+	},
+	models.ErrCodePoWInvalid: {
+		"The submitted nonce doesn't solve the proof-of-work challenge.",
+		"The proof of work was rejected. Run the command again.",
+	},
+	models.ErrCodeSignatureInvalid: {
+		"The signed authorization expired, was already used, or wasn't signed by the recipient.",
+		"Sign a fresh authorization with the recipient account and submit it once.",
+	},
+	models.ErrCodeSolvedTooFast: {
+		"The solution arrived sooner after the challenge than the faucet's minimum solve time.",
+		"This faucet enforces a minimum solve time. Update your CLI, which waits for it.",
+	},
+	models.ErrCodeInProgress: {
+		"Another request from your IP was still being handled.",
+		"Another request from your network is still running. Wait for it to finish, then try again.",
+	},
+	models.ErrCodeDistributionLimit: {
+		"The faucet reached its hourly or daily cap on tokens sent to everyone.",
+		"The faucet reached its distribution limit. Try again in an hour.",
+	},
+	models.ErrCodeFaucetEmpty: {
+		"The faucet's balance of the token is below what it keeps in reserve.",
+		"The faucet is low on this token. Try the other token or come back later.",
+	},
+	models.ErrCodeTokenDisabled: {
+		"The faucet operator paused distribution of the token.",
+		"Request another token with --token, or come back later.",
+	},
+	models.ErrCodeFeeInsufficient: {
+		"The faucet can't pay the transaction fee.",
+		"The faucet can't cover transaction fees right now. Try again later.",
+	},
+	models.ErrCodeTransferFailed: {
+		"The transfer transaction could not be submitted.",
+		"The transfer could not be submitted. Try again in a few minutes.",
+	},
+	models.ErrCodeRPCTimeout: {
+		"The Starknet node didn't answer in time. The transfer may still have gone through.",
+		"The Starknet node is slow to respond. Check your balance, then try again later.",
+	},
+	models.ErrCodeRPCUnavailable: {
+		"The faucet can't reach its Starknet node.",
+		"The faucet can't reach the Starknet network right now. Nothing was sent; try again in a few minutes.",
+	},
+	models.ErrCodeUnavailable: {
+		"A service the faucet depends on, such as its database, is down.",
+		"The faucet is temporarily unavailable. Try again in a few minutes.",
+	},
+	models.ErrCodeServerBusy: {
+		"The faucet hit its ceiling on requests from everyone at once.",
+		"The faucet is handling too many requests right now. Try again in a few seconds.",
+	},
+	models.ErrCodeRequestTimeout: {
+		"The faucet gave up on the request after running past its deadline. A token request may still have gone through.",
+		"The faucet is slow to respond. Check 'starknet-faucet status' before requesting again.",
+	},
+	models.ErrCodeInternal: {
+		"The faucet failed unexpectedly.",
+		"Something went wrong on the server. Try again later.",
+	},
+}
+
+// ErrorExplanation describes an API error code for people
+type ErrorExplanation struct {
+	Code    string `json:"code"`
+	Meaning string `json:"meaning"` // What went wrong
+	Hint    string `json:"hint"`    // Suggested next step
+}
+
+// ExplainErrorCode describes an API error code. ok is false for codes this
+// CLI doesn't know, e.g. ones added by a newer server.
+func ExplainErrorCode(code string) (explanation ErrorExplanation, ok bool) {
+	e, ok := errorExplanations[code]
+	if !ok {
+		return ErrorExplanation{Code: code}, false
+	}
+	return ErrorExplanation{Code: code, Meaning: e.meaning, Hint: e.hint}, true
+}
+
+// ErrorHint returns a suggested next step for an API error, or "" if there is none
+func ErrorHint(err error) string {
+	var unexpected *UnexpectedResponseError
+	if errors.As(err, &unexpected) {
+		if unexpected.Blocked {
+			return "The faucet's CDN or firewall refused the request. Wait a while or switch networks (VPNs are often blocked), and give the faucet operator the Ray ID, if shown, if it persists."
+		}
+		return "Something in front of the faucet answered instead of its API. Check --api-url, or try again in a few minutes."
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	return errorExplanations[apiErr.Code].hint
+}
+
+// UnexpectedResponseError is a response that did not come from the faucet
+// API, typically an HTML error page from a proxy, CDN or firewall in front of
+// it
+type UnexpectedResponseError struct {
+	StatusCode  int
+	ContentType string
+	Snippet     string // Page title or start of the body, as plain text
+	Blocked     bool   // The request was refused by a CDN or firewall
+	Blocker     string // "Cloudflare" when it was Cloudflare, else empty
+	RayID       string // Cloudflare's request ID, quote it when reporting a block
+}
+
+// Error implements the error interface
+func (e *UnexpectedResponseError) Error() string {
+	if e.Blocked {
+		by := "a firewall in front of the faucet"
+		if e.Blocker != "" {
+			by = e.Blocker
+		}
+		msg := fmt.Sprintf("request blocked by %s (HTTP %d)", by, e.StatusCode)
+		if e.RayID != "" {
+			msg += fmt.Sprintf(" (Ray ID: %s)", e.RayID)
+		}
+		return msg
+	}
+
+	msg := fmt.Sprintf("unexpected HTTP %d response that is not from the faucet API", e.StatusCode)
+	if e.ContentType != "" {
+		msg += fmt.Sprintf(" (%s)", e.ContentType)
+	}
+	if e.Snippet != "" {
+		msg += fmt.Sprintf(": %q", e.Snippet)
+	}
+	return msg
+}
+
+// maxSnippetLength is how much of an unexpected body is quoted in errors
+const maxSnippetLength = 120
+
+var (
+	htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlTag   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// newUnexpectedResponseError describes a non-API response from its status,
+// headers and body
+func newUnexpectedResponseError(status int, header http.Header, body []byte) *UnexpectedResponseError {
+	contentType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	e := &UnexpectedResponseError{
+		StatusCode:  status,
+		ContentType: contentType,
+		Snippet:     bodySnippet(body),
+		RayID:       header.Get("CF-Ray"),
+	}
+
+	if e.RayID != "" || strings.EqualFold(header.Get("Server"), "cloudflare") ||
+		bytes.Contains(bytes.ToLower(body), []byte("cloudflare")) {
+		e.Blocker = "Cloudflare"
+	}
+	// The faucet answers its own 403s and 429s in JSON, so a page with one of
+	// these statuses comes from whatever sits in front of it
+	e.Blocked = header.Get("CF-Mitigated") != "" ||
+		status == http.StatusForbidden || status == http.StatusTooManyRequests
+	return e
+}
+
+// bodySnippet returns the page title, or else the start of the body with
+// tags removed, on one line
+func bodySnippet(body []byte) string {
+	text := string(body)
+	if m := htmlTitle.FindStringSubmatch(text); m != nil {
+		text = m[1]
+	} else {
+		text = htmlTag.ReplaceAllString(text, " ")
+	}
+	text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+
+	if runes := []rune(text); len(runes) > maxSnippetLength {
+		text = string(runes[:maxSnippetLength]) + "..."
+	}
+	return text
+}
+
+// isAPIResponse reports whether a response body was written by the faucet
+// API, which always answers in JSON. Empty bodies carry nothing to misread.
+func isAPIResponse(header http.Header, body []byte) bool {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return true
+	}
+	contentType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+		return true
+	}
+	// Tolerate servers that leave the content type unset
+	return json.Valid(body)
+}
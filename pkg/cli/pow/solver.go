@@ -3,36 +3,68 @@ package pow
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
+
+	faucetpow "github.com/Giri-Aayush/starknet-faucet/internal/pow"
 )
 
+// assumedHashRate is the conservative hashes per second EstimateSolveTime
+// assumes when the machine hasn't been measured
+const assumedHashRate = 500000
+
+// ErrChallengeExpired is returned when the deadline passes before a solution is found
+var ErrChallengeExpired = errors.New("challenge expired before it was solved")
+
+// ErrMaxAttempts is returned when the attempt cap is reached before a solution is found
+var ErrMaxAttempts = errors.New("solve attempt cap reached")
+
 // SolveResult contains the result of solving a PoW challenge
 type SolveResult struct {
-	Nonce    int64
+	Nonce    uint64
 	Duration time.Duration
 }
 
 // Solver handles PoW challenge solving
-type Solver struct{}
+type Solver struct {
+	maxAttempts uint64 // 0 scales the cap with difficulty, see faucetpow.MaxAttempts
+}
 
 // NewSolver creates a new PoW solver
 func NewSolver() *Solver {
 	return &Solver{}
 }
 
+// NewSolverWithMaxAttempts creates a PoW solver that gives up after
+// maxAttempts nonces at any difficulty. 0 keeps the default cap.
+func NewSolverWithMaxAttempts(maxAttempts uint64) *Solver {
+	return &Solver{maxAttempts: maxAttempts}
+}
+
 // Solve solves a PoW challenge with progress updates
-func (s *Solver) Solve(challenge string, difficulty int, progressCallback func(int64, time.Duration)) (*SolveResult, error) {
+func (s *Solver) Solve(challenge string, difficulty int, progressCallback func(uint64, time.Duration)) (*SolveResult, error) {
+	return s.SolveBefore(challenge, difficulty, time.Time{}, progressCallback)
+}
+
+// SolveBefore solves a PoW challenge, giving up with ErrChallengeExpired once
+// deadline passes. A zero deadline never expires.
+func (s *Solver) SolveBefore(challenge string, difficulty int, deadline time.Time, progressCallback func(uint64, time.Duration)) (*SolveResult, error) {
 	prefix := strings.Repeat("0", difficulty)
+	maxAttempts := s.maxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = faucetpow.MaxAttempts(difficulty)
+	}
 	startTime := time.Now()
 
-	var nonce int64
 	var lastUpdate time.Time
 
-	for {
-		data := fmt.Sprintf("%s%d", challenge, nonce)
-		hash := sha256.Sum256([]byte(data))
+	for nonce := uint64(0); nonce < maxAttempts; nonce++ {
+		// Hashed as challenge + decimal nonce, matching the server's check
+		hash := sha256.Sum256([]byte(challenge + strconv.FormatUint(nonce, 10)))
 		hashHex := hex.EncodeToString(hash[:])
 
 		if strings.HasPrefix(hashHex, prefix) {
@@ -44,39 +76,79 @@ func (s *Solver) Solve(challenge string, difficulty int, progressCallback func(i
 			}, nil
 		}
 
-		nonce++
-
-		// Call progress callback every 0.5 seconds
-		if progressCallback != nil && time.Since(lastUpdate) >= 500*time.Millisecond {
-			progressCallback(nonce, time.Since(startTime))
+		// Call progress callback and check the deadline every 0.2 seconds
+		if (progressCallback != nil || !deadline.IsZero()) && time.Since(lastUpdate) >= 200*time.Millisecond {
+			if progressCallback != nil {
+				progressCallback(nonce+1, time.Since(startTime))
+			}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return nil, ErrChallengeExpired
+			}
 			lastUpdate = time.Now()
 		}
-
-		// Safety check - shouldn't happen with difficulty 4
-		if nonce > 100000000 {
-			return nil, fmt.Errorf("failed to solve challenge after %d attempts", nonce)
-		}
 	}
+
+	return nil, fmt.Errorf("%w: no solution at difficulty %d in %d attempts", ErrMaxAttempts, difficulty, maxAttempts)
 }
 
-// EstimateSolveTime estimates how long it will take to solve a challenge
-func EstimateSolveTime(difficulty int) time.Duration {
-	// Rough estimate: 16^difficulty attempts on average
-	attempts := 1
-	for i := 0; i < difficulty; i++ {
-		attempts *= 16
+// ExpectedAttempts returns the average number of hashes needed to solve a
+// challenge, as the server computes it
+func ExpectedAttempts(difficulty int) uint64 {
+	return faucetpow.ExpectedAttempts(difficulty)
+}
+
+// EstimateProgress estimates how far along a solve is as a fraction of the
+// expected attempts, and the time left at the current hash rate. PoW is
+// probabilistic, so the fraction can exceed 1; remaining is 0 once it does.
+func EstimateProgress(attempts uint64, elapsed time.Duration, difficulty int) (fraction float64, remaining time.Duration) {
+	expected := ExpectedAttempts(difficulty)
+	fraction = float64(attempts) / float64(expected)
+
+	if attempts > 0 && attempts < expected && elapsed > 0 {
+		hashesPerSecond := float64(attempts) / elapsed.Seconds()
+		remaining = time.Duration(float64(expected-attempts) / hashesPerSecond * float64(time.Second))
 	}
 
-	// Assume 500k hashes per second (conservative)
-	hashesPerSecond := 500000
-	seconds := attempts / hashesPerSecond
+	return fraction, remaining
+}
 
+// EstimateSolveTime estimates how long it will take to solve a challenge on
+// an average CPU, rounded down to the second and never under one
+func EstimateSolveTime(difficulty int) time.Duration {
 	// Add 20% buffer
-	seconds = int(float64(seconds) * 1.2)
+	estimate := EstimateSolveTimeAt(difficulty, assumedHashRate) / 10 * 12
 
-	if seconds < 1 {
-		seconds = 1
+	if estimate < time.Second {
+		return time.Second
+	}
+	return estimate.Truncate(time.Second)
+}
+
+// EstimateSolveTimeAt returns the average time to solve a challenge at
+// hashRate hashes per second. It saturates at the longest time.Duration.
+func EstimateSolveTimeAt(difficulty int, hashRate float64) time.Duration {
+	seconds := float64(ExpectedAttempts(difficulty)) / hashRate
+	if seconds >= float64(math.MaxInt64/int64(time.Second)) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// MeasureHashRate hashes for about d the way the solver does and returns the
+// hashes per second this machine manages
+func MeasureHashRate(d time.Duration) float64 {
+	prefix := strings.Repeat("0", 64) // Never matches, so every hash is checked in full
+	start := time.Now()
+
+	var hashes uint64
+	for time.Since(start) < d {
+		// Read the clock once per batch so it doesn't skew the measurement
+		for i := 0; i < 1000; i++ {
+			hash := sha256.Sum256([]byte("calibration" + strconv.FormatUint(hashes, 10)))
+			_ = strings.HasPrefix(hex.EncodeToString(hash[:]), prefix)
+			hashes++
+		}
 	}
 
-	return time.Duration(seconds) * time.Second
+	return float64(hashes) / time.Since(start).Seconds()
 }
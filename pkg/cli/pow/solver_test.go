@@ -0,0 +1,84 @@
+package pow
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverpow "github.com/Giri-Aayush/starknet-faucet/internal/pow"
+)
+
+func TestExpectedAttempts(t *testing.T) {
+	assert.Equal(t, uint64(1), ExpectedAttempts(0))
+	assert.Equal(t, uint64(16), ExpectedAttempts(1))
+	assert.Equal(t, uint64(65536), ExpectedAttempts(4))
+	assert.Equal(t, uint64(math.MaxUint64), ExpectedAttempts(16))
+}
+
+func TestEstimateProgress(t *testing.T) {
+	// Halfway through the expected attempts after 1s: another second to go
+	fraction, remaining := EstimateProgress(32768, time.Second, 4)
+	assert.InDelta(t, 0.5, fraction, 1e-9)
+	assert.Equal(t, time.Second, remaining)
+
+	// An unlucky solve runs past the estimate
+	fraction, remaining = EstimateProgress(100000, 2*time.Second, 4)
+	assert.Greater(t, fraction, 1.0)
+	assert.Zero(t, remaining)
+
+	// No timing data yet
+	_, remaining = EstimateProgress(0, 0, 4)
+	assert.Zero(t, remaining)
+}
+
+func TestEstimateSolveTime(t *testing.T) {
+	// 65536 attempts at 65536 hashes per second
+	assert.Equal(t, time.Second, EstimateSolveTimeAt(4, 65536))
+	assert.Equal(t, time.Duration(math.MaxInt64), EstimateSolveTimeAt(16, 1e6))
+
+	// The conservative estimate never promises under a second
+	assert.Equal(t, time.Second, EstimateSolveTime(1))
+	assert.Equal(t, 40*time.Second, EstimateSolveTime(6))
+
+	assert.Greater(t, MeasureHashRate(10*time.Millisecond), 0.0)
+}
+
+func TestSolveBeforeDeadline(t *testing.T) {
+	solver := NewSolver()
+
+	// Difficulty 1 is solved long before a generous deadline
+	result, err := solver.SolveBefore("abc", 1, time.Now().Add(time.Minute), nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+
+	// An effectively unsolvable challenge gives up once the deadline passes
+	start := time.Now()
+	_, err = solver.SolveBefore("abc", 16, time.Now().Add(300*time.Millisecond), nil)
+	assert.ErrorIs(t, err, ErrChallengeExpired)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestSolverMaxAttempts(t *testing.T) {
+	// Ten nonces are nowhere near enough for difficulty 8
+	_, err := NewSolverWithMaxAttempts(10).Solve("abc", 8, nil)
+	assert.ErrorIs(t, err, ErrMaxAttempts)
+	assert.ErrorContains(t, err, "difficulty 8 in 10 attempts")
+
+	result, err := NewSolverWithMaxAttempts(0).Solve("abc", 1, nil)
+	require.NoError(t, err)
+	assert.True(t, serverpow.Solves("abc", result.Nonce, 1), "0 keeps the default cap")
+}
+
+func TestSolutionsVerifyOnServer(t *testing.T) {
+	solver := NewSolver()
+	gen := serverpow.NewGenerator(2, 300)
+
+	for _, challenge := range []string{"abc", "test123", "0badc0ffee"} {
+		result, err := solver.Solve(challenge, 2, nil)
+		require.NoError(t, err)
+		assert.True(t, gen.VerifyPoW(challenge, result.Nonce, 2), challenge)
+	}
+}
@@ -2,11 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/Giri-Aayush/starknet-faucet/internal/models"
 )
 
@@ -24,8 +27,60 @@ var (
 	arrow     = cyan("→")
 )
 
+// Color modes accepted by SetColorMode
+const (
+	ColorAuto   = "auto"   // Color only on a terminal, and never when NO_COLOR is set
+	ColorAlways = "always" // Color even when output is piped
+	ColorNever  = "never"  // No escape codes at all
+)
+
+// SetColorMode turns colored output on or off. Unknown modes behave like ColorAuto.
+func SetColorMode(mode string) {
+	switch mode {
+	case ColorAlways:
+		color.NoColor = false
+	case ColorNever:
+		color.NoColor = true
+	default:
+		color.NoColor = !colorSupported()
+	}
+
+	// The symbols are colored once, so redo them under the new setting
+	checkMark = green("✓")
+	xMark = red("✗")
+	arrow = cyan("→")
+}
+
+// colorSupported reports whether stdout is a terminal that should get color,
+// following https://no-color.org
+func colorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// quiet suppresses the banner, spinners, info lines and intermediate steps so
+// only final results and errors are printed
+var quiet bool
+
+// SetQuiet turns quiet output on or off
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// Quiet reports whether quiet output is on
+func Quiet() bool {
+	return quiet
+}
+
 // PrintBanner prints the faucet banner
 func PrintBanner() {
+	if quiet {
+		return
+	}
+
 	title := "Starknet Terminal Faucet"
 	subtitle := "Testnet Tokens. Terminal-Native."
 	divider := strings.Repeat("─", 60)
@@ -43,56 +98,194 @@ func PrintSuccess(message string) {
 	fmt.Printf("%s %s\n", checkMark, message)
 }
 
-// PrintError prints an error message
+// PrintStep prints the success of an intermediate step, hidden in quiet mode
+func PrintStep(message string) {
+	if quiet {
+		return
+	}
+	PrintSuccess(message)
+}
+
+// PrintError prints an error message to stderr
 func PrintError(message string) {
-	fmt.Printf("%s %s\n", xMark, red(message))
+	fmt.Fprintf(color.Error, "%s %s\n", xMark, red(message))
 }
 
-// PrintInfo prints an info message
+// PrintInfo prints an info message, hidden in quiet mode
 func PrintInfo(message string) {
+	if quiet {
+		return
+	}
 	fmt.Printf("%s %s\n", arrow, message)
 }
 
-// NewSpinner creates a new spinner with a message
+// PrintSpacer prints a blank line between sections, omitted in quiet mode
+func PrintSpacer() {
+	if quiet {
+		return
+	}
+	fmt.Println()
+}
+
+// NewSpinner creates a new spinner with a message. In quiet mode the spinner
+// is disabled and Start does nothing.
 func NewSpinner(message string) *spinner.Spinner {
 	s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
 	s.Suffix = " " + message
 	s.Color("cyan")
+	if quiet {
+		s.Disable()
+	}
 	return s
 }
 
+// DefaultArrivalEstimate is assumed when the faucet doesn't report how long
+// its transfers take to arrive
+const DefaultArrivalEstimate = 30 * time.Second
+
+// Arrival is what PrintFaucetResponse knows about when the tokens arrive
+type Arrival struct {
+	Estimate time.Duration // From the faucet's /info, DefaultArrivalEstimate when 0
+	Elapsed  time.Duration // From submitting the request to its response
+}
+
+// arrivedStatuses are the finality statuses at which a transfer shows in the
+// recipient's balance
+var arrivedStatuses = map[string]bool{
+	"PRE_CONFIRMED":  true,
+	"ACCEPTED_ON_L2": true,
+	"ACCEPTED_ON_L1": true,
+}
+
+// arrivalMessage says when the tokens in txs arrive: how long they took when
+// the faucet waited for every transfer to be confirmed, or else the estimate
+func arrivalMessage(txs []models.TransactionInfo, arrival Arrival) string {
+	confirmed := true
+	for _, tx := range txs {
+		confirmed = confirmed && arrivedStatuses[tx.ConfirmationStatus]
+	}
+	if confirmed {
+		return fmt.Sprintf("Tokens arrived in %s.", formatArrival(arrival.Elapsed))
+	}
+
+	estimate := arrival.Estimate
+	if estimate <= 0 {
+		estimate = DefaultArrivalEstimate
+	}
+	return fmt.Sprintf("Tokens will arrive in ~%s.", formatArrival(estimate))
+}
+
+// formatArrival formats d in whole seconds, or minutes once it is longer
+// than a minute and a half
+func formatArrival(d time.Duration) string {
+	if d < 90*time.Second {
+		seconds := max(int(d.Round(time.Second)/time.Second), 1)
+		return fmt.Sprintf("%d second%s", seconds, pluralize(seconds))
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("%d minute%s", minutes, pluralize(minutes))
+}
+
 // PrintFaucetResponse prints a nicely formatted faucet response
-func PrintFaucetResponse(resp *models.FaucetResponse) {
+func PrintFaucetResponse(resp *models.FaucetResponse, arrival Arrival) {
+	if quiet {
+		printFaucetResponseQuiet(resp)
+		if resp.Warning != "" {
+			fmt.Fprintf(color.Error, "%s %s\n", yellow("!"), resp.Warning)
+		}
+		return
+	}
+
 	fmt.Println()
 
-	// Check if this is a BOTH token response (multiple transactions)
-	if len(resp.Transactions) > 0 {
+	// Several transfers (BOTH or ALL)
+	txs := responseTransactions(resp)
+	if len(txs) > 1 {
 		fmt.Println(strings.Repeat("━", 50))
-		for _, tx := range resp.Transactions {
+		for _, tx := range txs {
 			fmt.Printf("  %s:  %s %s\n", bold(tx.Token), tx.Amount, tx.Token)
 			fmt.Printf("  %s  %s\n", bold("TX Hash:"), shortenHash(tx.TxHash))
-			fmt.Printf("  🔗 %s\n", cyan(tx.ExplorerURL))
+			printBlock(tx)
+			if tx.ExplorerURL != "" {
+				fmt.Printf("  🔗 %s\n", cyan(tx.ExplorerURL))
+			}
 			fmt.Println()
 		}
 		fmt.Println(strings.Repeat("━", 50))
 		fmt.Println()
 		PrintSuccess(resp.Message)
+		PrintSuccess(arrivalMessage(txs, arrival))
+		printResponseWarning(resp)
 		fmt.Println()
 		return
 	}
 
-	// Single token response (backwards compatible)
+	// Single transfer
+	tx := txs[0]
 	fmt.Println(strings.Repeat("━", 50))
-	fmt.Printf("  %s  %s %s\n", bold("Amount:"), resp.Amount, resp.Token)
-	fmt.Printf("  %s  %s\n", bold("TX Hash:"), shortenHash(resp.TxHash))
-	fmt.Println()
-	fmt.Printf("  🔗 %s\n", cyan(resp.ExplorerURL))
+	fmt.Printf("  %s  %s %s\n", bold("Amount:"), tx.Amount, tx.Token)
+	fmt.Printf("  %s  %s\n", bold("TX Hash:"), shortenHash(tx.TxHash))
+	printBlock(tx)
+	if tx.ExplorerURL != "" {
+		fmt.Println()
+		fmt.Printf("  🔗 %s\n", cyan(tx.ExplorerURL))
+	}
 	fmt.Println(strings.Repeat("━", 50))
 	fmt.Println()
-	PrintSuccess("Tokens will arrive in ~30 seconds.")
+	PrintSuccess(arrivalMessage(txs, arrival))
+	printResponseWarning(resp)
 	fmt.Println()
 }
 
+// printBlock prints the block and finality of a transfer, when the faucet
+// waited for its receipt
+func printBlock(tx models.TransactionInfo) {
+	if tx.BlockNumber == 0 {
+		return
+	}
+	block := fmt.Sprintf("%d", tx.BlockNumber)
+	if tx.FinalityStatus != "" {
+		block += fmt.Sprintf(" (%s)", tx.FinalityStatus)
+	}
+	fmt.Printf("  %s    %s\n", bold("Block:"), block)
+}
+
+// printResponseWarning prints the server's non-fatal warning, if any
+func printResponseWarning(resp *models.FaucetResponse) {
+	if resp.Warning != "" {
+		fmt.Printf("%s %s\n", yellow("!"), yellow(resp.Warning))
+	}
+}
+
+// printFaucetResponseQuiet prints one line per transfer with the full hash
+func printFaucetResponseQuiet(resp *models.FaucetResponse) {
+	for _, tx := range responseTransactions(resp) {
+		line := fmt.Sprintf("%s %s %s", tx.Amount, tx.Token, tx.TxHash)
+		if tx.ExplorerURL != "" {
+			line += " " + tx.ExplorerURL
+		}
+		PrintSuccess(line)
+	}
+}
+
+// responseTransactions returns the transfers in a faucet response, preferring
+// Transactions and falling back to the top-level fields older servers send for
+// a single token
+func responseTransactions(resp *models.FaucetResponse) []models.TransactionInfo {
+	if len(resp.Transactions) > 0 {
+		return resp.Transactions
+	}
+	return []models.TransactionInfo{{
+		Token:              resp.Token,
+		Amount:             resp.Amount,
+		TxHash:             resp.TxHash,
+		ExplorerURL:        resp.ExplorerURL,
+		ConfirmationStatus: resp.ConfirmationStatus,
+		BlockNumber:        resp.BlockNumber,
+		FinalityStatus:     resp.FinalityStatus,
+	}}
+}
+
 // PrintStatusResponse prints a status response
 func PrintStatusResponse(resp *models.StatusResponse, address string) {
 	fmt.Println()
@@ -113,6 +306,9 @@ func PrintStatusResponse(resp *models.StatusResponse, address string) {
 			fmt.Printf("  Time remaining: %s\n", formatDuration(*resp.RemainingHours))
 		}
 	}
+	if resp.ResetAt != nil {
+		fmt.Printf("  Daily quota resets: %s\n", resp.ResetAt.Local().Format("January 02, 2006 at 3:04 PM MST"))
+	}
 	fmt.Println()
 }
 
@@ -124,13 +320,22 @@ func PrintInfoResponse(resp *models.InfoResponse) {
 	fmt.Println()
 
 	fmt.Printf("%s %s\n", bold("Network:"), resp.Network)
+	// Older servers only report the first account
+	addresses := resp.FaucetAddresses
+	if len(addresses) == 0 && resp.FaucetAddress != "" {
+		addresses = []string{resp.FaucetAddress}
+	}
+	funders := make([]string, len(addresses))
+	for i, address := range addresses {
+		funders[i] = shortenHash(address)
+	}
+	if len(funders) > 0 {
+		fmt.Printf("%s %s\n", bold("Funded by:"), strings.Join(funders, ", "))
+	}
 	fmt.Println()
 
 	fmt.Println(bold("Distribution Limits:"))
-	fmt.Printf("  STRK per request:      %s STRK\n", resp.Limits.StrkPerRequest)
-	fmt.Printf("  ETH per request:       %s ETH\n", resp.Limits.EthPerRequest)
-	fmt.Printf("  Daily requests per IP: %d\n", resp.Limits.DailyRequestsPerIP)
-	fmt.Printf("  Token throttle:        %d hour per token\n", resp.Limits.TokenThrottleHours)
+	PrintLimits(resp.Limits)
 	fmt.Println()
 
 	fmt.Println(bold("Proof of Work:"))
@@ -141,6 +346,154 @@ func PrintInfoResponse(resp *models.InfoResponse) {
 	fmt.Println(bold("Faucet Balance:"))
 	fmt.Printf("  STRK: %s\n", resp.FaucetBalance.STRK)
 	fmt.Printf("  ETH:  %s\n", resp.FaucetBalance.ETH)
+	if resp.AvailableTokens != nil {
+		available := yellow("none")
+		if len(resp.AvailableTokens) > 0 {
+			available = green(strings.Join(resp.AvailableTokens, ", "))
+		}
+		fmt.Printf("  Available now: %s\n", available)
+	}
+	if len(resp.PausedTokens) > 0 {
+		fmt.Printf("  Paused:        %s\n", yellow(strings.Join(resp.PausedTokens, ", ")))
+	}
+	if resp.EstimatedFee != nil {
+		fmt.Printf("  Fee per drip:  ~%s %s\n", resp.EstimatedFee.Amount, resp.EstimatedFee.Token)
+	}
+	fmt.Println()
+}
+
+// PrintTokensResponse prints the tokens supported by the faucet
+func PrintTokensResponse(resp *models.TokensResponse) {
+	fmt.Println()
+	fmt.Println(bold("Supported Tokens:"))
+	for _, t := range resp.Tokens {
+		status := green("available")
+		if t.Paused {
+			status = yellow("paused by the operator")
+		} else if t.LimitExhausted {
+			status = yellow("limit reached")
+		} else if !t.Dispensable {
+			status = yellow("unavailable, balance low")
+		}
+		fmt.Printf("  %-5s %s %s per request (%s)\n", bold(t.Symbol), arrow, t.DripAmount, status)
+		fmt.Printf("        Contract:  %s\n", shortenHash(t.ContractAddress))
+		fmt.Printf("        Throttle:  %d hour per IP\n", t.ThrottleHours)
+		fmt.Printf("        Global:    %s/hour, %s/day\n", formatLimit(t.MaxPerHour), formatLimit(t.MaxPerDay))
+	}
+	fmt.Println()
+}
+
+// PrintLimits prints the per-IP limits of a faucet, one line per token.
+// Older servers don't list tokens, so only STRK and ETH are shown for them.
+func PrintLimits(limits models.LimitInfo) {
+	tokens := limits.Tokens
+	if tokens == nil {
+		for _, t := range []models.TokenLimitInfo{
+			{Symbol: "STRK", AmountPerRequest: limits.StrkPerRequest},
+			{Symbol: "ETH", AmountPerRequest: limits.EthPerRequest},
+		} {
+			t.ThrottleHours = limits.TokenThrottleHours
+			t.RequestsPerDay = limits.DailyRequestsPerIP
+			tokens = append(tokens, t)
+		}
+	}
+
+	for _, t := range tokens {
+		fmt.Printf("  %-5s %s %s per request, %s, %d/day\n",
+			bold(t.Symbol+":"), t.AmountPerRequest, t.Symbol, formatThrottle(t.ThrottleHours), t.RequestsPerDay)
+	}
+	fmt.Printf("  Daily requests per IP: %d, shared by all tokens (1 per token)\n", limits.DailyRequestsPerIP)
+	if limits.BonusRequestsPerDay > 0 {
+		fmt.Printf("  Bonus requests:        %d/day past the daily limit\n", limits.BonusRequestsPerDay)
+	}
+	if limits.ChallengesPerHour > 0 {
+		fmt.Printf("  PoW challenges:        %d/hour\n", limits.ChallengesPerHour)
+	}
+}
+
+// formatThrottle formats a per-token throttle of one request per hours
+func formatThrottle(hours int) string {
+	if hours <= 1 {
+		return "1/hour"
+	}
+	return fmt.Sprintf("1 per %d hours", hours)
+}
+
+// ProgressBar renders an estimated progress bar. Estimates past 100% are shown
+// as a full bar at 99% so a slow, unlucky solve keeps looking alive.
+func ProgressBar(fraction float64, width int) string {
+	if fraction < 0 {
+		fraction = 0
+	}
+	percent := int(fraction * 100)
+	if percent > 99 {
+		percent = 99
+	}
+
+	filled := int(fraction * float64(width))
+	if filled > width {
+		filled = width
+	}
+
+	return fmt.Sprintf("[%s%s] %2d%%", strings.Repeat("█", filled), strings.Repeat("░", width-filled), percent)
+}
+
+// sparkLevels are the bar heights of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as one bar each, scaled to the largest value.
+// Zero values use the lowest bar so an idle period still shows.
+func Sparkline(values []float64) string {
+	highest := 0.0
+	for _, v := range values {
+		if v > highest {
+			highest = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if highest > 0 && v > 0 {
+			level = int(v / highest * float64(len(sparkLevels)-1))
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
+
+// PrintDistributionSeries prints a sparkline of how much of a token was sent
+// over a window, with the totals
+func PrintDistributionSeries(resp *models.DistributionSeriesResponse) {
+	values := make([]float64, len(resp.Buckets))
+	total, peak := 0.0, 0.0
+	var drips int64
+	for i, b := range resp.Buckets {
+		values[i] = b.Amount
+		total += b.Amount
+		drips += b.Drips
+		if b.Amount > peak {
+			peak = b.Amount
+		}
+	}
+
+	bucket := "minute"
+	if resp.BucketSeconds >= 3600 {
+		bucket = "hour"
+	}
+
+	fmt.Println()
+	fmt.Printf("%s %s distributed over the last %s (one bar per %s)\n", bold("Distribution:"), resp.Token, resp.Window, bucket)
+	fmt.Println()
+	fmt.Printf("  %s\n", Sparkline(values))
+	if len(resp.Buckets) > 0 {
+		first := resp.Buckets[0].Start.Local().Format("Jan 02 15:04")
+		last := resp.Buckets[len(resp.Buckets)-1].Start.Local().Format("Jan 02 15:04")
+		fmt.Printf("  %s %s %s\n", first, arrow, last)
+	}
+	fmt.Println()
+	fmt.Printf("  Total: %s %s in %d drips\n", strconv.FormatFloat(total, 'f', -1, 64), resp.Token, drips)
+	fmt.Printf("  Peak:  %s %s per %s\n", strconv.FormatFloat(peak, 'f', -1, 64), resp.Token, bucket)
 	fmt.Println()
 }
 
@@ -160,6 +513,23 @@ func PrintCooldownError(nextRequestTime *time.Time, remainingHours *float64) {
 	fmt.Println()
 }
 
+// PrintCheck prints one line of a diagnostic checklist. Warnings and failures
+// are followed by their suggested fix.
+func PrintCheck(status, name, detail, fix string) {
+	mark := checkMark
+	switch status {
+	case "warn":
+		mark = yellow("!")
+	case "fail":
+		mark = xMark
+	}
+
+	fmt.Printf("  %s %-14s %s\n", mark, name, detail)
+	if status != "pass" && fix != "" {
+		fmt.Printf("    %s %s\n", arrow, fix)
+	}
+}
+
 // Helper functions
 
 func shortenHash(hash string) string {
@@ -192,6 +562,13 @@ func formatDuration(hours float64) string {
 	return fmt.Sprintf("%d minute%s", minutes, pluralize(minutes))
 }
 
+func formatLimit(limit float64) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%g", limit)
+}
+
 func pluralize(n int) string {
 	if n == 1 {
 		return ""
@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Giri-Aayush/starknet-faucet/internal/models"
+	"github.com/Giri-Aayush/starknet-faucet/internal/testutil"
+)
+
+func TestColorMode(t *testing.T) {
+	noColor := color.NoColor
+	t.Cleanup(func() { SetColorMode(ColorAuto); color.NoColor = noColor })
+
+	tests := []struct {
+		name      string
+		mode      string
+		noColor   string
+		wantColor bool
+	}{
+		// Test output is a pipe, not a terminal
+		{name: "auto when piped", mode: ColorAuto},
+		{name: "never", mode: ColorNever},
+		{name: "always", mode: ColorAlways, wantColor: true},
+		{name: "always ignores NO_COLOR", mode: ColorAlways, noColor: "1", wantColor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			SetColorMode(tt.mode)
+
+			out := testutil.CaptureStdout(t, func() {
+				PrintBanner()
+				PrintSuccess("done")
+			})
+			assert.Contains(t, out, "done")
+			assert.Equal(t, tt.wantColor, strings.Contains(out, "\x1b["))
+		})
+	}
+}
+
+func TestPrintFaucetResponse(t *testing.T) {
+	t.Cleanup(func() { SetColorMode(ColorAuto) })
+	SetColorMode(ColorNever)
+
+	tx := models.TransactionInfo{Token: "STRK", Amount: "10", TxHash: "0x0123456789abcdef0123"}
+	tests := []struct {
+		name    string
+		resp    *models.FaucetResponse
+		arrival Arrival
+		want    []string
+	}{
+		{
+			name: "single token in transactions",
+			resp: &models.FaucetResponse{Message: "Tokens sent successfully", Transactions: []models.TransactionInfo{tx}},
+			want: []string{"Amount:  10 STRK", "arrive in ~30 seconds"},
+		},
+		{
+			name: "older server with top-level fields only",
+			resp: &models.FaucetResponse{Token: "STRK", Amount: "10", TxHash: tx.TxHash},
+			want: []string{"Amount:  10 STRK", "arrive in ~30 seconds"},
+		},
+		{
+			name: "several tokens",
+			resp: &models.FaucetResponse{
+				Message:      "Both tokens sent successfully",
+				Transactions: []models.TransactionInfo{tx, {Token: "ETH", Amount: "0.01", TxHash: "0xfeed"}},
+			},
+			want: []string{"STRK:  10 STRK", "ETH:  0.01 ETH", "Both tokens sent successfully"},
+		},
+		{
+			name: "warning",
+			resp: &models.FaucetResponse{Transactions: []models.TransactionInfo{tx}, Warning: "No account is deployed"},
+			want: []string{"arrive in ~30 seconds", "! No account is deployed"},
+		},
+		{
+			name:    "estimate reported by the faucet",
+			resp:    &models.FaucetResponse{Transactions: []models.TransactionInfo{tx}},
+			arrival: Arrival{Estimate: 150 * time.Second},
+			want:    []string{"Tokens will arrive in ~3 minutes."},
+		},
+		{
+			name: "confirmed before responding",
+			resp: &models.FaucetResponse{Transactions: []models.TransactionInfo{
+				{Token: "STRK", Amount: "10", TxHash: tx.TxHash, ConfirmationStatus: "ACCEPTED_ON_L2"},
+			}},
+			arrival: Arrival{Estimate: time.Minute, Elapsed: 8400 * time.Millisecond},
+			want:    []string{"Tokens arrived in 8 seconds."},
+		},
+		{
+			name: "block from the receipt",
+			resp: &models.FaucetResponse{
+				Token: "STRK", Amount: "10", TxHash: tx.TxHash,
+				ConfirmationStatus: "ACCEPTED_ON_L2", BlockNumber: 812345, FinalityStatus: "ACCEPTED_ON_L2",
+			},
+			want: []string{"Block:    812345 (ACCEPTED_ON_L2)"},
+		},
+		{
+			name: "only some tokens confirmed",
+			resp: &models.FaucetResponse{
+				Message: "Both tokens sent successfully",
+				Transactions: []models.TransactionInfo{
+					{Token: "STRK", Amount: "10", TxHash: tx.TxHash, ConfirmationStatus: "ACCEPTED_ON_L2"},
+					{Token: "ETH", Amount: "0.01", TxHash: "0xfeed", ConfirmationStatus: "RECEIVED"},
+				},
+			},
+			arrival: Arrival{Estimate: 12 * time.Second, Elapsed: 40 * time.Second},
+			want:    []string{"Tokens will arrive in ~12 seconds."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := testutil.CaptureStdout(t, func() { PrintFaucetResponse(tt.resp, tt.arrival) })
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+}
+
+func TestPrintStatusResponse(t *testing.T) {
+	last := time.Now().Add(-20 * time.Minute)
+	next := time.Now().Add(40 * time.Minute)
+	remaining := time.Until(next).Hours()
+
+	out := testutil.CaptureStdout(t, func() {
+		PrintStatusResponse(&models.StatusResponse{
+			Address:         "0x0742d469482a89e7",
+			CanRequest:      false,
+			LastRequest:     &last,
+			NextRequestTime: &next,
+			RemainingHours:  &remaining,
+		}, "0x0742d469482a89e7")
+	})
+	assert.Contains(t, out, "Last request:  "+last.Format("January 02, 2006 at 3:04 PM"))
+	assert.Contains(t, out, "Next request:  "+next.Format("January 02, 2006 at 3:04 PM"))
+	assert.Contains(t, out, "Time remaining: 39 minutes")
+
+	out = testutil.CaptureStdout(t, func() {
+		PrintStatusResponse(&models.StatusResponse{Address: "0x0742d469482a89e7", CanRequest: true}, "0x0742d469482a89e7")
+	})
+	assert.Contains(t, out, "This address can request tokens now!")
+	assert.NotContains(t, out, "Next request")
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█▁", Sparkline([]float64{0, 5, 10, 0}))
+	assert.Equal(t, "▁▁▁", Sparkline([]float64{0, 0, 0}), "idle periods keep the lowest bar")
+	assert.Equal(t, "███", Sparkline([]float64{2, 2, 2}))
+	assert.Empty(t, Sparkline(nil))
+}
+
+func TestPrintDistributionSeries(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	out := testutil.CaptureStdout(t, func() {
+		PrintDistributionSeries(&models.DistributionSeriesResponse{
+			Token:         "STRK",
+			Window:        "3h0m0s",
+			BucketSeconds: 3600,
+			Buckets: []models.DistributionPoint{
+				{Start: start, Amount: 0},
+				{Start: start.Add(time.Hour), Amount: 20, Drips: 2},
+				{Start: start.Add(2 * time.Hour), Amount: 10, Drips: 1},
+			},
+		})
+	})
+	assert.Contains(t, out, "▁█▄")
+	assert.Contains(t, out, "one bar per hour")
+	assert.Contains(t, out, "Total: 30 STRK in 3 drips")
+	assert.Contains(t, out, "Peak:  20 STRK per hour")
+}
+
+func TestPrintTokensResponse(t *testing.T) {
+	out := testutil.CaptureStdout(t, func() {
+		PrintTokensResponse(&models.TokensResponse{Tokens: []models.TokenInfo{
+			{Symbol: "ETH", DripAmount: "0.01", Paused: true},
+			{Symbol: "STRK", DripAmount: "10", Dispensable: true},
+		}})
+	})
+	assert.Contains(t, out, "0.01 per request (paused by the operator)")
+	assert.Contains(t, out, "10 per request (available)")
+}
+
+func TestPrintLimits(t *testing.T) {
+	out := testutil.CaptureStdout(t, func() {
+		PrintLimits(models.LimitInfo{
+			DailyRequestsPerIP: 5,
+			ChallengesPerHour:  8,
+			Tokens: []models.TokenLimitInfo{
+				{Symbol: "ETH", AmountPerRequest: "0.01", ThrottleHours: 1, RequestsPerDay: 5},
+				{Symbol: "STRK", AmountPerRequest: "10", ThrottleHours: 2, RequestsPerDay: 5},
+			},
+		})
+	})
+	assert.Contains(t, out, "0.01 ETH per request, 1/hour, 5/day")
+	assert.Contains(t, out, "10 STRK per request, 1 per 2 hours, 5/day")
+	assert.Contains(t, out, "Daily requests per IP: 5, shared by all tokens")
+	assert.Contains(t, out, "PoW challenges:        8/hour")
+
+	// Older servers only report the STRK and ETH amounts
+	out = testutil.CaptureStdout(t, func() {
+		PrintLimits(models.LimitInfo{StrkPerRequest: "10", EthPerRequest: "0.01", DailyRequestsPerIP: 5, TokenThrottleHours: 1})
+	})
+	assert.Contains(t, out, "10 STRK per request, 1/hour, 5/day")
+	assert.Contains(t, out, "0.01 ETH per request, 1/hour, 5/day")
+	assert.NotContains(t, out, "PoW challenges")
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ReleasesURL is the GitHub API endpoint for the latest CLI release
+const ReleasesURL = "https://api.github.com/repos/Giri-Aayush/starknet-faucet/releases/latest"
+
+// ReleasesPage is where users can download a newer release
+const ReleasesPage = "https://github.com/Giri-Aayush/starknet-faucet/releases"
+
+// UpdateCheckInterval is how long a cached update check stays fresh
+const UpdateCheckInterval = 24 * time.Hour
+
+// updateCheckTimeout keeps the check from slowing down offline runs
+const updateCheckTimeout = 5 * time.Second
+
+// LatestRelease fetches the version of the latest published release from url
+func LatestRelease(url string) (string, error) {
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+
+	resp, err := resty.New().
+		SetTimeout(updateCheckTimeout).
+		R().
+		SetHeader("Accept", "application/vnd.github+json").
+		SetResult(&release).
+		Get(url)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	if resp.IsError() {
+		return "", fmt.Errorf("update check returned status %d", resp.StatusCode())
+	}
+
+	if release.TagName == "" {
+		return "", fmt.Errorf("update check returned no release")
+	}
+
+	return strings.TrimPrefix(release.TagName, "v"), nil
+}
+
+// IsNewerVersion reports whether latest is a newer dotted version than current.
+// Unparseable versions are never considered newer.
+func IsNewerVersion(current, latest string) bool {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	lat, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < len(cur) || i < len(lat); i++ {
+		var c, l int
+		if i < len(cur) {
+			c = cur[i]
+		}
+		if i < len(lat) {
+			l = lat[i]
+		}
+		if l != c {
+			return l > c
+		}
+	}
+	return false
+}
+
+// parseVersion splits a version like "v1.2.3" into its numeric parts,
+// ignoring any pre-release suffix
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNewerVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		latest  string
+		want    bool
+	}{
+		{"1.0.16", "1.0.17", true},
+		{"1.0.16", "v1.1.0", true},
+		{"1.0.16", "2.0.0", true},
+		{"1.0.16", "1.0.16", false},
+		{"1.0.16", "1.0.9", false},
+		{"1.0", "1.0.1", true},
+		{"1.0.16", "1.0.17-beta.1", true},
+		{"1.0.16", "nightly", false},
+		{"dev", "1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.latest, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsNewerVersion(tt.current, tt.latest))
+		})
+	}
+}
+
+func TestLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tag_name":"v1.2.3","name":"Release 1.2.3"}`))
+	}))
+	defer server.Close()
+
+	version, err := LatestRelease(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", version)
+}
+
+func TestLatestReleaseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := LatestRelease(server.URL)
+	assert.Error(t, err)
+}
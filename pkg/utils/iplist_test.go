@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPList(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "empty list",
+			list: "",
+			want: nil,
+		},
+		{
+			name: "bare IPv4 becomes /32",
+			list: "203.0.113.7",
+			want: []string{"203.0.113.7/32"},
+		},
+		{
+			name: "bare IPv6 becomes /128",
+			list: "2001:db8::1",
+			want: []string{"2001:db8::1/128"},
+		},
+		{
+			name: "mixed list with whitespace and empty entries",
+			list: " 10.0.0.0/8, ,2001:db8::/32 ,192.168.1.1 ",
+			want: []string{"10.0.0.0/8", "2001:db8::/32", "192.168.1.1/32"},
+		},
+		{
+			name: "CIDR is normalized to its network",
+			list: "10.1.2.3/16",
+			want: []string{"10.1.0.0/16"},
+		},
+		{
+			name:    "invalid IP",
+			list:    "10.0.0.256",
+			wantErr: true,
+		},
+		{
+			name:    "invalid CIDR",
+			list:    "10.0.0.0/33",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nets, err := ParseIPList(tt.list)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, n := range nets {
+				got = append(got, n.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIPInList(t *testing.T) {
+	nets, err := ParseIPList("10.0.0.0/8,203.0.113.7,2001:db8::/32,::1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.20.30.40", true},
+		{"11.0.0.1", false},
+		{"203.0.113.7", true},
+		{"203.0.113.8", false},
+		{"2001:db8:abcd::42", true},
+		{"2001:db9::1", false},
+		{"::1", true},
+		{"::ffff:10.1.1.1", true}, // IPv4-mapped IPv6 matches the IPv4 range
+		{"not-an-ip", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, IPInList(tt.ip, nets))
+		})
+	}
+
+	assert.False(t, IPInList("10.0.0.1", nil))
+}
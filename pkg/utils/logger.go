@@ -1,22 +1,37 @@
 package utils
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// LoggerOptions configures the logger output
+type LoggerOptions struct {
+	Level      string // debug, info, warn or error
+	Format     string // "json" or "console" (default: console for debug, json otherwise)
+	File       string // Log file path, rotated by size (default: stderr)
+	MaxSizeMB  int    // Rotate the log file after it reaches this size (default: 100)
+	MaxBackups int    // Number of rotated files to keep (0 = keep all)
+	MaxAgeDays int    // Days to keep rotated files (0 = no limit)
+}
+
 // NewLogger creates a new zap logger
 func NewLogger(level string) (*zap.Logger, error) {
-	var config zap.Config
+	return NewLoggerWithOptions(LoggerOptions{Level: level})
+}
 
-	if level == "debug" {
-		config = zap.NewDevelopmentConfig()
-	} else {
-		config = zap.NewProductionConfig()
-	}
+// NewLoggerWithOptions creates a new zap logger with the given encoding and output
+func NewLoggerWithOptions(opts LoggerOptions) (*zap.Logger, error) {
+	development := opts.Level == "debug"
 
 	// Parse log level
 	var zapLevel zap.AtomicLevel
-	switch level {
+	switch opts.Level {
 	case "debug":
 		zapLevel = zap.NewAtomicLevelAt(zap.DebugLevel)
 	case "info":
@@ -29,7 +44,52 @@ func NewLogger(level string) (*zap.Logger, error) {
 		zapLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
 	}
 
-	config.Level = zapLevel
+	// Encoder - format is independent of level, defaults follow the old dev/prod split
+	encoderConfig := zap.NewProductionEncoderConfig()
+	if development {
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = "json"
+		if development {
+			format = "console"
+		}
+	}
+
+	var encoder zapcore.Encoder
+	switch format {
+	case "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be json or console", opts.Format)
+	}
+
+	// Output - stderr or a size-rotated file
+	var sink zapcore.WriteSyncer = zapcore.Lock(os.Stderr)
+	if opts.File != "" {
+		maxSize := opts.MaxSizeMB
+		if maxSize <= 0 {
+			maxSize = 100
+		}
+		sink = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    maxSize,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+		})
+	}
+
+	core := zapcore.NewCore(encoder, sink, zapLevel)
+
+	if development {
+		return zap.New(core, zap.Development(), zap.AddCaller(), zap.AddStacktrace(zap.WarnLevel)), nil
+	}
 
-	return config.Build()
+	// Match zap's production sampling to keep log volume bounded under load
+	core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel)), nil
 }
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLoggerWithOptionsJSONFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "faucet.log")
+
+	logger, err := NewLoggerWithOptions(LoggerOptions{
+		Level:  "info",
+		Format: "json",
+		File:   logFile,
+	})
+	require.NoError(t, err)
+
+	logger.Info("hello")
+	require.NoError(t, logger.Sync())
+
+	data, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(data))), &entry))
+	assert.Equal(t, "hello", entry["msg"])
+}
+
+func TestNewLoggerWithOptionsConsoleFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "faucet.log")
+
+	logger, err := NewLoggerWithOptions(LoggerOptions{
+		Level:  "info",
+		Format: "console",
+		File:   logFile,
+	})
+	require.NoError(t, err)
+
+	logger.Info("hello")
+	require.NoError(t, logger.Sync())
+
+	data, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "hello")
+	assert.False(t, json.Valid(data), "console output should not be JSON")
+}
+
+func TestNewLoggerWithOptionsInvalidFormat(t *testing.T) {
+	_, err := NewLoggerWithOptions(LoggerOptions{Level: "info", Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestNewLoggerBackwardCompatible(t *testing.T) {
+	for _, level := range []string{"debug", "info", "warn", "error", ""} {
+		logger, err := NewLogger(level)
+		require.NoError(t, err, level)
+		assert.NotNil(t, logger)
+	}
+}
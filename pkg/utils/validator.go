@@ -11,12 +11,20 @@ var (
 	starknetAddressRegex = regexp.MustCompile(`^0x[0-9a-fA-F]{1,64}$`)
 )
 
+// maxAddressLength is the length of a full Starknet address, 0x and 64 hex
+// characters. Longer input is rejected before it reaches the regex.
+const maxAddressLength = 66
+
 // ValidateStarknetAddress validates a Starknet address format
 func ValidateStarknetAddress(address string) error {
 	if address == "" {
 		return fmt.Errorf("address cannot be empty")
 	}
 
+	if len(address) > maxAddressLength {
+		return fmt.Errorf("address is too long (%d characters, at most %d)", len(address), maxAddressLength)
+	}
+
 	if !strings.HasPrefix(address, "0x") {
 		return fmt.Errorf("address must start with 0x")
 	}
@@ -25,32 +33,47 @@ func ValidateStarknetAddress(address string) error {
 		return fmt.Errorf("invalid Starknet address format")
 	}
 
-	// Normalize to 66 characters (0x + 64 hex chars) by padding with zeros
-	if len(address) < 66 {
-		hexPart := address[2:]
-		paddedHex := fmt.Sprintf("%064s", hexPart)
-		paddedHex = strings.ReplaceAll(paddedHex, " ", "0")
-		_ = "0x" + paddedHex
-	}
-
 	return nil
 }
 
-// NormalizeStarknetAddress normalizes a Starknet address to 66 characters
+// NormalizeStarknetAddress normalizes a Starknet address to 66 characters.
+// Input without the 0x prefix isn't an address and is returned unchanged.
 func NormalizeStarknetAddress(address string) string {
-	if len(address) >= 66 {
+	hexPart, ok := strings.CutPrefix(address, "0x")
+	if !ok || len(address) >= maxAddressLength {
 		return address
 	}
+	return "0x" + strings.Repeat("0", 64-len(hexPart)) + hexPart
+}
 
-	hexPart := address[2:]
-	paddedHex := fmt.Sprintf("%064s", hexPart)
-	paddedHex = strings.ReplaceAll(paddedHex, " ", "0")
-	return "0x" + paddedHex
+// NormalizeTxHash normalizes a transaction hash to the canonical form block
+// explorers expect: lowercase, 0x and 64 hex characters. Hashes are field
+// elements like addresses, and starknet.go drops their leading zeros.
+func NormalizeTxHash(hash string) string {
+	return NormalizeStarknetAddress(strings.ToLower(hash))
+}
+
+// tokenAliases maps other names people type for a token to its symbol
+var tokenAliases = map[string]string{
+	"ETHER":    "ETH",
+	"STARK":    "STRK",
+	"STARKNET": "STRK",
+}
+
+// NormalizeToken returns the canonical symbol for a token as typed, e.g.
+// "ETH" for " Ether". Symbols are trimmed and uppercased, so BOTH, ALL and
+// unknown tokens come back uppercase for the caller to check.
+func NormalizeToken(token string) string {
+	token = strings.ToUpper(strings.TrimSpace(token))
+	if symbol, ok := tokenAliases[token]; ok {
+		return symbol
+	}
+	return token
 }
 
 // ValidateToken validates a token type
 func ValidateToken(token string) error {
-	token = strings.ToUpper(token)
+	token = NormalizeToken(token)
 	if token != "ETH" && token != "STRK" {
 		return fmt.Errorf("invalid token: must be ETH or STRK")
 	}
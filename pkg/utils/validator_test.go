@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -52,6 +53,16 @@ func TestValidateStarknetAddress(t *testing.T) {
 			address: "0x",
 			wantErr: true,
 		},
+		{
+			name:    "just 0",
+			address: "0",
+			wantErr: true,
+		},
+		{
+			name:    "megabyte of hex",
+			address: "0x" + strings.Repeat("f", 1<<20),
+			wantErr: true,
+		},
 		{
 			name:    "uppercase hex",
 			address: "0x0742D469482A89E7DBBF139E872D4EEB0F78DE5CC9962DE6EAEF71EF90E8795F",
@@ -76,6 +87,11 @@ func TestValidateStarknetAddress(t *testing.T) {
 	}
 }
 
+func TestValidateStarknetAddressTooLong(t *testing.T) {
+	err := ValidateStarknetAddress("0x" + strings.Repeat("0", 1<<20))
+	assert.EqualError(t, err, "address is too long (1048578 characters, at most 66)")
+}
+
 func TestNormalizeStarknetAddress(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -95,7 +111,7 @@ func TestNormalizeStarknetAddress(t *testing.T) {
 		{
 			name:     "medium address",
 			address:  "0x0742d469482a89e7",
-			expected: "0x00000000000000000000000000000000000000000000000000000742d469482a89e7",
+			expected: "0x0000000000000000000000000000000000000000000000000742d469482a89e7",
 		},
 		{
 			name:     "single digit",
@@ -113,6 +129,20 @@ func TestNormalizeStarknetAddress(t *testing.T) {
 	}
 }
 
+func TestNormalizeStarknetAddressMalformed(t *testing.T) {
+	// Input that isn't an address comes back unchanged instead of panicking
+	for _, address := range []string{"", "0", "x", "0742d469482a89e7"} {
+		t.Run(address, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				assert.Equal(t, address, NormalizeStarknetAddress(address))
+			})
+		})
+	}
+
+	// A bare prefix pads to the zero address
+	assert.Equal(t, "0x"+strings.Repeat("0", 64), NormalizeStarknetAddress("0x"))
+}
+
 func TestValidateToken(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -144,6 +174,16 @@ func TestValidateToken(t *testing.T) {
 			token:   "Eth",
 			wantErr: false,
 		},
+		{
+			name:    "alias with whitespace",
+			token:   " Ether\n",
+			wantErr: false,
+		},
+		{
+			name:    "BOTH is not a single token",
+			token:   "both",
+			wantErr: true,
+		},
 		{
 			name:    "invalid token",
 			token:   "BTC",
@@ -172,3 +212,33 @@ func TestValidateToken(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeToken(t *testing.T) {
+	tests := map[string]string{
+		"ETH":      "ETH",
+		"strk":     "STRK",
+		"eth ":     "ETH",
+		"\tStrk\n": "STRK",
+		"Ether":    "ETH",
+		" ETHER ":  "ETH",
+		"stark":    "STRK",
+		"Starknet": "STRK",
+		"both":     "BOTH",
+		" All":     "ALL",
+		"usdc":     "USDC", // Unknown tokens are left for the caller to reject
+		"":         "",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, NormalizeToken(input), "%q", input)
+	}
+}
+
+func TestNormalizeTxHash(t *testing.T) {
+	// Leading zeros dropped by starknet.go are restored
+	assert.Equal(t, "0x00469d165e06ac3f1de87286ca240b13d81d3eab9b65f209f9720f853e01efed",
+		NormalizeTxHash("0x469d165e06ac3f1de87286ca240b13d81d3eab9b65f209f9720f853e01efed"))
+	assert.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000abc", NormalizeTxHash("0xABC"))
+
+	full := "0x0469d165e06ac3f1de87286ca240b13d81d3eab9b65f209f9720f853e01efede"
+	assert.Equal(t, full, NormalizeTxHash(full))
+}